@@ -0,0 +1,79 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InstallationProfileSpec defines the stages of an installation type
+type InstallationProfileSpec struct {
+	// Type is the installation type the profile applies to. The profile
+	// replaces the built-in stages of that installation type
+	// +kubebuilder:validation:Enum=workshop;managed;managed-api;self-managed
+	Type string `json:"type"`
+
+	// InstallStages group the products in the status of the installation.
+	// The first stage must be the bootstrap stage, which completes before
+	// any product is installed. The products are reconciled once the
+	// products they depend on have completed
+	InstallStages []InstallationProfileStage `json:"installStages"`
+
+	// UninstallStages are processed in order when the installation is
+	// deleted. Every installed product must be part of an uninstall stage
+	UninstallStages []InstallationProfileStage `json:"uninstallStages"`
+}
+
+type InstallationProfileStage struct {
+	Name StageName `json:"name"`
+	// +optional
+	Products []ProductName `json:"products,omitempty"`
+}
+
+// InstallationProfileStatus defines the observed state of InstallationProfile
+type InstallationProfileStatus struct {
+	// Valid is set when the operator loads the profile. Profiles that fail
+	// validation are ignored and the built-in stages are used instead
+	Valid   bool   `json:"valid"`
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// InstallationProfile is the Schema for the installationprofiles API.
+// Profiles are loaded from the operator namespace when the operator starts
+type InstallationProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   InstallationProfileSpec   `json:"spec,omitempty"`
+	Status InstallationProfileStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// InstallationProfileList contains a list of InstallationProfile
+type InstallationProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []InstallationProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&InstallationProfile{}, &InstallationProfileList{})
+}
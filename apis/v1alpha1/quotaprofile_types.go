@@ -0,0 +1,300 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// quotaProfileComponents are the workloads configured by a quota, by the
+// names used in the quota config
+var quotaProfileComponents = map[string]bool{
+	"backend_listener":   true,
+	"backend_worker":     true,
+	"apicast_production": true,
+	"apicast_staging":    true,
+	"rhssouser":          true,
+	"ratelimit":          true,
+	"grafana":            true,
+}
+
+// QuotaProfileSpec defines a quota tier. The tier is selected by setting the
+// quota parameter of the addon to the name of the QuotaProfile
+type QuotaProfileSpec struct {
+	// RateLimit of the requests to the APIs of the tier
+	RateLimit QuotaProfileRateLimit `json:"rateLimit"`
+
+	// Resources of the workloads of the tier, by component: backend_listener,
+	// backend_worker, apicast_production, apicast_staging, rhssouser,
+	// ratelimit and grafana. Every component must be listed
+	Resources map[string]QuotaProfileResources `json:"resources"`
+}
+
+// QuotaProfileRateLimit is the rate limit of a quota tier
+type QuotaProfileRateLimit struct {
+	// +kubebuilder:validation:Enum=second;minute;hour;day
+	Unit string `json:"unit"`
+
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerUnit uint32 `json:"requestsPerUnit"`
+
+	// ShadowMode counts the requests over the rate limit without rejecting
+	// them, to validate the tier before enforcing it. The default rate limit
+	// image doesn't support it: the rate limit service isn't reconciled
+	// while a tier in shadow mode is applied, unless the RELATED_IMAGE_RATELIMIT
+	// environment variable of the operator is set to an image that supports
+	// the shadow_mode key of the envoyproxy/ratelimit config
+	// +optional
+	ShadowMode bool `json:"shadowMode,omitempty"`
+
+	// Windows are limits of additional units, enforced together with the
+	// limit per unit, e.g. a burst limit per second on top of a sustained
+	// limit per day. Each unit can only be limited once
+	// +optional
+	Windows []QuotaProfileRateLimitWindow `json:"windows,omitempty"`
+
+	// AlertLimits override the rate limit alerts of the same name while the
+	// tier is applied
+	// +optional
+	AlertLimits map[string]QuotaProfileAlertLimit `json:"alertLimits,omitempty"`
+}
+
+// QuotaProfileRateLimitWindow is a limit of the requests per unit
+type QuotaProfileRateLimitWindow struct {
+	// +kubebuilder:validation:Enum=second;minute;hour;day
+	Unit string `json:"unit"`
+
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerUnit uint32 `json:"requestsPerUnit"`
+}
+
+// QuotaProfileAlertLimit is a rate limit alert of a quota tier
+type QuotaProfileAlertLimit struct {
+	// +kubebuilder:validation:Enum=Threshold;Spike
+	Type string `json:"type"`
+	// Level is the severity of the alert, e.g. warning
+	Level    string `json:"level"`
+	RuleName string `json:"ruleName"`
+	// Period of the rate of requests compared to the rate limit, e.g. 4h
+	Period string `json:"period"`
+
+	// Threshold of the rate of requests, as a percentage of the rate limit.
+	// Required for Threshold alerts
+	// +optional
+	Threshold *QuotaProfileAlertThreshold `json:"threshold,omitempty"`
+}
+
+// QuotaProfileAlertThreshold is the range of the rate of requests that
+// fires a Threshold alert, e.g. from 80% to 90%
+type QuotaProfileAlertThreshold struct {
+	MinRate string `json:"minRate"`
+	// +optional
+	MaxRate *string `json:"maxRate,omitempty"`
+}
+
+// quotaProfileAutoscaledComponents are the components that can be
+// autoscaled
+var quotaProfileAutoscaledComponents = map[string]bool{
+	"backend_listener":   true,
+	"backend_worker":     true,
+	"apicast_production": true,
+	"rhssouser":          true,
+	"ratelimit":          true,
+}
+
+// QuotaProfileResources are the replicas and resources of a workload
+type QuotaProfileResources struct {
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Autoscaling replaces the static replicas with a
+	// HorizontalPodAutoscaler. Supported by backend_listener,
+	// backend_worker, apicast_production, rhssouser and ratelimit
+	// +optional
+	Autoscaling *QuotaProfileAutoscaling `json:"autoscaling,omitempty"`
+}
+
+// QuotaProfileAutoscaling is the autoscaling of a workload
+type QuotaProfileAutoscaling struct {
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas"`
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilization is the average CPU utilization of the pods, as a
+	// percentage of their requests
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	TargetCPUUtilization int32 `json:"targetCPUUtilization"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuotaProfile is the Schema for the quotaprofiles API. A QuotaProfile in
+// the namespace of the operator defines a quota tier in addition to the
+// tiers shipped with the operator, and takes precedence over a shipped tier
+// of the same name
+type QuotaProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec QuotaProfileSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// QuotaProfileList contains a list of QuotaProfile
+type QuotaProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []QuotaProfile `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&QuotaProfile{}, &QuotaProfileList{})
+}
+
+func (p *QuotaProfile) ValidateCreate() error {
+	return p.Spec.Validate()
+}
+
+func (p *QuotaProfile) ValidateUpdate(old runtime.Object) error {
+	return p.Spec.Validate()
+}
+
+func (p *QuotaProfile) ValidateDelete() error {
+	return nil
+}
+
+// Validate returns an error if the tier can't be applied
+func (s QuotaProfileSpec) Validate() error {
+	switch s.RateLimit.Unit {
+	case "second", "minute", "hour", "day":
+	default:
+		return fmt.Errorf("rateLimit.unit: unsupported unit %q, must be second, minute, hour or day", s.RateLimit.Unit)
+	}
+	if s.RateLimit.RequestsPerUnit == 0 {
+		return fmt.Errorf("rateLimit.requestsPerUnit: must be greater than 0")
+	}
+	units := map[string]bool{s.RateLimit.Unit: true}
+	for i, window := range s.RateLimit.Windows {
+		switch window.Unit {
+		case "second", "minute", "hour", "day":
+		default:
+			return fmt.Errorf("rateLimit.windows[%d].unit: unsupported unit %q, must be second, minute, hour or day", i, window.Unit)
+		}
+		if window.RequestsPerUnit == 0 {
+			return fmt.Errorf("rateLimit.windows[%d].requestsPerUnit: must be greater than 0", i)
+		}
+		if units[window.Unit] {
+			return fmt.Errorf("rateLimit.windows[%d].unit: %s is already limited", i, window.Unit)
+		}
+		units[window.Unit] = true
+	}
+
+	alertNames := make([]string, 0, len(s.RateLimit.AlertLimits))
+	for name := range s.RateLimit.AlertLimits {
+		alertNames = append(alertNames, name)
+	}
+	sort.Strings(alertNames)
+	for _, name := range alertNames {
+		if err := s.RateLimit.AlertLimits[name].validate(fmt.Sprintf("rateLimit.alertLimits[%s]", name)); err != nil {
+			return err
+		}
+	}
+
+	missing := []string{}
+	for component := range quotaProfileComponents {
+		if _, ok := s.Resources[component]; !ok {
+			missing = append(missing, component)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("resources: missing components %v", missing)
+	}
+
+	components := make([]string, 0, len(s.Resources))
+	for component := range s.Resources {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	for _, component := range components {
+		field := fmt.Sprintf("resources[%s]", component)
+		if !quotaProfileComponents[component] {
+			return fmt.Errorf("%s: unknown component", field)
+		}
+		if err := s.Resources[component].validate(field); err != nil {
+			return err
+		}
+		if s.Resources[component].Autoscaling != nil && !quotaProfileAutoscaledComponents[component] {
+			return fmt.Errorf("%s.autoscaling: not supported by the component", field)
+		}
+	}
+
+	return nil
+}
+
+func (a QuotaProfileAlertLimit) validate(field string) error {
+	switch a.Type {
+	case "Threshold":
+		if a.Threshold == nil || a.Threshold.MinRate == "" {
+			return fmt.Errorf("%s.threshold.minRate: required for Threshold alerts", field)
+		}
+	case "Spike":
+	default:
+		return fmt.Errorf("%s.type: unsupported type %q, must be Threshold or Spike", field, a.Type)
+	}
+	if a.RuleName == "" {
+		return fmt.Errorf("%s.ruleName: required", field)
+	}
+	if a.Period == "" {
+		return fmt.Errorf("%s.period: required", field)
+	}
+	return nil
+}
+
+func (r QuotaProfileResources) validate(field string) error {
+	if r.Replicas < 0 {
+		return fmt.Errorf("%s.replicas: must be 0 or greater", field)
+	}
+	if a := r.Autoscaling; a != nil {
+		if a.MinReplicas < 1 || a.MaxReplicas < a.MinReplicas {
+			return fmt.Errorf("%s.autoscaling: minReplicas must be 1 or greater and maxReplicas at least minReplicas", field)
+		}
+		if a.TargetCPUUtilization < 1 || a.TargetCPUUtilization > 100 {
+			return fmt.Errorf("%s.autoscaling.targetCPUUtilization: must be between 1 and 100", field)
+		}
+		if _, ok := r.Resources.Requests[corev1.ResourceCPU]; !ok {
+			return fmt.Errorf("%s.autoscaling: the CPU utilization requires a CPU request", field)
+		}
+	}
+	for name, request := range r.Resources.Requests {
+		limit, ok := r.Resources.Limits[name]
+		if ok && request.Cmp(limit) > 0 {
+			return fmt.Errorf("%s.resources: the %s request %s is greater than the limit %s", field, name, request.String(), limit.String())
+		}
+	}
+	return nil
+}
@@ -40,6 +40,8 @@ var (
 	PhaseInProgress StatusPhase = "in progress"
 	PhaseCompleted  StatusPhase = "completed"
 	PhaseFailed     StatusPhase = "failed"
+	PhaseDisabled   StatusPhase = "disabled"
+	PhaseBlocked    StatusPhase = "blocked"
 
 	InstallationTypeWorkshop    InstallationType = "workshop"
 	InstallationTypeManaged     InstallationType = "managed"
@@ -133,6 +135,9 @@ var (
 	EventInstallationCompleted string = "InstallationCompleted"
 	EventPreflightCheckPassed  string = "PreflightCheckPassed"
 	EventUpgradeApproved       string = "UpgradeApproved"
+	EventPreUpgradeBackup      string = "PreUpgradeBackup"
+	EventQuotaPreview          string = "QuotaPreview"
+	EventQuotaDowngrade        string = "QuotaDowngrade"
 
 	DefaultOriginPullSecretName      = "pull-secret"
 	DefaultOriginPullSecretNamespace = "openshift-config"
@@ -141,6 +146,28 @@ var (
 	EnvKeyQuota         = "QUOTA"
 )
 
+// Condition types set on the RHMI status. The products conditions are
+// ConditionAvailable and ConditionDegraded
+const (
+	ConditionAvailable    = "Available"
+	ConditionProgressing  = "Progressing"
+	ConditionDegraded     = "Degraded"
+	ConditionUpgrading    = "Upgrading"
+	ConditionUninstalling = "Uninstalling"
+	// ConditionQuotaChangeHeld is true while a quota change is held because
+	// the cluster doesn't have the capacity for it
+	ConditionQuotaChangeHeld = "QuotaChangeHeld"
+)
+
+// requiredProducts are the products that other products depend on, and can't
+// be disabled through the spec
+var requiredProducts = []ProductName{
+	ProductCloudResources,
+	ProductMonitoring,
+	ProductMonitoringSpec,
+	ProductRHSSO,
+}
+
 // RHMISpec defines the desired state of RHMI
 type RHMISpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -191,6 +218,66 @@ type RHMISpec struct {
 	//
 	// url
 	DeadMansSnitchSecret string `json:"deadMansSnitchSecret,omitempty"`
+
+	// Products allows enabling or disabling individual products
+	// of the installation type. Products that are not listed are
+	// enabled. A product that is disabled after it was installed
+	// is uninstalled. The products that others depend on
+	// (cloud-resources, middleware-monitoring, monitoring-spec
+	// and rhsso) can't be disabled, nor can a product while
+	// enabled products of the installation type depend on it.
+	Products map[ProductName]ProductSpec `json:"products,omitempty"`
+
+	// DryRun is a flag that, when set to true, stops the operator
+	// from applying any change to the cluster. Instead, on every
+	// reconcile, the changes that the reconcilers of every stage
+	// would perform are computed and published as a plan in the
+	// rhmi-dry-run-plan ConfigMap of the installation namespace.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ClusterStorageBackup configures the backups of the in-cluster
+	// postgres and redis instances, used when useClusterStorage is
+	// true. Their dumps are written to a PersistentVolumeClaim of the
+	// installation namespace by default
+	// +optional
+	ClusterStorageBackup *ClusterStorageBackupSpec `json:"clusterStorageBackup,omitempty"`
+}
+
+// ClusterStorageBackupSpec defines where the dumps of the in-cluster postgres
+// and redis instances are written, and how many of them are kept
+type ClusterStorageBackupSpec struct {
+	// PVCName is the name of a PersistentVolumeClaim in the
+	// installation namespace the dumps are written to. When not set,
+	// a claim named <instance>-backups is created for each instance
+	// +optional
+	PVCName string `json:"pvcName,omitempty"`
+
+	// S3Secret is the name of a secret in the installation namespace
+	// containing the details of an S3-compatible bucket. When set, the
+	// dumps are uploaded to the bucket instead of a claim. The
+	// backups-s3-credentials secret can be used. The secret must
+	// contain the following fields:
+	//
+	// credentialKeyID
+	// credentialSecretKey
+	// bucketName
+	// bucketRegion
+	// endpoint (optional, for buckets outside of AWS)
+	// +optional
+	S3Secret string `json:"s3Secret,omitempty"`
+
+	// Retention is the number of dumps kept for each instance. The
+	// older dumps are removed after each backup. Defaults to 3, all
+	// the dumps are kept when 0
+	// +optional
+	Retention *int `json:"retention,omitempty"`
+}
+
+type ProductSpec struct {
+	// Enabled defaults to true when not set
+	// +optional
+	// +nullable
+	Enabled *bool `json:"enabled,omitempty"`
 }
 
 type PullSecretSpec struct {
@@ -218,6 +305,135 @@ type RHMIStatus struct {
 	ToVersion          string                        `json:"toVersion,omitempty"`
 	Quota              string                        `json:"quota,omitempty"`
 	ToQuota            string                        `json:"toQuota,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// DeferredActions are the disruptive actions waiting for the next
+	// maintenance window
+	// +optional
+	DeferredActions []DeferredAction `json:"deferredActions,omitempty"`
+	// PreUpgradeBackups are the outcomes of the backups taken before the
+	// products are upgraded
+	// +optional
+	PreUpgradeBackups []PreUpgradeBackupStatus `json:"preUpgradeBackups,omitempty"`
+	// QuotaPreview is the impact of the last quota change, computed before
+	// the change is applied
+	// +optional
+	QuotaPreview *QuotaPreview `json:"quotaPreview,omitempty"`
+	// QuotaDowngrade is the progress of the last change to a quota with a
+	// smaller rate limit
+	// +optional
+	QuotaDowngrade *QuotaDowngradeStatus `json:"quotaDowngrade,omitempty"`
+}
+
+// QuotaDowngradeStatus is the progress of the change to a quota with a
+// smaller rate limit
+type QuotaDowngradeStatus struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Phase is "blocked" while the peak usage of the APIs exceeds a window
+	// of the rate limit of the smaller quota, or can't be read, and the
+	// downgrade isn't acknowledged, "in progress" while the workloads are
+	// scaled down in steps, then "completed"
+	Phase StatusPhase `json:"phase"`
+	// PeakRequestsPerMinute is the highest number of requests to the APIs
+	// in a minute before the downgrade
+	PeakRequestsPerMinute int64 `json:"peakRequestsPerMinute"`
+	// LimitPerMinute is the sustained rate limit of the smaller quota, the
+	// lowest rate of its windows in requests per minute
+	LimitPerMinute int64 `json:"limitPerMinute"`
+	// Acknowledged is true when the downgrade was applied in spite of the
+	// usage, through the acknowledgement annotation
+	// +optional
+	Acknowledged bool `json:"acknowledged,omitempty"`
+	// StartTime is the time the scale down started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// Message is the reason the downgrade is blocked or acknowledged: the
+	// windows whose limit is exceeded, or the error reading the usage of the
+	// APIs
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// QuotaPreview is the impact of changing the quota of the installation
+type QuotaPreview struct {
+	From        string      `json:"from,omitempty"`
+	To          string      `json:"to"`
+	GeneratedAt metav1.Time `json:"generatedAt"`
+	// Components are the workloads whose replicas or resource requests
+	// change
+	// +optional
+	Components []QuotaComponentChange `json:"components,omitempty"`
+	// RateLimit is the change of the rate limit, if any
+	// +optional
+	RateLimit *QuotaRateLimitChange `json:"rateLimit,omitempty"`
+	Capacity  QuotaCapacity         `json:"capacity"`
+}
+
+// QuotaComponentChange is the change of the replicas and the resource
+// requests of each pod of a workload
+type QuotaComponentChange struct {
+	Product      ProductName `json:"product"`
+	Name         string      `json:"name"`
+	FromReplicas int32       `json:"fromReplicas"`
+	ToReplicas   int32       `json:"toReplicas"`
+	FromCPU      string      `json:"fromCPU,omitempty"`
+	ToCPU        string      `json:"toCPU,omitempty"`
+	FromMemory   string      `json:"fromMemory,omitempty"`
+	ToMemory     string      `json:"toMemory,omitempty"`
+}
+
+// QuotaRateLimitChange is the change of the rate limit, as
+// <requests>/<unit>
+type QuotaRateLimitChange struct {
+	From string `json:"from,omitempty"`
+	To   string `json:"to"`
+}
+
+// QuotaCapacity compares the resources the quota change requests with the
+// resources available in the schedulable nodes of the cluster
+type QuotaCapacity struct {
+	AllocatableCPU    string `json:"allocatableCPU"`
+	AllocatableMemory string `json:"allocatableMemory"`
+	RequestedCPU      string `json:"requestedCPU"`
+	RequestedMemory   string `json:"requestedMemory"`
+	// RequiredCPU and RequiredMemory are the requests added by the change,
+	// negative when the change frees resources
+	RequiredCPU    string `json:"requiredCPU"`
+	RequiredMemory string `json:"requiredMemory"`
+	// Fits is true when the cluster can schedule the pods of the change
+	Fits    bool   `json:"fits"`
+	Message string `json:"message,omitempty"`
+}
+
+// PreUpgradeBackupStatus is the outcome of the backup taken before the
+// upgrade of a product
+type PreUpgradeBackupStatus struct {
+	Product ProductName `json:"product"`
+	// InstallPlan is the install plan of the upgrade
+	InstallPlan string      `json:"installPlan"`
+	Phase       StatusPhase `json:"phase"`
+	// Attempts is the number of times the backup was attempted
+	Attempts    int          `json:"attempts,omitempty"`
+	LastAttempt *metav1.Time `json:"lastAttempt,omitempty"`
+	// Backup is the name of the backup of the last attempt, the snapshot or
+	// Job of each component is named <component>-<backup>
+	Backup string `json:"backup,omitempty"`
+	// Message describes the outcome, or the error of the last attempt
+	Message string `json:"message,omitempty"`
+}
+
+// DeferredAction is a disruptive action, such as restarting the pods of a
+// product, that was postponed as it's outside of the maintenance window
+type DeferredAction struct {
+	// Product is empty for the actions that apply to the whole installation
+	// +optional
+	Product ProductName `json:"product,omitempty"`
+	Action  string      `json:"action"`
+	// DeferredSince is the first time the action was deferred
+	DeferredSince metav1.Time `json:"deferredSince"`
 }
 
 type RHMIStageStatus struct {
@@ -234,6 +450,14 @@ type RHMIProductStatus struct {
 	Type            string          `json:"type,omitempty"`
 	Mobile          bool            `json:"mobile,omitempty"`
 	Status          StatusPhase     `json:"status"`
+	// BlockedBy lists the dependencies the product is waiting for before
+	// it's reconciled
+	// +optional
+	BlockedBy []ProductName `json:"blockedBy,omitempty"`
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -259,6 +483,22 @@ func (i *RHMI) GetProductStatusObject(product ProductName) *RHMIProductStatus {
 	}
 }
 
+// IsProductEnabled returns false if the product has been disabled in the
+// spec. Products required by other products are always enabled
+func (i *RHMI) IsProductEnabled(product ProductName) bool {
+	for _, required := range requiredProducts {
+		if product == required {
+			return true
+		}
+	}
+
+	productSpec, ok := i.Spec.Products[product]
+	if !ok || productSpec.Enabled == nil {
+		return true
+	}
+	return *productSpec.Enabled
+}
+
 func (i *RHMI) GetPullSecretSpec() *PullSecretSpec {
 	if i.Spec.PullSecret.Name != "" && i.Spec.PullSecret.Namespace != "" {
 		return &(i.Spec.PullSecret)
@@ -0,0 +1,142 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RHMIBackupSpec defines the products to back up
+type RHMIBackupSpec struct {
+	// Products to back up. Every product of the installation is backed up
+	// when empty
+	// +optional
+	Products []ProductName `json:"products,omitempty"`
+
+	// Timeout of the backup of each component, e.g. "30m". Defaults to 20m.
+	// The verification of each backup has the same timeout
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Verification restores the snapshots and dumps into throwaway
+	// instances of the cloud resource operator once they complete, and runs
+	// a sanity check against them. The backups of Jobs aren't verified
+	// +optional
+	Verification *RHMIBackupVerification `json:"verification,omitempty"`
+}
+
+// RHMIBackupVerification configures the verification of the backups
+type RHMIBackupVerification struct {
+	// Checks overrides the sanity checks run against the restored
+	// instances, by the name of the component, e.g.
+	// threescale-postgres-rhmi
+	// +optional
+	Checks map[string]BackupSanityCheck `json:"checks,omitempty"`
+}
+
+// BackupSanityCheck is run against an instance restored from a backup
+type BackupSanityCheck struct {
+	// Query returning a number: a SQL query for postgres, or a redis
+	// command for redis, e.g. "DBSIZE"
+	Query string `json:"query"`
+	// Min is the minimum result of the query for the backup to be
+	// verified
+	// +optional
+	Min int64 `json:"min,omitempty"`
+}
+
+// RHMIBackupStatus defines the observed state of RHMIBackup
+type RHMIBackupStatus struct {
+	// Phase is "in progress" while the backups run, then "completed" or
+	// "failed". A backup is performed once, create a new RHMIBackup to back
+	// up again
+	Phase StatusPhase `json:"phase,omitempty"`
+
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Components are the backups performed for each product
+	Components []RHMIBackupComponentStatus `json:"components,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// RHMIBackupComponentStatus is the progress of the backup of a component of a
+// product, such as its postgres instance
+type RHMIBackupComponentStatus struct {
+	Product ProductName `json:"product"`
+	// Name of the resource backed up
+	Name string `json:"name"`
+	// Type of the backup created, e.g. PostgresSnapshot or Job
+	Type string `json:"type,omitempty"`
+	// Backup is the name of the snapshot or Job created
+	Backup string `json:"backup,omitempty"`
+
+	Phase          StatusPhase  `json:"phase"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Duration of the backup, e.g. "3m20s"
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
+
+	// Verification of the backup, when requested
+	Verification *RHMIBackupVerificationStatus `json:"verification,omitempty"`
+}
+
+// RHMIBackupVerificationStatus is the result of the verification of the backup
+// of a component
+type RHMIBackupVerificationStatus struct {
+	Phase StatusPhase `json:"phase"`
+	// Query run against the restored instance
+	Query string `json:"query,omitempty"`
+	// Result of the query
+	Result *int64 `json:"result,omitempty"`
+	// Verified is true if the result of the query reached the minimum of
+	// the check. A check without a minimum, such as the default check of
+	// redis, passes on an empty instance: the backup is restored, but left
+	// unverified
+	Verified       bool         `json:"verified"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	Error          string       `json:"error,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// RHMIBackup is the Schema for the rhmibackups API. Creating a RHMIBackup
+// triggers a backup of the products of the installation
+type RHMIBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RHMIBackupSpec   `json:"spec,omitempty"`
+	Status RHMIBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RHMIBackupList contains a list of RHMIBackup
+type RHMIBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RHMIBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RHMIBackup{}, &RHMIBackupList{})
+}
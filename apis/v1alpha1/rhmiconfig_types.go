@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -39,6 +40,9 @@ const (
 	DefaultNotBeforeDays      = 7
 	DefaultWaitForMaintenance = true
 
+	// Length of the maintenance window starting at Maintenance.ApplyFrom
+	DefaultMaintenanceDuration = 6 * time.Hour
+
 	// Maximum allowed number of days to schedule an upgrade via `NotBeforeDays`
 	// MaxUpgradeDays = 14
 )
@@ -48,6 +52,11 @@ type RHMIConfigSpec struct {
 	Upgrade     Upgrade     `json:"upgrade,omitempty"`
 	Maintenance Maintenance `json:"maintenance,omitempty"`
 	Backup      Backup      `json:"backup,omitempty"`
+
+	// time-zone: string, IANA time zone of the maintenance, backup and
+	// blackout times, e.g. "Australia/Sydney". Defaults to UTC
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 // RHMIConfigStatus defines the observed state of RHMIConfig
@@ -63,11 +72,28 @@ type RHMIConfigStatus struct {
 	Maintenance      RHMIConfigStatusMaintenance `json:"maintenance,omitempty"`
 	Upgrade          RHMIConfigStatusUpgrade     `json:"upgrade,omitempty"`
 	UpgradeAvailable *UpgradeAvailable           `json:"upgradeAvailable,omitempty"`
+	Backup           *RHMIConfigStatusBackup     `json:"backup,omitempty"`
+}
+
+type RHMIConfigStatusBackup struct {
+	// PrunedAt is the last time snapshots were removed by the retention
+	// policy
+	PrunedAt *metav1.Time `json:"prunedAt,omitempty"`
+	// Pruned are the snapshots removed at PrunedAt, as <kind>/<name>
+	Pruned []string `json:"pruned,omitempty"`
 }
 
 type RHMIConfigStatusMaintenance struct {
+	// ApplyFrom is the start of the next maintenance window, in format "2-1-2006 15:04". UTC time
 	ApplyFrom string `json:"applyFrom,omitempty"`
-	Duration  string `json:"duration,omitempty"`
+	// ApplyFromLocal is ApplyFrom in the time zone of the RHMIConfig, in
+	// format "2-1-2006 15:04 MST". Only set when spec.timeZone is set
+	ApplyFromLocal string `json:"applyFromLocal,omitempty"`
+	Duration       string `json:"duration,omitempty"`
+
+	// Blackout is the blackout period in effect, if any. Upgrades and
+	// disruptive changes are not applied until it ends
+	Blackout *BlackoutPeriod `json:"blackout,omitempty"`
 }
 
 type RHMIConfigStatusUpgrade struct {
@@ -76,8 +102,11 @@ type RHMIConfigStatusUpgrade struct {
 }
 
 type UpgradeSchedule struct {
-	// For is the calculated time when the upgrade is scheduled for, in format "2 Jan 2006 15:04"
+	// For is the calculated time when the upgrade is scheduled for, in format "2 Jan 2006 15:04". UTC time
 	For string `json:"for,omitempty"`
+	// ForLocal is For in the time zone of the RHMIConfig, in format
+	// "2 Jan 2006 15:04 MST". Only set when spec.timeZone is set
+	ForLocal string `json:"forLocal,omitempty"`
 }
 
 type UpgradeScheduleCalculation string
@@ -89,6 +118,19 @@ const ApplyOn UpgradeScheduleCalculation = "ApplyOn"
 
 const DateFormat = "2 Jan 2006 15:04"
 
+// LocalDateFormat is DateFormat with the abbreviation of the time zone
+const LocalDateFormat = DateFormat + " MST"
+
+var weekDays = []string{
+	"sun",
+	"mon",
+	"tue",
+	"wed",
+	"thu",
+	"fri",
+	"sat",
+}
+
 type Upgrade struct {
 	// contacts: list of contacts which are comma separated
 	// "user1@example.com,user2@example.com"
@@ -110,14 +152,243 @@ type Upgrade struct {
 
 type Maintenance struct {
 	// apply-from: string, day time. Currently this is a 6 hour window.
-	// Format: "DDD hh:mm" > "sun 23:00". UTC time
+	// Format: "DDD hh:mm" > "sun 23:00". Time in spec.timeZone, UTC by default
 	ApplyFrom string `json:"applyFrom,omitempty"`
+
+	// windows: additional weekly maintenance windows
+	// +optional
+	Windows []MaintenanceWindow `json:"windows,omitempty"`
+
+	// blackouts: date ranges during which upgrades and disruptive changes are
+	// never applied, even inside a maintenance window
+	// +optional
+	Blackouts []BlackoutPeriod `json:"blackouts,omitempty"`
+}
+
+type MaintenanceWindow struct {
+	// apply-from: string, day time.
+	// Format: "DDD hh:mm" > "sun 23:00". Time in spec.timeZone, UTC by default
+	ApplyFrom string `json:"applyFrom"`
+
+	// duration: string, length of the window, between 1h and 24h
+	// Format: "6h", "90m". Defaults to 6h
+	// +optional
+	Duration string `json:"duration,omitempty"`
+}
+
+type BlackoutPeriod struct {
+	// from: string, start of the blackout
+	// Format: "2 Jan 2006 15:04". Time in spec.timeZone, UTC by default
+	From string `json:"from"`
+
+	// to: string, end of the blackout
+	// Format: "2 Jan 2006 15:04". Time in spec.timeZone, UTC by default
+	To string `json:"to"`
+
+	// reason: string, description of the blackout, e.g. "end of quarter"
+	// +optional
+	Reason string `json:"reason,omitempty"`
 }
 
 type Backup struct {
 	// apply-on: string, day time.
-	// Format: "DDD hh:mm" > "wed 20:00". UTC time
+	// Format: "DDD hh:mm" > "wed 20:00". Time in spec.timeZone, UTC by default
 	ApplyOn string `json:"applyOn,omitempty"`
+
+	// retention: policy of the postgres and redis snapshots, taken before
+	// upgrades and by RHMIBackups. The snapshots are kept when not set
+	// +optional
+	Retention *BackupRetention `json:"retention,omitempty"`
+
+	// pre-upgrade: policy of the backups taken before the products are
+	// upgraded. The upgrade is blocked until the backup completes when
+	// not set
+	// +optional
+	PreUpgrade *PreUpgradeBackup `json:"preUpgrade,omitempty"`
+}
+
+type PreUpgradeBackupFailurePolicy string
+
+const (
+	// PreUpgradeBackupBlock keeps the upgrade of the product blocked once
+	// the retries of the backup are exhausted
+	PreUpgradeBackupBlock PreUpgradeBackupFailurePolicy = "Block"
+	// PreUpgradeBackupWarn emits a warning and upgrades the product once
+	// the retries of the backup are exhausted
+	PreUpgradeBackupWarn PreUpgradeBackupFailurePolicy = "Warn"
+)
+
+// PreUpgradeBackup is the policy of the pre-upgrade backups of every
+// product, with the overrides of each product
+type PreUpgradeBackup struct {
+	PreUpgradeBackupPolicy `json:",inline"`
+
+	// products: overrides the policy for the backups of a product, by the
+	// name of the product, e.g. "3scale"
+	// +optional
+	Products map[ProductName]PreUpgradeBackupPolicy `json:"products,omitempty"`
+}
+
+type PreUpgradeBackupPolicy struct {
+	// skip: bool, upgrade the product without taking a backup
+	// +optional
+	Skip *bool `json:"skip,omitempty"`
+
+	// timeout: duration of each attempt of the backup, e.g. "30m".
+	// Defaults to 20m
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// retries: int, number of times a failed backup is retried before the
+	// failure policy applies. Defaults to 0
+	// +optional
+	Retries *int `json:"retries,omitempty"`
+
+	// retry-interval: duration to wait before the first retry, e.g. "5m".
+	// It's doubled on each retry. Defaults to 5m
+	// +optional
+	RetryInterval *metav1.Duration `json:"retryInterval,omitempty"`
+
+	// failure-policy: "Block" or "Warn". Defaults to "Block"
+	// +optional
+	// +kubebuilder:validation:Enum=Block;Warn
+	FailurePolicy PreUpgradeBackupFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// ForProduct returns the policy of the backups of the product, with the
+// overrides of the product applied. Unset fields are left to the caller
+// to default
+func (p *PreUpgradeBackup) ForProduct(product ProductName) PreUpgradeBackupPolicy {
+	policy := p.PreUpgradeBackupPolicy
+	override, ok := p.Products[product]
+	if !ok {
+		return policy
+	}
+
+	if override.Skip != nil {
+		policy.Skip = override.Skip
+	}
+	if override.Timeout != nil {
+		policy.Timeout = override.Timeout
+	}
+	if override.Retries != nil {
+		policy.Retries = override.Retries
+	}
+	if override.RetryInterval != nil {
+		policy.RetryInterval = override.RetryInterval
+	}
+	if override.FailurePolicy != "" {
+		policy.FailurePolicy = override.FailurePolicy
+	}
+	return policy
+}
+
+// Validate ensures the durations and retries of the policies aren't
+// negative, and their failure policies are known
+func (p *PreUpgradeBackup) Validate() error {
+	if err := p.PreUpgradeBackupPolicy.validate("spec.backup.preUpgrade"); err != nil {
+		return err
+	}
+
+	products := make([]string, 0, len(p.Products))
+	for product := range p.Products {
+		products = append(products, string(product))
+	}
+	sort.Strings(products)
+	for _, product := range products {
+		if err := p.Products[ProductName(product)].validate("spec.backup.preUpgrade.products." + product); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p PreUpgradeBackupPolicy) validate(field string) error {
+	if p.Timeout != nil && p.Timeout.Duration <= 0 {
+		return fmt.Errorf("value of %s.timeout must be greater than zero", field)
+	}
+	if p.Retries != nil && *p.Retries < 0 {
+		return fmt.Errorf("value of %s.retries must be greater or equal to zero", field)
+	}
+	if p.RetryInterval != nil && p.RetryInterval.Duration < 0 {
+		return fmt.Errorf("value of %s.retryInterval must be greater or equal to zero", field)
+	}
+	switch p.FailurePolicy {
+	case "", PreUpgradeBackupBlock, PreUpgradeBackupWarn:
+		return nil
+	default:
+		return fmt.Errorf("value of %s.failurePolicy must be %s or %s", field, PreUpgradeBackupBlock, PreUpgradeBackupWarn)
+	}
+}
+
+// BackupRetention defines how long the snapshots of each instance are kept.
+// A snapshot is kept while it's within any of the limits, and removed once
+// it's beyond all of them
+type BackupRetention struct {
+	SnapshotRetention `json:",inline"`
+
+	// postgres: overrides the limits for the snapshots of postgres instances
+	// +optional
+	Postgres *SnapshotRetention `json:"postgres,omitempty"`
+
+	// redis: overrides the limits for the snapshots of redis instances
+	// +optional
+	Redis *SnapshotRetention `json:"redis,omitempty"`
+}
+
+type SnapshotRetention struct {
+	// keep-last: int, number of most recent snapshots kept for each instance
+	// +optional
+	KeepLast *int `json:"keepLast,omitempty"`
+
+	// keep-days: int, number of days a snapshot is kept
+	// +optional
+	KeepDays *int `json:"keepDays,omitempty"`
+}
+
+// ForType returns the limits of the snapshots of the given type, e.g.
+// "postgres", with the overrides of the type applied
+func (r *BackupRetention) ForType(resourceType string) SnapshotRetention {
+	retention := r.SnapshotRetention
+	override := r.Postgres
+	if resourceType == "redis" {
+		override = r.Redis
+	}
+	if override == nil {
+		return retention
+	}
+
+	if override.KeepLast != nil {
+		retention.KeepLast = override.KeepLast
+	}
+	if override.KeepDays != nil {
+		retention.KeepDays = override.KeepDays
+	}
+	return retention
+}
+
+// Validate ensures the limits of the retention aren't negative
+func (r *BackupRetention) Validate() error {
+	limits := []struct {
+		field     string
+		retention *SnapshotRetention
+	}{
+		{field: "spec.backup.retention", retention: &r.SnapshotRetention},
+		{field: "spec.backup.retention.postgres", retention: r.Postgres},
+		{field: "spec.backup.retention.redis", retention: r.Redis},
+	}
+	for _, limit := range limits {
+		if limit.retention == nil {
+			continue
+		}
+		if limit.retention.KeepLast != nil && *limit.retention.KeepLast < 0 {
+			return fmt.Errorf("value of %s.keepLast must be greater or equal to zero", limit.field)
+		}
+		if limit.retention.KeepDays != nil && *limit.retention.KeepDays < 0 {
+			return fmt.Errorf("value of %s.keepDays must be greater or equal to zero", limit.field)
+		}
+	}
+	return nil
 }
 
 type UpgradeAvailable struct {
@@ -155,6 +426,25 @@ func init() {
 }
 
 func (c *RHMIConfig) ValidateCreate() error {
+	if _, _, err := ValidateBackupAndMaintenance(c.Spec.Backup.ApplyOn, c.Spec.Maintenance.ApplyFrom); err != nil {
+		return err
+	}
+
+	loc, err := c.Spec.GetLocation()
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateMaintenanceWindows(c.Spec.Maintenance, loc); err != nil {
+		return err
+	}
+
+	if c.Spec.Backup.Retention != nil {
+		if err := c.Spec.Backup.Retention.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -163,6 +453,27 @@ func (c *RHMIConfig) ValidateUpdate(old runtime.Object) error {
 		return err
 	}
 
+	loc, err := c.Spec.GetLocation()
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateMaintenanceWindows(c.Spec.Maintenance, loc); err != nil {
+		return err
+	}
+
+	if c.Spec.Backup.Retention != nil {
+		if err := c.Spec.Backup.Retention.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Spec.Backup.PreUpgrade != nil {
+		if err := c.Spec.Backup.PreUpgrade.Validate(); err != nil {
+			return err
+		}
+	}
+
 	// Validate the NotBeforeDays. Must be an integer n where
 	// n > 0 && n <= MaxUpgradeDays
 	if c.Spec.Upgrade.NotBeforeDays != nil {
@@ -291,16 +602,7 @@ func ValidateBackupAndMaintenance(backupApplyOn, maintenanceApplyFrom string) (s
 	maintenanceTime := maintenanceSegments[1]
 
 	// verify maintenance day is valid
-	var expectedDays = []string{
-		"sun",
-		"mon",
-		"tue",
-		"wed",
-		"thu",
-		"fri",
-		"sat",
-	}
-	if !contains(expectedDays, strings.ToLower(maintenanceDay)) {
+	if !contains(weekDays, strings.ToLower(maintenanceDay)) {
 		return "", "", fmt.Errorf("formatting failure, found invalid maintenance applyFrom value. Expected: `DDD HH:mm` found: %s", maintenanceApplyFrom)
 	}
 
@@ -331,6 +633,90 @@ func ValidateBackupAndMaintenance(backupApplyOn, maintenanceApplyFrom string) (s
 	return backupApplyOn, maintenanceApplyFrom, nil
 }
 
+// GetLocation returns the time zone of the RHMIConfig, UTC if it's not set
+func (s RHMIConfigSpec) GetLocation() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec.timeZone value : expected an IANA time zone such as Australia/Sydney, found %s", s.TimeZone)
+	}
+	return loc, nil
+}
+
+// ValidateMaintenanceWindows ensures that the additional maintenance windows
+// and the blackout periods are correctly formatted. The blackout periods are
+// parsed in loc
+func ValidateMaintenanceWindows(maintenance Maintenance, loc *time.Location) error {
+	for i, window := range maintenance.Windows {
+		if err := validateWeeklyTime(window.ApplyFrom); err != nil {
+			return fmt.Errorf("invalid spec.maintenance.windows[%d].applyFrom value : %w", i, err)
+		}
+		if _, err := window.GetDuration(); err != nil {
+			return fmt.Errorf("invalid spec.maintenance.windows[%d].duration value : %w", i, err)
+		}
+	}
+
+	for i, blackout := range maintenance.Blackouts {
+		from, to, err := blackout.GetRange(loc)
+		if err != nil {
+			return fmt.Errorf("invalid spec.maintenance.blackouts[%d] value : %w", i, err)
+		}
+		if !from.Before(to) {
+			return fmt.Errorf("invalid spec.maintenance.blackouts[%d] value : from %s must be before to %s", i, blackout.From, blackout.To)
+		}
+	}
+
+	return nil
+}
+
+// GetDuration returns the duration of the window, defaulting to the 6 hours
+// of the ApplyFrom window
+func (w MaintenanceWindow) GetDuration() (time.Duration, error) {
+	if w.Duration == "" {
+		return DefaultMaintenanceDuration, nil
+	}
+
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return 0, fmt.Errorf("expected format like 6h or 90m, found %s", w.Duration)
+	}
+	if duration < time.Hour || duration > 24*time.Hour {
+		return 0, fmt.Errorf("duration must be between 1h and 24h, found %s", w.Duration)
+	}
+	return duration, nil
+}
+
+// GetRange returns the start and end of the blackout period in loc
+func (b BlackoutPeriod) GetRange(loc *time.Location) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation(DateFormat, b.From, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("expected from format `%s` found: %s", DateFormat, b.From)
+	}
+	to, err := time.ParseInLocation(DateFormat, b.To, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("expected to format `%s` found: %s", DateFormat, b.To)
+	}
+	return from, to, nil
+}
+
+// validateWeeklyTime ensures the value is in format `DDD HH:mm`
+func validateWeeklyTime(value string) error {
+	segments := strings.Split(value, " ")
+	if len(segments) != 2 {
+		return fmt.Errorf("expected format DDD HH:mm , found format %s", value)
+	}
+	if !contains(weekDays, strings.ToLower(segments[0])) {
+		return fmt.Errorf("expected format DDD HH:mm , found invalid day %s", segments[0])
+	}
+	if _, err := time.Parse("15:04", segments[1]); err != nil {
+		return fmt.Errorf("expected format DDD HH:mm , found %s: %v", value, err)
+	}
+	return nil
+}
+
 // timeBlockOverlaps checks if two time ranges overlap and returns true
 // if they do
 func timeBlockOverlaps(startA, endA, startB, endB time.Time) bool {
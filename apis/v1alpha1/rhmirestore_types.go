@@ -0,0 +1,139 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestoreAction is the action performed by a step of a restore
+type RestoreAction string
+
+const (
+	// RestoreActionScaleDown scales down the workloads of the product so
+	// nothing writes to its data while it's restored
+	RestoreActionScaleDown RestoreAction = "ScaleDown"
+	// RestoreActionRestore restores a component from its backup
+	RestoreActionRestore RestoreAction = "Restore"
+	// RestoreActionScaleUp scales the workloads of the product back to
+	// their replicas before the restore
+	RestoreActionScaleUp RestoreAction = "ScaleUp"
+)
+
+// RHMIRestoreSpec defines the backup to restore
+type RHMIRestoreSpec struct {
+	// Backup is the name of the RHMIBackup to restore, in the namespace of
+	// the RHMIRestore
+	Backup string `json:"backup"`
+
+	// Products to restore. Every product in the backup is restored when
+	// empty
+	// +optional
+	Products []ProductName `json:"products,omitempty"`
+
+	// Timeout of each step of the restore, e.g. "1h". Defaults to 1h
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// RHMIRestoreStatus defines the observed state of RHMIRestore
+type RHMIRestoreStatus struct {
+	// Phase is "in progress" while the steps run, then "completed" or
+	// "failed". A restore is performed once, create a new RHMIRestore to
+	// restore again
+	Phase StatusPhase `json:"phase,omitempty"`
+
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Steps of the restore, run in order
+	Steps []RHMIRestoreStep `json:"steps,omitempty"`
+
+	// Workloads scaled down for the restore, with their replicas before it
+	Workloads []RHMIRestoreWorkload `json:"workloads,omitempty"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// RHMIRestoreStep is the progress of a step of the restore
+type RHMIRestoreStep struct {
+	Product ProductName   `json:"product"`
+	Action  RestoreAction `json:"action"`
+	// Component restored by a Restore step
+	Component string `json:"component,omitempty"`
+	// Type of the backup restored, e.g. PostgresSnapshot
+	Type string `json:"type,omitempty"`
+	// Backup is the name of the snapshot restored
+	Backup string `json:"backup,omitempty"`
+
+	Phase          StatusPhase  `json:"phase"`
+	StartTime      *metav1.Time `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	Message        string       `json:"message,omitempty"`
+}
+
+// RHMIRestoreWorkload is a workload scaled down during the restore
+type RHMIRestoreWorkload struct {
+	Product    ProductName `json:"product"`
+	APIVersion string      `json:"apiVersion"`
+	Kind       string      `json:"kind"`
+	Name       string      `json:"name"`
+	Namespace  string      `json:"namespace"`
+	// Path of the replicas in the workload, e.g. spec.replicas
+	Path     string `json:"path"`
+	Replicas int64  `json:"replicas"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// RHMIRestore is the Schema for the rhmirestores API. Creating a RHMIRestore
+// restores the products of the installation from a RHMIBackup
+type RHMIRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RHMIRestoreSpec   `json:"spec,omitempty"`
+	Status RHMIRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RHMIRestoreList contains a list of RHMIRestore
+type RHMIRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RHMIRestore `json:"items"`
+}
+
+// IsRestoring returns true if the restore is in progress and includes the
+// product. The product isn't reconciled until the restore finishes
+func (r *RHMIRestore) IsRestoring(product ProductName) bool {
+	if r.Status.Phase != PhaseInProgress {
+		return false
+	}
+	for _, step := range r.Status.Steps {
+		if step.Product == product {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	SchemeBuilder.Register(&RHMIRestore{}, &RHMIRestoreList{})
+}
@@ -21,6 +21,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -42,73 +43,907 @@ func (in *AlertingEmailAddresses) DeepCopy() *AlertingEmailAddresses {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Backup) DeepCopyInto(out *Backup) {
 	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PreUpgrade != nil {
+		in, out := &in.PreUpgrade, &out.PreUpgrade
+		*out = new(PreUpgradeBackup)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backup.
+func (in *Backup) DeepCopy() *Backup {
+	if in == nil {
+		return nil
+	}
+	out := new(Backup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetention) DeepCopyInto(out *BackupRetention) {
+	*out = *in
+	in.SnapshotRetention.DeepCopyInto(&out.SnapshotRetention)
+	if in.Postgres != nil {
+		in, out := &in.Postgres, &out.Postgres
+		*out = new(SnapshotRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(SnapshotRetention)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetention.
+func (in *BackupRetention) DeepCopy() *BackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupSanityCheck) DeepCopyInto(out *BackupSanityCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupSanityCheck.
+func (in *BackupSanityCheck) DeepCopy() *BackupSanityCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupSanityCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlackoutPeriod) DeepCopyInto(out *BlackoutPeriod) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlackoutPeriod.
+func (in *BlackoutPeriod) DeepCopy() *BlackoutPeriod {
+	if in == nil {
+		return nil
+	}
+	out := new(BlackoutPeriod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStorageBackupSpec) DeepCopyInto(out *ClusterStorageBackupSpec) {
+	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStorageBackupSpec.
+func (in *ClusterStorageBackupSpec) DeepCopy() *ClusterStorageBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStorageBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredAction) DeepCopyInto(out *DeferredAction) {
+	*out = *in
+	in.DeferredSince.DeepCopyInto(&out.DeferredSince)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredAction.
+func (in *DeferredAction) DeepCopy() *DeferredAction {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationProfile) DeepCopyInto(out *InstallationProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationProfile.
+func (in *InstallationProfile) DeepCopy() *InstallationProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstallationProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationProfileList) DeepCopyInto(out *InstallationProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]InstallationProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationProfileList.
+func (in *InstallationProfileList) DeepCopy() *InstallationProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *InstallationProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationProfileSpec) DeepCopyInto(out *InstallationProfileSpec) {
+	*out = *in
+	if in.InstallStages != nil {
+		in, out := &in.InstallStages, &out.InstallStages
+		*out = make([]InstallationProfileStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UninstallStages != nil {
+		in, out := &in.UninstallStages, &out.UninstallStages
+		*out = make([]InstallationProfileStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationProfileSpec.
+func (in *InstallationProfileSpec) DeepCopy() *InstallationProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationProfileStage) DeepCopyInto(out *InstallationProfileStage) {
+	*out = *in
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make([]ProductName, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationProfileStage.
+func (in *InstallationProfileStage) DeepCopy() *InstallationProfileStage {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationProfileStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallationProfileStatus) DeepCopyInto(out *InstallationProfileStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallationProfileStatus.
+func (in *InstallationProfileStatus) DeepCopy() *InstallationProfileStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallationProfileStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Maintenance) DeepCopyInto(out *Maintenance) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blackouts != nil {
+		in, out := &in.Blackouts, &out.Blackouts
+		*out = make([]BlackoutPeriod, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Maintenance.
+func (in *Maintenance) DeepCopy() *Maintenance {
+	if in == nil {
+		return nil
+	}
+	out := new(Maintenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreUpgradeBackup) DeepCopyInto(out *PreUpgradeBackup) {
+	*out = *in
+	in.PreUpgradeBackupPolicy.DeepCopyInto(&out.PreUpgradeBackupPolicy)
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make(map[ProductName]PreUpgradeBackupPolicy, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreUpgradeBackup.
+func (in *PreUpgradeBackup) DeepCopy() *PreUpgradeBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(PreUpgradeBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreUpgradeBackupPolicy) DeepCopyInto(out *PreUpgradeBackupPolicy) {
+	*out = *in
+	if in.Skip != nil {
+		in, out := &in.Skip, &out.Skip
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Retries != nil {
+		in, out := &in.Retries, &out.Retries
+		*out = new(int)
+		**out = **in
+	}
+	if in.RetryInterval != nil {
+		in, out := &in.RetryInterval, &out.RetryInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreUpgradeBackupPolicy.
+func (in *PreUpgradeBackupPolicy) DeepCopy() *PreUpgradeBackupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PreUpgradeBackupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PreUpgradeBackupStatus) DeepCopyInto(out *PreUpgradeBackupStatus) {
+	*out = *in
+	if in.LastAttempt != nil {
+		in, out := &in.LastAttempt, &out.LastAttempt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PreUpgradeBackupStatus.
+func (in *PreUpgradeBackupStatus) DeepCopy() *PreUpgradeBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PreUpgradeBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProductSpec) DeepCopyInto(out *ProductSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProductSpec.
+func (in *ProductSpec) DeepCopy() *ProductSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProductSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullSecretSpec) DeepCopyInto(out *PullSecretSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullSecretSpec.
+func (in *PullSecretSpec) DeepCopy() *PullSecretSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PullSecretSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaCapacity) DeepCopyInto(out *QuotaCapacity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaCapacity.
+func (in *QuotaCapacity) DeepCopy() *QuotaCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaComponentChange) DeepCopyInto(out *QuotaComponentChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaComponentChange.
+func (in *QuotaComponentChange) DeepCopy() *QuotaComponentChange {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaComponentChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaDowngradeStatus) DeepCopyInto(out *QuotaDowngradeStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaDowngradeStatus.
+func (in *QuotaDowngradeStatus) DeepCopy() *QuotaDowngradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaDowngradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaPreview) DeepCopyInto(out *QuotaPreview) {
+	*out = *in
+	in.GeneratedAt.DeepCopyInto(&out.GeneratedAt)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]QuotaComponentChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(QuotaRateLimitChange)
+		**out = **in
+	}
+	out.Capacity = in.Capacity
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaPreview.
+func (in *QuotaPreview) DeepCopy() *QuotaPreview {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaPreview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfile) DeepCopyInto(out *QuotaProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfile.
+func (in *QuotaProfile) DeepCopy() *QuotaProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuotaProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileAlertLimit) DeepCopyInto(out *QuotaProfileAlertLimit) {
+	*out = *in
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(QuotaProfileAlertThreshold)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileAlertLimit.
+func (in *QuotaProfileAlertLimit) DeepCopy() *QuotaProfileAlertLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileAlertLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileAlertThreshold) DeepCopyInto(out *QuotaProfileAlertThreshold) {
+	*out = *in
+	if in.MaxRate != nil {
+		in, out := &in.MaxRate, &out.MaxRate
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileAlertThreshold.
+func (in *QuotaProfileAlertThreshold) DeepCopy() *QuotaProfileAlertThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileAlertThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileAutoscaling) DeepCopyInto(out *QuotaProfileAutoscaling) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileAutoscaling.
+func (in *QuotaProfileAutoscaling) DeepCopy() *QuotaProfileAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileList) DeepCopyInto(out *QuotaProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]QuotaProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileList.
+func (in *QuotaProfileList) DeepCopy() *QuotaProfileList {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *QuotaProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileRateLimit) DeepCopyInto(out *QuotaProfileRateLimit) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]QuotaProfileRateLimitWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.AlertLimits != nil {
+		in, out := &in.AlertLimits, &out.AlertLimits
+		*out = make(map[string]QuotaProfileAlertLimit, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileRateLimit.
+func (in *QuotaProfileRateLimit) DeepCopy() *QuotaProfileRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileRateLimitWindow) DeepCopyInto(out *QuotaProfileRateLimitWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileRateLimitWindow.
+func (in *QuotaProfileRateLimitWindow) DeepCopy() *QuotaProfileRateLimitWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileRateLimitWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileResources) DeepCopyInto(out *QuotaProfileResources) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(QuotaProfileAutoscaling)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileResources.
+func (in *QuotaProfileResources) DeepCopy() *QuotaProfileResources {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaProfileSpec) DeepCopyInto(out *QuotaProfileSpec) {
+	*out = *in
+	in.RateLimit.DeepCopyInto(&out.RateLimit)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(map[string]QuotaProfileResources, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaProfileSpec.
+func (in *QuotaProfileSpec) DeepCopy() *QuotaProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaRateLimitChange) DeepCopyInto(out *QuotaRateLimitChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaRateLimitChange.
+func (in *QuotaRateLimitChange) DeepCopy() *QuotaRateLimitChange {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaRateLimitChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMI) DeepCopyInto(out *RHMI) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMI.
+func (in *RHMI) DeepCopy() *RHMI {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMI)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RHMI) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIBackup) DeepCopyInto(out *RHMIBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackup.
+func (in *RHMIBackup) DeepCopy() *RHMIBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RHMIBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIBackupComponentStatus) DeepCopyInto(out *RHMIBackupComponentStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RHMIBackupVerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backup.
-func (in *Backup) DeepCopy() *Backup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackupComponentStatus.
+func (in *RHMIBackupComponentStatus) DeepCopy() *RHMIBackupComponentStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(Backup)
+	out := new(RHMIBackupComponentStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Maintenance) DeepCopyInto(out *Maintenance) {
+func (in *RHMIBackupList) DeepCopyInto(out *RHMIBackupList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RHMIBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Maintenance.
-func (in *Maintenance) DeepCopy() *Maintenance {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackupList.
+func (in *RHMIBackupList) DeepCopy() *RHMIBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(Maintenance)
+	out := new(RHMIBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RHMIBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PullSecretSpec) DeepCopyInto(out *PullSecretSpec) {
+func (in *RHMIBackupSpec) DeepCopyInto(out *RHMIBackupSpec) {
 	*out = *in
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make([]ProductName, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RHMIBackupVerification)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullSecretSpec.
-func (in *PullSecretSpec) DeepCopy() *PullSecretSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackupSpec.
+func (in *RHMIBackupSpec) DeepCopy() *RHMIBackupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PullSecretSpec)
+	out := new(RHMIBackupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RHMI) DeepCopyInto(out *RHMI) {
+func (in *RHMIBackupStatus) DeepCopyInto(out *RHMIBackupStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]RHMIBackupComponentStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMI.
-func (in *RHMI) DeepCopy() *RHMI {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackupStatus.
+func (in *RHMIBackupStatus) DeepCopy() *RHMIBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RHMI)
+	out := new(RHMIBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RHMI) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIBackupVerification) DeepCopyInto(out *RHMIBackupVerification) {
+	*out = *in
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make(map[string]BackupSanityCheck, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	return nil
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackupVerification.
+func (in *RHMIBackupVerification) DeepCopy() *RHMIBackupVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIBackupVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIBackupVerificationStatus) DeepCopyInto(out *RHMIBackupVerificationStatus) {
+	*out = *in
+	if in.Result != nil {
+		in, out := &in.Result, &out.Result
+		*out = new(int64)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIBackupVerificationStatus.
+func (in *RHMIBackupVerificationStatus) DeepCopy() *RHMIBackupVerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIBackupVerificationStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -174,8 +1009,8 @@ func (in *RHMIConfigList) DeepCopyObject() runtime.Object {
 func (in *RHMIConfigSpec) DeepCopyInto(out *RHMIConfigSpec) {
 	*out = *in
 	in.Upgrade.DeepCopyInto(&out.Upgrade)
-	out.Maintenance = in.Maintenance
-	out.Backup = in.Backup
+	in.Maintenance.DeepCopyInto(&out.Maintenance)
+	in.Backup.DeepCopyInto(&out.Backup)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigSpec.
@@ -191,13 +1026,18 @@ func (in *RHMIConfigSpec) DeepCopy() *RHMIConfigSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMIConfigStatus) DeepCopyInto(out *RHMIConfigStatus) {
 	*out = *in
-	out.Maintenance = in.Maintenance
+	in.Maintenance.DeepCopyInto(&out.Maintenance)
 	in.Upgrade.DeepCopyInto(&out.Upgrade)
 	if in.UpgradeAvailable != nil {
 		in, out := &in.UpgradeAvailable, &out.UpgradeAvailable
 		*out = new(UpgradeAvailable)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(RHMIConfigStatusBackup)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigStatus.
@@ -210,9 +1050,38 @@ func (in *RHMIConfigStatus) DeepCopy() *RHMIConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIConfigStatusBackup) DeepCopyInto(out *RHMIConfigStatusBackup) {
+	*out = *in
+	if in.PrunedAt != nil {
+		in, out := &in.PrunedAt, &out.PrunedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Pruned != nil {
+		in, out := &in.Pruned, &out.Pruned
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigStatusBackup.
+func (in *RHMIConfigStatusBackup) DeepCopy() *RHMIConfigStatusBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIConfigStatusBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMIConfigStatusMaintenance) DeepCopyInto(out *RHMIConfigStatusMaintenance) {
 	*out = *in
+	if in.Blackout != nil {
+		in, out := &in.Blackout, &out.Blackout
+		*out = new(BlackoutPeriod)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIConfigStatusMaintenance.
@@ -280,6 +1149,18 @@ func (in *RHMIList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMIProductStatus) DeepCopyInto(out *RHMIProductStatus) {
 	*out = *in
+	if in.BlockedBy != nil {
+		in, out := &in.BlockedBy, &out.BlockedBy
+		*out = make([]ProductName, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIProductStatus.
@@ -292,11 +1173,180 @@ func (in *RHMIProductStatus) DeepCopy() *RHMIProductStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIRestore) DeepCopyInto(out *RHMIRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIRestore.
+func (in *RHMIRestore) DeepCopy() *RHMIRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RHMIRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIRestoreList) DeepCopyInto(out *RHMIRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RHMIRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIRestoreList.
+func (in *RHMIRestoreList) DeepCopy() *RHMIRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RHMIRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIRestoreSpec) DeepCopyInto(out *RHMIRestoreSpec) {
+	*out = *in
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make([]ProductName, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIRestoreSpec.
+func (in *RHMIRestoreSpec) DeepCopy() *RHMIRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIRestoreStatus) DeepCopyInto(out *RHMIRestoreStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RHMIRestoreStep, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]RHMIRestoreWorkload, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIRestoreStatus.
+func (in *RHMIRestoreStatus) DeepCopy() *RHMIRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIRestoreStep) DeepCopyInto(out *RHMIRestoreStep) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIRestoreStep.
+func (in *RHMIRestoreStep) DeepCopy() *RHMIRestoreStep {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIRestoreStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHMIRestoreWorkload) DeepCopyInto(out *RHMIRestoreWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIRestoreWorkload.
+func (in *RHMIRestoreWorkload) DeepCopy() *RHMIRestoreWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(RHMIRestoreWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHMISpec) DeepCopyInto(out *RHMISpec) {
 	*out = *in
 	out.PullSecret = in.PullSecret
 	out.AlertingEmailAddresses = in.AlertingEmailAddresses
+	if in.Products != nil {
+		in, out := &in.Products, &out.Products
+		*out = make(map[ProductName]ProductSpec, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.ClusterStorageBackup != nil {
+		in, out := &in.ClusterStorageBackup, &out.ClusterStorageBackup
+		*out = new(ClusterStorageBackupSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMISpec.
@@ -316,7 +1366,7 @@ func (in *RHMIStageStatus) DeepCopyInto(out *RHMIStageStatus) {
 		in, out := &in.Products, &out.Products
 		*out = make(map[ProductName]RHMIProductStatus, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 }
@@ -341,6 +1391,37 @@ func (in *RHMIStatus) DeepCopyInto(out *RHMIStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeferredActions != nil {
+		in, out := &in.DeferredActions, &out.DeferredActions
+		*out = make([]DeferredAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreUpgradeBackups != nil {
+		in, out := &in.PreUpgradeBackups, &out.PreUpgradeBackups
+		*out = make([]PreUpgradeBackupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.QuotaPreview != nil {
+		in, out := &in.QuotaPreview, &out.QuotaPreview
+		*out = new(QuotaPreview)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QuotaDowngrade != nil {
+		in, out := &in.QuotaDowngrade, &out.QuotaDowngrade
+		*out = new(QuotaDowngradeStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHMIStatus.
@@ -353,6 +1434,31 @@ func (in *RHMIStatus) DeepCopy() *RHMIStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRetention) DeepCopyInto(out *SnapshotRetention) {
+	*out = *in
+	if in.KeepLast != nil {
+		in, out := &in.KeepLast, &out.KeepLast
+		*out = new(int)
+		**out = **in
+	}
+	if in.KeepDays != nil {
+		in, out := &in.KeepDays, &out.KeepDays
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetention.
+func (in *SnapshotRetention) DeepCopy() *SnapshotRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Upgrade) DeepCopyInto(out *Upgrade) {
 	*out = *in
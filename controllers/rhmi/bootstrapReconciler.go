@@ -24,6 +24,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/metrics"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/owner"
 
@@ -36,6 +37,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	schedulingv1 "k8s.io/api/scheduling/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -125,7 +127,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	r.deleteObsoleteService(ctx, serverClient)
 
 	if installation.Spec.Type == string(rhmiv1alpha1.InstallationTypeManagedApi) {
-		if err = r.processQuota(installation, request.Namespace, installationQuota, serverClient); err != nil {
+		if err = r.processQuota(ctx, installation, request.Namespace, installationQuota, serverClient); err != nil {
 			events.HandleError(r.recorder, installation, integreatlyv1alpha1.PhaseFailed, "Error while processing the Quota", err)
 			installation.Status.LastError = err.Error()
 			return integreatlyv1alpha1.PhaseFailed, err
@@ -512,7 +514,7 @@ func generateSecret(length int) string {
 	return string(buf)
 }
 
-func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace string,
+func (r *Reconciler) processQuota(ctx context.Context, installation *rhmiv1alpha1.RHMI, namespace string,
 	installationQuota *quota.Quota, serverClient k8sclient.Client) error {
 	isQuotaUpdated := false
 
@@ -520,6 +522,7 @@ func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace str
 	if err != nil {
 		return err
 	}
+	requestedQuota := quotaParam
 
 	// get the quota config map from the cluster
 	configMap := &corev1.ConfigMap{}
@@ -539,15 +542,185 @@ func (r *Reconciler) processQuota(installation *rhmiv1alpha1.RHMI, namespace str
 		isQuotaUpdated = true
 	}
 
+	// Preview the pending quota change, or the quota requested by the
+	// annotation, before it's applied
+	previewQuota := installation.Annotations[quota.PreviewAnnotation]
+	if isQuotaUpdated && installation.Status.Quota != "" {
+		previewQuota = quotaParam
+	}
+	if err := r.previewQuota(ctx, installation, configMap, previewQuota, serverClient); err != nil {
+		return err
+	}
+
+	// A quota change the cluster doesn't have the capacity for is held, as
+	// it would fail half-way when the new pods can't be scheduled
+	if r.holdQuotaChange(installation, quotaParam) {
+		quotaParam = installation.Status.Quota
+		isQuotaUpdated = false
+	}
+
+	// A quota change scales and restarts the product pods, the current quota
+	// is kept until the maintenance window opens
+	gate := maintenance.GateFromContext(ctx)
+	if isQuotaUpdated && installation.Status.Quota != "" {
+		allowed, err := gate.Allow(ctx, "", changeQuotaAction)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			r.log.Infof("Quota change deferred until the maintenance window", l.Fields{"quota": installation.Status.Quota, "toQuota": quotaParam})
+			quotaParam = installation.Status.Quota
+			isQuotaUpdated = false
+		}
+	} else {
+		gate.Discard("", changeQuotaAction)
+	}
+
+	// A downgrade is kept from throttling the current traffic of the APIs
+	if downgrade := installation.Status.QuotaDowngrade; downgrade != nil && downgrade.Phase == rhmiv1alpha1.PhaseBlocked && downgrade.To != requestedQuota {
+		installation.Status.QuotaDowngrade = nil
+	}
+	if isQuotaUpdated && installation.Status.Quota != "" {
+		allowed, err := r.checkQuotaDowngrade(ctx, installation, configMap, quotaParam, serverClient)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			quotaParam = installation.Status.Quota
+			isQuotaUpdated = false
+		}
+	}
+
 	// Updates the installation quota to the quota param if the quota is updated
-	err = quota.GetQuota(quotaParam, configMap, installationQuota, isQuotaUpdated)
+	err = quota.GetQuota(ctx, serverClient, quotaParam, configMap, installationQuota, isQuotaUpdated)
 	if err != nil {
 		return err
 	}
 
+	// The workloads are scaled down in steps after a downgrade
+	downgrade := installation.Status.QuotaDowngrade
+	if downgrade != nil && downgrade.Phase == rhmiv1alpha1.PhaseInProgress && downgrade.To == quotaParam {
+		done, err := installationQuota.StageScaleDown(ctx, serverClient, configMap, downgrade, time.Now())
+		if err != nil {
+			return err
+		}
+		if done {
+			downgrade.Phase = rhmiv1alpha1.PhaseCompleted
+			r.recorder.Event(installation, "Normal", rhmiv1alpha1.EventQuotaDowngrade,
+				fmt.Sprintf("Downgrade from quota %s to %s completed", downgrade.From, downgrade.To))
+		}
+	}
+
 	return nil
 }
 
+// checkQuotaDowngrade returns false if the change to the quota to is a
+// downgrade that's blocked by the usage of the APIs. The outcome is set in
+// the status of the installation
+func (r *Reconciler) checkQuotaDowngrade(ctx context.Context, installation *rhmiv1alpha1.RHMI, configMap *corev1.ConfigMap, to string, serverClient k8sclient.Client) (bool, error) {
+	current := installation.Status.QuotaDowngrade
+	if current != nil && current.From == installation.Status.Quota && current.To == to && current.Phase == rhmiv1alpha1.PhaseInProgress {
+		return true, nil
+	}
+
+	monitoringConfig, err := r.ConfigManager.ReadMonitoring()
+	if err != nil {
+		return false, fmt.Errorf("error reading monitoring config: %w", err)
+	}
+	usage, err := quota.NewPrometheusUsageGetter(fmt.Sprintf("http://prometheus-operated.%s.svc:9090", monitoringConfig.GetNamespace()))
+	if err != nil {
+		return false, err
+	}
+
+	acknowledged := installation.Annotations[quota.DowngradeAcknowledgedAnnotation] == to
+	downgrade, err := quota.CheckDowngrade(ctx, serverClient, configMap, installation.Status.Quota, to, usage, acknowledged, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("error checking the downgrade to quota %s: %w", to, err)
+	}
+	installation.Status.QuotaDowngrade = downgrade
+	if downgrade == nil {
+		return true, nil
+	}
+
+	if downgrade.Phase == rhmiv1alpha1.PhaseBlocked {
+		r.log.Warningf("Quota downgrade blocked", l.Fields{"quota": downgrade.From, "toQuota": downgrade.To, "reason": downgrade.Message})
+		if current == nil || current.To != to || current.Phase != rhmiv1alpha1.PhaseBlocked {
+			r.recorder.Event(installation, "Warning", rhmiv1alpha1.EventQuotaDowngrade,
+				fmt.Sprintf("Downgrade from quota %s to %s blocked: %s", downgrade.From, downgrade.To, downgrade.Message))
+		}
+		return false, nil
+	}
+
+	message := fmt.Sprintf("Downgrade from quota %s to %s started, the workloads are scaled down in steps", downgrade.From, downgrade.To)
+	eventType := "Normal"
+	if downgrade.Acknowledged {
+		message = fmt.Sprintf("%s. %s", message, downgrade.Message)
+		eventType = "Warning"
+		r.log.Warningf("Quota downgrade acknowledged", l.Fields{"quota": downgrade.From, "toQuota": downgrade.To, "reason": downgrade.Message})
+	}
+	r.recorder.Event(installation, eventType, rhmiv1alpha1.EventQuotaDowngrade, message)
+	return true, nil
+}
+
+// previewQuota sets the impact of changing the quota of the installation to
+// the quota to in the status of the installation. The preview is refreshed
+// after PreviewRefreshInterval, as the capacity of the cluster changes
+func (r *Reconciler) previewQuota(ctx context.Context, installation *rhmiv1alpha1.RHMI, configMap *corev1.ConfigMap, to string, serverClient k8sclient.Client) error {
+	if to == "" || to == installation.Status.Quota {
+		installation.Status.QuotaPreview = nil
+		return nil
+	}
+
+	current := installation.Status.QuotaPreview
+	if current != nil && current.From == installation.Status.Quota && current.To == to &&
+		time.Since(current.GeneratedAt.Time) < quota.PreviewRefreshInterval {
+		return nil
+	}
+
+	preview, err := quota.PreviewChange(ctx, serverClient, configMap, installation.Status.Quota, to, time.Now())
+	if err != nil {
+		return fmt.Errorf("error previewing the change to quota %s: %w", to, err)
+	}
+	installation.Status.QuotaPreview = preview
+	r.log.Infof("Quota change previewed", l.Fields{"quota": preview.From, "toQuota": preview.To, "fits": preview.Capacity.Fits})
+
+	if !preview.Capacity.Fits && (current == nil || current.To != to || current.Capacity.Fits) {
+		r.recorder.Event(installation, "Warning", rhmiv1alpha1.EventQuotaPreview,
+			fmt.Sprintf("The cluster may not have the capacity for the change to quota %s: %s", to, preview.Capacity.Message))
+	}
+	return nil
+}
+
+// holdQuotaChange returns true if the change of the quota of the installation
+// to the quota to is held, because its preview doesn't fit in the cluster
+// and the change isn't acknowledged. The outcome is set in the
+// QuotaChangeHeld condition of the installation
+func (r *Reconciler) holdQuotaChange(installation *rhmiv1alpha1.RHMI, to string) bool {
+	conditions := &installation.Status.Conditions
+	generation := installation.GetGeneration()
+
+	preview := installation.Status.QuotaPreview
+	if installation.Status.Quota == "" || to == installation.Status.Quota ||
+		preview == nil || preview.To != to || preview.Capacity.Fits {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionQuotaChangeHeld, false, reasonAsExpected, "")
+		return false
+	}
+
+	if installation.Annotations[quota.CapacityAcknowledgedAnnotation] == to {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionQuotaChangeHeld, false, reasonCapacityAcknowledged,
+			fmt.Sprintf("The change to quota %s is applied although the cluster may not have the capacity for it: %s", to, preview.Capacity.Message))
+		return false
+	}
+
+	if !meta.IsStatusConditionTrue(*conditions, rhmiv1alpha1.ConditionQuotaChangeHeld) {
+		r.log.Warningf("Quota change held", l.Fields{"quota": installation.Status.Quota, "toQuota": to, "reason": preview.Capacity.Message})
+	}
+	setCondition(conditions, generation, rhmiv1alpha1.ConditionQuotaChangeHeld, true, reasonInsufficientCapacity,
+		fmt.Sprintf("The change from quota %s to %s is held until the cluster has the capacity for it, or the %s annotation is set to %s: %s",
+			installation.Status.Quota, to, quota.CapacityAcknowledgedAnnotation, to, preview.Capacity.Message))
+	return true
+}
+
 func getSecretQuotaParam(installation *rhmiv1alpha1.RHMI, serverClient k8sclient.Client, namespace string) (string, error) {
 	// Check for normal addon quota parameter
 	quotaParam, found, err := addon.GetStringParameterByInstallType(context.TODO(), serverClient, rhmiv1alpha1.InstallationTypeManagedApi, namespace, addon.QuotaParamName)
@@ -11,8 +11,10 @@ import (
 	moqclient "github.com/integr8ly/integreatly-operator/pkg/client"
 	"github.com/integr8ly/integreatly-operator/pkg/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 	rbacv1 "k8s.io/api/rbac/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -129,3 +131,84 @@ func assertRoleBindingNotFound(client k8sclient.Client) error {
 
 	return nil
 }
+
+func TestReconciler_holdQuotaChange(t *testing.T) {
+	preview := func(to string, fits bool) *integreatlyv1alpha1.QuotaPreview {
+		return &integreatlyv1alpha1.QuotaPreview{
+			From:     "100",
+			To:       to,
+			Capacity: integreatlyv1alpha1.QuotaCapacity{Fits: fits, Message: "requires 4 CPU, 2 available"},
+		}
+	}
+
+	tests := []struct {
+		Name           string
+		To             string
+		Preview        *integreatlyv1alpha1.QuotaPreview
+		Annotations    map[string]string
+		ExpectedHeld   bool
+		ExpectedReason string
+	}{
+		{
+			Name:           "Test change that fits is applied",
+			To:             "200",
+			Preview:        preview("200", true),
+			ExpectedReason: reasonAsExpected,
+		},
+		{
+			Name:           "Test change that doesn't fit is held",
+			To:             "200",
+			Preview:        preview("200", false),
+			ExpectedHeld:   true,
+			ExpectedReason: reasonInsufficientCapacity,
+		},
+		{
+			Name:           "Test acknowledged change that doesn't fit is applied",
+			To:             "200",
+			Preview:        preview("200", false),
+			Annotations:    map[string]string{quota.CapacityAcknowledgedAnnotation: "200"},
+			ExpectedReason: reasonCapacityAcknowledged,
+		},
+		{
+			Name:           "Test acknowledgement of another quota doesn't apply the change",
+			To:             "200",
+			Preview:        preview("200", false),
+			Annotations:    map[string]string{quota.CapacityAcknowledgedAnnotation: "500"},
+			ExpectedHeld:   true,
+			ExpectedReason: reasonInsufficientCapacity,
+		},
+		{
+			Name:           "Test preview of another quota doesn't hold the change",
+			To:             "200",
+			Preview:        preview("500", false),
+			ExpectedReason: reasonAsExpected,
+		},
+		{
+			Name:           "Test no change is held without a quota change",
+			To:             "100",
+			Preview:        nil,
+			ExpectedReason: reasonAsExpected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			installation := &integreatlyv1alpha1.RHMI{
+				ObjectMeta: v1.ObjectMeta{Annotations: tt.Annotations},
+				Status:     integreatlyv1alpha1.RHMIStatus{Quota: "100", QuotaPreview: tt.Preview},
+			}
+			reconciler, err := NewBootstrapReconciler(&config.ConfigReadWriterMock{}, installation, &marketplace.MarketplaceInterfaceMock{}, record.NewFakeRecorder(50), l.NewLogger())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if held := reconciler.holdQuotaChange(installation, tt.To); held != tt.ExpectedHeld {
+				t.Fatalf("expected held %t, got %t", tt.ExpectedHeld, held)
+			}
+			condition := meta.FindStatusCondition(installation.Status.Conditions, integreatlyv1alpha1.ConditionQuotaChangeHeld)
+			if condition == nil || condition.Reason != tt.ExpectedReason || meta.IsStatusConditionTrue(installation.Status.Conditions, integreatlyv1alpha1.ConditionQuotaChangeHeld) != tt.ExpectedHeld {
+				t.Fatalf("expected condition %s with reason %s, got %+v", integreatlyv1alpha1.ConditionQuotaChangeHeld, tt.ExpectedReason, condition)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"fmt"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the RHMI and product conditions
+const (
+	reasonInstallationComplete = "InstallationComplete"
+	reasonInstalling           = "Installing"
+	reasonStageInProgress      = "StageInProgress"
+	reasonReconcileFailed      = "ReconcileFailed"
+	reasonAsExpected           = "AsExpected"
+	reasonUpgradeInProgress    = "UpgradeInProgress"
+	reasonNoUpgrade            = "NoUpgradeInProgress"
+	reasonDeleted              = "InstallationDeleted"
+	reasonNotDeleted           = "InstallationNotDeleted"
+	reasonProductInstalled     = "ProductInstalled"
+	reasonProductDisabled      = "ProductDisabled"
+	reasonDependenciesNotReady = "DependenciesNotReady"
+	reasonInsufficientCapacity = "InsufficientCapacity"
+	reasonCapacityAcknowledged = "CapacityAcknowledged"
+)
+
+// setInstallationConditions updates the conditions of the installation at
+// the end of a reconcile of the install stages
+func setInstallationConditions(installation *rhmiv1alpha1.RHMI, installInProgress bool) {
+	conditions := &installation.Status.Conditions
+	generation := installation.GetGeneration()
+
+	switch {
+	case !installInProgress:
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, true, reasonInstallationComplete, "All the install stages are complete")
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionProgressing, false, reasonInstallationComplete, "All the install stages are complete")
+	case installation.Status.Version != "":
+		// A previous install completed, the products remain available
+		// while the stages are reconciled again
+		stageMessage := fmt.Sprintf("Stage %s is in progress", installation.Status.Stage)
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, true, reasonStageInProgress, stageMessage)
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionProgressing, true, reasonStageInProgress, stageMessage)
+	default:
+		stageMessage := fmt.Sprintf("Stage %s is in progress", installation.Status.Stage)
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, false, reasonInstalling, stageMessage)
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionProgressing, true, reasonStageInProgress, stageMessage)
+	}
+
+	if installation.Status.LastError != "" {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionDegraded, true, reasonReconcileFailed, installation.Status.LastError)
+	} else {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionDegraded, false, reasonAsExpected, "")
+	}
+
+	if installation.Status.Version != "" && installation.Status.ToVersion != "" {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionUpgrading, true, reasonUpgradeInProgress,
+			fmt.Sprintf("Upgrading from version %s to %s", installation.Status.Version, installation.Status.ToVersion))
+	} else {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionUpgrading, false, reasonNoUpgrade, "")
+	}
+
+	setCondition(conditions, generation, rhmiv1alpha1.ConditionUninstalling, false, reasonNotDeleted, "")
+}
+
+// setUninstallConditions updates the conditions of an installation that is
+// being deleted
+func setUninstallConditions(installation *rhmiv1alpha1.RHMI, lastError string) {
+	conditions := &installation.Status.Conditions
+	generation := installation.GetGeneration()
+
+	setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, false, reasonDeleted, "The installation is being uninstalled")
+	setCondition(conditions, generation, rhmiv1alpha1.ConditionProgressing, true, reasonDeleted, "The installation is being uninstalled")
+	setCondition(conditions, generation, rhmiv1alpha1.ConditionUninstalling, true, reasonDeleted, "The installation is being uninstalled")
+	setCondition(conditions, generation, rhmiv1alpha1.ConditionUpgrading, false, reasonNoUpgrade, "")
+
+	if lastError != "" {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionDegraded, true, reasonReconcileFailed, lastError)
+	} else {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionDegraded, false, reasonAsExpected, "")
+	}
+}
+
+// setProductConditions updates the conditions of a product after it was
+// reconciled. The product status is rebuilt on every reconcile, so the
+// conditions are carried over from the current status of the installation
+// to keep their transition times
+func setProductConditions(installation *rhmiv1alpha1.RHMI, product *rhmiv1alpha1.RHMIProductStatus, err error) {
+	current := installation.GetProductStatusObject(product.Name).Conditions
+	product.Conditions = append([]metav1.Condition{}, current...)
+
+	conditions := &product.Conditions
+	generation := installation.GetGeneration()
+
+	switch product.Status {
+	case rhmiv1alpha1.PhaseCompleted:
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, true, reasonProductInstalled, "")
+	case rhmiv1alpha1.PhaseDisabled:
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, false, reasonProductDisabled, "The product is disabled in the installation spec")
+	case rhmiv1alpha1.PhaseBlocked:
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, false, reasonDependenciesNotReady, fmt.Sprintf("Waiting for the products %v to complete", product.BlockedBy))
+	default:
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionAvailable, false, reasonInstalling, fmt.Sprintf("The product is in phase %q", product.Status))
+	}
+
+	if err != nil || product.Status == rhmiv1alpha1.PhaseFailed {
+		message := "The product failed to reconcile"
+		if err != nil {
+			message = err.Error()
+		}
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionDegraded, true, reasonReconcileFailed, message)
+	} else {
+		setCondition(conditions, generation, rhmiv1alpha1.ConditionDegraded, false, reasonAsExpected, "")
+	}
+}
+
+// setCondition sets the condition in the list. The last transition time is
+// only updated when the status of the condition changes
+func setCondition(conditions *[]metav1.Condition, generation int64, conditionType string, status bool, reason, message string) {
+	conditionStatus := metav1.ConditionFalse
+	if status {
+		conditionStatus = metav1.ConditionTrue
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		ObservedGeneration: generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
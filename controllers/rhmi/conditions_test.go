@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetInstallationConditions(t *testing.T) {
+	tests := []struct {
+		Name              string
+		Status            rhmiv1alpha1.RHMIStatus
+		InstallInProgress bool
+		Expected          map[string]metav1.ConditionStatus
+	}{
+		{
+			Name:              "first install in progress",
+			Status:            rhmiv1alpha1.RHMIStatus{Stage: rhmiv1alpha1.ProductsStage, ToVersion: "1.0.0"},
+			InstallInProgress: true,
+			Expected: map[string]metav1.ConditionStatus{
+				rhmiv1alpha1.ConditionAvailable:    metav1.ConditionFalse,
+				rhmiv1alpha1.ConditionProgressing:  metav1.ConditionTrue,
+				rhmiv1alpha1.ConditionDegraded:     metav1.ConditionFalse,
+				rhmiv1alpha1.ConditionUpgrading:    metav1.ConditionFalse,
+				rhmiv1alpha1.ConditionUninstalling: metav1.ConditionFalse,
+			},
+		},
+		{
+			Name:              "upgrade in progress with errors",
+			Status:            rhmiv1alpha1.RHMIStatus{Stage: rhmiv1alpha1.ProductsStage, Version: "1.0.0", ToVersion: "1.1.0", LastError: "failed"},
+			InstallInProgress: true,
+			Expected: map[string]metav1.ConditionStatus{
+				rhmiv1alpha1.ConditionAvailable:   metav1.ConditionTrue,
+				rhmiv1alpha1.ConditionProgressing: metav1.ConditionTrue,
+				rhmiv1alpha1.ConditionDegraded:    metav1.ConditionTrue,
+				rhmiv1alpha1.ConditionUpgrading:   metav1.ConditionTrue,
+			},
+		},
+		{
+			Name:   "install complete",
+			Status: rhmiv1alpha1.RHMIStatus{Stage: "complete", Version: "1.0.0"},
+			Expected: map[string]metav1.ConditionStatus{
+				rhmiv1alpha1.ConditionAvailable:   metav1.ConditionTrue,
+				rhmiv1alpha1.ConditionProgressing: metav1.ConditionFalse,
+				rhmiv1alpha1.ConditionDegraded:    metav1.ConditionFalse,
+				rhmiv1alpha1.ConditionUpgrading:   metav1.ConditionFalse,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			installation := &rhmiv1alpha1.RHMI{Status: tt.Status}
+			setInstallationConditions(installation, tt.InstallInProgress)
+
+			for conditionType, status := range tt.Expected {
+				if !meta.IsStatusConditionPresentAndEqual(installation.Status.Conditions, conditionType, status) {
+					t.Errorf("expected condition %s to be %s, got %+v", conditionType, status, meta.FindStatusCondition(installation.Status.Conditions, conditionType))
+				}
+			}
+		})
+	}
+}
+
+func TestSetProductConditions(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	installation := &rhmiv1alpha1.RHMI{
+		Status: rhmiv1alpha1.RHMIStatus{
+			Stages: map[rhmiv1alpha1.StageName]rhmiv1alpha1.RHMIStageStatus{
+				rhmiv1alpha1.ProductsStage: {
+					Products: map[rhmiv1alpha1.ProductName]rhmiv1alpha1.RHMIProductStatus{
+						rhmiv1alpha1.Product3Scale: {
+							Name: rhmiv1alpha1.Product3Scale,
+							Conditions: []metav1.Condition{
+								{
+									Type:               rhmiv1alpha1.ConditionAvailable,
+									Status:             metav1.ConditionTrue,
+									Reason:             reasonProductInstalled,
+									LastTransitionTime: transitionTime,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The product is still completed, the transition time is kept
+	product := &rhmiv1alpha1.RHMIProductStatus{Name: rhmiv1alpha1.Product3Scale, Status: rhmiv1alpha1.PhaseCompleted}
+	setProductConditions(installation, product, nil)
+	available := meta.FindStatusCondition(product.Conditions, rhmiv1alpha1.ConditionAvailable)
+	if available == nil || !available.LastTransitionTime.Equal(&transitionTime) {
+		t.Fatalf("expected the Available condition transition time to be kept, got %+v", available)
+	}
+	if !meta.IsStatusConditionFalse(product.Conditions, rhmiv1alpha1.ConditionDegraded) {
+		t.Fatalf("expected the Degraded condition to be false, got %+v", product.Conditions)
+	}
+
+	// The product failed, it is no longer available and is degraded
+	product = &rhmiv1alpha1.RHMIProductStatus{Name: rhmiv1alpha1.Product3Scale, Status: rhmiv1alpha1.PhaseFailed}
+	setProductConditions(installation, product, errors.New("reconcile failed"))
+	available = meta.FindStatusCondition(product.Conditions, rhmiv1alpha1.ConditionAvailable)
+	if available == nil || available.Status != metav1.ConditionFalse || available.LastTransitionTime.Equal(&transitionTime) {
+		t.Fatalf("expected the Available condition to transition to false, got %+v", available)
+	}
+	degraded := meta.FindStatusCondition(product.Conditions, rhmiv1alpha1.ConditionDegraded)
+	if degraded == nil || degraded.Status != metav1.ConditionTrue || degraded.Message != "reconcile failed" {
+		t.Fatalf("expected the Degraded condition to be true with the error message, got %+v", degraded)
+	}
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"sort"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products"
+)
+
+// blockingDependencies returns the dependencies of the product that haven't
+// completed yet. Dependencies that are not part of the installation type or
+// that are disabled are ignored, as they are never installed
+func blockingDependencies(installation *rhmiv1alpha1.RHMI, installType *Type, product rhmiv1alpha1.ProductName) []rhmiv1alpha1.ProductName {
+	var blockedBy []rhmiv1alpha1.ProductName
+	for _, dependency := range products.GetDependencies(product) {
+		if !installType.HasProduct(string(dependency)) || !installation.IsProductEnabled(dependency) {
+			continue
+		}
+		if installation.GetProductStatusObject(dependency).Status != rhmiv1alpha1.PhaseCompleted {
+			blockedBy = append(blockedBy, dependency)
+		}
+	}
+	return blockedBy
+}
+
+// dependentProducts returns the enabled products of the installation type
+// that depend on the product
+func dependentProducts(installation *rhmiv1alpha1.RHMI, installType *Type, product rhmiv1alpha1.ProductName) []rhmiv1alpha1.ProductName {
+	var dependents []rhmiv1alpha1.ProductName
+	for _, stage := range installType.GetInstallStages() {
+		for name := range stage.Products {
+			if !installation.IsProductEnabled(name) {
+				continue
+			}
+			for _, dependency := range products.GetDependencies(name) {
+				if dependency == product {
+					dependents = append(dependents, name)
+					break
+				}
+			}
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i] < dependents[j] })
+	return dependents
+}
@@ -0,0 +1,256 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func installationWithProducts(products map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase) *rhmiv1alpha1.RHMI {
+	stage := rhmiv1alpha1.RHMIStageStatus{
+		Name:     rhmiv1alpha1.ProductsStage,
+		Products: map[rhmiv1alpha1.ProductName]rhmiv1alpha1.RHMIProductStatus{},
+	}
+	for name, phase := range products {
+		stage.Products[name] = rhmiv1alpha1.RHMIProductStatus{Name: name, Status: phase}
+	}
+
+	return &rhmiv1alpha1.RHMI{
+		Status: rhmiv1alpha1.RHMIStatus{
+			Stages: map[rhmiv1alpha1.StageName]rhmiv1alpha1.RHMIStageStatus{
+				rhmiv1alpha1.ProductsStage: stage,
+			},
+		},
+	}
+}
+
+func TestBlockingDependencies(t *testing.T) {
+	managedApi, err := TypeFactory(string(rhmiv1alpha1.InstallationTypeManagedApi))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name         string
+		Installation *rhmiv1alpha1.RHMI
+		Product      rhmiv1alpha1.ProductName
+		Expected     []rhmiv1alpha1.ProductName
+	}{
+		{
+			Name:         "test product without dependencies is never blocked",
+			Installation: installationWithProducts(nil),
+			Product:      rhmiv1alpha1.ProductCloudResources,
+		},
+		{
+			Name: "test product is blocked by incomplete dependencies",
+			Installation: installationWithProducts(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase{
+				rhmiv1alpha1.ProductCloudResources: rhmiv1alpha1.PhaseCompleted,
+				rhmiv1alpha1.ProductMonitoring:     rhmiv1alpha1.PhaseInProgress,
+			}),
+			Product:  rhmiv1alpha1.ProductRHSSO,
+			Expected: []rhmiv1alpha1.ProductName{rhmiv1alpha1.ProductMonitoring, rhmiv1alpha1.ProductMonitoringSpec},
+		},
+		{
+			Name: "test product is not blocked once its dependencies completed",
+			Installation: installationWithProducts(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase{
+				rhmiv1alpha1.ProductRHSSO: rhmiv1alpha1.PhaseCompleted,
+			}),
+			Product: rhmiv1alpha1.Product3Scale,
+		},
+		{
+			Name: "test dependencies outside of the installation type are ignored",
+			Installation: installationWithProducts(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase{
+				rhmiv1alpha1.ProductRHSSO:     rhmiv1alpha1.PhaseCompleted,
+				rhmiv1alpha1.ProductRHSSOUser: rhmiv1alpha1.PhaseCompleted,
+			}),
+			Product:  rhmiv1alpha1.ProductSolutionExplorer,
+			Expected: []rhmiv1alpha1.ProductName{rhmiv1alpha1.Product3Scale},
+		},
+		{
+			Name: "test disabled dependencies are ignored",
+			Installation: func() *rhmiv1alpha1.RHMI {
+				installation := installationWithProducts(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase{
+					rhmiv1alpha1.ProductRHSSO:     rhmiv1alpha1.PhaseCompleted,
+					rhmiv1alpha1.ProductRHSSOUser: rhmiv1alpha1.PhaseCompleted,
+				})
+				disabled := false
+				installation.Spec.Products = map[rhmiv1alpha1.ProductName]rhmiv1alpha1.ProductSpec{
+					rhmiv1alpha1.Product3Scale: {Enabled: &disabled},
+				}
+				return installation
+			}(),
+			Product: rhmiv1alpha1.ProductSolutionExplorer,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			blockedBy := blockingDependencies(tc.Installation, managedApi, tc.Product)
+			if !reflect.DeepEqual(blockedBy, tc.Expected) {
+				t.Fatalf("expected blocking dependencies %v, got %v", tc.Expected, blockedBy)
+			}
+		})
+	}
+}
+
+func TestProcessStageBlockedProducts(t *testing.T) {
+	managedApi, err := TypeFactory(string(rhmiv1alpha1.InstallationTypeManagedApi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	installation := installationWithProducts(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase{
+		rhmiv1alpha1.ProductRHSSO: rhmiv1alpha1.PhaseInProgress,
+	})
+	stage := &Stage{
+		Name: rhmiv1alpha1.ProductsStage,
+		Products: map[rhmiv1alpha1.ProductName]rhmiv1alpha1.RHMIProductStatus{
+			rhmiv1alpha1.Product3Scale:  {Name: rhmiv1alpha1.Product3Scale},
+			rhmiv1alpha1.ProductGrafana: {Name: rhmiv1alpha1.ProductGrafana},
+		},
+	}
+
+	r := &RHMIReconciler{}
+	phase, err := r.processStage(context.TODO(), installation, stage, managedApi, nil, &quota.Quota{}, l.NewLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if phase != rhmiv1alpha1.PhaseInProgress {
+		t.Fatalf("expected stage phase %s, got %s", rhmiv1alpha1.PhaseInProgress, phase)
+	}
+
+	for name, product := range stage.Products {
+		if product.Status != rhmiv1alpha1.PhaseBlocked {
+			t.Errorf("expected %s to be blocked, got %s", name, product.Status)
+		}
+		if !reflect.DeepEqual(product.BlockedBy, []rhmiv1alpha1.ProductName{rhmiv1alpha1.ProductRHSSO}) {
+			t.Errorf("expected %s to be blocked by rhsso, got %v", name, product.BlockedBy)
+		}
+		condition := meta.FindStatusCondition(product.Conditions, rhmiv1alpha1.ConditionAvailable)
+		if condition == nil || condition.Reason != reasonDependenciesNotReady {
+			t.Errorf("expected %s to have the %s reason, got %v", name, reasonDependenciesNotReady, condition)
+		}
+	}
+}
+
+func TestDependentProducts(t *testing.T) {
+	managed, err := TypeFactory(string(rhmiv1alpha1.InstallationTypeManaged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	disabled := false
+
+	cases := []struct {
+		Name     string
+		Products map[rhmiv1alpha1.ProductName]rhmiv1alpha1.ProductSpec
+		Product  rhmiv1alpha1.ProductName
+		Expected []rhmiv1alpha1.ProductName
+	}{
+		{
+			Name:     "test enabled products depending on the product are returned",
+			Product:  rhmiv1alpha1.Product3Scale,
+			Expected: []rhmiv1alpha1.ProductName{rhmiv1alpha1.ProductSolutionExplorer},
+		},
+		{
+			Name: "test disabled products are ignored",
+			Products: map[rhmiv1alpha1.ProductName]rhmiv1alpha1.ProductSpec{
+				rhmiv1alpha1.ProductSolutionExplorer: {Enabled: &disabled},
+			},
+			Product: rhmiv1alpha1.Product3Scale,
+		},
+		{
+			Name:    "test product nothing depends on",
+			Product: rhmiv1alpha1.ProductSolutionExplorer,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			installation := &rhmiv1alpha1.RHMI{Spec: rhmiv1alpha1.RHMISpec{Products: tc.Products}}
+			dependents := dependentProducts(installation, managed, tc.Product)
+			if !reflect.DeepEqual(dependents, tc.Expected) {
+				t.Fatalf("expected dependent products %v, got %v", tc.Expected, dependents)
+			}
+		})
+	}
+}
+
+func TestProcessStageDisabledDependency(t *testing.T) {
+	managed, err := TypeFactory(string(rhmiv1alpha1.InstallationTypeManaged))
+	if err != nil {
+		t.Fatal(err)
+	}
+	installation := installationWithProducts(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.StatusPhase{
+		rhmiv1alpha1.Product3Scale: rhmiv1alpha1.PhaseCompleted,
+	})
+	disabled := false
+	installation.Spec.Products = map[rhmiv1alpha1.ProductName]rhmiv1alpha1.ProductSpec{
+		rhmiv1alpha1.Product3Scale: {Enabled: &disabled},
+	}
+	stage := &Stage{
+		Name: rhmiv1alpha1.ProductsStage,
+		Products: map[rhmiv1alpha1.ProductName]rhmiv1alpha1.RHMIProductStatus{
+			rhmiv1alpha1.Product3Scale: {Name: rhmiv1alpha1.Product3Scale, Status: rhmiv1alpha1.PhaseCompleted},
+		},
+	}
+
+	r := &RHMIReconciler{}
+	phase, err := r.processStage(context.TODO(), installation, stage, managed, nil, &quota.Quota{}, l.NewLogger())
+	if err == nil {
+		t.Fatal("expected an error disabling a product the solution explorer depends on")
+	}
+	if phase != rhmiv1alpha1.PhaseInProgress {
+		t.Fatalf("expected stage phase %s, got %s", rhmiv1alpha1.PhaseInProgress, phase)
+	}
+
+	// The product is left as it is
+	product := stage.Products[rhmiv1alpha1.Product3Scale]
+	if product.Status != rhmiv1alpha1.PhaseCompleted {
+		t.Fatalf("expected 3scale to be left completed, got %s", product.Status)
+	}
+	condition := meta.FindStatusCondition(product.Conditions, rhmiv1alpha1.ConditionDegraded)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		t.Fatalf("expected 3scale to be degraded, got %v", condition)
+	}
+}
+
+func TestJoinStageErrors(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Errors   []error
+		Expected string
+	}{
+		{
+			Name:     "test no errors",
+			Expected: "",
+		},
+		{
+			Name:     "test single error",
+			Errors:   []error{errors.New("bootstrap failed")},
+			Expected: "bootstrap failed",
+		},
+		{
+			Name: "test product errors of several stages",
+			Errors: []error{
+				&resources.MultiErr{Errors: []string{"failed installation of rhsso"}},
+				&resources.MultiErr{Errors: []string{"failed installation of 3scale"}},
+			},
+			Expected: "product installation errors : failed installation of rhsso:  failed installation of 3scale",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if message := joinStageErrors(tc.Errors); message != tc.Expected {
+				t.Fatalf("expected %q, got %q", tc.Expected, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/dryrun"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"github.com/integr8ly/integreatly-operator/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// bootstrapPlanEntry is the entry of the bootstrap stage in the dry run plan,
+// as it isn't reconciled by a product reconciler
+const bootstrapPlanEntry rhmiv1alpha1.ProductName = "bootstrap"
+
+// dryRunReconcile runs the reconcilers of every stage of the installation
+// type with a client that sends every write in dry run mode, and publishes
+// the resulting plan. The other clients of the reconcilers and their event
+// recorders are replaced by dry run ones as well, see
+// products.NewDryRunReconciler. Nothing is applied to the cluster and the
+// status of the installation is left untouched
+func (r *RHMIReconciler) dryRunReconcile(installation *rhmiv1alpha1.RHMI, installType *Type, installationCfgMap string, request ctrl.Request) (ctrl.Result, error) {
+	log.Info("dry run mode enabled, computing installation plan")
+
+	result := ctrl.Result{
+		Requeue:      true,
+		RequeueAfter: 5 * time.Minute,
+	}
+
+	serverClient, err := k8sclient.New(r.restConfig, k8sclient.Options{
+		Scheme: r.mgr.GetScheme(),
+	})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("could not create server client: %w", err)
+	}
+
+	// Work on a copy of the installation, the reconcilers update its status
+	// as they progress and those changes must not be persisted
+	dryRunInstallation := installation.DeepCopy()
+	dryRunInstallation.Status.ToVersion = version.GetVersionByType(installation.Spec.Type)
+	if dryRunInstallation.Status.Stages == nil {
+		dryRunInstallation.Status.Stages = map[rhmiv1alpha1.StageName]rhmiv1alpha1.RHMIStageStatus{}
+	}
+
+	plan := dryrun.NewPlan(installation, dryRunInstallation.Status.ToVersion)
+	installationQuota := &quota.Quota{}
+
+	// Unlike a regular reconcile, every stage is processed even if the
+	// previous one did not complete, so the plan covers the whole install
+	for _, stage := range installType.GetInstallStages() {
+		stageLog := l.NewLoggerWithContext(l.Fields{l.StageLogContext: stage.Name})
+
+		if stage.Name == rhmiv1alpha1.BootstrapStage {
+			phase, err := r.dryRunBootstrapStage(dryRunInstallation, serverClient, plan, installationCfgMap, installationQuota, stageLog, request)
+			plan.SetProductResult(stage.Name, bootstrapPlanEntry, phase, err)
+			continue
+		}
+
+		for productName := range stage.Products {
+			phase, err := r.dryRunProduct(dryRunInstallation, installType, stage.Name, productName, serverClient, plan, installationCfgMap, installationQuota)
+			plan.SetProductResult(stage.Name, productName, phase, err)
+		}
+	}
+
+	if err := plan.Publish(context.TODO(), serverClient, installation.Namespace); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	log.Infof("dry run plan published", l.Fields{"configMap": dryrun.PlanConfigMapName, "changes": plan.Changes()})
+	return result, nil
+}
+
+func (r *RHMIReconciler) dryRunBootstrapStage(installation *rhmiv1alpha1.RHMI, serverClient k8sclient.Client, plan *dryrun.Plan, installationCfgMap string, installationQuota *quota.Quota, log l.Logger, request ctrl.Request) (rhmiv1alpha1.StatusPhase, error) {
+	recorder := plan.RecorderFor(rhmiv1alpha1.BootstrapStage, bootstrapPlanEntry)
+	dryRunClient := dryrun.NewClient(serverClient, r.mgr.GetScheme(), recorder)
+
+	configManager, err := config.NewManager(context.TODO(), dryRunClient, installation.Namespace, installationCfgMap, installation)
+	if err != nil {
+		return rhmiv1alpha1.PhaseFailed, err
+	}
+
+	reconciler, err := NewBootstrapReconciler(configManager, installation, marketplace.NewManager(), dryrun.NewEventRecorder(r.mgr.GetScheme(), recorder), log)
+	if err != nil {
+		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("failed to build a reconciler for Bootstrap: %w", err)
+	}
+
+	return reconciler.Reconcile(context.TODO(), installation, dryRunClient, installationQuota, request)
+}
+
+func (r *RHMIReconciler) dryRunProduct(installation *rhmiv1alpha1.RHMI, installType *Type, stageName rhmiv1alpha1.StageName, productName rhmiv1alpha1.ProductName, serverClient k8sclient.Client, plan *dryrun.Plan, installationCfgMap string, installationQuota *quota.Quota) (rhmiv1alpha1.StatusPhase, error) {
+	productLog := l.NewLoggerWithContext(l.Fields{l.ProductLogContext: productName})
+	recorder := plan.RecorderFor(stageName, productName)
+	dryRunClient := dryrun.NewClient(serverClient, r.mgr.GetScheme(), recorder)
+
+	// The config manager writes the product configuration into the
+	// installation ConfigMap, so it must use the dry run client as well
+	configManager, err := config.NewManager(context.TODO(), dryRunClient, installation.Namespace, installationCfgMap, installation)
+	if err != nil {
+		return rhmiv1alpha1.PhaseFailed, err
+	}
+
+	productStatus := installation.GetProductStatusObject(productName)
+	if !installation.IsProductEnabled(productName) {
+		if dependents := dependentProducts(installation, installType, productName); len(dependents) > 0 {
+			return productStatus.Status, fmt.Errorf("product %s can't be disabled, the enabled products %v depend on it", productName, dependents)
+		}
+		return r.reconcileDisabledProduct(context.TODO(), installation, productStatus, configManager, dryRunClient, productLog, recorder)
+	}
+
+	reconciler, err := r.newProductReconciler(productName, configManager, installation, productLog, recorder)
+	if err != nil {
+		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("failed to build a reconciler for %s: %w", productName, err)
+	}
+
+	return reconciler.Reconcile(context.TODO(), installation, productStatus, dryRunClient, installationQuota.GetProduct(productName))
+}
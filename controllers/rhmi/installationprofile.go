@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"k8s.io/apimachinery/pkg/api/meta"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// loadInstallationProfiles lists the InstallationProfile CRs in the given
+// namespace and returns the installation types they define, indexed by
+// installation type. The result of the validation of every profile is
+// written to its status, invalid profiles are ignored
+func loadInstallationProfiles(ctx context.Context, serverClient k8sclient.Client, namespace string) (map[string]*Type, error) {
+	installationTypes := map[string]*Type{}
+
+	profiles := &rhmiv1alpha1.InstallationProfileList{}
+	if err := serverClient.List(ctx, profiles, k8sclient.InNamespace(namespace)); err != nil {
+		if meta.IsNoMatchError(err) {
+			log.Warning("InstallationProfile CRD not found, using built-in installation profiles")
+			return installationTypes, nil
+		}
+		return nil, fmt.Errorf("failed to list installation profiles: %w", err)
+	}
+
+	// Sort the profiles so that, if several profiles target the same
+	// installation type, the same one is loaded on every restart
+	sort.Slice(profiles.Items, func(i, j int) bool {
+		return profiles.Items[i].Name < profiles.Items[j].Name
+	})
+
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+
+		err := validateInstallationProfile(profile.Spec)
+		if err == nil && installationTypes[profile.Spec.Type] != nil {
+			err = fmt.Errorf("another installation profile is already loaded for installation type %s", profile.Spec.Type)
+		}
+
+		if err != nil {
+			log.Errorf("Invalid installation profile, ignoring it", l.Fields{"profile": profile.Name}, err)
+			profile.Status = rhmiv1alpha1.InstallationProfileStatus{Valid: false, Message: err.Error()}
+		} else {
+			log.Infof("Loaded installation profile", l.Fields{"profile": profile.Name, "type": profile.Spec.Type})
+			installationTypes[profile.Spec.Type] = NewTypeFromProfile(profile.Spec)
+			profile.Status = rhmiv1alpha1.InstallationProfileStatus{Valid: true}
+		}
+
+		if err := serverClient.Status().Update(ctx, profile); err != nil {
+			return nil, fmt.Errorf("failed to update status of installation profile %s: %w", profile.Name, err)
+		}
+	}
+
+	return installationTypes, nil
+}
+
+// validateInstallationProfile checks that the stages of a profile can be
+// processed by the RHMI reconciler
+func validateInstallationProfile(profile rhmiv1alpha1.InstallationProfileSpec) error {
+	if len(profile.InstallStages) == 0 || profile.InstallStages[0].Name != rhmiv1alpha1.BootstrapStage {
+		return fmt.Errorf("the first install stage must be the %s stage", rhmiv1alpha1.BootstrapStage)
+	}
+	if len(profile.InstallStages[0].Products) != 0 {
+		return fmt.Errorf("the %s stage can't contain products", rhmiv1alpha1.BootstrapStage)
+	}
+
+	installed, err := validateProfileStages(profile.InstallStages)
+	if err != nil {
+		return fmt.Errorf("invalid install stages: %w", err)
+	}
+	uninstalled, err := validateProfileStages(profile.UninstallStages)
+	if err != nil {
+		return fmt.Errorf("invalid uninstall stages: %w", err)
+	}
+
+	for product := range installed {
+		if !uninstalled[product] {
+			return fmt.Errorf("product %s is installed but not part of any uninstall stage", product)
+		}
+	}
+
+	return nil
+}
+
+// validateProfileStages checks that the stage names are unique and that every
+// product is known and only part of one stage. It returns the products of
+// the stages
+func validateProfileStages(stages []rhmiv1alpha1.InstallationProfileStage) (map[rhmiv1alpha1.ProductName]bool, error) {
+	known := knownProducts()
+	stageNames := map[rhmiv1alpha1.StageName]bool{}
+	products := map[rhmiv1alpha1.ProductName]bool{}
+
+	for i, stage := range stages {
+		if stage.Name == "" {
+			return nil, fmt.Errorf("stage %d has no name", i)
+		}
+		if stageNames[stage.Name] {
+			return nil, fmt.Errorf("stage %s is declared more than once", stage.Name)
+		}
+		if i > 0 && stage.Name == rhmiv1alpha1.BootstrapStage {
+			return nil, fmt.Errorf("the %s stage can only be the first install stage", rhmiv1alpha1.BootstrapStage)
+		}
+		stageNames[stage.Name] = true
+
+		for _, product := range stage.Products {
+			if !known[product] {
+				return nil, fmt.Errorf("unknown product %s in stage %s", product, stage.Name)
+			}
+			if products[product] {
+				return nil, fmt.Errorf("product %s is part of more than one stage", product)
+			}
+			products[product] = true
+		}
+	}
+
+	return products, nil
+}
+
+// knownProducts returns the products registered with the operator, which
+// are the products the operator has a reconciler for
+func knownProducts() map[rhmiv1alpha1.ProductName]bool {
+	known := map[rhmiv1alpha1.ProductName]bool{}
+	for _, product := range registry.Products() {
+		known[product] = true
+	}
+	return known
+}
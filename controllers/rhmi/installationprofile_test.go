@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateInstallationProfile(t *testing.T) {
+	for _, profile := range builtinProfiles {
+		if err := validateInstallationProfile(profile); err != nil {
+			t.Errorf("built-in profile %s is invalid: %v", profile.Type, err)
+		}
+	}
+
+	tests := []struct {
+		Name    string
+		Profile integreatlyv1alpha1.InstallationProfileSpec
+		Valid   bool
+	}{
+		{
+			Name:    "trimmed profile is valid",
+			Profile: trimmedProfile(),
+			Valid:   true,
+		},
+		{
+			Name: "first stage must be bootstrap",
+			Profile: func() integreatlyv1alpha1.InstallationProfileSpec {
+				profile := trimmedProfile()
+				profile.InstallStages = profile.InstallStages[1:]
+				return profile
+			}(),
+		},
+		{
+			Name: "unknown product",
+			Profile: func() integreatlyv1alpha1.InstallationProfileSpec {
+				profile := trimmedProfile()
+				profile.InstallStages[1].Products = append(profile.InstallStages[1].Products, "unknown")
+				return profile
+			}(),
+		},
+		{
+			Name: "product in more than one stage",
+			Profile: func() integreatlyv1alpha1.InstallationProfileSpec {
+				profile := trimmedProfile()
+				profile.InstallStages = append(profile.InstallStages, integreatlyv1alpha1.InstallationProfileStage{
+					Name:     integreatlyv1alpha1.ProductsStage,
+					Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
+				})
+				return profile
+			}(),
+		},
+		{
+			Name: "installed product without uninstall stage",
+			Profile: func() integreatlyv1alpha1.InstallationProfileSpec {
+				profile := trimmedProfile()
+				profile.UninstallStages = nil
+				return profile
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			err := validateInstallationProfile(tt.Profile)
+			if tt.Valid && err != nil {
+				t.Fatalf("expected profile to be valid, got: %v", err)
+			}
+			if !tt.Valid && err == nil {
+				t.Fatal("expected profile to be invalid")
+			}
+		})
+	}
+}
+
+func TestLoadInstallationProfiles(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := trimmedProfile()
+	invalid.UninstallStages = nil
+
+	serverClient := fakeclient.NewFakeClientWithScheme(scheme,
+		&integreatlyv1alpha1.InstallationProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "a-trimmed", Namespace: "test-namespace"},
+			Spec:       trimmedProfile(),
+		},
+		&integreatlyv1alpha1.InstallationProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "b-duplicate", Namespace: "test-namespace"},
+			Spec:       trimmedProfile(),
+		},
+		&integreatlyv1alpha1.InstallationProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: "c-invalid", Namespace: "test-namespace"},
+			Spec:       invalid,
+		},
+	)
+
+	installationTypes, err := loadInstallationProfiles(context.TODO(), serverClient, "test-namespace")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	installType, ok := installationTypes[string(integreatlyv1alpha1.InstallationTypeManagedApi)]
+	if !ok || len(installationTypes) != 1 {
+		t.Fatalf("expected only the managed-api installation type to be loaded, got %v", installationTypes)
+	}
+	if len(installType.GetInstallStages()) != 2 || len(installType.GetInstallStages()[1].Products) != 1 {
+		t.Fatalf("unexpected install stages: %v", installType.GetInstallStages())
+	}
+
+	for name, valid := range map[string]bool{"a-trimmed": true, "b-duplicate": false, "c-invalid": false} {
+		profile := &integreatlyv1alpha1.InstallationProfile{}
+		if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: "test-namespace"}, profile); err != nil {
+			t.Fatal(err)
+		}
+		if profile.Status.Valid != valid {
+			t.Errorf("expected profile %s valid status to be %t, got %t: %s", name, valid, profile.Status.Valid, profile.Status.Message)
+		}
+	}
+}
+
+func trimmedProfile() integreatlyv1alpha1.InstallationProfileSpec {
+	return integreatlyv1alpha1.InstallationProfileSpec{
+		Type: string(integreatlyv1alpha1.InstallationTypeManagedApi),
+		InstallStages: []integreatlyv1alpha1.InstallationProfileStage{
+			{Name: integreatlyv1alpha1.BootstrapStage},
+			{
+				Name:     integreatlyv1alpha1.CloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
+			},
+		},
+		UninstallStages: []integreatlyv1alpha1.InstallationProfileStage{
+			{
+				Name:     integreatlyv1alpha1.UninstallCloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
+			},
+		},
+	}
+}
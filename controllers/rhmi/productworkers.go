@@ -0,0 +1,414 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/products"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// productReconcileWorkers is the maximum number of products of a stage
+	// reconciled at the same time
+	productReconcileWorkers = 4
+	// productReconcileTimeout is the time a product reconcile can take
+	// before the product is reported as in progress, and retried in the
+	// next reconcile
+	productReconcileTimeout = 5 * time.Minute
+)
+
+type productResult struct {
+	product rhmiv1alpha1.RHMIProductStatus
+	err     error
+	// buildErr is set when the reconciler of the product can't be created.
+	// The product is failed, and so is the stage once the results of the
+	// other products are merged
+	buildErr        error
+	versionMismatch bool
+	// installation is the copy of the installation the product was
+	// reconciled against. It's nil if the reconcile timed out, as the
+	// product reconciler may still be using it
+	installation *rhmiv1alpha1.RHMI
+}
+
+// reconcileStageProducts reconciles the products of the stage using a
+// bounded pool of workers, and returns the result of each product
+func (r *RHMIReconciler) reconcileStageProducts(ctx context.Context, installation *rhmiv1alpha1.RHMI, stage *Stage, configManager config.ConfigReadWriter, quotaconfig *quota.Quota) []productResult {
+	productStatuses := make(chan rhmiv1alpha1.RHMIProductStatus, len(stage.Products))
+	for _, product := range stage.Products {
+		productStatuses <- product
+	}
+	close(productStatuses)
+
+	workers := productReconcileWorkers
+	if len(stage.Products) < workers {
+		workers = len(stage.Products)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make([]productResult, 0, len(stage.Products))
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for product := range productStatuses {
+				// Each product gets its own copy of the installation, as the
+				// product reconcilers modify it
+				result := r.reconcileProductWithTimeout(ctx, installation.DeepCopy(), product, configManager, quotaconfig.GetProduct(product.Name))
+
+				mu.Lock()
+				results = append(results, result)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// reconcileProductWithTimeout reconciles the product, giving up after
+// productReconcileTimeout. The context of the reconcile is cancelled in that
+// case, which fails the calls of its client, and the reconcile only works on
+// its own copy of the installation until it returns
+func (r *RHMIReconciler) reconcileProductWithTimeout(ctx context.Context, installation *rhmiv1alpha1.RHMI, product rhmiv1alpha1.RHMIProductStatus, configManager config.ConfigReadWriter, productConfig quota.ProductConfig) productResult {
+	ctx, cancel := context.WithTimeout(ctx, productReconcileTimeout)
+	defer cancel()
+
+	// The installation can't be read once the reconcile timed out, keep a
+	// copy to set the product conditions in that case
+	current := installation.DeepCopy()
+
+	done := make(chan productResult, 1)
+	go func() {
+		done <- r.reconcileProduct(ctx, installation, product, configManager, productConfig)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		product.Status = rhmiv1alpha1.PhaseInProgress
+		err := fmt.Errorf("reconcile of %s did not finish after %s", product.Name, productReconcileTimeout)
+		setProductConditions(current, &product, err)
+		return productResult{product: product, err: err}
+	}
+}
+
+// mergeStageInstallations merges the changes each product of the stage made
+// to its copy of the installation. Every result is merged, even if merging
+// another one fails
+func mergeStageInstallations(installation, original *rhmiv1alpha1.RHMI, results []productResult) error {
+	mErr := &resources.MultiErr{}
+	for _, result := range results {
+		if result.installation == nil {
+			continue
+		}
+		if err := mergeProductInstallationChanges(installation, original, result.installation); err != nil {
+			mErr.Add(fmt.Errorf("failed to merge the changes of %s: %w", result.product.Name, err))
+		}
+	}
+	if len(mErr.Errors) > 0 {
+		return mErr
+	}
+	return nil
+}
+
+// failedBuild returns the result of a product whose reconciler couldn't be
+// built, with the product failed
+func failedBuild(installation *rhmiv1alpha1.RHMI, product rhmiv1alpha1.RHMIProductStatus, result productResult) productResult {
+	product.Status = rhmiv1alpha1.PhaseFailed
+	setProductConditions(installation, &product, result.buildErr)
+	result.product = product
+	return result
+}
+
+func (r *RHMIReconciler) reconcileProduct(ctx context.Context, installation *rhmiv1alpha1.RHMI, product rhmiv1alpha1.RHMIProductStatus, configManager config.ConfigReadWriter, productConfig quota.ProductConfig) productResult {
+	productLog := l.NewLoggerWithContext(l.Fields{l.ProductLogContext: product.Name})
+	result := productResult{installation: installation}
+
+	client, err := k8sclient.New(r.restConfig, k8sclient.Options{
+		Scheme: r.mgr.GetScheme(),
+	})
+	if err != nil {
+		result.buildErr = fmt.Errorf("could not create server client: %w", err)
+		return failedBuild(installation, product, result)
+	}
+	serverClient := &stageClient{Client: client, ctx: ctx, installation: installation}
+
+	if !installation.IsProductEnabled(product.Name) {
+		product.Status, result.err = r.reconcileDisabledProduct(ctx, installation, &product, configManager, serverClient, productLog, nil)
+		setProductConditions(installation, &product, result.err)
+		result.product = product
+		return result
+	}
+
+	restore, err := productRestore(ctx, serverClient, installation.Namespace, product.Name)
+	if err != nil {
+		product.Status = rhmiv1alpha1.PhaseInProgress
+		result.err = err
+		setProductConditions(installation, &product, err)
+		result.product = product
+		return result
+	}
+	if restore != "" {
+		productLog.Infof("Skipping the reconcile of the product while it's restored", l.Fields{"restore": restore})
+		product.Status = rhmiv1alpha1.PhaseInProgress
+		setProductConditions(installation, &product, nil)
+		result.product = product
+		return result
+	}
+
+	reconciler, err := products.NewReconciler(product.Name, r.restConfig, configManager, installation, r.mgr, productLog, r.productsInstallationLoader)
+	if err != nil {
+		result.buildErr = fmt.Errorf("failed to build a reconciler for %s: %w", product.Name, err)
+		return failedBuild(installation, product, result)
+	}
+
+	result.versionMismatch = !products.VerifyVersion(product.Name, installation)
+
+	// The pre-upgrade backups of the product follow its own policy
+	ctx = backup.WithPreUpgradeGate(ctx, backup.PreUpgradeGateFromContext(ctx).ForProduct(product.Name))
+
+	product.Status, result.err = reconciler.Reconcile(ctx, installation, &product, serverClient, productConfig)
+	setProductConditions(installation, &product, result.err)
+	result.product = product
+	return result
+}
+
+// mergeProductInstallationChanges applies the changes a product reconciler
+// made to its copy of the installation, compared to the original
+// installation it was copied from: the finalizers, labels and annotations it
+// added or removed, and the fields of the status it changed. The changes of
+// every product are merged in turn, so the fields changed by several
+// products are merged key by key
+func mergeProductInstallationChanges(installation, original, productInstallation *rhmiv1alpha1.RHMI) error {
+	finalizers := installation.GetFinalizers()
+	for _, finalizer := range productInstallation.GetFinalizers() {
+		if !resources.Contains(original.GetFinalizers(), finalizer) && !resources.Contains(finalizers, finalizer) {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	for _, finalizer := range original.GetFinalizers() {
+		if !resources.Contains(productInstallation.GetFinalizers(), finalizer) {
+			finalizers = resources.Remove(finalizers, finalizer)
+		}
+	}
+	installation.SetFinalizers(finalizers)
+
+	installation.SetLabels(mergeStringMap(installation.GetLabels(), original.GetLabels(), productInstallation.GetLabels()))
+	installation.SetAnnotations(mergeStringMap(installation.GetAnnotations(), original.GetAnnotations(), productInstallation.GetAnnotations()))
+
+	var current, base, changed map[string]interface{}
+	for _, status := range []struct {
+		from rhmiv1alpha1.RHMIStatus
+		to   *map[string]interface{}
+	}{
+		{installation.Status, &current},
+		{original.Status, &base},
+		{productInstallation.Status, &changed},
+	} {
+		raw, err := json.Marshal(status.from)
+		if err != nil {
+			return fmt.Errorf("failed to marshal installation status: %w", err)
+		}
+		if err := json.Unmarshal(raw, status.to); err != nil {
+			return fmt.Errorf("failed to unmarshal installation status: %w", err)
+		}
+	}
+
+	merged, err := json.Marshal(mergeChangedFields(current, base, changed))
+	if err != nil {
+		return fmt.Errorf("failed to marshal merged installation status: %w", err)
+	}
+	status := rhmiv1alpha1.RHMIStatus{}
+	if err := json.Unmarshal(merged, &status); err != nil {
+		return fmt.Errorf("failed to unmarshal merged installation status: %w", err)
+	}
+	installation.Status = status
+	return nil
+}
+
+// mergeStringMap applies the keys added, changed or removed from base in
+// changed to current
+func mergeStringMap(current, base, changed map[string]string) map[string]string {
+	merged := map[string]string{}
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range changed {
+		if baseValue, ok := base[key]; !ok || baseValue != value {
+			merged[key] = value
+		}
+	}
+	for key := range base {
+		if _, ok := changed[key]; !ok {
+			delete(merged, key)
+		}
+	}
+	if len(merged) == 0 && current == nil {
+		return nil
+	}
+	return merged
+}
+
+// mergeChangedFields applies the fields added, changed or removed from base
+// in changed to current. Objects are merged field by field, any other value
+// changed replaces the value of current
+func mergeChangedFields(current, base, changed map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for key, value := range current {
+		merged[key] = value
+	}
+	for key, value := range changed {
+		baseValue, ok := base[key]
+		if ok && reflect.DeepEqual(baseValue, value) {
+			continue
+		}
+		currentObject, currentIsObject := merged[key].(map[string]interface{})
+		baseObject, baseIsObject := baseValue.(map[string]interface{})
+		changedObject, changedIsObject := value.(map[string]interface{})
+		if currentIsObject && changedIsObject && (baseIsObject || !ok) {
+			merged[key] = mergeChangedFields(currentObject, baseObject, changedObject)
+			continue
+		}
+		merged[key] = value
+	}
+	for key := range base {
+		if _, ok := changed[key]; !ok {
+			delete(merged, key)
+		}
+	}
+	return merged
+}
+
+// stageClient is the client passed to the product reconcilers of a stage.
+// The writes of the installation are not sent to the API server, as the
+// products are reconciled concurrently and would conflict with each other.
+// They are merged into the installation, which is updated at the end of
+// the reconcile. Every call fails once ctx is done, so a product reconcile
+// that timed out stops changing the cluster
+type stageClient struct {
+	k8sclient.Client
+	ctx          context.Context
+	installation *rhmiv1alpha1.RHMI
+}
+
+// isInstallation returns true if obj is the installation. A copy of the
+// installation other than the one of the product is copied into it, so its
+// changes are merged
+func (c *stageClient) isInstallation(obj runtime.Object) bool {
+	installation, ok := obj.(*rhmiv1alpha1.RHMI)
+	if !ok || installation.Name != c.installation.Name || installation.Namespace != c.installation.Namespace {
+		return false
+	}
+	if installation != c.installation {
+		c.installation.SetFinalizers(installation.GetFinalizers())
+		c.installation.SetLabels(installation.GetLabels())
+		c.installation.SetAnnotations(installation.GetAnnotations())
+		stages := c.installation.Status.Stages
+		installation.Status.DeepCopyInto(&c.installation.Status)
+		c.installation.Status.Stages = stages
+	}
+	return true
+}
+
+func (c *stageClient) Get(ctx context.Context, key k8sclient.ObjectKey, obj runtime.Object) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *stageClient) List(ctx context.Context, list runtime.Object, opts ...k8sclient.ListOption) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *stageClient) Create(ctx context.Context, obj runtime.Object, opts ...k8sclient.CreateOption) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *stageClient) Delete(ctx context.Context, obj runtime.Object, opts ...k8sclient.DeleteOption) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *stageClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...k8sclient.DeleteAllOfOption) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *stageClient) Update(ctx context.Context, obj runtime.Object, opts ...k8sclient.UpdateOption) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if c.isInstallation(obj) {
+		return nil
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *stageClient) Patch(ctx context.Context, obj runtime.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	if err := c.ctx.Err(); err != nil {
+		return err
+	}
+	if c.isInstallation(obj) {
+		return nil
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *stageClient) Status() k8sclient.StatusWriter {
+	return &stageStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+// stageStatusWriter defers the status writes of the installation like
+// stageClient
+type stageStatusWriter struct {
+	k8sclient.StatusWriter
+	client *stageClient
+}
+
+func (w *stageStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...k8sclient.UpdateOption) error {
+	if err := w.client.ctx.Err(); err != nil {
+		return err
+	}
+	if w.client.isInstallation(obj) {
+		return nil
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *stageStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	if err := w.client.ctx.Err(); err != nil {
+		return err
+	}
+	if w.client.isInstallation(obj) {
+		return nil
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
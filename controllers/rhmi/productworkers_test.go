@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMergeProductInstallationChanges(t *testing.T) {
+	installation := &rhmiv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{
+			Finalizers:  []string{deletionFinalizer, "rhsso.integreatly.org/finalizer", "grafana.integreatly.org/finalizer"},
+			Annotations: map[string]string{"grafana": "configured"},
+		},
+		Status: rhmiv1alpha1.RHMIStatus{
+			Version:         "1.0.0",
+			SMTPEnabled:     true,
+			LastError:       "previous error",
+			QuotaPreview:    &rhmiv1alpha1.QuotaPreview{From: "10", To: "20"},
+			PreflightStatus: rhmiv1alpha1.PreflightSuccess,
+		},
+	}
+	original := installation.DeepCopy()
+
+	// 3scale adds its finalizer and an annotation
+	threescaleInstallation := installation.DeepCopy()
+	threescaleInstallation.SetFinalizers(append(threescaleInstallation.GetFinalizers(), "3scale.integreatly.org/finalizer"))
+	threescaleInstallation.Annotations["3scale"] = "configured"
+
+	// grafana is disabled and removes its finalizer and annotation
+	grafanaInstallation := installation.DeepCopy()
+	grafanaInstallation.SetFinalizers([]string{deletionFinalizer, "rhsso.integreatly.org/finalizer"})
+	delete(grafanaInstallation.Annotations, "grafana")
+
+	// rhsso enables the GitHub OAuth integration and clears the error
+	rhssoInstallation := installation.DeepCopy()
+	rhssoInstallation.Status.GitHubOAuthEnabled = true
+	rhssoInstallation.Status.LastError = ""
+
+	// 3scale changes a field of the quota preview, while rhsso leaves it as
+	// it was
+	threescaleInstallation.Status.QuotaPreview.From = "20"
+
+	for _, productInstallation := range []*rhmiv1alpha1.RHMI{threescaleInstallation, grafanaInstallation, rhssoInstallation} {
+		if err := mergeProductInstallationChanges(installation, original, productInstallation); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := []string{deletionFinalizer, "rhsso.integreatly.org/finalizer", "3scale.integreatly.org/finalizer"}
+	if !reflect.DeepEqual(installation.GetFinalizers(), expected) {
+		t.Fatalf("expected finalizers %v, got %v", expected, installation.GetFinalizers())
+	}
+	if !reflect.DeepEqual(installation.GetAnnotations(), map[string]string{"3scale": "configured"}) {
+		t.Fatalf("unexpected annotations %v", installation.GetAnnotations())
+	}
+	expectedStatus := rhmiv1alpha1.RHMIStatus{
+		Version:            "1.0.0",
+		SMTPEnabled:        true,
+		GitHubOAuthEnabled: true,
+		QuotaPreview:       &rhmiv1alpha1.QuotaPreview{From: "20", To: "20"},
+		PreflightStatus:    rhmiv1alpha1.PreflightSuccess,
+	}
+	if !reflect.DeepEqual(installation.Status, expectedStatus) {
+		t.Fatalf("expected status %+v, got %+v", expectedStatus, installation.Status)
+	}
+}
+
+func TestMergeStageInstallations(t *testing.T) {
+	installation := &rhmiv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Finalizers: []string{deletionFinalizer}},
+	}
+	original := installation.DeepCopy()
+
+	// 3scale fails to build its reconciler after marin3r added its
+	// finalizer, and the reconcile of amq online timed out
+	marin3rInstallation := installation.DeepCopy()
+	marin3rInstallation.SetFinalizers(append(marin3rInstallation.GetFinalizers(), "marin3r.integreatly.org/finalizer"))
+	threescaleInstallation := installation.DeepCopy()
+	results := []productResult{
+		failedBuild(threescaleInstallation, rhmiv1alpha1.RHMIProductStatus{Name: rhmiv1alpha1.Product3Scale},
+			productResult{installation: threescaleInstallation, buildErr: errors.New("failed to build a reconciler")}),
+		{product: rhmiv1alpha1.RHMIProductStatus{Name: rhmiv1alpha1.ProductAMQOnline}},
+		{product: rhmiv1alpha1.RHMIProductStatus{Name: rhmiv1alpha1.ProductMarin3r}, installation: marin3rInstallation},
+	}
+
+	if err := mergeStageInstallations(installation, original, results); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{deletionFinalizer, "marin3r.integreatly.org/finalizer"}
+	if !reflect.DeepEqual(installation.GetFinalizers(), expected) {
+		t.Fatalf("expected finalizers %v, got %v", expected, installation.GetFinalizers())
+	}
+	if results[0].product.Status != rhmiv1alpha1.PhaseFailed {
+		t.Fatalf("expected the product that failed to build to be failed, got %s", results[0].product.Status)
+	}
+}
+
+func TestStageClient(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := rhmiv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	installation := &rhmiv1alpha1.RHMI{ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: "test-namespace"}}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "test-namespace"}}
+	serverClient := fakeclient.NewFakeClientWithScheme(scheme, installation.DeepCopy(), configMap.DeepCopy())
+	ctx, cancel := context.WithCancel(context.TODO())
+	client := &stageClient{Client: serverClient, ctx: ctx, installation: installation}
+
+	getPersisted := func() *rhmiv1alpha1.RHMI {
+		persisted := &rhmiv1alpha1.RHMI{}
+		if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: "rhmi", Namespace: "test-namespace"}, persisted); err != nil {
+			t.Fatal(err)
+		}
+		return persisted
+	}
+
+	installation.SetFinalizers([]string{"3scale.integreatly.org/finalizer"})
+	if err := client.Update(context.TODO(), installation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	patched := installation.DeepCopy()
+	patched.SetAnnotations(map[string]string{"3scale": "configured"})
+	if err := client.Patch(context.TODO(), patched, k8sclient.MergeFrom(installation)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	installation.Status.GitHubOAuthEnabled = true
+	if err := client.Status().Update(context.TODO(), installation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.Status().Patch(context.TODO(), installation, k8sclient.MergeFrom(getPersisted())); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	persisted := getPersisted()
+	if len(persisted.GetFinalizers()) != 0 || len(persisted.GetAnnotations()) != 0 || persisted.Status.GitHubOAuthEnabled {
+		t.Fatalf("expected the installation writes to be deferred, got %+v", persisted)
+	}
+	if installation.GetAnnotations()["3scale"] != "configured" {
+		t.Fatalf("expected the patch of a copy of the installation to be kept, got %v", installation.GetAnnotations())
+	}
+
+	configMap.Data = map[string]string{"key": "value"}
+	if err := client.Update(context.TODO(), configMap); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: "config", Namespace: "test-namespace"}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if configMap.Data["key"] != "value" {
+		t.Fatal("expected other objects to be updated")
+	}
+
+	// The calls of a product reconcile that timed out fail
+	cancel()
+	configMap.Data = map[string]string{"key": "changed"}
+	if err := client.Update(context.TODO(), configMap); err != context.Canceled {
+		t.Fatalf("expected the update to be cancelled, got %v", err)
+	}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: "config", Namespace: "test-namespace"}, configMap); err != context.Canceled {
+		t.Fatalf("expected the get to be cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// productRestore returns the name of the RHMIRestore in progress for the
+// product, or an empty string if it isn't being restored. The product isn't
+// reconciled during the restore, as its reconciler would scale the
+// workloads stopped by the restore back up
+func productRestore(ctx context.Context, client k8sclient.Client, namespace string, product rhmiv1alpha1.ProductName) (string, error) {
+	restores := &rhmiv1alpha1.RHMIRestoreList{}
+	if err := client.List(ctx, restores, k8sclient.InNamespace(namespace)); err != nil {
+		return "", fmt.Errorf("failed to list the restores: %w", err)
+	}
+
+	for _, restore := range restores.Items {
+		if restore.IsRestoring(product) {
+			return restore.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -35,6 +35,7 @@ import (
 
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 
+	"github.com/integr8ly/integreatly-operator/pkg/resources/dryrun"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/poddistribution"
 	"github.com/integr8ly/integreatly-operator/pkg/webhooks"
 
@@ -69,7 +70,9 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/products"
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	"github.com/integr8ly/integreatly-operator/version"
 )
@@ -87,6 +90,8 @@ const (
 	priorityClassNameEnvName         = "PRIORITY_CLASS_NAME"
 	managedServicePriorityClassName  = "rhoam-pod-priority"
 	routeRequestUrl                  = "/apis/route.openshift.io/v1"
+	rebalancePodsAction              = "rebalance pods across zones"
+	changeQuotaAction                = "change quota"
 )
 
 var (
@@ -107,6 +112,10 @@ type RHMIReconciler struct {
 	restConfig      *rest.Config
 	customInformers map[string]map[string]*cache.Informer
 
+	// installationTypes are the installation types loaded from the
+	// InstallationProfile CRs, they take precedence over the built-in ones
+	installationTypes map[string]*Type
+
 	productsInstallationLoader marketplace.ProductsInstallationLoader
 }
 
@@ -195,6 +204,12 @@ func New(mgr ctrl.Manager) *RHMIReconciler {
 // Permission to list nodes in order to determine if a cluster is multi-az
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=list
 
+// Permission to list pods in order to preview the capacity required by a quota change
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list
+
+// Permission to autoscale the components of a quota
+// +kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;delete
+
 // Permission to get cluster infrastructure details for alerting
 // +kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions;infrastructures;oauths,verbs=get;list
 
@@ -244,7 +259,7 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		RequeueAfter: 10 * time.Second,
 	}
 
-	installType, err := TypeFactory(installation.Spec.Type)
+	installType, err := r.getInstallationType(installation.Spec.Type)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -253,6 +268,12 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		installationCfgMap = installation.Spec.NamespacePrefix + DefaultInstallationConfigMapName
 	}
 
+	// In dry run mode the changes of every stage are computed and published
+	// as a plan, but nothing is applied. Uninstalls are never dry run
+	if installation.Spec.DryRun && installation.DeletionTimestamp == nil {
+		return r.dryRunReconcile(installation, installType, installationCfgMap, request)
+	}
+
 	cssreAlertingEmailAddress := os.Getenv(alertingEmailAddressEnvName)
 	if installation.Spec.AlertingEmailAddresses.CSSRE == "" && cssreAlertingEmailAddress != "" {
 		log.Info("Adding CS-SRE alerting email address to RHMI CR")
@@ -329,7 +350,7 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 	// either not checked, or rechecking preflight checks
 	if installation.Status.PreflightStatus == rhmiv1alpha1.PreflightInProgress ||
 		installation.Status.PreflightStatus == rhmiv1alpha1.PreflightFail {
-		return r.preflightChecks(installation, installType, configManager)
+		return r.preflightChecks(installation, installType)
 	}
 
 	// If the CR is being deleted, handle uninstall and return
@@ -366,16 +387,34 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		log.Error("Error reconciling alerts for the rhmi installation", err)
 	}
 
+	// The stages after bootstrap are all processed, the products whose
+	// dependencies haven't completed yet are reported as blocked
+	// Disruptive actions of the products are deferred until the
+	// maintenance window opens
+	gate := maintenance.NewGate(r.Client, installation)
+	ctx := maintenance.WithGate(context.TODO(), gate)
+	// The products are upgraded once their backups complete, or the
+	// pre-upgrade backup policy allows to continue without them
+	preUpgradeGate := backup.NewPreUpgradeGate(r.mgr.GetEventRecorderFor("Pre-upgrade Backup"), installation)
+	ctx = backup.WithPreUpgradeGate(ctx, preUpgradeGate)
+
 	installationQuota := &quota.Quota{}
+	var stageErrors []error
+	productVersionMismatchFound = false
 	for _, stage := range installType.GetInstallStages() {
 		var err error
 		var stagePhase rhmiv1alpha1.StatusPhase
 		var stageLog = l.NewLoggerWithContext(l.Fields{l.StageLogContext: stage.Name})
 
+		// The stage of the installation is the first incomplete stage
+		if !installInProgress {
+			installation.Status.Stage = stage.Name
+		}
+
 		if stage.Name == rhmiv1alpha1.BootstrapStage {
-			stagePhase, err = r.bootstrapStage(installation, configManager, stageLog, installationQuota, request)
+			stagePhase, err = r.bootstrapStage(ctx, installation, configManager, stageLog, installationQuota, request)
 		} else {
-			stagePhase, err = r.processStage(installation, &stage, configManager, installationQuota, stageLog)
+			stagePhase, err = r.processStage(ctx, installation, &stage, installType, configManager, installationQuota, stageLog)
 		}
 
 		if installation.Status.Stages == nil {
@@ -388,19 +427,21 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		}
 
 		if err != nil {
-			installation.Status.LastError = err.Error()
-		} else {
-			installation.Status.LastError = ""
+			stageErrors = append(stageErrors, err)
 		}
 
-		//don't move to next stage until current stage is complete
 		if stagePhase != rhmiv1alpha1.PhaseCompleted {
 			stageLog.Infof("Status", l.Fields{"stage.Name": stage.Name, "stagePhase": stagePhase})
 			installInProgress = true
-			break
+			// nothing can be installed until the bootstrap stage is complete
+			if stage.Name == rhmiv1alpha1.BootstrapStage {
+				break
+			}
 		}
 	}
 
+	installation.Status.LastError = joinStageErrors(stageErrors)
+
 	// Entered on first reconcile where all stages reported complete after an upgrade / install
 	if installation.Status.ToVersion == version.GetVersionByType(installation.Spec.Type) && !installInProgress && !productVersionMismatchFound {
 		installation.Status.Version = version.GetVersionByType(installation.Spec.Type)
@@ -419,7 +460,9 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 		metrics.RHMIStatusAvailable.Set(1)
 		retryRequeue.RequeueAfter = 5 * time.Minute
 		if installation.Spec.RebalancePods {
-			r.reconcilePodDistribution(installation)
+			r.reconcilePodDistribution(ctx, installation, gate)
+		} else {
+			gate.Discard("", rebalancePodsAction)
 		}
 
 		if installation.Spec.Type == string(rhmiv1alpha1.InstallationTypeManagedApi) {
@@ -431,7 +474,10 @@ func (r *RHMIReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
 			}
 		}
 	}
+	installation.Status.DeferredActions = gate.DeferredActions()
+	installation.Status.PreUpgradeBackups = preUpgradeGate.Statuses()
 	metrics.SetRHMIStatus(installation)
+	setInstallationConditions(installation, installInProgress)
 
 	err = r.updateStatusAndObject(originalInstallation, installation)
 	return retryRequeue, err
@@ -602,7 +648,18 @@ func (r *RHMIReconciler) getURLFromRoute(routeName string, namespace string, rc
 	return host, nil
 }
 
-func (r *RHMIReconciler) reconcilePodDistribution(installation *rhmiv1alpha1.RHMI) {
+func (r *RHMIReconciler) reconcilePodDistribution(ctx context.Context, installation *rhmiv1alpha1.RHMI, gate *maintenance.Gate) {
+	// Rebalancing deletes pods, it's deferred until the maintenance window
+	allowed, err := gate.Allow(ctx, "", rebalancePodsAction)
+	if err != nil {
+		log.Error("Error checking the maintenance window for pod distribution", err)
+		installation.Status.LastError = err.Error()
+		return
+	}
+	if !allowed {
+		log.Info("Pod distribution deferred until the maintenance window")
+		return
+	}
 
 	serverClient, err := k8sclient.New(r.restConfig, k8sclient.Options{})
 	if err != nil {
@@ -610,7 +667,7 @@ func (r *RHMIReconciler) reconcilePodDistribution(installation *rhmiv1alpha1.RHM
 		installation.Status.LastError = err.Error()
 		return
 	}
-	mErr := poddistribution.ReconcilePodDistribution(context.TODO(), serverClient, installation.Spec.NamespacePrefix, installation.Spec.Type)
+	mErr := poddistribution.ReconcilePodDistribution(ctx, serverClient, installation.Spec.NamespacePrefix, installation.Spec.Type)
 	if mErr != nil && len(mErr.Errors) > 0 {
 		logrus.Errorf("Error reconciling pod distributions %v", mErr)
 		installation.Status.LastError = mErr.Error()
@@ -687,8 +744,15 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 	// Set metrics status to unavailable
 	metrics.RHMIStatusAvailable.Set(0)
 
+	originalStatus := installation.Status.DeepCopy()
 	installation.Status.Stage = rhmiv1alpha1.StageName("deletion")
 	installation.Status.LastError = ""
+	setUninstallConditions(installation, "")
+	if !reflect.DeepEqual(originalStatus, &installation.Status) {
+		if err := r.Status().Update(context.TODO(), installation); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
 	// updates rhmi status metric to deletion
 	metrics.SetRHMIStatus(installation)
@@ -736,6 +800,7 @@ func (r *RHMIReconciler) handleUninstall(installation *rhmiv1alpha1.RHMI, instal
 		if pendingUninstalls {
 			if len(merr.Errors) > 0 {
 				installation.Status.LastError = merr.Error()
+				setUninstallConditions(installation, installation.Status.LastError)
 				r.Client.Status().Update(context.TODO(), installation)
 			}
 			err = r.Client.Update(context.TODO(), installation)
@@ -805,7 +870,7 @@ func upgradeFirstReconcile(installation *rhmiv1alpha1.RHMI) bool {
 	return status.Version != "" && status.ToVersion == "" && status.Version != version.GetVersionByType(installation.Spec.Type)
 }
 
-func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, installationType *Type, configManager *config.Manager) (ctrl.Result, error) {
+func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, installationType *Type) (ctrl.Result, error) {
 	log.Info("Running preflight checks..")
 	installation.Status.Stage = rhmiv1alpha1.StageName("Preflight Checks")
 	result := ctrl.Result{
@@ -934,7 +999,7 @@ func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, instal
 	}
 
 	for _, ns := range namespaces.Items {
-		products, err := r.checkNamespaceForProducts(ns, installation, installationType, configManager)
+		products, err := r.checkNamespaceForProducts(ns, installation, installationType)
 		if err != nil {
 			// error searching for existing products, keep trying
 			log.Info("error looking for existing deployments, will retry")
@@ -959,7 +1024,7 @@ func (r *RHMIReconciler) preflightChecks(installation *rhmiv1alpha1.RHMI, instal
 	return result, nil
 }
 
-func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installation *rhmiv1alpha1.RHMI, installationType *Type, configManager *config.Manager) ([]string, error) {
+func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installation *rhmiv1alpha1.RHMI, installationType *Type) ([]string, error) {
 	foundProducts := []string{}
 	if strings.HasPrefix(ns.Name, "openshift-") {
 		return foundProducts, nil
@@ -973,11 +1038,10 @@ func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installa
 	})
 	for _, stage := range installationType.InstallStages {
 		for _, product := range stage.Products {
-			reconciler, err := products.NewReconciler(product.Name, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
-			if err != nil {
-				return foundProducts, err
+			if !installation.IsProductEnabled(product.Name) {
+				continue
 			}
-			search := reconciler.GetPreflightObject(ns.Name)
+			search := products.PreflightObject(product.Name, ns.Name)
 			if search == nil {
 				continue
 			}
@@ -993,7 +1057,7 @@ func (r *RHMIReconciler) checkNamespaceForProducts(ns corev1.Namespace, installa
 	return foundProducts, nil
 }
 
-func (r *RHMIReconciler) bootstrapStage(installation *rhmiv1alpha1.RHMI, configManager config.ConfigReadWriter, log l.Logger, quota *quota.Quota, request ctrl.Request) (rhmiv1alpha1.StatusPhase, error) {
+func (r *RHMIReconciler) bootstrapStage(ctx context.Context, installation *rhmiv1alpha1.RHMI, configManager config.ConfigReadWriter, log l.Logger, quota *quota.Quota, request ctrl.Request) (rhmiv1alpha1.StatusPhase, error) {
 	installation.Status.Stage = rhmiv1alpha1.BootstrapStage
 	mpm := marketplace.NewManager()
 
@@ -1007,7 +1071,7 @@ func (r *RHMIReconciler) bootstrapStage(installation *rhmiv1alpha1.RHMI, configM
 	if err != nil {
 		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("could not create server client: %w", err)
 	}
-	phase, err := reconciler.Reconcile(context.TODO(), installation, serverClient, quota, request)
+	phase, err := reconciler.Reconcile(ctx, installation, serverClient, quota, request)
 	if err != nil || phase == rhmiv1alpha1.PhaseFailed {
 		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("Bootstrap stage reconcile failed: %w", err)
 	}
@@ -1015,41 +1079,101 @@ func (r *RHMIReconciler) bootstrapStage(installation *rhmiv1alpha1.RHMI, configM
 	return phase, nil
 }
 
-func (r *RHMIReconciler) processStage(installation *rhmiv1alpha1.RHMI, stage *Stage,
+func (r *RHMIReconciler) processStage(ctx context.Context, installation *rhmiv1alpha1.RHMI, stage *Stage, installType *Type,
 	configManager config.ConfigReadWriter, quotaconfig *quota.Quota, _ l.Logger) (rhmiv1alpha1.StatusPhase, error) {
 	incompleteStage := false
-	productVersionMismatchFound = false
 
 	var mErr error
 	productsAux := make(map[rhmiv1alpha1.ProductName]rhmiv1alpha1.RHMIProductStatus)
-	installation.Status.Stage = stage.Name
 
-	for productName, product := range stage.Products {
-		productLog := l.NewLoggerWithContext(l.Fields{l.ProductLogContext: product.Name})
+	// Products waiting for their dependencies are not reconciled. Disabled
+	// products are not blocked, so they can be removed straight away, unless
+	// enabled products depend on them. Those are left as they are until the
+	// products depending on them are disabled as well
+	readyStage := &Stage{Name: stage.Name, Products: map[rhmiv1alpha1.ProductName]rhmiv1alpha1.RHMIProductStatus{}}
+	for name, product := range stage.Products {
+		if !installation.IsProductEnabled(name) {
+			if dependents := dependentProducts(installation, installType, name); len(dependents) > 0 {
+				err := fmt.Errorf("product %s can't be disabled, the enabled products %v depend on it", name, dependents)
+				if mErr == nil {
+					mErr = &resources.MultiErr{}
+				}
+				mErr.(*resources.MultiErr).Add(err)
+				setProductConditions(installation, &product, err)
+				productsAux[name] = product
+				incompleteStage = true
+				continue
+			}
+		}
 
-		reconciler, err := products.NewReconciler(product.Name, r.restConfig, configManager, installation, r.mgr, productLog, r.productsInstallationLoader)
+		blockedBy := blockingDependencies(installation, installType, name)
+		if len(blockedBy) > 0 && installation.IsProductEnabled(name) {
+			product.Status = rhmiv1alpha1.PhaseBlocked
+			product.BlockedBy = blockedBy
+			setProductConditions(installation, &product, nil)
+			productsAux[name] = product
+			incompleteStage = true
+			continue
+		}
+		readyStage.Products[name] = product
+	}
+
+	// The products of the stage are reconciled concurrently, each one
+	// against its own copy of the installation. The results are merged
+	// once all of them have finished
+	original := installation.DeepCopy()
+	results := r.reconcileStageProducts(ctx, installation, readyStage, configManager, quotaconfig)
+
+	// The changes of every product are merged before any result is
+	// processed, so the finalizers and status changes of the other products
+	// are kept when one of them fails
+	stageFailed := false
+	if err := mergeStageInstallations(installation, original, results); err != nil {
+		if mErr == nil {
+			mErr = &resources.MultiErr{}
+		}
+		mErr.(*resources.MultiErr).Add(err)
+		stageFailed = true
+	}
 
-		if err != nil {
-			return rhmiv1alpha1.PhaseFailed, fmt.Errorf("failed to build a reconciler for %s: %w", product.Name, err)
+	for _, result := range results {
+		product := result.product
+
+		// The reconciler of the product couldn't be built, the product
+		// fails and so does the stage, once the other results are recorded
+		if result.buildErr != nil {
+			if mErr == nil {
+				mErr = &resources.MultiErr{}
+			}
+			mErr.(*resources.MultiErr).Add(result.buildErr)
+			stageFailed = true
+			productsAux[product.Name] = product
+			continue
 		}
 
-		if !reconciler.VerifyVersion(installation) {
+		if result.versionMismatch {
 			productVersionMismatchFound = true
 		}
 
-		serverClient, err := k8sclient.New(r.restConfig, k8sclient.Options{
-			Scheme: r.mgr.GetScheme(),
-		})
-		if err != nil {
-			return rhmiv1alpha1.PhaseFailed, fmt.Errorf("could not create server client: %w", err)
+		if !installation.IsProductEnabled(product.Name) {
+			if result.err != nil {
+				if mErr == nil {
+					mErr = &resources.MultiErr{}
+				}
+				mErr.(*resources.MultiErr).Add(fmt.Errorf("failed uninstall of disabled product %s: %w", product.Name, result.err))
+			}
+			if product.Status != rhmiv1alpha1.PhaseDisabled {
+				incompleteStage = true
+			}
+			productsAux[product.Name] = product
+			continue
 		}
-		product.Status, err = reconciler.Reconcile(context.TODO(), installation, &product, serverClient, quotaconfig.GetProduct(productName))
 
-		if err != nil {
+		if result.err != nil {
 			if mErr == nil {
 				mErr = &resources.MultiErr{}
 			}
-			mErr.(*resources.MultiErr).Add(fmt.Errorf("failed installation of %s: %w", product.Name, err))
+			mErr.(*resources.MultiErr).Add(fmt.Errorf("failed installation of %s: %w", product.Name, result.err))
 		}
 
 		// Verify that watches for this product CRDs have been created
@@ -1081,7 +1205,11 @@ func (r *RHMIReconciler) processStage(installation *rhmiv1alpha1.RHMI, stage *St
 			incompleteStage = true
 		}
 		productsAux[product.Name] = product
-		*stage = Stage{Name: stage.Name, Products: productsAux}
+	}
+	*stage = Stage{Name: stage.Name, Products: productsAux}
+
+	if stageFailed {
+		return rhmiv1alpha1.PhaseFailed, mErr
 	}
 
 	//some products in this stage have not installed successfully yet
@@ -1091,6 +1219,70 @@ func (r *RHMIReconciler) processStage(installation *rhmiv1alpha1.RHMI, stage *St
 	return rhmiv1alpha1.PhaseCompleted, mErr
 }
 
+// joinStageErrors returns the message of the errors of the install stages.
+// The product errors of every stage are combined in a single MultiErr
+func joinStageErrors(stageErrors []error) string {
+	switch len(stageErrors) {
+	case 0:
+		return ""
+	case 1:
+		return stageErrors[0].Error()
+	}
+
+	mErr := &resources.MultiErr{}
+	for _, err := range stageErrors {
+		if stageErr, ok := err.(*resources.MultiErr); ok {
+			mErr.Errors = append(mErr.Errors, stageErr.Errors...)
+			continue
+		}
+		mErr.Add(err)
+	}
+	return mErr.Error()
+}
+
+// reconcileDisabledProduct removes a product that was disabled in the spec
+// after being installed, through the uninstall logic of the product. The
+// finalizer of the product is removed from the installation once the product
+// is gone
+func (r *RHMIReconciler) reconcileDisabledProduct(ctx context.Context, installation *rhmiv1alpha1.RHMI, product *rhmiv1alpha1.RHMIProductStatus, configManager config.ConfigReadWriter, serverClient k8sclient.Client, log l.Logger, dryRunRecorder dryrun.Recorder) (rhmiv1alpha1.StatusPhase, error) {
+	finalizer := resources.GetProductFinalizer(string(product.Name))
+	if !resources.Contains(installation.GetFinalizers(), finalizer) {
+		return rhmiv1alpha1.PhaseDisabled, nil
+	}
+
+	log.Info("Product disabled, uninstalling")
+
+	reconciler, err := r.newProductReconciler(product.Name, configManager, installation, log, dryRunRecorder)
+	if err != nil {
+		return rhmiv1alpha1.PhaseFailed, fmt.Errorf("failed to build a reconciler for %s: %w", product.Name, err)
+	}
+
+	if uninstaller, ok := reconciler.(products.UninstallInterface); ok {
+		phase, err := uninstaller.Uninstall(ctx, installation, serverClient)
+		if err != nil || phase == rhmiv1alpha1.PhaseFailed {
+			return rhmiv1alpha1.PhaseFailed, err
+		}
+		if phase != rhmiv1alpha1.PhaseCompleted {
+			return rhmiv1alpha1.PhaseInProgress, nil
+		}
+	}
+
+	// The installation object is updated at the end of the reconcile
+	log.Infof("Product uninstalled, removing finalizer", l.Fields{"finalizer": finalizer})
+	installation.SetFinalizers(resources.Remove(installation.GetFinalizers(), finalizer))
+	return rhmiv1alpha1.PhaseDisabled, nil
+}
+
+// newProductReconciler builds the reconciler of the product. If a dry run
+// recorder is passed, every client of the reconciler records its writes in
+// it instead of applying them
+func (r *RHMIReconciler) newProductReconciler(product rhmiv1alpha1.ProductName, configManager config.ConfigReadWriter, installation *rhmiv1alpha1.RHMI, log l.Logger, dryRunRecorder dryrun.Recorder) (products.Interface, error) {
+	if dryRunRecorder != nil {
+		return products.NewDryRunReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader, dryRunRecorder)
+	}
+	return products.NewReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+}
+
 // handle the deletion of CRO config map
 func (r *RHMIReconciler) handleCROConfigDeletion(rhmi rhmiv1alpha1.RHMI) error {
 	// get cloud resource config map
@@ -1119,6 +1311,12 @@ func (r *RHMIReconciler) handleCROConfigDeletion(rhmi rhmiv1alpha1.RHMI) error {
 }
 
 func (r *RHMIReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// The products are reconciled in the order of their dependencies, the
+	// operator can't start if they contain a cycle
+	if err := products.ValidateDependencies(); err != nil {
+		return fmt.Errorf("invalid product dependencies: %w", err)
+	}
+
 	// Creates a new managed install CR if it is not available
 	kubeConfig := mgr.GetConfig()
 	client, err := k8sclient.New(kubeConfig, k8sclient.Options{
@@ -1133,6 +1331,11 @@ func (r *RHMIReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 
+	r.installationTypes, err = loadInstallationProfiles(context.TODO(), client, installation.Namespace)
+	if err != nil {
+		return err
+	}
+
 	enqueueAllInstallations := &handler.EnqueueRequestsFromMapFunc{
 		ToRequests: installationMapper{context: context.TODO(), client: mgr.GetClient()},
 	}
@@ -1157,6 +1360,15 @@ func (r *RHMIReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return nil
 }
 
+// getInstallationType returns the installation type loaded from an
+// InstallationProfile CR if there is one, or the built-in one otherwise
+func (r *RHMIReconciler) getInstallationType(installationType string) (*Type, error) {
+	if t, ok := r.installationTypes[installationType]; ok {
+		return t, nil
+	}
+	return TypeFactory(installationType)
+}
+
 func (r *RHMIReconciler) createInstallationCR(ctx context.Context, serverClient k8sclient.Client) (*rhmiv1alpha1.RHMI, error) {
 	namespace, err := resources.GetWatchNamespace()
 	if err != nil {
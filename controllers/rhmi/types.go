@@ -11,293 +11,196 @@ type Stage struct {
 	Name     integreatlyv1alpha1.StageName
 }
 
+// Built-in installation profiles. They are used for every installation type
+// that doesn't have an InstallationProfile CR in the operator namespace
 var (
-	allManagedApiStages = &Type{
-		[]Stage{
+	managedApiProfile = integreatlyv1alpha1.InstallationProfileSpec{
+		Type: string(integreatlyv1alpha1.InstallationTypeManagedApi),
+		InstallStages: []integreatlyv1alpha1.InstallationProfileStage{
 			{
 				Name: integreatlyv1alpha1.BootstrapStage,
 			},
 			{
-				Name: integreatlyv1alpha1.CloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {
-						Name: integreatlyv1alpha1.ProductCloudResources,
-					},
-				},
+				Name:     integreatlyv1alpha1.CloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
 			},
 			{
 				Name: integreatlyv1alpha1.MonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductMonitoring,
+					integreatlyv1alpha1.ProductMonitoringSpec,
 				},
 			},
 			{
-				Name: integreatlyv1alpha1.AuthenticationStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO: {
-						Name: integreatlyv1alpha1.ProductRHSSO,
-					},
-				},
+				Name:     integreatlyv1alpha1.AuthenticationStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
 			},
 			{
 				Name: integreatlyv1alpha1.ProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.Product3Scale:    {Name: integreatlyv1alpha1.Product3Scale},
-					integreatlyv1alpha1.ProductRHSSOUser: {Name: integreatlyv1alpha1.ProductRHSSOUser},
-					integreatlyv1alpha1.ProductMarin3r:   {Name: integreatlyv1alpha1.ProductMarin3r},
-					integreatlyv1alpha1.ProductGrafana:   {Name: integreatlyv1alpha1.ProductGrafana},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.Product3Scale,
+					integreatlyv1alpha1.ProductRHSSOUser,
+					integreatlyv1alpha1.ProductMarin3r,
+					integreatlyv1alpha1.ProductGrafana,
 				},
 			},
 		},
-		[]Stage{
+		UninstallStages: []integreatlyv1alpha1.InstallationProfileStage{
 			{
 				Name: integreatlyv1alpha1.UninstallProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO:     {Name: integreatlyv1alpha1.ProductRHSSO},
-					integreatlyv1alpha1.Product3Scale:    {Name: integreatlyv1alpha1.Product3Scale},
-					integreatlyv1alpha1.ProductRHSSOUser: {Name: integreatlyv1alpha1.ProductRHSSOUser},
-					integreatlyv1alpha1.ProductMarin3r:   {Name: integreatlyv1alpha1.ProductMarin3r},
-					integreatlyv1alpha1.ProductGrafana:   {Name: integreatlyv1alpha1.ProductGrafana},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductRHSSO,
+					integreatlyv1alpha1.Product3Scale,
+					integreatlyv1alpha1.ProductRHSSOUser,
+					integreatlyv1alpha1.ProductMarin3r,
+					integreatlyv1alpha1.ProductGrafana,
 				},
 			},
 			{
-				Name: integreatlyv1alpha1.UninstallCloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {Name: integreatlyv1alpha1.ProductCloudResources},
-				},
+				Name:     integreatlyv1alpha1.UninstallCloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
 			},
 			{
 				Name: integreatlyv1alpha1.UninstallMonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductMonitoring,
+					integreatlyv1alpha1.ProductMonitoringSpec,
 				},
 			},
 		},
 	}
-	allManagedStages = &Type{
-		[]Stage{
+	managedProfile = integreatlyv1alpha1.InstallationProfileSpec{
+		Type: string(integreatlyv1alpha1.InstallationTypeManaged),
+		InstallStages: []integreatlyv1alpha1.InstallationProfileStage{
 			{
 				Name: integreatlyv1alpha1.BootstrapStage,
 			},
 			{
-				Name: integreatlyv1alpha1.CloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {
-						Name: integreatlyv1alpha1.ProductCloudResources,
-					},
-				},
+				Name:     integreatlyv1alpha1.CloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
 			},
 			{
 				Name: integreatlyv1alpha1.MonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductMonitoring,
+					integreatlyv1alpha1.ProductMonitoringSpec,
 				},
 			},
 			{
-				Name: integreatlyv1alpha1.AuthenticationStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO: {
-						Name: integreatlyv1alpha1.ProductRHSSO,
-					},
-				},
+				Name:     integreatlyv1alpha1.AuthenticationStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
 			},
 			{
 				Name: integreatlyv1alpha1.ProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductFuse:                {Name: integreatlyv1alpha1.ProductFuse},
-					integreatlyv1alpha1.ProductFuseOnOpenshift:     {Name: integreatlyv1alpha1.ProductFuseOnOpenshift},
-					integreatlyv1alpha1.ProductCodeReadyWorkspaces: {Name: integreatlyv1alpha1.ProductCodeReadyWorkspaces},
-					integreatlyv1alpha1.ProductAMQOnline:           {Name: integreatlyv1alpha1.ProductAMQOnline},
-					integreatlyv1alpha1.Product3Scale:              {Name: integreatlyv1alpha1.Product3Scale},
-					integreatlyv1alpha1.ProductRHSSOUser:           {Name: integreatlyv1alpha1.ProductRHSSOUser},
-					integreatlyv1alpha1.ProductUps:                 {Name: integreatlyv1alpha1.ProductUps},
-					integreatlyv1alpha1.ProductApicurito:           {Name: integreatlyv1alpha1.ProductApicurito},
-					integreatlyv1alpha1.ProductDataSync:            {Name: integreatlyv1alpha1.ProductDataSync},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductFuse,
+					integreatlyv1alpha1.ProductFuseOnOpenshift,
+					integreatlyv1alpha1.ProductCodeReadyWorkspaces,
+					integreatlyv1alpha1.ProductAMQOnline,
+					integreatlyv1alpha1.Product3Scale,
+					integreatlyv1alpha1.ProductRHSSOUser,
+					integreatlyv1alpha1.ProductUps,
+					integreatlyv1alpha1.ProductApicurito,
+					integreatlyv1alpha1.ProductDataSync,
 				},
 			},
 			{
-				Name: integreatlyv1alpha1.SolutionExplorerStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductSolutionExplorer: {Name: integreatlyv1alpha1.ProductSolutionExplorer},
-				},
+				Name:     integreatlyv1alpha1.SolutionExplorerStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductSolutionExplorer},
 			},
 		},
-		[]Stage{
+		UninstallStages: []integreatlyv1alpha1.InstallationProfileStage{
 			{
 				Name: integreatlyv1alpha1.UninstallProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO:               {Name: integreatlyv1alpha1.ProductRHSSO},
-					integreatlyv1alpha1.ProductFuse:                {Name: integreatlyv1alpha1.ProductFuse},
-					integreatlyv1alpha1.ProductFuseOnOpenshift:     {Name: integreatlyv1alpha1.ProductFuseOnOpenshift},
-					integreatlyv1alpha1.ProductCodeReadyWorkspaces: {Name: integreatlyv1alpha1.ProductCodeReadyWorkspaces},
-					integreatlyv1alpha1.ProductAMQOnline:           {Name: integreatlyv1alpha1.ProductAMQOnline},
-					integreatlyv1alpha1.Product3Scale:              {Name: integreatlyv1alpha1.Product3Scale},
-					integreatlyv1alpha1.ProductRHSSOUser:           {Name: integreatlyv1alpha1.ProductRHSSOUser},
-					integreatlyv1alpha1.ProductUps:                 {Name: integreatlyv1alpha1.ProductUps},
-					integreatlyv1alpha1.ProductApicurito:           {Name: integreatlyv1alpha1.ProductApicurito},
-					integreatlyv1alpha1.ProductDataSync:            {Name: integreatlyv1alpha1.ProductDataSync},
-					integreatlyv1alpha1.ProductSolutionExplorer:    {Name: integreatlyv1alpha1.ProductSolutionExplorer},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductRHSSO,
+					integreatlyv1alpha1.ProductFuse,
+					integreatlyv1alpha1.ProductFuseOnOpenshift,
+					integreatlyv1alpha1.ProductCodeReadyWorkspaces,
+					integreatlyv1alpha1.ProductAMQOnline,
+					integreatlyv1alpha1.Product3Scale,
+					integreatlyv1alpha1.ProductRHSSOUser,
+					integreatlyv1alpha1.ProductUps,
+					integreatlyv1alpha1.ProductApicurito,
+					integreatlyv1alpha1.ProductDataSync,
+					integreatlyv1alpha1.ProductSolutionExplorer,
 				},
 			},
 			{
-				Name: integreatlyv1alpha1.UninstallCloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {Name: integreatlyv1alpha1.ProductCloudResources},
-				},
+				Name:     integreatlyv1alpha1.UninstallCloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
 			},
 			{
 				Name: integreatlyv1alpha1.UninstallMonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductMonitoring,
+					integreatlyv1alpha1.ProductMonitoringSpec,
 				},
 			},
 		},
 	}
-	allWorkshopStages = &Type{
-		[]Stage{
-			{
-				Name: integreatlyv1alpha1.BootstrapStage,
-			},
-			{
-				Name: integreatlyv1alpha1.CloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {
-						Name: integreatlyv1alpha1.ProductCloudResources,
-					},
-				},
-			},
-			{
-				Name: integreatlyv1alpha1.MonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
-				},
-			},
-			{
-				Name: integreatlyv1alpha1.AuthenticationStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO: {
-						Name: integreatlyv1alpha1.ProductRHSSO,
-					},
-				},
-			},
-			{
-				Name: integreatlyv1alpha1.ProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductFuse:                {Name: integreatlyv1alpha1.ProductFuse},
-					integreatlyv1alpha1.ProductFuseOnOpenshift:     {Name: integreatlyv1alpha1.ProductFuseOnOpenshift},
-					integreatlyv1alpha1.ProductCodeReadyWorkspaces: {Name: integreatlyv1alpha1.ProductCodeReadyWorkspaces},
-					integreatlyv1alpha1.ProductAMQOnline:           {Name: integreatlyv1alpha1.ProductAMQOnline},
-					integreatlyv1alpha1.Product3Scale:              {Name: integreatlyv1alpha1.Product3Scale},
-					integreatlyv1alpha1.ProductRHSSOUser:           {Name: integreatlyv1alpha1.ProductRHSSOUser},
-					integreatlyv1alpha1.ProductUps:                 {Name: integreatlyv1alpha1.ProductUps},
-					integreatlyv1alpha1.ProductApicurito:           {Name: integreatlyv1alpha1.ProductApicurito},
-					integreatlyv1alpha1.ProductDataSync:            {Name: integreatlyv1alpha1.ProductDataSync},
-				},
-			},
-			{
-				Name: integreatlyv1alpha1.SolutionExplorerStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductSolutionExplorer: {Name: integreatlyv1alpha1.ProductSolutionExplorer},
-				},
-			},
-		},
-		[]Stage{
-			{
-				Name: integreatlyv1alpha1.UninstallProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO:               {Name: integreatlyv1alpha1.ProductRHSSO},
-					integreatlyv1alpha1.ProductFuse:                {Name: integreatlyv1alpha1.ProductFuse},
-					integreatlyv1alpha1.ProductFuseOnOpenshift:     {Name: integreatlyv1alpha1.ProductFuseOnOpenshift},
-					integreatlyv1alpha1.ProductCodeReadyWorkspaces: {Name: integreatlyv1alpha1.ProductCodeReadyWorkspaces},
-					integreatlyv1alpha1.ProductAMQOnline:           {Name: integreatlyv1alpha1.ProductAMQOnline},
-					integreatlyv1alpha1.Product3Scale:              {Name: integreatlyv1alpha1.Product3Scale},
-					integreatlyv1alpha1.ProductRHSSOUser:           {Name: integreatlyv1alpha1.ProductRHSSOUser},
-					integreatlyv1alpha1.ProductUps:                 {Name: integreatlyv1alpha1.ProductUps},
-					integreatlyv1alpha1.ProductApicurito:           {Name: integreatlyv1alpha1.ProductApicurito},
-					integreatlyv1alpha1.ProductDataSync:            {Name: integreatlyv1alpha1.ProductDataSync},
-					integreatlyv1alpha1.ProductSolutionExplorer:    {Name: integreatlyv1alpha1.ProductSolutionExplorer},
-				},
-			},
-			{
-				Name: integreatlyv1alpha1.UninstallCloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {Name: integreatlyv1alpha1.ProductCloudResources},
-				},
-			},
-			{
-				Name: integreatlyv1alpha1.UninstallMonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
-				},
-			},
-		},
+	// The workshop installation type installs the same products as managed
+	workshopProfile = integreatlyv1alpha1.InstallationProfileSpec{
+		Type:            string(integreatlyv1alpha1.InstallationTypeWorkshop),
+		InstallStages:   managedProfile.InstallStages,
+		UninstallStages: managedProfile.UninstallStages,
 	}
-	allSelfManagedStages = &Type{
-		[]Stage{
+	selfManagedProfile = integreatlyv1alpha1.InstallationProfileSpec{
+		Type: string(integreatlyv1alpha1.InstallationTypeSelfManaged),
+		InstallStages: []integreatlyv1alpha1.InstallationProfileStage{
 			{
 				Name: integreatlyv1alpha1.BootstrapStage,
 			},
 			{
-				Name: integreatlyv1alpha1.CloudResourcesStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources: {
-						Name: integreatlyv1alpha1.ProductCloudResources,
-					},
-				},
+				Name:     integreatlyv1alpha1.CloudResourcesStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductCloudResources},
 			},
 			{
 				Name: integreatlyv1alpha1.MonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductMonitoring,
+					integreatlyv1alpha1.ProductMonitoringSpec,
 				},
 			},
 			{
-				Name: integreatlyv1alpha1.AuthenticationStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductRHSSO: {
-						Name: integreatlyv1alpha1.ProductRHSSO,
-					},
-				},
+				Name:     integreatlyv1alpha1.AuthenticationStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
 			},
 			{
-				Name: integreatlyv1alpha1.ProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductAMQStreams: {Name: integreatlyv1alpha1.ProductAMQStreams},
-				},
+				Name:     integreatlyv1alpha1.ProductsStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductAMQStreams},
 			},
 			{
-				Name: integreatlyv1alpha1.SolutionExplorerStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductSolutionExplorer: {Name: integreatlyv1alpha1.ProductSolutionExplorer},
-				},
+				Name:     integreatlyv1alpha1.SolutionExplorerStage,
+				Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductSolutionExplorer},
 			},
 		},
-		[]Stage{
+		UninstallStages: []integreatlyv1alpha1.InstallationProfileStage{
 			{
 				Name: integreatlyv1alpha1.UninstallProductsStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductCloudResources:   {Name: integreatlyv1alpha1.ProductCloudResources},
-					integreatlyv1alpha1.ProductRHSSO:            {Name: integreatlyv1alpha1.ProductRHSSO},
-					integreatlyv1alpha1.ProductAMQStreams:       {Name: integreatlyv1alpha1.ProductAMQStreams},
-					integreatlyv1alpha1.ProductSolutionExplorer: {Name: integreatlyv1alpha1.ProductSolutionExplorer},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductCloudResources,
+					integreatlyv1alpha1.ProductRHSSO,
+					integreatlyv1alpha1.ProductAMQStreams,
+					integreatlyv1alpha1.ProductSolutionExplorer,
 				},
 			},
 			{
 				Name: integreatlyv1alpha1.UninstallMonitoringStage,
-				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
-					integreatlyv1alpha1.ProductMonitoring:     {Name: integreatlyv1alpha1.ProductMonitoring},
-					integreatlyv1alpha1.ProductMonitoringSpec: {Name: integreatlyv1alpha1.ProductMonitoringSpec},
+				Products: []integreatlyv1alpha1.ProductName{
+					integreatlyv1alpha1.ProductMonitoring,
+					integreatlyv1alpha1.ProductMonitoringSpec,
 				},
 			},
 		},
 	}
+
+	builtinProfiles = []integreatlyv1alpha1.InstallationProfileSpec{
+		managedApiProfile,
+		managedProfile,
+		workshopProfile,
+		selfManagedProfile,
+	}
 )
 
 type Type struct {
@@ -305,12 +208,20 @@ type Type struct {
 	UninstallStages []Stage
 }
 
+// HasProduct returns true if the product is part of an install stage
 func (t *Type) HasProduct(product string) bool {
+	for _, stage := range t.InstallStages {
+		if _, ok := stage.Products[integreatlyv1alpha1.ProductName(product)]; ok {
+			return true
+		}
+	}
 	return false
 }
 
-//GetInstallStages returns indexed arrays of products names this is worked through starting at 0
-//the install will not move to the next index until all installs in the current index have completed successfully
+// GetInstallStages returns the install stages, processed in order. Apart
+// from the bootstrap stage, which must complete first, the stages only group
+// the products in the status. A product is reconciled as soon as the
+// products it depends on have completed
 func (t *Type) GetInstallStages() []Stage {
 	return t.InstallStages
 }
@@ -319,34 +230,37 @@ func (t *Type) GetUninstallStages() []Stage {
 	return t.UninstallStages
 }
 
+// TypeFactory returns the installation type built from the built-in profile
+// of the given installation type
 func TypeFactory(installationType string) (*Type, error) {
-	//TODO: export this logic to a configmap for each installation type
-	switch installationType {
-	case string(integreatlyv1alpha1.InstallationTypeWorkshop):
-		return newWorkshopType(), nil
-	case string(integreatlyv1alpha1.InstallationTypeManaged):
-		return newManagedType(), nil
-	case string(integreatlyv1alpha1.InstallationTypeManagedApi):
-		return newManagedApiType(), nil
-	case string(integreatlyv1alpha1.InstallationTypeSelfManaged):
-		return newSelfManagedType(), nil
-	default:
-		return nil, errors.New("unknown installation type: " + installationType)
+	for _, profile := range builtinProfiles {
+		if profile.Type == installationType {
+			return NewTypeFromProfile(profile), nil
+		}
 	}
+	return nil, errors.New("unknown installation type: " + installationType)
 }
 
-func newWorkshopType() *Type {
-	return allWorkshopStages
-}
-
-func newManagedType() *Type {
-	return allManagedStages
-}
-
-func newManagedApiType() *Type {
-	return allManagedApiStages
+// NewTypeFromProfile builds the install and uninstall stages declared by an
+// installation profile. The profile is expected to be valid
+func NewTypeFromProfile(profile integreatlyv1alpha1.InstallationProfileSpec) *Type {
+	return &Type{
+		InstallStages:   newStagesFromProfile(profile.InstallStages),
+		UninstallStages: newStagesFromProfile(profile.UninstallStages),
+	}
 }
 
-func newSelfManagedType() *Type {
-	return allSelfManagedStages
+func newStagesFromProfile(profileStages []integreatlyv1alpha1.InstallationProfileStage) []Stage {
+	stages := make([]Stage, 0, len(profileStages))
+	for _, profileStage := range profileStages {
+		stage := Stage{Name: profileStage.Name}
+		if len(profileStage.Products) > 0 {
+			stage.Products = make(map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus, len(profileStage.Products))
+			for _, product := range profileStage.Products {
+				stage.Products[product] = integreatlyv1alpha1.RHMIProductStatus{Name: product}
+			}
+		}
+		stages = append(stages, stage)
+	}
+	return stages
 }
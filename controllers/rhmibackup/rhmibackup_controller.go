@@ -0,0 +1,546 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/metrics"
+	"github.com/integr8ly/integreatly-operator/pkg/products"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultInstallationConfigMapName = "installation-config"
+
+	// maxBackupNameLength is the maximum length of a Job name
+	maxBackupNameLength = 63
+	// backupNameHashLength is the length of the hash suffix of the names
+	// truncated to maxBackupNameLength
+	backupNameHashLength = 10
+)
+
+// backupPollInterval is the time between checks of the progress of the
+// backups
+var backupPollInterval = 10 * time.Second
+
+var log = l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "rhmi_backup_controller"})
+
+// RHMIBackupReconciler reconciles a RHMIBackup object
+type RHMIBackupReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	mgr                        ctrl.Manager
+	restConfig                 *rest.Config
+	productsInstallationLoader marketplace.ProductsInstallationLoader
+
+	// getBackupExecutor returns the backup of a product
+	getBackupExecutor func(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) (backup.BackupExecutor, error)
+	// getSanityChecks returns the checks of the restored backups of a
+	// product, by component
+	getSanityChecks func(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) (map[string]integreatlyv1alpha1.BackupSanityCheck, error)
+	// getBackupVerifier returns the verifier of the backups of a component
+	getBackupVerifier func(executor backup.ComponentBackupExecutor) (backup.BackupVerifier, error)
+}
+
+func New(mgr ctrl.Manager) *RHMIBackupReconciler {
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.Timeout = 10 * time.Second
+
+	r := &RHMIBackupReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		mgr:        mgr,
+		restConfig: restConfig,
+		productsInstallationLoader: marketplace.NewFSProductInstallationLoader(
+			marketplace.GetProductsInstallationPath(),
+		),
+	}
+	r.getBackupExecutor = r.productBackupExecutor
+	r.getSanityChecks = r.productSanityChecks
+	r.getBackupVerifier = backup.NewBackupVerifier
+	return r
+}
+
+// +kubebuilder:rbac:groups=integreatly.org,resources=rhmibackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=integreatly.org,resources=rhmibackups/status,verbs=get;update;patch
+
+// Reconcile starts the backups of the components and checks their progress
+// until they all finish, requeueing in between. The progress is recorded in
+// the status, so the backups are resumed after a restart of the operator
+func (r *RHMIBackupReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	ctx := context.TODO()
+
+	rhmiBackup := &integreatlyv1alpha1.RHMIBackup{}
+	if err := r.Get(ctx, request.NamespacedName, rhmiBackup); err != nil {
+		if k8serr.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	backupLog := l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "rhmi_backup_controller", "backup": rhmiBackup.Name})
+
+	switch rhmiBackup.Status.Phase {
+	case integreatlyv1alpha1.PhaseCompleted, integreatlyv1alpha1.PhaseFailed:
+		return ctrl.Result{}, nil
+	case integreatlyv1alpha1.PhaseNone:
+		if err := r.prepareBackup(ctx, rhmiBackup); err != nil {
+			backupLog.Error("Failed to prepare the backup", err)
+			now := metav1.Now()
+			rhmiBackup.Status.Phase = integreatlyv1alpha1.PhaseFailed
+			rhmiBackup.Status.CompletionTime = &now
+			rhmiBackup.Status.Error = err.Error()
+			return ctrl.Result{}, r.Status().Update(ctx, rhmiBackup)
+		}
+		backupLog.Infof("Backup started", l.Fields{"components": len(rhmiBackup.Status.Components)})
+		return ctrl.Result{Requeue: true}, r.Status().Update(ctx, rhmiBackup)
+	}
+
+	executors, err := r.componentExecutors(ctx, rhmiBackup)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	timeout := backup.DefaultTimeout
+	if rhmiBackup.Spec.Timeout != nil {
+		timeout = rhmiBackup.Spec.Timeout.Duration
+	}
+
+	inProgress := false
+	for i := range rhmiBackup.Status.Components {
+		status := &rhmiBackup.Status.Components[i]
+		if status.Phase != integreatlyv1alpha1.PhaseInProgress {
+			continue
+		}
+
+		executor, ok := executors[componentKey(status.Product, status.Name)]
+		if !ok {
+			failComponent(status, fmt.Errorf("%s is no longer backed up by %s", status.Name, status.Product), backupLog)
+			continue
+		}
+		r.reconcileComponent(rhmiBackup, status, executor, timeout, backupLog)
+		inProgress = inProgress || status.Phase == integreatlyv1alpha1.PhaseInProgress
+	}
+
+	if inProgress {
+		if err := r.Status().Update(ctx, rhmiBackup); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update the status of the backup: %w", err)
+		}
+		return ctrl.Result{RequeueAfter: backupPollInterval}, nil
+	}
+
+	completed := metav1.Now()
+	rhmiBackup.Status.CompletionTime = &completed
+	rhmiBackup.Status.Phase = integreatlyv1alpha1.PhaseCompleted
+	if failed := failedComponents(rhmiBackup); len(failed) > 0 {
+		rhmiBackup.Status.Phase = integreatlyv1alpha1.PhaseFailed
+		rhmiBackup.Status.Error = fmt.Sprintf("backup of %s failed", strings.Join(failed, ", "))
+	}
+
+	backupLog.Infof("Backup finished", l.Fields{"phase": rhmiBackup.Status.Phase})
+	return ctrl.Result{}, r.Status().Update(ctx, rhmiBackup)
+}
+
+// prepareBackup lists the components to back up in the status of the
+// RHMIBackup
+func (r *RHMIBackupReconciler) prepareBackup(ctx context.Context, rhmiBackup *integreatlyv1alpha1.RHMIBackup) error {
+	installation, configManager, err := r.getInstallation(ctx, rhmiBackup.Namespace)
+	if err != nil {
+		return err
+	}
+
+	productNames, err := productsToBackup(installation, rhmiBackup.Spec.Products)
+	if err != nil {
+		return err
+	}
+
+	rhmiBackup.Status.Components = []integreatlyv1alpha1.RHMIBackupComponentStatus{}
+	for _, productName := range productNames {
+		components, err := r.productComponents(installation, productName, configManager)
+		if err != nil {
+			return err
+		}
+
+		for _, component := range components {
+			rhmiBackup.Status.Components = append(rhmiBackup.Status.Components, integreatlyv1alpha1.RHMIBackupComponentStatus{
+				Product: productName,
+				Name:    component.ComponentName(),
+				Type:    component.BackupType(),
+				Backup:  backupName(component.ComponentName(), rhmiBackup.Name),
+				Phase:   integreatlyv1alpha1.PhaseInProgress,
+			})
+		}
+	}
+
+	now := metav1.Now()
+	rhmiBackup.Status.Phase = integreatlyv1alpha1.PhaseInProgress
+	rhmiBackup.Status.StartTime = &now
+	return nil
+}
+
+// componentExecutors returns the executors of the components of the backup
+// still in progress, by componentKey
+func (r *RHMIBackupReconciler) componentExecutors(ctx context.Context, rhmiBackup *integreatlyv1alpha1.RHMIBackup) (map[string]backup.ComponentBackupExecutor, error) {
+	installation, configManager, err := r.getInstallation(ctx, rhmiBackup.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	executors := map[string]backup.ComponentBackupExecutor{}
+	for _, status := range rhmiBackup.Status.Components {
+		if status.Phase != integreatlyv1alpha1.PhaseInProgress {
+			continue
+		}
+		if _, ok := executors[componentKey(status.Product, status.Name)]; ok {
+			continue
+		}
+
+		components, err := r.productComponents(installation, status.Product, configManager)
+		if err != nil {
+			return nil, err
+		}
+		for _, component := range components {
+			executors[componentKey(status.Product, component.ComponentName())] = component
+		}
+	}
+	return executors, nil
+}
+
+// productComponents returns the executors backing up each component of the
+// product. The backups run in the background, so only the executors of
+// single components are supported
+func (r *RHMIBackupReconciler) productComponents(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) ([]backup.ComponentBackupExecutor, error) {
+	executor, err := r.getBackupExecutor(installation, product, configManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the backup of %s: %w", product, err)
+	}
+
+	components := []backup.ComponentBackupExecutor{}
+	for _, each := range backup.Components(executor) {
+		component, ok := each.(backup.ComponentBackupExecutor)
+		if !ok {
+			return nil, fmt.Errorf("backup %T of %s can't be performed on demand", each, product)
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// reconcileComponent starts the backup of the component, or checks its
+// progress once started. The backup fails if it doesn't complete within the
+// timeout. When requested, the component stays in progress until its backup
+// is verified
+func (r *RHMIBackupReconciler) reconcileComponent(rhmiBackup *integreatlyv1alpha1.RHMIBackup, status *integreatlyv1alpha1.RHMIBackupComponentStatus, executor backup.ComponentBackupExecutor, timeout time.Duration, backupLog l.Logger) {
+	if status.StartTime == nil {
+		if err := executor.StartNamedBackup(r.Client, status.Backup); err != nil {
+			failComponent(status, err, backupLog)
+			return
+		}
+		started := metav1.Now()
+		status.StartTime = &started
+		return
+	}
+
+	if status.Verification != nil {
+		r.checkVerification(rhmiBackup, status, executor, timeout, backupLog)
+		return
+	}
+
+	done, err := executor.CheckNamedBackup(r.Client, status.Backup)
+	if err != nil {
+		failComponent(status, err, backupLog)
+		return
+	}
+	if !done {
+		if time.Since(status.StartTime.Time) > timeout {
+			failComponent(status, fmt.Errorf("backup timed out after %s", timeout), backupLog)
+		}
+		return
+	}
+
+	completed := metav1.Now()
+	status.CompletionTime = &completed
+	status.Duration = completed.Sub(status.StartTime.Time).Round(time.Second).String()
+	status.Phase = integreatlyv1alpha1.PhaseCompleted
+
+	if rhmiBackup.Spec.Verification != nil {
+		r.startVerification(rhmiBackup, status, executor, backupLog)
+	}
+}
+
+// getInstallation returns the installation in the namespace and its config
+func (r *RHMIBackupReconciler) getInstallation(ctx context.Context, namespace string) (*integreatlyv1alpha1.RHMI, config.ConfigReadWriter, error) {
+	installation, err := resources.GetRhmiCr(r.Client, ctx, namespace, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the installation: %w", err)
+	}
+	if installation == nil {
+		return nil, nil, fmt.Errorf("no installation found in namespace %s", namespace)
+	}
+
+	configManager, err := config.NewManager(ctx, r.Client, installation.Namespace, installationConfigMapName(installation), installation)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the installation config: %w", err)
+	}
+	return installation, configManager, nil
+}
+
+// startVerification starts the restore of the backup of the component into
+// a throwaway instance, and keeps the component in progress until the check
+// against the instance completes. Components without a verifier aren't
+// verified
+func (r *RHMIBackupReconciler) startVerification(rhmiBackup *integreatlyv1alpha1.RHMIBackup, status *integreatlyv1alpha1.RHMIBackupComponentStatus, component backup.ComponentBackupExecutor, backupLog l.Logger) {
+	verifier, err := r.getBackupVerifier(component)
+	if err != nil {
+		backupLog.Infof("Skipping the verification of the backup", l.Fields{"component": component.ComponentName(), "reason": err.Error()})
+		return
+	}
+
+	started := metav1.Now()
+	status.Verification = &integreatlyv1alpha1.RHMIBackupVerificationStatus{
+		Phase:     integreatlyv1alpha1.PhaseInProgress,
+		StartTime: &started,
+	}
+	status.Phase = integreatlyv1alpha1.PhaseInProgress
+
+	check, err := r.componentSanityCheck(rhmiBackup, status.Product, component)
+	if err != nil {
+		r.completeVerification(status, component, verifier, err, backupLog)
+		return
+	}
+	status.Verification.Query = check.Query
+
+	if err := verifier.StartVerification(r.Client, status.Backup); err != nil {
+		r.completeVerification(status, component, verifier, err, backupLog)
+	}
+}
+
+// checkVerification runs the check against the instance restored from the
+// backup of the component once it's available. The verification fails if it
+// doesn't complete within the timeout
+func (r *RHMIBackupReconciler) checkVerification(rhmiBackup *integreatlyv1alpha1.RHMIBackup, status *integreatlyv1alpha1.RHMIBackupComponentStatus, component backup.ComponentBackupExecutor, timeout time.Duration, backupLog l.Logger) {
+	verifier, err := r.getBackupVerifier(component)
+	if err != nil {
+		r.completeVerification(status, component, nil, err, backupLog)
+		return
+	}
+
+	check, err := r.componentSanityCheck(rhmiBackup, status.Product, component)
+	if err != nil {
+		r.completeVerification(status, component, verifier, err, backupLog)
+		return
+	}
+
+	done, result, err := verifier.CheckVerification(r.Client, status.Backup, check)
+	if err == nil && !done {
+		if time.Since(status.Verification.StartTime.Time) <= timeout {
+			return
+		}
+		err = fmt.Errorf("verification timed out after %s", timeout)
+	}
+	if done {
+		status.Verification.Result = &result
+		// A check without a minimum passes on an empty instance, so the
+		// backup is only verified by checks with a minimum
+		status.Verification.Verified = err == nil && check.Min > 0
+	}
+	r.completeVerification(status, component, verifier, err, backupLog)
+}
+
+// completeVerification records the result of the verification of the
+// backup of the component, and removes the throwaway instance. A failed
+// verification fails the component
+func (r *RHMIBackupReconciler) completeVerification(status *integreatlyv1alpha1.RHMIBackupComponentStatus, component backup.ComponentBackupExecutor, verifier backup.BackupVerifier, err error, backupLog l.Logger) {
+	if verifier != nil {
+		if cleanupErr := verifier.CleanupVerification(r.Client, status.Backup); cleanupErr != nil {
+			backupLog.Error(fmt.Sprintf("Failed to remove the instance restored to verify the backup of %s", component.ComponentName()), cleanupErr)
+		}
+	}
+
+	completed := metav1.Now()
+	status.Verification.CompletionTime = &completed
+	status.Verification.Phase = integreatlyv1alpha1.PhaseCompleted
+	status.Phase = integreatlyv1alpha1.PhaseCompleted
+	if err != nil {
+		backupLog.Error(fmt.Sprintf("Verification of the backup of %s failed", component.ComponentName()), err)
+		status.Verification.Phase = integreatlyv1alpha1.PhaseFailed
+		status.Verification.Error = err.Error()
+		status.Verification.Verified = false
+		status.Phase = integreatlyv1alpha1.PhaseFailed
+		status.Error = fmt.Sprintf("verification failed: %s", err)
+	} else if !status.Verification.Verified {
+		backupLog.Warning(fmt.Sprintf("The backup of %s was restored, but its sanity check has no minimum, so it's unverified", component.ComponentName()))
+	}
+	metrics.SetBackupVerification(component.ComponentName(), component.BackupType(), status.Verification.Verified)
+}
+
+// componentSanityCheck returns the check run against the restored backup of
+// the component
+func (r *RHMIBackupReconciler) componentSanityCheck(rhmiBackup *integreatlyv1alpha1.RHMIBackup, product integreatlyv1alpha1.ProductName, component backup.ComponentBackupExecutor) (integreatlyv1alpha1.BackupSanityCheck, error) {
+	installation, configManager, err := r.getInstallation(context.TODO(), rhmiBackup.Namespace)
+	if err != nil {
+		return integreatlyv1alpha1.BackupSanityCheck{}, err
+	}
+	checks, err := r.getSanityChecks(installation, product, configManager)
+	if err != nil {
+		return integreatlyv1alpha1.BackupSanityCheck{}, fmt.Errorf("failed to get the sanity checks of %s: %w", product, err)
+	}
+	return *sanityCheck(component, rhmiBackup.Spec.Verification, checks), nil
+}
+
+// sanityCheck returns the check of the restored backup of the component: the
+// check of the RHMIBackup, or of the product, or the default check of the
+// type of backup
+func sanityCheck(component backup.ComponentBackupExecutor, verification *integreatlyv1alpha1.RHMIBackupVerification, productChecks map[string]integreatlyv1alpha1.BackupSanityCheck) *integreatlyv1alpha1.BackupSanityCheck {
+	if check, ok := verification.Checks[component.ComponentName()]; ok {
+		return &check
+	}
+	if check, ok := productChecks[component.ComponentName()]; ok {
+		return &check
+	}
+	check := backup.DefaultSanityCheck(component.BackupType())
+	return &check
+}
+
+// productBackupExecutor returns the pre-upgrade backup of the product, or a
+// noop backup if the product has nothing to back up
+func (r *RHMIBackupReconciler) productBackupExecutor(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) (backup.BackupExecutor, error) {
+	reconciler, err := products.NewReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	backupReconciler, ok := reconciler.(products.BackupInterface)
+	if !ok {
+		return backup.NewNoopBackupExecutor(), nil
+	}
+	return backupReconciler.PreUpgradeBackupExecutor(), nil
+}
+
+// productSanityChecks returns the checks of the restored backups of the
+// product, if it has checks of its own
+func (r *RHMIBackupReconciler) productSanityChecks(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) (map[string]integreatlyv1alpha1.BackupSanityCheck, error) {
+	reconciler, err := products.NewReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	verificationReconciler, ok := reconciler.(products.BackupVerificationInterface)
+	if !ok {
+		return nil, nil
+	}
+	return verificationReconciler.BackupSanityChecks(), nil
+}
+
+// productsToBackup returns the requested products, or every product of the
+// installation if none is requested
+func productsToBackup(installation *integreatlyv1alpha1.RHMI, requested []integreatlyv1alpha1.ProductName) ([]integreatlyv1alpha1.ProductName, error) {
+	installed := map[integreatlyv1alpha1.ProductName]bool{}
+	for _, stage := range installation.Status.Stages {
+		for productName := range stage.Products {
+			installed[productName] = true
+		}
+	}
+
+	if len(requested) == 0 {
+		for productName := range installed {
+			requested = append(requested, productName)
+		}
+	}
+
+	productNames := []integreatlyv1alpha1.ProductName{}
+	for _, productName := range requested {
+		if !installed[productName] {
+			return nil, fmt.Errorf("product %s is not installed", productName)
+		}
+		productNames = append(productNames, productName)
+	}
+	sort.Slice(productNames, func(i, j int) bool { return productNames[i] < productNames[j] })
+	return productNames, nil
+}
+
+func failComponent(status *integreatlyv1alpha1.RHMIBackupComponentStatus, err error, backupLog l.Logger) {
+	backupLog.Error(fmt.Sprintf("Backup of %s failed", status.Name), err)
+	completed := metav1.Now()
+	status.CompletionTime = &completed
+	if status.StartTime != nil {
+		status.Duration = completed.Sub(status.StartTime.Time).Round(time.Second).String()
+	}
+	status.Phase = integreatlyv1alpha1.PhaseFailed
+	status.Error = err.Error()
+}
+
+func failedComponents(rhmiBackup *integreatlyv1alpha1.RHMIBackup) []string {
+	failed := []string{}
+	for _, status := range rhmiBackup.Status.Components {
+		if status.Phase == integreatlyv1alpha1.PhaseFailed {
+			failed = append(failed, status.Name)
+		}
+	}
+	return failed
+}
+
+// componentKey identifies a component of a product
+func componentKey(product integreatlyv1alpha1.ProductName, name string) string {
+	return fmt.Sprintf("%s/%s", product, name)
+}
+
+// backupName returns the name of the snapshot or Job created for the
+// component. Names longer than a Job name are truncated and suffixed with a
+// hash of the full name, so that backups sharing a long prefix don't collide
+func backupName(componentName, rhmiBackupName string) string {
+	name := fmt.Sprintf("%s-%s", componentName, rhmiBackupName)
+	if len(name) <= maxBackupNameLength {
+		return name
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(name)))[:backupNameHashLength]
+	prefix := strings.TrimRight(name[:maxBackupNameLength-backupNameHashLength-1], "-.")
+	return fmt.Sprintf("%s-%s", prefix, hash)
+}
+
+func installationConfigMapName(installation *integreatlyv1alpha1.RHMI) string {
+	if name := os.Getenv("INSTALLATION_CONFIG_MAP"); name != "" {
+		return name
+	}
+	return installation.Spec.NamespacePrefix + defaultInstallationConfigMapName
+}
+
+func (r *RHMIBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&integreatlyv1alpha1.RHMIBackup{}).
+		Complete(r)
+}
@@ -0,0 +1,511 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "redhat-rhmi-operator"
+
+type mockComponentExecutor struct {
+	name    string
+	err     error
+	pending bool
+}
+
+func (e *mockComponentExecutor) PerformBackup(client k8sclient.Client, timeout time.Duration) error {
+	return e.err
+}
+
+func (e *mockComponentExecutor) ComponentName() string {
+	return e.name
+}
+
+func (e *mockComponentExecutor) BackupType() string {
+	return string(backup.PostgresSnapshotType)
+}
+
+func (e *mockComponentExecutor) PerformNamedBackup(client k8sclient.Client, backupName string, timeout time.Duration) error {
+	return e.err
+}
+
+func (e *mockComponentExecutor) StartNamedBackup(client k8sclient.Client, backupName string) error {
+	return nil
+}
+
+func (e *mockComponentExecutor) CheckNamedBackup(client k8sclient.Client, backupName string) (bool, error) {
+	return e.err == nil && !e.pending, e.err
+}
+
+// reconcileBackup reconciles the backup until it finishes
+func reconcileBackup(t *testing.T, reconciler *RHMIBackupReconciler, key types.NamespacedName) {
+	for i := 0; i < 10; i++ {
+		result, err := reconciler.Reconcile(ctrl.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Requeue && result.RequeueAfter == 0 {
+			return
+		}
+	}
+	t.Fatal("expected the backup to finish")
+}
+
+func getBuildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func installation() *integreatlyv1alpha1.RHMI {
+	return &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: testNamespace},
+		Status: integreatlyv1alpha1.RHMIStatus{
+			Stages: map[integreatlyv1alpha1.StageName]integreatlyv1alpha1.RHMIStageStatus{
+				integreatlyv1alpha1.ProductsStage: {
+					Name: integreatlyv1alpha1.ProductsStage,
+					Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
+						integreatlyv1alpha1.Product3Scale:  {Name: integreatlyv1alpha1.Product3Scale},
+						integreatlyv1alpha1.ProductUps:     {Name: integreatlyv1alpha1.ProductUps},
+						integreatlyv1alpha1.ProductGrafana: {Name: integreatlyv1alpha1.ProductGrafana},
+					},
+				},
+			},
+		},
+	}
+}
+
+// backupExecutors returns the backups of the products of the test
+// installation. UPS fails if failUps is set, and never completes if
+// pendingUps is set
+func backupExecutors(failUps, pendingUps bool) func(*integreatlyv1alpha1.RHMI, integreatlyv1alpha1.ProductName, config.ConfigReadWriter) (backup.BackupExecutor, error) {
+	return func(_ *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, _ config.ConfigReadWriter) (backup.BackupExecutor, error) {
+		switch product {
+		case integreatlyv1alpha1.Product3Scale:
+			return backup.NewConcurrentBackupExecutor(
+				&mockComponentExecutor{name: "threescale-postgres-rhmi"},
+				&mockComponentExecutor{name: "threescale-redis-rhmi"},
+			), nil
+		case integreatlyv1alpha1.ProductUps:
+			executor := &mockComponentExecutor{name: "ups-postgres-rhmi"}
+			if failUps {
+				executor.err = errors.New("snapshot failed")
+			}
+			executor.pending = pendingUps
+			return executor, nil
+		default:
+			return backup.NewNoopBackupExecutor(), nil
+		}
+	}
+}
+
+func TestRHMIBackupReconcile(t *testing.T) {
+	scheme := getBuildScheme(t)
+
+	cases := []struct {
+		Name           string
+		Backup         *integreatlyv1alpha1.RHMIBackup
+		Objects        []runtime.Object
+		FailUps        bool
+		Pending        bool
+		ExpectedPhase  integreatlyv1alpha1.StatusPhase
+		ExpectedBackup map[string]integreatlyv1alpha1.StatusPhase
+		ExpectedError  string
+	}{
+		{
+			Name:          "test backup of every product",
+			Backup:        &integreatlyv1alpha1.RHMIBackup{},
+			Objects:       []runtime.Object{installation()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			ExpectedBackup: map[string]integreatlyv1alpha1.StatusPhase{
+				"threescale-postgres-rhmi": integreatlyv1alpha1.PhaseCompleted,
+				"threescale-redis-rhmi":    integreatlyv1alpha1.PhaseCompleted,
+				"ups-postgres-rhmi":        integreatlyv1alpha1.PhaseCompleted,
+			},
+		},
+		{
+			Name: "test backup of a single product",
+			Backup: &integreatlyv1alpha1.RHMIBackup{
+				Spec: integreatlyv1alpha1.RHMIBackupSpec{
+					Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductUps},
+				},
+			},
+			Objects:       []runtime.Object{installation()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			ExpectedBackup: map[string]integreatlyv1alpha1.StatusPhase{
+				"ups-postgres-rhmi": integreatlyv1alpha1.PhaseCompleted,
+			},
+		},
+		{
+			Name:          "test failed component fails the backup",
+			Backup:        &integreatlyv1alpha1.RHMIBackup{},
+			Objects:       []runtime.Object{installation()},
+			FailUps:       true,
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedBackup: map[string]integreatlyv1alpha1.StatusPhase{
+				"threescale-postgres-rhmi": integreatlyv1alpha1.PhaseCompleted,
+				"threescale-redis-rhmi":    integreatlyv1alpha1.PhaseCompleted,
+				"ups-postgres-rhmi":        integreatlyv1alpha1.PhaseFailed,
+			},
+			ExpectedError: "backup of ups-postgres-rhmi failed",
+		},
+		{
+			Name: "test backup of a product that is not installed",
+			Backup: &integreatlyv1alpha1.RHMIBackup{
+				Spec: integreatlyv1alpha1.RHMIBackupSpec{
+					Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductFuse},
+				},
+			},
+			Objects:       []runtime.Object{installation()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedError: "product fuse is not installed",
+		},
+		{
+			Name:          "test backup without installation",
+			Backup:        &integreatlyv1alpha1.RHMIBackup{},
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedError: "no installation found in namespace " + testNamespace,
+		},
+		{
+			Name: "test backup in progress is resumed",
+			Backup: &integreatlyv1alpha1.RHMIBackup{
+				Status: integreatlyv1alpha1.RHMIBackupStatus{
+					Phase: integreatlyv1alpha1.PhaseInProgress,
+					Components: []integreatlyv1alpha1.RHMIBackupComponentStatus{
+						{Product: integreatlyv1alpha1.Product3Scale, Name: "threescale-postgres-rhmi", Phase: integreatlyv1alpha1.PhaseCompleted},
+						{Product: integreatlyv1alpha1.Product3Scale, Name: "threescale-redis-rhmi", Phase: integreatlyv1alpha1.PhaseInProgress, StartTime: &metav1.Time{Time: time.Now()}},
+					},
+				},
+			},
+			Objects:       []runtime.Object{installation()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			ExpectedBackup: map[string]integreatlyv1alpha1.StatusPhase{
+				"threescale-postgres-rhmi": integreatlyv1alpha1.PhaseCompleted,
+				"threescale-redis-rhmi":    integreatlyv1alpha1.PhaseCompleted,
+			},
+		},
+		{
+			Name: "test backup in progress times out",
+			Backup: &integreatlyv1alpha1.RHMIBackup{
+				Spec: integreatlyv1alpha1.RHMIBackupSpec{
+					Timeout: &metav1.Duration{Duration: time.Minute},
+				},
+				Status: integreatlyv1alpha1.RHMIBackupStatus{
+					Phase: integreatlyv1alpha1.PhaseInProgress,
+					Components: []integreatlyv1alpha1.RHMIBackupComponentStatus{
+						{Product: integreatlyv1alpha1.ProductUps, Name: "ups-postgres-rhmi", Phase: integreatlyv1alpha1.PhaseInProgress, StartTime: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+					},
+				},
+			},
+			Objects:       []runtime.Object{installation()},
+			Pending:       true,
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedBackup: map[string]integreatlyv1alpha1.StatusPhase{
+				"ups-postgres-rhmi": integreatlyv1alpha1.PhaseFailed,
+			},
+			ExpectedError: "backup of ups-postgres-rhmi failed",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Backup.Name = "test-backup"
+			tc.Backup.Namespace = testNamespace
+			client := fake.NewFakeClientWithScheme(scheme, append(tc.Objects, tc.Backup)...)
+
+			reconciler := &RHMIBackupReconciler{
+				Client:            client,
+				Scheme:            scheme,
+				getBackupExecutor: backupExecutors(tc.FailUps, tc.Pending),
+			}
+
+			key := types.NamespacedName{Name: tc.Backup.Name, Namespace: testNamespace}
+			reconcileBackup(t, reconciler, key)
+
+			rhmiBackup := &integreatlyv1alpha1.RHMIBackup{}
+			if err := client.Get(context.TODO(), key, rhmiBackup); err != nil {
+				t.Fatal(err)
+			}
+			if rhmiBackup.Status.Phase != tc.ExpectedPhase {
+				t.Fatalf("expected phase %s, got %s", tc.ExpectedPhase, rhmiBackup.Status.Phase)
+			}
+			if rhmiBackup.Status.Error != tc.ExpectedError {
+				t.Fatalf("expected error %q, got %q", tc.ExpectedError, rhmiBackup.Status.Error)
+			}
+			if rhmiBackup.Status.CompletionTime == nil {
+				t.Fatal("expected the completion time to be set")
+			}
+
+			if len(rhmiBackup.Status.Components) != len(tc.ExpectedBackup) {
+				t.Fatalf("expected components %v, got %v", tc.ExpectedBackup, rhmiBackup.Status.Components)
+			}
+			for _, component := range rhmiBackup.Status.Components {
+				if component.Phase != tc.ExpectedBackup[component.Name] {
+					t.Fatalf("expected component %s phase %s, got %s", component.Name, tc.ExpectedBackup[component.Name], component.Phase)
+				}
+			}
+		})
+	}
+}
+
+// mockVerifier records the checks run against the restored backups, and
+// returns the result of the backup unless it is pending
+type mockVerifier struct {
+	results   map[string]int64
+	pending   map[string]bool
+	started   map[string]bool
+	checks    map[string]integreatlyv1alpha1.BackupSanityCheck
+	cleanedUp map[string]bool
+}
+
+func newMockVerifier(results map[string]int64, pending map[string]bool) *mockVerifier {
+	return &mockVerifier{
+		results:   results,
+		pending:   pending,
+		started:   map[string]bool{},
+		checks:    map[string]integreatlyv1alpha1.BackupSanityCheck{},
+		cleanedUp: map[string]bool{},
+	}
+}
+
+func (v *mockVerifier) StartVerification(client k8sclient.Client, backupName string) error {
+	v.started[backupName] = true
+	return nil
+}
+
+func (v *mockVerifier) CheckVerification(client k8sclient.Client, backupName string, check integreatlyv1alpha1.BackupSanityCheck) (bool, int64, error) {
+	if !v.started[backupName] {
+		return false, 0, fmt.Errorf("verification of %s not started", backupName)
+	}
+	if v.pending[backupName] {
+		return false, 0, nil
+	}
+	v.checks[backupName] = check
+	if result := v.results[backupName]; result < check.Min {
+		return true, result, fmt.Errorf("sanity check returned %d, expected at least %d", result, check.Min)
+	}
+	return true, v.results[backupName], nil
+}
+
+func (v *mockVerifier) CleanupVerification(client k8sclient.Client, backupName string) error {
+	v.cleanedUp[backupName] = true
+	return nil
+}
+
+func TestRHMIBackupVerification(t *testing.T) {
+	scheme := getBuildScheme(t)
+
+	rhmiBackup := &integreatlyv1alpha1.RHMIBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "verified", Namespace: testNamespace},
+		Spec: integreatlyv1alpha1.RHMIBackupSpec{
+			Verification: &integreatlyv1alpha1.RHMIBackupVerification{
+				Checks: map[string]integreatlyv1alpha1.BackupSanityCheck{
+					"threescale-redis-rhmi": {Query: "DBSIZE", Min: 10},
+				},
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, installation(), rhmiBackup)
+	verifier := newMockVerifier(map[string]int64{
+		"threescale-postgres-rhmi-verified": 20,
+		"threescale-redis-rhmi-verified":    10,
+	}, nil)
+
+	reconciler := &RHMIBackupReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		getBackupExecutor: backupExecutors(false, false),
+		getSanityChecks: func(_ *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, _ config.ConfigReadWriter) (map[string]integreatlyv1alpha1.BackupSanityCheck, error) {
+			if product != integreatlyv1alpha1.Product3Scale {
+				return nil, nil
+			}
+			return map[string]integreatlyv1alpha1.BackupSanityCheck{
+				"threescale-postgres-rhmi": {Query: "SELECT count(*) FROM accounts", Min: 1},
+			}, nil
+		},
+		getBackupVerifier: func(_ backup.ComponentBackupExecutor) (backup.BackupVerifier, error) {
+			return verifier, nil
+		},
+	}
+
+	key := types.NamespacedName{Name: rhmiBackup.Name, Namespace: testNamespace}
+	reconcileBackup(t, reconciler, key)
+	if err := client.Get(context.TODO(), key, rhmiBackup); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedQueries := map[string]string{
+		"threescale-postgres-rhmi-verified": "SELECT count(*) FROM accounts",
+		"threescale-redis-rhmi-verified":    "DBSIZE",
+		"ups-postgres-rhmi-verified":        backup.DefaultSanityCheck(string(backup.PostgresSnapshotType)).Query,
+	}
+	for backupName, query := range expectedQueries {
+		if verifier.checks[backupName].Query != query {
+			t.Fatalf("expected %s to be verified with %q, got %q", backupName, query, verifier.checks[backupName].Query)
+		}
+		if !verifier.cleanedUp[backupName] {
+			t.Fatalf("expected the instance restored from %s to be removed", backupName)
+		}
+	}
+
+	expectedPhases := map[string]integreatlyv1alpha1.StatusPhase{
+		"threescale-postgres-rhmi": integreatlyv1alpha1.PhaseCompleted,
+		"threescale-redis-rhmi":    integreatlyv1alpha1.PhaseCompleted,
+		"ups-postgres-rhmi":        integreatlyv1alpha1.PhaseFailed,
+	}
+	for _, component := range rhmiBackup.Status.Components {
+		if component.Verification == nil || component.Verification.Phase != expectedPhases[component.Name] {
+			t.Fatalf("expected the verification of %s to be %s, got %+v", component.Name, expectedPhases[component.Name], component.Verification)
+		}
+		if component.Phase != expectedPhases[component.Name] {
+			t.Fatalf("expected component %s phase %s, got %s", component.Name, expectedPhases[component.Name], component.Phase)
+		}
+		if verified := expectedPhases[component.Name] == integreatlyv1alpha1.PhaseCompleted; component.Verification.Verified != verified {
+			t.Fatalf("expected the backup of %s verified to be %t, got %t", component.Name, verified, component.Verification.Verified)
+		}
+	}
+	if rhmiBackup.Status.Phase != integreatlyv1alpha1.PhaseFailed {
+		t.Fatalf("expected the failed verification to fail the backup, got %s", rhmiBackup.Status.Phase)
+	}
+}
+
+func TestRHMIBackupUnverified(t *testing.T) {
+	scheme := getBuildScheme(t)
+
+	rhmiBackup := &integreatlyv1alpha1.RHMIBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "verified", Namespace: testNamespace},
+		Spec: integreatlyv1alpha1.RHMIBackupSpec{
+			Verification: &integreatlyv1alpha1.RHMIBackupVerification{
+				Checks: map[string]integreatlyv1alpha1.BackupSanityCheck{
+					"threescale-redis-rhmi": {Query: "DBSIZE"},
+				},
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, installation(), rhmiBackup)
+	verifier := newMockVerifier(map[string]int64{
+		"threescale-postgres-rhmi-verified": 20,
+		"threescale-redis-rhmi-verified":    0,
+		"ups-postgres-rhmi-verified":        5,
+	}, nil)
+
+	reconciler := &RHMIBackupReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		getBackupExecutor: backupExecutors(false, false),
+		getSanityChecks: func(_ *integreatlyv1alpha1.RHMI, _ integreatlyv1alpha1.ProductName, _ config.ConfigReadWriter) (map[string]integreatlyv1alpha1.BackupSanityCheck, error) {
+			return nil, nil
+		},
+		getBackupVerifier: func(_ backup.ComponentBackupExecutor) (backup.BackupVerifier, error) {
+			return verifier, nil
+		},
+	}
+
+	key := types.NamespacedName{Name: rhmiBackup.Name, Namespace: testNamespace}
+	reconcileBackup(t, reconciler, key)
+	if err := client.Get(context.TODO(), key, rhmiBackup); err != nil {
+		t.Fatal(err)
+	}
+
+	// The redis check has no minimum, the redis backup completes without
+	// being verified
+	for _, component := range rhmiBackup.Status.Components {
+		if component.Verification == nil || component.Verification.Phase != integreatlyv1alpha1.PhaseCompleted {
+			t.Fatalf("expected the verification of %s to complete, got %+v", component.Name, component.Verification)
+		}
+		if verified := component.Name != "threescale-redis-rhmi"; component.Verification.Verified != verified {
+			t.Fatalf("expected the backup of %s verified to be %t, got %t", component.Name, verified, component.Verification.Verified)
+		}
+	}
+	if rhmiBackup.Status.Phase != integreatlyv1alpha1.PhaseCompleted {
+		t.Fatalf("expected the unverified backup to complete, got %s", rhmiBackup.Status.Phase)
+	}
+}
+
+func TestRHMIBackupVerificationTimeout(t *testing.T) {
+	scheme := getBuildScheme(t)
+
+	rhmiBackup := &integreatlyv1alpha1.RHMIBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "verified", Namespace: testNamespace},
+		Spec: integreatlyv1alpha1.RHMIBackupSpec{
+			Timeout:      &metav1.Duration{Duration: time.Nanosecond},
+			Verification: &integreatlyv1alpha1.RHMIBackupVerification{},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, installation(), rhmiBackup)
+	verifier := newMockVerifier(nil, map[string]bool{"threescale-redis-rhmi-verified": true})
+
+	reconciler := &RHMIBackupReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		getBackupExecutor: backupExecutors(false, false),
+		getSanityChecks: func(_ *integreatlyv1alpha1.RHMI, _ integreatlyv1alpha1.ProductName, _ config.ConfigReadWriter) (map[string]integreatlyv1alpha1.BackupSanityCheck, error) {
+			return nil, nil
+		},
+		getBackupVerifier: func(_ backup.ComponentBackupExecutor) (backup.BackupVerifier, error) {
+			return verifier, nil
+		},
+	}
+
+	key := types.NamespacedName{Name: rhmiBackup.Name, Namespace: testNamespace}
+	reconcileBackup(t, reconciler, key)
+	if err := client.Get(context.TODO(), key, rhmiBackup); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, component := range rhmiBackup.Status.Components {
+		if component.Name != "threescale-redis-rhmi" {
+			continue
+		}
+		if component.Verification == nil || component.Verification.Phase != integreatlyv1alpha1.PhaseFailed || !strings.Contains(component.Verification.Error, "timed out") {
+			t.Fatalf("expected the pending verification to time out, got %+v", component.Verification)
+		}
+		if !verifier.cleanedUp[component.Backup] {
+			t.Fatalf("expected the instance restored from %s to be removed", component.Backup)
+		}
+	}
+	if rhmiBackup.Status.Phase != integreatlyv1alpha1.PhaseFailed {
+		t.Fatalf("expected the timed out verification to fail the backup, got %s", rhmiBackup.Status.Phase)
+	}
+}
+
+func TestBackupName(t *testing.T) {
+	if name := backupName("ups-postgres-rhmi", "before-change"); name != "ups-postgres-rhmi-before-change" {
+		t.Fatalf("unexpected backup name %s", name)
+	}
+
+	name := backupName("threescale-backend-redis-rhmi", "backup-before-the-change-of-the-3scale-configuration")
+	if len(name) > maxBackupNameLength {
+		t.Fatalf("expected the name to be truncated to %d characters, got %s", maxBackupNameLength, name)
+	}
+
+	// Backups sharing the truncated prefix still get distinct names
+	other := backupName("threescale-backend-redis-rhmi", "backup-before-the-change-of-the-3scale-configuration-2")
+	if other == name {
+		t.Fatalf("expected distinct names for distinct backups, got %s for both", name)
+	}
+	if len(other) > maxBackupNameLength {
+		t.Fatalf("expected the name to be truncated to %d characters, got %s", maxBackupNameLength, other)
+	}
+}
@@ -2,16 +2,15 @@ package helpers
 
 import (
 	"context"
+	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	rhmiconfigv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const WINDOW = 6
-
 func UpdateStatus(ctx context.Context, client k8sclient.Client, config *rhmiconfigv1alpha1.RHMIConfig) error {
 
 	// removes the upgrade schedule time from the CR, if Upgrade.Schedule is set to false
@@ -20,16 +19,28 @@ func UpdateStatus(ctx context.Context, client k8sclient.Client, config *rhmiconf
 		return client.Status().Update(ctx, config)
 	}
 
+	schedule, err := maintenance.NewSchedule(config.Spec)
+	if err != nil {
+		return err
+	}
+	// The local times are only shown when a time zone is set
+	showLocal := config.Spec.TimeZone != ""
+
 	// Calculate the next maintenance window based on the maintenance schedule
 	if config.Spec.Maintenance.ApplyFrom != "" {
-		mtStart, _, err := getWeeklyWindowFromNow(config.Spec.Maintenance.ApplyFrom, time.Hour*WINDOW)
+		mtStart, mtEnd, err := schedule.NextWindow(time.Now().UTC())
 		if err != nil {
 			return err
 		}
 
-		config.Status.Maintenance.ApplyFrom = mtStart.Format("2-1-2006 15:04")
-		config.Status.Maintenance.Duration = strconv.Itoa(WINDOW) + "hrs"
+		config.Status.Maintenance.ApplyFrom = mtStart.UTC().Format("2-1-2006 15:04")
+		config.Status.Maintenance.ApplyFromLocal = ""
+		if showLocal {
+			config.Status.Maintenance.ApplyFromLocal = mtStart.In(schedule.Location).Format("2-1-2006 15:04 MST")
+		}
+		config.Status.Maintenance.Duration = formatDuration(mtEnd.Sub(mtStart))
 	}
+	config.Status.Maintenance.Blackout = schedule.ActiveBlackout(time.Now().UTC())
 
 	client.Status().Update(ctx, config)
 
@@ -45,68 +56,41 @@ func UpdateStatus(ctx context.Context, client k8sclient.Client, config *rhmiconf
 		waitForMaintenance = *config.Spec.Upgrade.WaitForMaintenance
 	}
 
+	// The days are added in the time zone of the config so the local time
+	// doesn't change across daylight saving time changes
 	upgradeSchedule := config.Status.UpgradeAvailable.AvailableAt.
-		Add(daysDuration(notBeforeDays))
+		In(schedule.Location).
+		AddDate(0, 0, notBeforeDays)
 
 	if waitForMaintenance {
-		var err error
-		upgradeSchedule, _, err = getWeeklyWindow(upgradeSchedule, config.Spec.Maintenance.ApplyFrom, time.Hour*WINDOW)
+		upgradeSchedule, _, err = schedule.NextWindow(upgradeSchedule)
 		if err != nil {
 			return err
 		}
+	} else {
+		upgradeSchedule = schedule.SkipBlackouts(upgradeSchedule)
 	}
 
 	// Update the upgrade status
+	scheduled := &rhmiconfigv1alpha1.UpgradeSchedule{
+		For: upgradeSchedule.UTC().Format(rhmiconfigv1alpha1.DateFormat),
+	}
+	if showLocal {
+		scheduled.ForLocal = upgradeSchedule.In(schedule.Location).Format(rhmiconfigv1alpha1.LocalDateFormat)
+	}
 	config.Status.Upgrade = rhmiconfigv1alpha1.RHMIConfigStatusUpgrade{
-		Scheduled: &rhmiconfigv1alpha1.UpgradeSchedule{
-			For: upgradeSchedule.Format(rhmiconfigv1alpha1.DateFormat),
-		},
+		Scheduled: scheduled,
 	}
 
 	return client.Status().Update(ctx, config)
 }
 
-func getWeeklyWindow(from time.Time, windowStartStr string, duration time.Duration) (time.Time, time.Time, error) {
-	var shortDays = map[string]int{
-		"sun": 0,
-		"mon": 1,
-		"tue": 2,
-		"wed": 3,
-		"thu": 4,
-		"fri": 5,
-		"sat": 6,
-	}
-
-	windowSegments := strings.Split(windowStartStr, " ")
-	windowDay := windowSegments[0]
-
-	windowTimeSegments := strings.Split(windowSegments[1], ":")
-	windowHour, err := strconv.Atoi(windowTimeSegments[0])
-	if err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-	windowMin, err := strconv.Atoi(windowTimeSegments[1])
-	if err != nil {
-		return time.Time{}, time.Time{}, err
-	}
-
-	//calculate how far away from maintenance day today is, within the current week
-	dayDiff := shortDays[strings.ToLower(windowDay)] - int(from.Weekday())
-	if dayDiff < 0 {
-		dayDiff = 7 + dayDiff
+// formatDuration returns the duration in the format of the status, e.g. 6hrs
+func formatDuration(duration time.Duration) string {
+	hours := int(duration / time.Hour)
+	minutes := int((duration % time.Hour) / time.Minute)
+	if minutes == 0 {
+		return strconv.Itoa(hours) + "hrs"
 	}
-
-	//negative days roll back the month and year, tested here: https://play.golang.org/p/gBBHw49nH1b
-	windowStart := time.Date(from.Year(), from.Month(), from.Day(), windowHour, windowMin, 0, 0, time.UTC)
-	windowStart = windowStart.Add(daysDuration(dayDiff))
-	return windowStart, windowStart.Add(duration), nil
-}
-
-//windowStartStr must be in format: sun 23:00
-func getWeeklyWindowFromNow(windowStartStr string, duration time.Duration) (time.Time, time.Time, error) {
-	return getWeeklyWindow(time.Now().UTC(), windowStartStr, duration)
-}
-
-func daysDuration(numberOfDays int) time.Duration {
-	return time.Duration(numberOfDays) * 24 * time.Hour
+	return fmt.Sprintf("%dhrs %dmins", hours, minutes)
 }
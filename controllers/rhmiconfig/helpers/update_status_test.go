@@ -264,6 +264,56 @@ func TestUpdateStatus(t *testing.T) {
 				For: nowOffset(-2).Add(3 * 24 * time.Hour).Format(rhmiconfigv1alpha1.DateFormat),
 			},
 		}),
+		makeScheduleScenario(&scheduleScenario{
+			name: "do not wait for maintenance, available during a blackout",
+			config: &rhmiconfigv1alpha1.RHMIConfig{
+				Spec: rhmiconfigv1alpha1.RHMIConfigSpec{
+					Maintenance: rhmiconfigv1alpha1.Maintenance{
+						Blackouts: []rhmiconfigv1alpha1.BlackoutPeriod{{
+							From: nowOffset(-24).Format(rhmiconfigv1alpha1.DateFormat),
+							To:   nowOffset(48).Format(rhmiconfigv1alpha1.DateFormat),
+						}},
+					},
+					Upgrade: rhmiconfigv1alpha1.Upgrade{
+						NotBeforeDays:      intPtr(0),
+						WaitForMaintenance: boolPtr(false),
+						Schedule:           boolPtr(true),
+					},
+				},
+				Status: rhmiconfigv1alpha1.RHMIConfigStatus{
+					UpgradeAvailable: &rhmiconfigv1alpha1.UpgradeAvailable{
+						TargetVersion: targetVersion,
+						AvailableAt:   kubeNow(0),
+					},
+				},
+			},
+			expectedSchedule: &rhmiconfigv1alpha1.UpgradeSchedule{
+				For: nowOffset(48).Format(rhmiconfigv1alpha1.DateFormat),
+			},
+		}),
+		makeScheduleScenario(&scheduleScenario{
+			name: "do not wait for maintenance, local time zone",
+			config: &rhmiconfigv1alpha1.RHMIConfig{
+				Spec: rhmiconfigv1alpha1.RHMIConfigSpec{
+					TimeZone: "Australia/Sydney",
+					Upgrade: rhmiconfigv1alpha1.Upgrade{
+						NotBeforeDays:      intPtr(0),
+						WaitForMaintenance: boolPtr(false),
+						Schedule:           boolPtr(true),
+					},
+				},
+				Status: rhmiconfigv1alpha1.RHMIConfigStatus{
+					UpgradeAvailable: &rhmiconfigv1alpha1.UpgradeAvailable{
+						TargetVersion: targetVersion,
+						AvailableAt:   kubeNow(0),
+					},
+				},
+			},
+			expectedSchedule: &rhmiconfigv1alpha1.UpgradeSchedule{
+				For:      kubeNow(0).Format(rhmiconfigv1alpha1.DateFormat),
+				ForLocal: kubeNow(0).In(sydney()).Format(rhmiconfigv1alpha1.LocalDateFormat),
+			},
+		}),
 	}
 
 	for _, scenario := range scenarios {
@@ -277,43 +327,6 @@ func TestUpdateStatus(t *testing.T) {
 	}
 }
 
-func TestGetWeeklyWindow(t *testing.T) {
-	// Monday
-	from := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
-
-	// Test same day
-	r, _, err := getWeeklyWindow(from, "Mon 00:00", time.Hour)
-	if err != nil {
-		t.Errorf("Error calculating weekly window for same day: %v", err)
-	} else if r.Day() != from.Day() || r.Month() != from.Month() || r.Year() != from.Year() {
-		t.Errorf("Expected result to be same day, got %s", r.Format(rhmiconfigv1alpha1.DateFormat))
-	}
-
-	// Test next day
-	r, _, err = getWeeklyWindow(from, "Tue 00:00", time.Hour)
-	if err != nil {
-		t.Errorf("Error calculating weekly window for same day: %v", err)
-	} else if r.Day() != from.Day()+1 || r.Month() != from.Month() || r.Year() != from.Year() {
-		t.Errorf("Expected result to be next day, got %s", r.Format(rhmiconfigv1alpha1.DateFormat))
-	}
-
-	// Test day before
-	r, _, err = getWeeklyWindow(from, "SuN 00:00", time.Hour)
-	if err != nil {
-		t.Errorf("Error calculating weekly window for same day: %v", err)
-	} else if r.Day() != from.Day()+6 || r.Month() != from.Month() || r.Year() != from.Year() {
-		t.Errorf("Expected result to be next Sunday, got %s", r.Format(rhmiconfigv1alpha1.DateFormat))
-	}
-
-	// Test 3 days after
-	r, _, err = getWeeklyWindow(from, "Thu 02:00", time.Hour)
-	if err != nil {
-		t.Errorf("Error calculating weekly window for same day: %v", err)
-	} else if r.Day() != from.Day()+3 || r.Month() != from.Month() || r.Year() != from.Year() {
-		t.Errorf("Expected result to be Thursday, got %s", r.Format(rhmiconfigv1alpha1.DateFormat))
-	}
-}
-
 func buildScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 
@@ -334,6 +347,14 @@ func kubeNow(addHours int) metav1.Time {
 	return t
 }
 
+func sydney() *time.Location {
+	loc, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
 func now() time.Time {
 	return time.Now().UTC()
 }
@@ -25,7 +25,10 @@ import (
 
 	croUtil "github.com/integr8ly/cloud-resource-operator/pkg/client"
 	"github.com/integr8ly/integreatly-operator/controllers/rhmiconfig/helpers"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 	k8sErr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -99,6 +102,13 @@ func (r *RHMIConfigReconciler) Reconcile(request ctrl.Request) (ctrl.Result, err
 		return retryRequeue, err
 	}
 
+	// remove the snapshots beyond the retention policy. The config is
+	// reconciled periodically so they're pruned as they expire
+	if err := r.pruneSnapshots(rhmiConfig); err != nil {
+		log.Error("rhmi config failure while pruning snapshots", err)
+		return retryRequeue, err
+	}
+
 	log.Info("rhmi config reconciled successfully")
 	return ctrl.Result{Requeue: true, RequeueAfter: 5 * time.Minute}, nil
 }
@@ -117,6 +127,21 @@ func (r *RHMIConfigReconciler) ReconcileCloudResourceStrategies(config *rhmiconf
 		return fmt.Errorf("failure validating backup and maintenance values : %v", err)
 	}
 
+	// the cloud providers expect UTC times, they are converted with the current
+	// offset of the time zone. The config is reconciled periodically so the
+	// times follow the daylight saving time changes
+	loc, err := config.Spec.GetLocation()
+	if err != nil {
+		return fmt.Errorf("failure validating time zone : %v", err)
+	}
+	now := time.Now()
+	if backupApplyOn, err = maintenance.DailyTimeToUTC(now, backupApplyOn, loc); err != nil {
+		return fmt.Errorf("failure converting backup applyOn to UTC : %v", err)
+	}
+	if maintenanceApplyFrom, err = maintenance.WeeklyTimeToUTC(now, maintenanceApplyFrom, loc); err != nil {
+		return fmt.Errorf("failure converting maintenance applyFrom to UTC : %v", err)
+	}
+
 	// build time config expected by CRO
 	timeConfig := &croUtil.StrategyTimeConfig{
 		BackupStartTime:      backupApplyOn,
@@ -132,6 +157,29 @@ func (r *RHMIConfigReconciler) ReconcileCloudResourceStrategies(config *rhmiconf
 	return nil
 }
 
+// pruneSnapshots removes the postgres and redis snapshots beyond the retention
+// of the config, and reports the removed snapshots in the status
+func (r *RHMIConfigReconciler) pruneSnapshots(config *rhmiconfigv1alpha1.RHMIConfig) error {
+	pruned, err := backup.PruneSnapshots(context.TODO(), r.Client, config.Namespace, config.Spec.Backup.Retention, time.Now())
+	if len(pruned) > 0 {
+		log.Infof("pruned snapshots beyond the retention", l.Fields{"snapshots": pruned})
+
+		now := metav1.Now()
+		config.Status.Backup = &rhmiconfigv1alpha1.RHMIConfigStatusBackup{
+			PrunedAt: &now,
+			Pruned:   pruned,
+		}
+		if updateErr := r.Status().Update(context.TODO(), config); updateErr != nil {
+			return fmt.Errorf("failed to report the pruned snapshots : %v", updateErr)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to prune snapshots : %v", err)
+	}
+
+	return nil
+}
+
 // we require that blank applyOn and applyFrom values be set to defaults
 // we expect a user to set their own times, but in the case where times are not set
 // we set our maintenance applyFrom values to be Thu 02:00
@@ -0,0 +1,545 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/products"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const defaultInstallationConfigMapName = "installation-config"
+
+var log = l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "rhmi_restore_controller"})
+
+// RHMIRestoreReconciler reconciles a RHMIRestore object
+type RHMIRestoreReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	mgr                        ctrl.Manager
+	restConfig                 *rest.Config
+	productsInstallationLoader marketplace.ProductsInstallationLoader
+
+	// getRestoreWorkloads returns the workloads stopped while the data of a
+	// product is restored
+	getRestoreWorkloads func(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) ([]backup.Workload, error)
+	// getRestoreExecutor returns the restore of a component of the product
+	// backed up by a RHMIBackup
+	getRestoreExecutor func(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter, componentName, backupType string) (backup.RestoreExecutor, error)
+}
+
+// restorePollInterval is how often the progress of a step is checked
+var restorePollInterval = 15 * time.Second
+
+func New(mgr ctrl.Manager) *RHMIRestoreReconciler {
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.Timeout = 10 * time.Second
+
+	r := &RHMIRestoreReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		mgr:        mgr,
+		restConfig: restConfig,
+		productsInstallationLoader: marketplace.NewFSProductInstallationLoader(
+			marketplace.GetProductsInstallationPath(),
+		),
+	}
+	r.getRestoreWorkloads = r.productRestoreWorkloads
+	r.getRestoreExecutor = r.productRestoreExecutor
+	return r
+}
+
+// +kubebuilder:rbac:groups=integreatly.org,resources=rhmirestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=integreatly.org,resources=rhmirestores/status,verbs=get;update;patch
+
+// Reconcile starts the next step of the restore, or checks the progress of
+// the step in progress. The steps run in the background one after the other,
+// so the restore is resumed after a restart of the operator
+func (r *RHMIRestoreReconciler) Reconcile(request ctrl.Request) (ctrl.Result, error) {
+	ctx := context.TODO()
+
+	rhmiRestore := &integreatlyv1alpha1.RHMIRestore{}
+	if err := r.Get(ctx, request.NamespacedName, rhmiRestore); err != nil {
+		if k8serr.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	restoreLog := l.NewLoggerWithContext(l.Fields{l.ControllerLogContext: "rhmi_restore_controller", "restore": rhmiRestore.Name})
+
+	switch rhmiRestore.Status.Phase {
+	case integreatlyv1alpha1.PhaseCompleted, integreatlyv1alpha1.PhaseFailed:
+		return ctrl.Result{}, nil
+	case integreatlyv1alpha1.PhaseNone:
+		if err := r.prepareRestore(ctx, rhmiRestore); err != nil {
+			restoreLog.Error("Failed to prepare the restore", err)
+			now := metav1.Now()
+			rhmiRestore.Status.Phase = integreatlyv1alpha1.PhaseFailed
+			rhmiRestore.Status.CompletionTime = &now
+			rhmiRestore.Status.Error = err.Error()
+			return ctrl.Result{}, r.Status().Update(ctx, rhmiRestore)
+		}
+		restoreLog.Infof("Restore started", l.Fields{"backup": rhmiRestore.Spec.Backup, "steps": len(rhmiRestore.Status.Steps)})
+		return ctrl.Result{Requeue: true}, r.Status().Update(ctx, rhmiRestore)
+	}
+
+	index := nextStep(rhmiRestore)
+	if index < 0 {
+		completeRestore(rhmiRestore)
+		restoreLog.Infof("Restore finished", l.Fields{"phase": rhmiRestore.Status.Phase})
+		return ctrl.Result{}, r.Status().Update(ctx, rhmiRestore)
+	}
+
+	step := &rhmiRestore.Status.Steps[index]
+	if step.Phase == integreatlyv1alpha1.PhaseNone {
+		restoreLog.Infof("Starting restore step", l.Fields{"product": step.Product, "action": step.Action, "component": step.Component})
+		done, err := r.startStep(ctx, rhmiRestore, index)
+		step = &rhmiRestore.Status.Steps[index]
+		switch {
+		case err != nil:
+			restoreLog.Error(fmt.Sprintf("Restore step %s of %s failed", step.Action, step.Product), err)
+			failStep(rhmiRestore, index, err.Error())
+		case done:
+			completeStep(rhmiRestore, index)
+		default:
+			started := metav1.Now()
+			step.Phase = integreatlyv1alpha1.PhaseInProgress
+			step.StartTime = &started
+		}
+		return ctrl.Result{Requeue: true}, r.Status().Update(ctx, rhmiRestore)
+	}
+
+	done, err := r.checkStep(ctx, rhmiRestore, index)
+	step = &rhmiRestore.Status.Steps[index]
+	switch {
+	case err != nil:
+		restoreLog.Error(fmt.Sprintf("Restore step %s of %s failed", step.Action, step.Product), err)
+		failStep(rhmiRestore, index, err.Error())
+	case done:
+		restoreLog.Infof("Restore step completed", l.Fields{"product": step.Product, "action": step.Action, "component": step.Component})
+		completeStep(rhmiRestore, index)
+	case step.StartTime != nil && time.Since(step.StartTime.Time) > restoreTimeout(rhmiRestore):
+		failStep(rhmiRestore, index, fmt.Sprintf("timed out after %s", restoreTimeout(rhmiRestore)))
+	default:
+		return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+	}
+	return ctrl.Result{Requeue: true}, r.Status().Update(ctx, rhmiRestore)
+}
+
+// prepareRestore lists the steps of the restore in its status. The products
+// are restored one after the other, each one is scaled down, its components
+// restored, and then scaled back up
+func (r *RHMIRestoreReconciler) prepareRestore(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore) error {
+	rhmiBackup := &integreatlyv1alpha1.RHMIBackup{}
+	if err := r.Get(ctx, types.NamespacedName{Name: rhmiRestore.Spec.Backup, Namespace: rhmiRestore.Namespace}, rhmiBackup); err != nil {
+		return fmt.Errorf("failed to get RHMIBackup %s: %w", rhmiRestore.Spec.Backup, err)
+	}
+
+	components, err := componentsToRestore(rhmiBackup, rhmiRestore.Spec.Products)
+	if err != nil {
+		return err
+	}
+
+	productNames := []integreatlyv1alpha1.ProductName{}
+	for productName := range components {
+		productNames = append(productNames, productName)
+	}
+	sort.Slice(productNames, func(i, j int) bool { return productNames[i] < productNames[j] })
+
+	steps := []integreatlyv1alpha1.RHMIRestoreStep{}
+	for _, productName := range productNames {
+		steps = append(steps, integreatlyv1alpha1.RHMIRestoreStep{Product: productName, Action: integreatlyv1alpha1.RestoreActionScaleDown})
+		for _, component := range components[productName] {
+			steps = append(steps, integreatlyv1alpha1.RHMIRestoreStep{
+				Product:   productName,
+				Action:    integreatlyv1alpha1.RestoreActionRestore,
+				Component: component.Name,
+				Type:      component.Type,
+				Backup:    component.Backup,
+			})
+		}
+		steps = append(steps, integreatlyv1alpha1.RHMIRestoreStep{Product: productName, Action: integreatlyv1alpha1.RestoreActionScaleUp})
+	}
+
+	now := metav1.Now()
+	rhmiRestore.Status.Phase = integreatlyv1alpha1.PhaseInProgress
+	rhmiRestore.Status.StartTime = &now
+	rhmiRestore.Status.Steps = steps
+	return nil
+}
+
+// startStep starts the step, and returns true if it completed already. The
+// workloads are scaled down, or the restore of the component started, and
+// their progress is checked by checkStep. The workloads are scaled up within
+// the step, as there is nothing left to wait for afterwards
+func (r *RHMIRestoreReconciler) startStep(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore, index int) (bool, error) {
+	step := rhmiRestore.Status.Steps[index]
+	switch step.Action {
+	case integreatlyv1alpha1.RestoreActionScaleDown:
+		return false, r.scaleDown(ctx, rhmiRestore, step.Product)
+	case integreatlyv1alpha1.RestoreActionRestore:
+		executor, err := r.restoreExecutor(ctx, rhmiRestore, step)
+		if err != nil {
+			return false, err
+		}
+		return false, executor.StartRestore(r.Client, step.Backup)
+	case integreatlyv1alpha1.RestoreActionScaleUp:
+		return true, r.scaleUp(rhmiRestore, step.Product)
+	default:
+		return false, fmt.Errorf("unknown restore action %s", step.Action)
+	}
+}
+
+// checkStep returns true once the step in progress completed
+func (r *RHMIRestoreReconciler) checkStep(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore, index int) (bool, error) {
+	step := rhmiRestore.Status.Steps[index]
+	switch step.Action {
+	case integreatlyv1alpha1.RestoreActionScaleDown:
+		return r.scaledDown(ctx, rhmiRestore, step.Product)
+	case integreatlyv1alpha1.RestoreActionRestore:
+		executor, err := r.restoreExecutor(ctx, rhmiRestore, step)
+		if err != nil {
+			return false, err
+		}
+		return executor.CheckRestore(r.Client, step.Backup)
+	case integreatlyv1alpha1.RestoreActionScaleUp:
+		// Scaled up when the step started
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown restore action %s", step.Action)
+	}
+}
+
+// restoreExecutor returns the restore of the component of the step
+func (r *RHMIRestoreReconciler) restoreExecutor(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore, step integreatlyv1alpha1.RHMIRestoreStep) (backup.RestoreExecutor, error) {
+	installation, configManager, err := r.getInstallation(ctx, rhmiRestore.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return r.getRestoreExecutor(installation, step.Product, configManager, step.Component, step.Type)
+}
+
+// scaleDown scales the workloads of the product to 0, recording their
+// replicas in the status. The replicas recorded by an interrupted scale
+// down are kept, as the workloads may already be scaled down
+func (r *RHMIRestoreReconciler) scaleDown(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore, product integreatlyv1alpha1.ProductName) error {
+	workloads, err := r.productWorkloads(ctx, rhmiRestore, product)
+	if err != nil {
+		return err
+	}
+
+	for _, workload := range workloads {
+		if recordedWorkload(rhmiRestore, product, workload) == nil {
+			replicas, err := workload.Replicas(r.Client)
+			if err != nil {
+				return err
+			}
+			rhmiRestore.Status.Workloads = append(rhmiRestore.Status.Workloads, integreatlyv1alpha1.RHMIRestoreWorkload{
+				Product:    product,
+				APIVersion: workload.GroupVersionKind.GroupVersion().String(),
+				Kind:       workload.GroupVersionKind.Kind,
+				Name:       workload.Name,
+				Namespace:  workload.Namespace,
+				Path:       workload.ReplicasPath,
+				Replicas:   replicas,
+			})
+			// The replicas are recorded before the workload is scaled down
+			// so they can't be lost
+			if err := r.Status().Update(ctx, rhmiRestore); err != nil {
+				return fmt.Errorf("failed to update the status of the restore: %w", err)
+			}
+		}
+
+		if err := workload.Scale(r.Client, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scaledDown returns true once the pods of the workloads of the product are
+// gone
+func (r *RHMIRestoreReconciler) scaledDown(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore, product integreatlyv1alpha1.ProductName) (bool, error) {
+	workloads, err := r.productWorkloads(ctx, rhmiRestore, product)
+	if err != nil {
+		return false, err
+	}
+
+	for _, workload := range workloads {
+		scaledDown, err := workload.ScaledDown(r.Client)
+		if err != nil || !scaledDown {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// productWorkloads returns the workloads of the product stopped during the
+// restore
+func (r *RHMIRestoreReconciler) productWorkloads(ctx context.Context, rhmiRestore *integreatlyv1alpha1.RHMIRestore, product integreatlyv1alpha1.ProductName) ([]backup.Workload, error) {
+	installation, configManager, err := r.getInstallation(ctx, rhmiRestore.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	workloads, err := r.getRestoreWorkloads(installation, product, configManager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the workloads of %s: %w", product, err)
+	}
+	return workloads, nil
+}
+
+// getInstallation returns the installation in the namespace of the restore
+// and its config
+func (r *RHMIRestoreReconciler) getInstallation(ctx context.Context, namespace string) (*integreatlyv1alpha1.RHMI, config.ConfigReadWriter, error) {
+	installation, err := resources.GetRhmiCr(r.Client, ctx, namespace, log)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the installation: %w", err)
+	}
+	if installation == nil {
+		return nil, nil, fmt.Errorf("no installation found in namespace %s", namespace)
+	}
+
+	configManager, err := config.NewManager(ctx, r.Client, installation.Namespace, installationConfigMapName(installation), installation)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the installation config: %w", err)
+	}
+	return installation, configManager, nil
+}
+
+// scaleUp scales the workloads of the product back to their replicas
+// before the restore, in reverse order
+func (r *RHMIRestoreReconciler) scaleUp(rhmiRestore *integreatlyv1alpha1.RHMIRestore, product integreatlyv1alpha1.ProductName) error {
+	var mErr *resources.MultiErr
+	for i := len(rhmiRestore.Status.Workloads) - 1; i >= 0; i-- {
+		recorded := rhmiRestore.Status.Workloads[i]
+		if recorded.Product != product {
+			continue
+		}
+
+		workload := backup.Workload{
+			GroupVersionKind: schema.FromAPIVersionAndKind(recorded.APIVersion, recorded.Kind),
+			Name:             recorded.Name,
+			Namespace:        recorded.Namespace,
+			ReplicasPath:     recorded.Path,
+		}
+		if err := workload.Scale(r.Client, recorded.Replicas); err != nil {
+			if mErr == nil {
+				mErr = &resources.MultiErr{}
+			}
+			mErr.Add(err)
+		}
+	}
+
+	if mErr != nil {
+		return mErr
+	}
+	return nil
+}
+
+// productRestoreWorkloads returns the workloads of the product to stop
+// during the restore, none if the product doesn't need to be stopped
+func (r *RHMIRestoreReconciler) productRestoreWorkloads(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter) ([]backup.Workload, error) {
+	reconciler, err := products.NewReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	restoreReconciler, ok := reconciler.(products.RestoreInterface)
+	if !ok {
+		return nil, nil
+	}
+	return restoreReconciler.RestoreWorkloads(), nil
+}
+
+// productRestoreExecutor returns the restore of the component backed up by
+// the pre-upgrade backup of the product. The backup type recorded in the
+// RHMIBackup must match the current backup of the component, as the storage
+// of the installation may have changed since
+func (r *RHMIRestoreReconciler) productRestoreExecutor(installation *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, configManager config.ConfigReadWriter, componentName, backupType string) (backup.RestoreExecutor, error) {
+	reconciler, err := products.NewReconciler(product, r.restConfig, configManager, installation, r.mgr, log, r.productsInstallationLoader)
+	if err != nil {
+		return nil, err
+	}
+
+	backupReconciler, ok := reconciler.(products.BackupInterface)
+	if !ok {
+		return nil, fmt.Errorf("%s has no backups to restore", product)
+	}
+
+	for _, each := range backup.Components(backupReconciler.PreUpgradeBackupExecutor()) {
+		component, ok := each.(backup.ComponentBackupExecutor)
+		if !ok || component.ComponentName() != componentName {
+			continue
+		}
+		if component.BackupType() != backupType {
+			return nil, fmt.Errorf("%s is backed up as %s, the %s backup can't be restored", componentName, component.BackupType(), backupType)
+		}
+		return backup.NewRestoreExecutor(component)
+	}
+	return nil, fmt.Errorf("%s has no component %s to restore", product, componentName)
+}
+
+// componentsToRestore returns the completed components of the backup for
+// the requested products, or for every product in the backup if none is
+// requested
+func componentsToRestore(rhmiBackup *integreatlyv1alpha1.RHMIBackup, requested []integreatlyv1alpha1.ProductName) (map[integreatlyv1alpha1.ProductName][]integreatlyv1alpha1.RHMIBackupComponentStatus, error) {
+	if rhmiBackup.Status.Phase != integreatlyv1alpha1.PhaseCompleted && rhmiBackup.Status.Phase != integreatlyv1alpha1.PhaseFailed {
+		return nil, fmt.Errorf("RHMIBackup %s has not finished", rhmiBackup.Name)
+	}
+
+	backedUp := map[integreatlyv1alpha1.ProductName][]integreatlyv1alpha1.RHMIBackupComponentStatus{}
+	for _, component := range rhmiBackup.Status.Components {
+		if component.Phase == integreatlyv1alpha1.PhaseCompleted && component.Backup != "" {
+			backedUp[component.Product] = append(backedUp[component.Product], component)
+		}
+	}
+
+	if len(requested) == 0 {
+		if len(backedUp) == 0 {
+			return nil, fmt.Errorf("RHMIBackup %s has no completed backups", rhmiBackup.Name)
+		}
+		return backedUp, nil
+	}
+
+	components := map[integreatlyv1alpha1.ProductName][]integreatlyv1alpha1.RHMIBackupComponentStatus{}
+	for _, productName := range requested {
+		if len(backedUp[productName]) == 0 {
+			return nil, fmt.Errorf("RHMIBackup %s has no completed backups of %s", rhmiBackup.Name, productName)
+		}
+		components[productName] = backedUp[productName]
+	}
+	return components, nil
+}
+
+// nextStep returns the index of the next step to run, or -1 if there are
+// none left. Once a step has failed, only the steps scaling up the products
+// are run
+func nextStep(rhmiRestore *integreatlyv1alpha1.RHMIRestore) int {
+	failed := false
+	for i, step := range rhmiRestore.Status.Steps {
+		switch step.Phase {
+		case integreatlyv1alpha1.PhaseCompleted:
+			continue
+		case integreatlyv1alpha1.PhaseFailed:
+			failed = true
+			continue
+		}
+		if failed && step.Action != integreatlyv1alpha1.RestoreActionScaleUp {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func completeStep(rhmiRestore *integreatlyv1alpha1.RHMIRestore, index int) {
+	completed := metav1.Now()
+	step := &rhmiRestore.Status.Steps[index]
+	step.Phase = integreatlyv1alpha1.PhaseCompleted
+	step.CompletionTime = &completed
+	step.Message = ""
+}
+
+func failStep(rhmiRestore *integreatlyv1alpha1.RHMIRestore, index int, message string) {
+	completed := metav1.Now()
+	step := &rhmiRestore.Status.Steps[index]
+	step.Phase = integreatlyv1alpha1.PhaseFailed
+	step.CompletionTime = &completed
+	step.Message = message
+}
+
+// completeRestore sets the result of the restore once there are no steps
+// left to run
+func completeRestore(rhmiRestore *integreatlyv1alpha1.RHMIRestore) {
+	completed := metav1.Now()
+	rhmiRestore.Status.CompletionTime = &completed
+	rhmiRestore.Status.Phase = integreatlyv1alpha1.PhaseCompleted
+
+	failed := []string{}
+	for i := range rhmiRestore.Status.Steps {
+		step := &rhmiRestore.Status.Steps[i]
+		switch step.Phase {
+		case integreatlyv1alpha1.PhaseFailed:
+			name := fmt.Sprintf("%s %s", step.Action, step.Product)
+			if step.Component != "" {
+				name = fmt.Sprintf("%s %s", step.Action, step.Component)
+			}
+			failed = append(failed, name)
+		case integreatlyv1alpha1.PhaseNone:
+			step.Message = "not run as a previous step failed"
+		}
+	}
+	if len(failed) > 0 {
+		rhmiRestore.Status.Phase = integreatlyv1alpha1.PhaseFailed
+		rhmiRestore.Status.Error = fmt.Sprintf("restore steps failed: %s", strings.Join(failed, ", "))
+	}
+}
+
+// restoreTimeout returns the time each step of the restore is given to
+// complete
+func restoreTimeout(rhmiRestore *integreatlyv1alpha1.RHMIRestore) time.Duration {
+	if rhmiRestore.Spec.Timeout != nil {
+		return rhmiRestore.Spec.Timeout.Duration
+	}
+	return backup.DefaultRestoreTimeout
+}
+
+func recordedWorkload(rhmiRestore *integreatlyv1alpha1.RHMIRestore, product integreatlyv1alpha1.ProductName, workload backup.Workload) *integreatlyv1alpha1.RHMIRestoreWorkload {
+	for i, recorded := range rhmiRestore.Status.Workloads {
+		if recorded.Product == product && recorded.Kind == workload.GroupVersionKind.Kind &&
+			recorded.Name == workload.Name && recorded.Namespace == workload.Namespace && recorded.Path == workload.ReplicasPath {
+			return &rhmiRestore.Status.Workloads[i]
+		}
+	}
+	return nil
+}
+
+func installationConfigMapName(installation *integreatlyv1alpha1.RHMI) string {
+	if name := os.Getenv("INSTALLATION_CONFIG_MAP"); name != "" {
+		return name
+	}
+	return installation.Spec.NamespacePrefix + defaultInstallationConfigMapName
+}
+
+func (r *RHMIRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&integreatlyv1alpha1.RHMIRestore{}).
+		Complete(r)
+}
@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "redhat-rhmi-operator"
+
+// mockRestoreExecutor fails the restore if err is set, keeps it in progress
+// if pending is set, and records the replicas of the workload at the time of
+// the restore
+type mockRestoreExecutor struct {
+	name     string
+	err      error
+	pending  bool
+	workload backup.Workload
+	replicas *int64
+}
+
+func (e *mockRestoreExecutor) ComponentName() string {
+	return e.name
+}
+
+func (e *mockRestoreExecutor) StartRestore(client k8sclient.Client, backupName string) error {
+	replicas, err := e.workload.Replicas(client)
+	if err != nil {
+		return err
+	}
+	*e.replicas = replicas
+	return nil
+}
+
+func (e *mockRestoreExecutor) CheckRestore(client k8sclient.Client, backupName string) (bool, error) {
+	if e.err != nil {
+		return false, e.err
+	}
+	return !e.pending, nil
+}
+
+func getBuildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := appsv1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func installation() *integreatlyv1alpha1.RHMI {
+	return &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: testNamespace},
+	}
+}
+
+func rhmiBackup() *integreatlyv1alpha1.RHMIBackup {
+	return &integreatlyv1alpha1.RHMIBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-backup", Namespace: testNamespace},
+		Status: integreatlyv1alpha1.RHMIBackupStatus{
+			Phase: integreatlyv1alpha1.PhaseCompleted,
+			Components: []integreatlyv1alpha1.RHMIBackupComponentStatus{
+				{
+					Product: integreatlyv1alpha1.Product3Scale,
+					Name:    "threescale-postgres-rhmi",
+					Type:    string(backup.PostgresSnapshotType),
+					Backup:  "threescale-postgres-rhmi-test-backup",
+					Phase:   integreatlyv1alpha1.PhaseCompleted,
+				},
+				{
+					Product: integreatlyv1alpha1.ProductMarin3r,
+					Name:    "ratelimit-service-redis-rhmi",
+					Type:    string(backup.RedisSnapshotType),
+					Backup:  "ratelimit-service-redis-rhmi-test-backup",
+					Phase:   integreatlyv1alpha1.PhaseCompleted,
+				},
+			},
+		},
+	}
+}
+
+func TestRHMIRestoreReconcile(t *testing.T) {
+	scheme := getBuildScheme(t)
+	replicas := int32(3)
+	workload := backup.NewDeploymentWorkload("system-app", "redhat-rhmi-3scale", nil)
+	started := metav1.Now()
+	startedHourAgo := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	cases := []struct {
+		Name           string
+		Restore        *integreatlyv1alpha1.RHMIRestore
+		Objects        []runtime.Object
+		RestoreErr     error
+		RestorePending bool
+		ExpectedPhase  integreatlyv1alpha1.StatusPhase
+		ExpectedSteps  []integreatlyv1alpha1.StatusPhase
+		ExpectedError  string
+	}{
+		{
+			Name: "test restore of a product",
+			Restore: &integreatlyv1alpha1.RHMIRestore{
+				Spec: integreatlyv1alpha1.RHMIRestoreSpec{
+					Backup:   "test-backup",
+					Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.Product3Scale},
+				},
+			},
+			Objects:       []runtime.Object{installation(), rhmiBackup()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			ExpectedSteps: []integreatlyv1alpha1.StatusPhase{
+				integreatlyv1alpha1.PhaseCompleted,
+				integreatlyv1alpha1.PhaseCompleted,
+				integreatlyv1alpha1.PhaseCompleted,
+			},
+		},
+		{
+			Name: "test failed restore scales the products back up",
+			Restore: &integreatlyv1alpha1.RHMIRestore{
+				Spec: integreatlyv1alpha1.RHMIRestoreSpec{Backup: "test-backup"},
+			},
+			Objects:       []runtime.Object{installation(), rhmiBackup()},
+			RestoreErr:    errors.New("snapshot not found"),
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedSteps: []integreatlyv1alpha1.StatusPhase{
+				// 3scale
+				integreatlyv1alpha1.PhaseCompleted,
+				integreatlyv1alpha1.PhaseFailed,
+				integreatlyv1alpha1.PhaseCompleted,
+				// marin3r isn't restored after the failure
+				integreatlyv1alpha1.PhaseNone,
+				integreatlyv1alpha1.PhaseNone,
+				integreatlyv1alpha1.PhaseCompleted,
+			},
+			ExpectedError: "restore steps failed: Restore threescale-postgres-rhmi",
+		},
+		{
+			Name: "test restore of a product that is not in the backup",
+			Restore: &integreatlyv1alpha1.RHMIRestore{
+				Spec: integreatlyv1alpha1.RHMIRestoreSpec{
+					Backup:   "test-backup",
+					Products: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
+				},
+			},
+			Objects:       []runtime.Object{installation(), rhmiBackup()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedError: "RHMIBackup test-backup has no completed backups of rhsso",
+		},
+		{
+			Name: "test restore of a missing backup",
+			Restore: &integreatlyv1alpha1.RHMIRestore{
+				Spec: integreatlyv1alpha1.RHMIRestoreSpec{Backup: "test-backup"},
+			},
+			Objects:       []runtime.Object{installation()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseFailed,
+			ExpectedError: `failed to get RHMIBackup test-backup: rhmibackups.integreatly.org "test-backup" not found`,
+		},
+		{
+			Name: "test restore in progress is resumed",
+			Restore: &integreatlyv1alpha1.RHMIRestore{
+				Spec: integreatlyv1alpha1.RHMIRestoreSpec{Backup: "test-backup"},
+				Status: integreatlyv1alpha1.RHMIRestoreStatus{
+					Phase: integreatlyv1alpha1.PhaseInProgress,
+					Steps: []integreatlyv1alpha1.RHMIRestoreStep{
+						{Product: integreatlyv1alpha1.Product3Scale, Action: integreatlyv1alpha1.RestoreActionScaleDown, Phase: integreatlyv1alpha1.PhaseCompleted},
+						{Product: integreatlyv1alpha1.Product3Scale, Action: integreatlyv1alpha1.RestoreActionRestore, Component: "threescale-postgres-rhmi", Phase: integreatlyv1alpha1.PhaseInProgress, StartTime: &started},
+						{Product: integreatlyv1alpha1.Product3Scale, Action: integreatlyv1alpha1.RestoreActionScaleUp},
+					},
+					Workloads: []integreatlyv1alpha1.RHMIRestoreWorkload{
+						{Product: integreatlyv1alpha1.Product3Scale, APIVersion: "apps/v1", Kind: "Deployment", Name: "system-app", Namespace: "redhat-rhmi-3scale", Path: "spec.replicas", Replicas: 3},
+					},
+				},
+			},
+			Objects:       []runtime.Object{installation(), rhmiBackup()},
+			ExpectedPhase: integreatlyv1alpha1.PhaseCompleted,
+			ExpectedSteps: []integreatlyv1alpha1.StatusPhase{
+				integreatlyv1alpha1.PhaseCompleted,
+				integreatlyv1alpha1.PhaseCompleted,
+				integreatlyv1alpha1.PhaseCompleted,
+			},
+		},
+		{
+			Name: "test restore in progress times out",
+			Restore: &integreatlyv1alpha1.RHMIRestore{
+				Spec: integreatlyv1alpha1.RHMIRestoreSpec{Backup: "test-backup"},
+				Status: integreatlyv1alpha1.RHMIRestoreStatus{
+					Phase: integreatlyv1alpha1.PhaseInProgress,
+					Steps: []integreatlyv1alpha1.RHMIRestoreStep{
+						{Product: integreatlyv1alpha1.Product3Scale, Action: integreatlyv1alpha1.RestoreActionScaleDown, Phase: integreatlyv1alpha1.PhaseCompleted},
+						{Product: integreatlyv1alpha1.Product3Scale, Action: integreatlyv1alpha1.RestoreActionRestore, Component: "threescale-postgres-rhmi", Phase: integreatlyv1alpha1.PhaseInProgress, StartTime: &startedHourAgo},
+						{Product: integreatlyv1alpha1.Product3Scale, Action: integreatlyv1alpha1.RestoreActionScaleUp},
+					},
+					Workloads: []integreatlyv1alpha1.RHMIRestoreWorkload{
+						{Product: integreatlyv1alpha1.Product3Scale, APIVersion: "apps/v1", Kind: "Deployment", Name: "system-app", Namespace: "redhat-rhmi-3scale", Path: "spec.replicas", Replicas: 3},
+					},
+				},
+			},
+			Objects:        []runtime.Object{installation(), rhmiBackup()},
+			RestorePending: true,
+			ExpectedPhase:  integreatlyv1alpha1.PhaseFailed,
+			ExpectedSteps: []integreatlyv1alpha1.StatusPhase{
+				integreatlyv1alpha1.PhaseCompleted,
+				integreatlyv1alpha1.PhaseFailed,
+				integreatlyv1alpha1.PhaseCompleted,
+			},
+			ExpectedError: "restore steps failed: Restore threescale-postgres-rhmi",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Restore.Name = "test-restore"
+			tc.Restore.Namespace = testNamespace
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "system-app", Namespace: "redhat-rhmi-3scale"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			}
+			if tc.Restore.Status.Phase == integreatlyv1alpha1.PhaseInProgress {
+				// Scaled down before the interruption
+				deployment.Spec.Replicas = new(int32)
+			}
+			client := fake.NewFakeClientWithScheme(scheme, append(tc.Objects, tc.Restore, deployment)...)
+
+			replicasDuringRestore := int64(-1)
+			reconciler := &RHMIRestoreReconciler{
+				Client: client,
+				Scheme: scheme,
+				getRestoreWorkloads: func(_ *integreatlyv1alpha1.RHMI, product integreatlyv1alpha1.ProductName, _ config.ConfigReadWriter) ([]backup.Workload, error) {
+					if product == integreatlyv1alpha1.Product3Scale {
+						return []backup.Workload{workload}, nil
+					}
+					return nil, nil
+				},
+				getRestoreExecutor: func(_ *integreatlyv1alpha1.RHMI, _ integreatlyv1alpha1.ProductName, _ config.ConfigReadWriter, componentName, _ string) (backup.RestoreExecutor, error) {
+					return &mockRestoreExecutor{name: componentName, err: tc.RestoreErr, pending: tc.RestorePending, workload: workload, replicas: &replicasDuringRestore}, nil
+				},
+			}
+
+			key := types.NamespacedName{Name: tc.Restore.Name, Namespace: testNamespace}
+			rhmiRestore := &integreatlyv1alpha1.RHMIRestore{}
+			for i := 0; ; i++ {
+				if i > 20 {
+					t.Fatal("expected the restore to finish")
+				}
+				result, err := reconciler.Reconcile(ctrl.Request{NamespacedName: key})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !result.Requeue && result.RequeueAfter == 0 {
+					break
+				}
+			}
+
+			if err := client.Get(context.TODO(), key, rhmiRestore); err != nil {
+				t.Fatal(err)
+			}
+			if rhmiRestore.Status.Phase != tc.ExpectedPhase {
+				t.Fatalf("expected phase %s, got %s", tc.ExpectedPhase, rhmiRestore.Status.Phase)
+			}
+			if rhmiRestore.Status.Error != tc.ExpectedError {
+				t.Fatalf("expected error %q, got %q", tc.ExpectedError, rhmiRestore.Status.Error)
+			}
+			if rhmiRestore.Status.CompletionTime == nil {
+				t.Fatal("expected the completion time to be set")
+			}
+
+			steps := []integreatlyv1alpha1.StatusPhase{}
+			for _, step := range rhmiRestore.Status.Steps {
+				steps = append(steps, step.Phase)
+			}
+			if fmt.Sprint(steps) != fmt.Sprint(tc.ExpectedSteps) {
+				t.Fatalf("expected steps %v, got %v", tc.ExpectedSteps, steps)
+			}
+
+			if replicasDuringRestore > 0 {
+				t.Fatalf("expected the workload to be scaled down during the restore, got %d replicas", replicasDuringRestore)
+			}
+			if len(tc.ExpectedSteps) > 0 {
+				if replicas, err := workload.Replicas(client); err != nil || replicas != 3 {
+					t.Fatalf("expected the workload to be scaled back to 3 replicas, got %d, error %v", replicas, err)
+				}
+			}
+		})
+	}
+}
+
+func TestNextStep(t *testing.T) {
+	rhmiRestore := &integreatlyv1alpha1.RHMIRestore{
+		Status: integreatlyv1alpha1.RHMIRestoreStatus{
+			Steps: []integreatlyv1alpha1.RHMIRestoreStep{
+				{Action: integreatlyv1alpha1.RestoreActionScaleDown, Phase: integreatlyv1alpha1.PhaseCompleted},
+				{Action: integreatlyv1alpha1.RestoreActionRestore, Phase: integreatlyv1alpha1.PhaseFailed},
+				{Action: integreatlyv1alpha1.RestoreActionRestore},
+				{Action: integreatlyv1alpha1.RestoreActionScaleUp},
+			},
+		},
+	}
+	if index := nextStep(rhmiRestore); index != 3 {
+		t.Fatalf("expected the scale up to run after a failure, got step %d", index)
+	}
+
+	rhmiRestore.Status.Steps[3].Phase = integreatlyv1alpha1.PhaseCompleted
+	if index := nextStep(rhmiRestore); index != -1 {
+		t.Fatalf("expected no steps left, got step %d", index)
+	}
+}
@@ -24,6 +24,7 @@ import (
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	catalogsourceClient "github.com/integr8ly/integreatly-operator/pkg/resources/catalogsource"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 
 	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 
@@ -340,6 +341,19 @@ func (r *SubscriptionReconciler) HandleUpgrades(ctx context.Context, rhmiSubscri
 	}
 
 	if !isServiceAffecting {
+		// Upgrades are never approved during a blackout period
+		schedule, err := maintenance.NewSchedule(config.Spec)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if blackout := schedule.ActiveBlackout(time.Now().UTC()); blackout != nil {
+			log.Infof("Upgrade approval postponed until the end of the blackout period", l.Fields{"Reason": blackout.Reason, "To": blackout.To})
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: time.Minute,
+			}, nil
+		}
+
 		eventRecorder := r.mgr.GetEventRecorderFor("RHMI Upgrade")
 		if config.Status.UpgradeAvailable != nil && config.Status.UpgradeAvailable.TargetVersion == rhmiSubscription.Status.CurrentCSV {
 			config.Status.UpgradeAvailable = nil
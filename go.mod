@@ -21,6 +21,7 @@ require (
 	github.com/go-openapi/spec v0.20.3
 	github.com/go-openapi/strfmt v0.20.1
 	github.com/golang/protobuf v1.4.3
+	github.com/gomodule/redigo v1.8.5
 	github.com/google/go-cmp v0.5.4
 	github.com/google/go-querystring v1.0.0
 	github.com/googleapis/gnostic v0.5.3 // indirect
@@ -42,6 +43,7 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/prometheus/alertmanager v0.22.0
 	github.com/prometheus/client_golang v1.10.0
+	github.com/prometheus/common v0.23.0
 	github.com/sirupsen/logrus v1.7.0
 	github.com/syndesisio/syndesis/install/operator v0.0.0-20201210151747-8264b9904eab
 	golang.org/x/net v0.0.0-20210421230115-4e50805a0758
@@ -19,6 +19,9 @@ package main
 import (
 	"flag"
 	"os"
+	// the RHMIConfig time zones are loaded from the embedded database, the
+	// operator image may not ship one
+	_ "time/tzdata"
 
 	integreatlymetrics "github.com/integr8ly/integreatly-operator/pkg/metrics"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,17 +29,22 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	customMetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	rhmiv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	namespacecontroller "github.com/integr8ly/integreatly-operator/controllers/namespacelabel"
 	rhmicontroller "github.com/integr8ly/integreatly-operator/controllers/rhmi"
+	rhmibackupcontroller "github.com/integr8ly/integreatly-operator/controllers/rhmibackup"
 	rhmiconfigcontroller "github.com/integr8ly/integreatly-operator/controllers/rhmiconfig"
+	rhmirestorecontroller "github.com/integr8ly/integreatly-operator/controllers/rhmirestore"
 	subscriptioncontroller "github.com/integr8ly/integreatly-operator/controllers/subscription"
 	usercontroller "github.com/integr8ly/integreatly-operator/controllers/user"
 	"github.com/integr8ly/integreatly-operator/pkg/addon"
+	"github.com/integr8ly/integreatly-operator/pkg/products/marin3r"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/webhooks"
 	"github.com/sirupsen/logrus"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -59,6 +67,8 @@ func init() {
 	customMetrics.Registry.MustRegister(integreatlymetrics.RHOAMStatus)
 	customMetrics.Registry.MustRegister(integreatlymetrics.ThreeScaleUserAction)
 	customMetrics.Registry.MustRegister(integreatlymetrics.Quota)
+	customMetrics.Registry.MustRegister(integreatlymetrics.BackupVerification)
+	customMetrics.Registry.MustRegister(integreatlymetrics.BackupVerificationTimestamp)
 	integreatlymetrics.OperatorVersion.Add(1)
 
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
@@ -70,8 +80,11 @@ func init() {
 
 func main() {
 	var metricsAddr string
+	var rateLimitUsageAddr string
 	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8383", "The address the metric endpoint binds to.")
+	flag.StringVar(&rateLimitUsageAddr, "rate-limit-usage-addr", ":8384", "The address the rate limit usage endpoint binds to. "+
+		"It's served by the leader to the users allowed to get /ratelimit/usage. Empty disables the endpoint.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -114,6 +127,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "RHMIConfig")
 		os.Exit(1)
 	}
+	if err = rhmibackupcontroller.New(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RHMIBackup")
+		os.Exit(1)
+	}
+	if err = rhmirestorecontroller.New(mgr).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RHMIRestore")
+		os.Exit(1)
+	}
 	if err = namespacecontroller.New(mgr).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Namespace")
 		os.Exit(1)
@@ -133,6 +154,10 @@ func main() {
 	}
 	// +kubebuilder:scaffold:builder
 
+	if err := setupRateLimitUsage(mgr, rateLimitUsageAddr, watchNamespace); err != nil {
+		setupLog.Error(err, "Error setting up rate limit usage server")
+	}
+
 	if err := setupWebhooks(mgr); err != nil {
 		setupLog.Error(err, "Error setting up webhook server")
 	}
@@ -144,6 +169,24 @@ func main() {
 	}
 }
 
+func setupRateLimitUsage(mgr ctrl.Manager, addr, watchNamespace string) error {
+	if addr == "" {
+		return nil
+	}
+
+	// The rate limit config and redis secret are in the marin3r namespace,
+	// outside of the cache of the manager
+	client, err := k8sclient.New(mgr.GetConfig(), k8sclient.Options{
+		Scheme: mgr.GetScheme(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return mgr.Add(marin3r.NewRateLimitUsageServer(addr, client, watchNamespace,
+		l.NewLoggerWithContext(l.Fields{l.ComponentLogContext: "rate_limit_usage"})))
+}
+
 func setupWebhooks(mgr ctrl.Manager) error {
 	rhmiConfigRegister, err := webhooks.WebhookRegisterFor(&rhmiv1alpha1.RHMIConfig{})
 	if err != nil {
@@ -160,6 +203,21 @@ func setupWebhooks(mgr ctrl.Manager) error {
 			NamespacedScope(),
 	})
 
+	quotaProfileRegister, err := webhooks.WebhookRegisterFor(&rhmiv1alpha1.QuotaProfile{})
+	if err != nil {
+		return err
+	}
+
+	webhooks.Config.AddWebhook(webhooks.IntegreatlyWebhook{
+		Name:     "quotaprofile",
+		Register: quotaProfileRegister,
+		Rule: webhooks.NewRule().
+			OneResource("integreatly.org", "v1alpha1", "quotaprofiles").
+			ForCreate().
+			ForUpdate().
+			NamespacedScope(),
+	})
+
 	webhooks.Config.AddWebhook(webhooks.IntegreatlyWebhook{
 		Name: "rhmiconfig-mutate",
 		Rule: webhooks.NewRule().
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -89,6 +90,10 @@ type ConfigReadable interface {
 }
 
 type Manager struct {
+	// mu guards cfgmap, as the products of a stage are reconciled
+	// concurrently
+	mu sync.Mutex
+
 	Client       k8sclient.Client
 	Namespace    string
 	cfgmap       *corev1.ConfigMap
@@ -303,6 +308,9 @@ func (m *Manager) ReadMarin3r() (*Marin3r, error) {
 }
 
 func (m *Manager) WriteConfig(config ConfigReadable) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	stringConfig, err := yaml.Marshal(config.Read())
 	err = m.Client.Get(m.context, k8sclient.ObjectKey{Name: m.cfgmap.Name, Namespace: m.Namespace}, m.cfgmap)
 	if errors.IsNotFound(err) {
@@ -317,7 +325,10 @@ func (m *Manager) WriteConfig(config ConfigReadable) error {
 }
 
 func (m *Manager) readConfigForProduct(product integreatlyv1alpha1.ProductName) (ProductConfig, error) {
+	m.mu.Lock()
 	config := m.cfgmap.Data[string(product)]
+	m.mu.Unlock()
+
 	decoder := yaml.NewDecoder(strings.NewReader(config))
 	retConfig := ProductConfig{}
 	if config == "" {
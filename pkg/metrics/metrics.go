@@ -113,6 +113,28 @@ var (
 			"toQuota",
 		},
 	)
+
+	BackupVerification = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rhmi_backup_verification",
+			Help: "Result of the last verification of the backups of a component, 1 if the restored backup passed a sanity check with a minimum",
+		},
+		[]string{
+			"component",
+			"type",
+		},
+	)
+
+	BackupVerificationTimestamp = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "rhmi_backup_verification_timestamp_seconds",
+			Help: "Time of the last verification of the backups of a component",
+		},
+		[]string{
+			"component",
+			"type",
+		},
+	)
 )
 
 // SetRHMIInfo exposes rhmi info metrics with labels from the installation CR
@@ -162,3 +184,12 @@ func SetQuota(stage string, quota string, toQuota string) {
 	Quota.Reset()
 	Quota.WithLabelValues(stage, quota, toQuota).Set(float64(1))
 }
+
+func SetBackupVerification(component, backupType string, verified bool) {
+	result := float64(0)
+	if verified {
+		result = 1
+	}
+	BackupVerification.WithLabelValues(component, backupType).Set(result)
+	BackupVerificationTimestamp.WithLabelValues(component, backupType).SetToCurrentTime()
+}
@@ -89,7 +89,7 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+func preflightObject(ns string) runtime.Object {
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "api-server",
@@ -98,8 +98,8 @@ func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
 	}
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
-	product := installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductAMQOnline]
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+	product := *installation.GetProductStatusObject(integreatlyv1alpha1.ProductAMQOnline)
 	return version.VerifyProductAndOperatorVersion(
 		product,
 		string(integreatlyv1alpha1.VersionAMQOnline),
@@ -114,15 +114,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	productNamespace := r.Config.GetNamespace()
 
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, r.Config.GetNamespace(), r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -216,6 +208,21 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the AMQ Online namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, r.Config.GetNamespace(), r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+	phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 // CreateResource Creates a generic kubernetes resource from a template
 func (r *Reconciler) createResource(ctx context.Context, resourceName string, serverClient k8sclient.Client) (runtime.Object, error) {
 	if r.extraParams == nil {
@@ -241,7 +248,9 @@ func (r *Reconciler) createResource(ctx context.Context, resourceName string, se
 	return resource, nil
 }
 
-func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
+// PreUpgradeBackupExecutor returns the backup of the enmasse postgres and
+// persistent volumes, taken before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
 	return backup.NewConcurrentBackupExecutor(
 		backup.NewCronJobBackupExecutor(
 			"enmasse-postgres-backup",
@@ -625,7 +634,7 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, serverClient k8s
 		ctx,
 		target,
 		[]string{productNamespace},
-		r.preUpgradeBackupExecutor(),
+		r.PreUpgradeBackupExecutor(),
 		serverClient,
 		catalogSourceReconciler,
 		r.log,
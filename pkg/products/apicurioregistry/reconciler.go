@@ -79,9 +79,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-// GetPreflightObject returns an object that will be checked in the preflight checks in the main
+// preflightObject returns an object that will be checked in the preflight checks in the main
 // Installation controller to ensure there isn't a conflicting Camel K installation.
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+func preflightObject(ns string) runtime.Object {
 	return &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "apicurio-registry-operator",
@@ -91,9 +91,9 @@ func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
 }
 
 // VerifyVersion verifies the product and operator versions are correct
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.SolutionExplorerStage].Products[integreatlyv1alpha1.ProductApicurioRegistry],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductApicurioRegistry),
 		string(integreatlyv1alpha1.VersionApicurioRegistry),
 		string(integreatlyv1alpha1.OperatorVersionApicurioRegistry),
 	)
@@ -104,15 +104,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	productNamespace := r.Config.GetNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, client, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		phase, err := resources.RemoveNamespace(ctx, installation, client, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-		phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, client)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -171,6 +163,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Apicurio Registry namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	phase, err := resources.RemoveNamespace(ctx, installation, client, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+	phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileStorage(ctx context.Context, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	amqStreams, err := r.ConfigManager.ReadAMQStreams()
 	if err != nil {
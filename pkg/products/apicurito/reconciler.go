@@ -34,7 +34,6 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -92,13 +91,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductApicurito],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductApicurito),
 		string(integreatlyv1alpha1.VersionApicurito),
 		string(integreatlyv1alpha1.OperatorVersionApicurito),
 	)
@@ -108,30 +103,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	productNamespace := r.Config.GetNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-
-		// Check if namespace is still present before trying to delete it resources
-		_, err := resources.GetNS(ctx, productNamespace, serverClient)
-		if !k8serr.IsNotFound(err) {
-			phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-		_, err = resources.GetNS(ctx, operatorNamespace, serverClient)
-		if !k8serr.IsNotFound(err) {
-			phase, err := resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-
-		//if both namespaces are deleted, return complete
-		_, operatorNSErr := resources.GetNS(ctx, operatorNamespace, serverClient)
-		_, nsErr := resources.GetNS(ctx, productNamespace, serverClient)
-		if k8serr.IsNotFound(operatorNSErr) && k8serr.IsNotFound(nsErr) {
-			return integreatlyv1alpha1.PhaseCompleted, nil
-		}
-		return integreatlyv1alpha1.PhaseInProgress, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -207,6 +179,36 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Apicurito namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	// Check if namespace is still present before trying to delete it resources
+	_, err := resources.GetNS(ctx, productNamespace, serverClient)
+	if !k8serr.IsNotFound(err) {
+		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+	_, err = resources.GetNS(ctx, operatorNamespace, serverClient)
+	if !k8serr.IsNotFound(err) {
+		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+
+	//if both namespaces are deleted, return complete
+	_, operatorNSErr := resources.GetNS(ctx, operatorNamespace, serverClient)
+	_, nsErr := resources.GetNS(ctx, productNamespace, serverClient)
+	if k8serr.IsNotFound(operatorNSErr) && k8serr.IsNotFound(nsErr) {
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+	return integreatlyv1alpha1.PhaseInProgress, nil
+}
+
 func (r *Reconciler) reconcileComponents(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 
 	r.log.Info("Reconciling Apicurito components")
@@ -0,0 +1,27 @@
+package products
+
+// The products shipped with the operator register themselves with the
+// registry from their own packages, they are imported here so that their
+// init functions run whenever the reconcilers are built. An add-on product
+// registers itself the same way, from an init function of its package, and
+// must be imported by the operator, for example with a blank import here
+import (
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/amqonline"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/amqstreams"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/apicurioregistry"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/apicurito"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/cloudresources"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/codeready"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/datasync"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/fuse"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/fuseonopenshift"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/grafana"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/marin3r"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/monitoring"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/monitoringspec"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/rhsso"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/rhssouser"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/solutionexplorer"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/threescale"
+	_ "github.com/integr8ly/integreatly-operator/pkg/products/ups"
+)
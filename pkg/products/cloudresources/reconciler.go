@@ -34,7 +34,6 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -91,12 +90,8 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
-	product := installation.Status.Stages[integreatlyv1alpha1.CloudResourcesStage].Products[integreatlyv1alpha1.ProductCloudResources]
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+	product := *installation.GetProductStatusObject(integreatlyv1alpha1.ProductCloudResources)
 	return version.VerifyProductAndOperatorVersion(
 		product,
 		string(integreatlyv1alpha1.VersionCloudResources),
@@ -108,34 +103,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 
 	phase, err := r.ReconcileFinalizer(ctx, client, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		// Check if namespace is still present before trying to delete it resources
-		_, err := resources.GetNS(ctx, operatorNamespace, client)
-		if !k8serr.IsNotFound(err) {
-
-			phase, err := r.removeSnapshots(ctx, installation, client)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-
-			// overrides cro default deletion strategy to delete resources snapshots
-			phase, err = r.createDeletionStrategy(ctx, installation, client)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-
-			// ensure resources are cleaned up before deleting the namespace
-			phase, err = r.cleanupResources(ctx, installation, client)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-
-			// remove the namespace
-			phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, client)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -193,6 +161,41 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the snapshots and the resources provisioned through the
+// cloud resource operator, then the operator namespace
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+
+	// Check if namespace is still present before trying to delete it resources
+	_, err := resources.GetNS(ctx, operatorNamespace, client)
+	if !k8serr.IsNotFound(err) {
+
+		phase, err := r.removeSnapshots(ctx, installation, client)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+
+		// overrides cro default deletion strategy to delete resources snapshots
+		phase, err = r.createDeletionStrategy(ctx, installation, client)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+
+		// ensure resources are cleaned up before deleting the namespace
+		phase, err = r.cleanupResources(ctx, installation, client)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+
+		// remove the namespace
+		phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) removeSnapshots(ctx context.Context, installation *integreatlyv1alpha1.RHMI, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 
 	r.log.Info("Removing postgres and redis snapshots")
@@ -0,0 +1,15 @@
+package cloudresources
+
+import (
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+)
+
+func init() {
+	registry.Register(integreatlyv1alpha1.ProductCloudResources, registry.Registration{
+		Factory: func(opts *registry.ReconcilerOptions) (registry.Interface, error) {
+			return NewReconciler(opts.ConfigManager, opts.Installation, opts.MPM, opts.Recorder, opts.Log, opts.ProductDeclaration)
+		},
+		VerifyVersion: verifyVersion,
+	})
+}
@@ -88,7 +88,7 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+func preflightObject(ns string) runtime.Object {
 	return &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "codeready",
@@ -97,9 +97,9 @@ func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
 	}
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductCodeReadyWorkspaces],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductCodeReadyWorkspaces),
 		string(integreatlyv1alpha1.VersionCodeReadyWorkspaces),
 		string(integreatlyv1alpha1.OperatorVersionCodeReadyWorkspaces),
 	)
@@ -110,17 +110,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	productNamespace := r.Config.GetNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, r.installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		phase, err := resources.RemoveNamespace(ctx, r.installation, serverClient, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, r.installation, serverClient)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -190,6 +180,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the CodeReady Workspaces namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileExternalDatasources(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	r.log.Info("Reconciling external datastore")
 	ns := r.installation.Namespace
@@ -479,21 +487,19 @@ func (r *Reconciler) createCheCluster(ctx context.Context, kcCfg *config.RHSSO,
 	return cheCluster, nil
 }
 
-func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
+// PreUpgradeBackupExecutor returns the backup of the codeready persistent
+// volumes and postgres, taken before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
 	pvBackup := backup.NewCronJobBackupExecutor(
 		"codeready-pv-backup",
 		r.Config.GetNamespace(),
 		"codeready-preupgrade-pv-backup",
 	)
 
-	if r.installation.Spec.UseClusterStorage != "false" {
-		return pvBackup
-	}
-
 	return backup.NewConcurrentBackupExecutor(
 		pvBackup,
-		backup.NewAWSBackupExecutor(
-			r.installation.Namespace,
+		backup.NewInstallationBackupExecutor(
+			r.installation,
 			"codeready-postgres-rhmi",
 			backup.PostgresSnapshotType,
 		),
@@ -634,7 +640,7 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, serverClient k8s
 		ctx,
 		target,
 		[]string{productNamespace},
-		r.preUpgradeBackupExecutor(),
+		r.PreUpgradeBackupExecutor(),
 		serverClient,
 		catalogSourceReconciler,
 		r.log,
@@ -0,0 +1,17 @@
+package codeready
+
+import (
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+)
+
+func init() {
+	registry.Register(integreatlyv1alpha1.ProductCodeReadyWorkspaces, registry.Registration{
+		Dependencies: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
+		Factory: func(opts *registry.ReconcilerOptions) (registry.Interface, error) {
+			return NewReconciler(opts.ConfigManager, opts.Installation, opts.MPM, opts.Recorder, opts.Log, opts.ProductDeclaration)
+		},
+		PreflightObject: preflightObject,
+		VerifyVersion:   verifyVersion,
+	})
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	"github.com/integr8ly/integreatly-operator/version"
 	"github.com/operator-framework/operator-lifecycle-manager/pkg/lib/ownerutil"
-	"k8s.io/apimachinery/pkg/runtime"
+
+	templatev1 "github.com/openshift/api/template/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
@@ -51,13 +53,9 @@ type Reconciler struct {
 	installation  *integreatlyv1alpha1.RHMI
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductDataSync],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductDataSync),
 		string(integreatlyv1alpha1.VersionDataSync),
 		"",
 	)
@@ -105,6 +103,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the data sync templates. They are found through their
+// owner reference to the installation, as the templates are downloaded
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	templates := &templatev1.TemplateList{}
+	if err := serverClient.List(ctx, templates, k8sclient.InNamespace(r.Config.GetNamespace())); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list templates in %s: %w", r.Config.GetNamespace(), err)
+	}
+
+	for i := range templates.Items {
+		template := &templates.Items[i]
+		if !ownerutil.IsOwnedBy(template, installation) {
+			continue
+		}
+		r.log.Infof("Removing datasync template", l.Fields{"template": template.Name})
+		if err := serverClient.Delete(ctx, template); err != nil && !k8serr.IsNotFound(err) {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to remove datasync template %s: %w", template.Name, err)
+		}
+	}
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileTemplates(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	for _, templateFn := range datasyncTemplates {
 		fileUrl := templatesBaseURL + string(r.Config.GetProductVersion()) + openshiftTemplatesFolder + templateFn
@@ -150,3 +150,42 @@ func TestDataSync(t *testing.T) {
 func getLogger() l.Logger {
 	return l.NewLoggerWithContext(l.Fields{l.ProductLogContext: integreatlyv1alpha1.ProductDataSync})
 }
+
+func TestDataSyncUninstall(t *testing.T) {
+	scheme := scheme.Scheme
+	if err := integreatlyv1alpha1.AddToSchemes.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to initialize scheme: %s", err)
+	}
+
+	installation := &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: OperatorNamespace, UID: "rhmi-uid"},
+	}
+	owned := &templatev1.Template{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "datasync-http",
+			Namespace:       datasyncNs,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "RHMI", Name: installation.Name, UID: installation.UID}},
+		},
+	}
+	other := &templatev1.Template{
+		ObjectMeta: metav1.ObjectMeta{Name: "cakephp-mysql-example", Namespace: datasyncNs},
+	}
+	client := fakeclient.NewFakeClientWithScheme(scheme, owned, other)
+
+	reconciler, err := NewReconciler(getFakeConfig(), installation, &marketplace.MarketplaceInterfaceMock{}, setupRecorder(), getLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	phase, err := reconciler.Uninstall(context.TODO(), installation, client)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		t.Fatalf("expected the uninstall to complete, got %s, %v", phase, err)
+	}
+
+	templates := &templatev1.TemplateList{}
+	if err := client.List(context.TODO(), templates, k8sclient.InNamespace(datasyncNs)); err != nil {
+		t.Fatal(err)
+	}
+	if len(templates.Items) != 1 || templates.Items[0].Name != other.Name {
+		t.Fatalf("expected only the datasync templates to be removed, got %v", templates.Items)
+	}
+}
@@ -0,0 +1,93 @@
+package products
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+)
+
+// GetDependencies returns the products the product depends on
+func GetDependencies(product integreatlyv1alpha1.ProductName) []integreatlyv1alpha1.ProductName {
+	registration, ok := registry.Get(product)
+	if !ok {
+		return nil
+	}
+	return registration.Dependencies
+}
+
+// ValidateDependencies checks the dependency graph of the registered
+// products. It returns an error if a product depends on a product that isn't
+// registered, or if the dependencies contain a cycle
+func ValidateDependencies() error {
+	return validateDependencies(registry.Registrations())
+}
+
+func validateDependencies(registrations map[integreatlyv1alpha1.ProductName]registry.Registration) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[integreatlyv1alpha1.ProductName]int{}
+	var path []integreatlyv1alpha1.ProductName
+
+	var visit func(product integreatlyv1alpha1.ProductName) error
+	visit = func(product integreatlyv1alpha1.ProductName) error {
+		switch state[product] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle found: %s", formatCycle(path, product))
+		}
+
+		state[product] = visiting
+		path = append(path, product)
+		for _, dependency := range registrations[product].Dependencies {
+			if _, ok := registrations[dependency]; !ok {
+				return fmt.Errorf("product %s depends on unknown product %s", product, dependency)
+			}
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[product] = visited
+		return nil
+	}
+
+	// Visit the products in order so the same cycle is reported every time
+	names := make([]integreatlyv1alpha1.ProductName, 0, len(registrations))
+	for name := range registrations {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatCycle returns the part of the path that starts and ends with the
+// given product, e.g. "a -> b -> a"
+func formatCycle(path []integreatlyv1alpha1.ProductName, product integreatlyv1alpha1.ProductName) string {
+	start := 0
+	for i, p := range path {
+		if p == product {
+			start = i
+			break
+		}
+	}
+
+	names := []string{}
+	for _, p := range path[start:] {
+		names = append(names, string(p))
+	}
+	names = append(names, string(product))
+	return strings.Join(names, " -> ")
+}
@@ -0,0 +1,83 @@
+package products
+
+import (
+	"strings"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	dependsOn := func(products ...integreatlyv1alpha1.ProductName) registry.Registration {
+		return registry.Registration{Dependencies: products}
+	}
+
+	cases := []struct {
+		Name          string
+		Registrations map[integreatlyv1alpha1.ProductName]registry.Registration
+		ExpectedError string
+	}{
+		{
+			Name: "test valid dependency graph",
+			Registrations: map[integreatlyv1alpha1.ProductName]registry.Registration{
+				"a": dependsOn(),
+				"b": dependsOn("a"),
+				"c": dependsOn("a", "b"),
+			},
+		},
+		{
+			Name: "test dependency on unknown product",
+			Registrations: map[integreatlyv1alpha1.ProductName]registry.Registration{
+				"a": dependsOn("missing"),
+			},
+			ExpectedError: "product a depends on unknown product missing",
+		},
+		{
+			Name: "test product depending on itself",
+			Registrations: map[integreatlyv1alpha1.ProductName]registry.Registration{
+				"a": dependsOn("a"),
+			},
+			ExpectedError: "dependency cycle found: a -> a",
+		},
+		{
+			Name: "test dependency cycle",
+			Registrations: map[integreatlyv1alpha1.ProductName]registry.Registration{
+				"a": dependsOn(),
+				"b": dependsOn("a", "d"),
+				"c": dependsOn("b"),
+				"d": dependsOn("c"),
+			},
+			ExpectedError: "dependency cycle found: b -> d -> c -> b",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := validateDependencies(tc.Registrations)
+			if tc.ExpectedError == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.ExpectedError) {
+				t.Fatalf("expected error %q, got %v", tc.ExpectedError, err)
+			}
+		})
+	}
+}
+
+func TestBuiltinDependencies(t *testing.T) {
+	if err := ValidateDependencies(); err != nil {
+		t.Fatalf("unexpected error validating the built-in dependencies: %v", err)
+	}
+
+	dependencies := GetDependencies(integreatlyv1alpha1.Product3Scale)
+	if len(dependencies) != 1 || dependencies[0] != integreatlyv1alpha1.ProductRHSSO {
+		t.Fatalf("expected 3scale to depend on rhsso, got %v", dependencies)
+	}
+	if dependencies := GetDependencies(integreatlyv1alpha1.ProductCloudResources); len(dependencies) != 0 {
+		t.Fatalf("expected cloud resources to have no dependencies, got %v", dependencies)
+	}
+}
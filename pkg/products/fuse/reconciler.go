@@ -100,9 +100,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-// GetPreflightObject returns an object that will be checked in the preflight checks in the main
+// preflightObject returns an object that will be checked in the preflight checks in the main
 // Installation controller to ensure there isn't a conflicting Syndesis already installed.
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+func preflightObject(ns string) runtime.Object {
 	return &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "syndesis-server",
@@ -111,9 +111,9 @@ func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
 	}
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductFuse],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductFuse),
 		string(integreatlyv1alpha1.VersionFuseOnline),
 		string(integreatlyv1alpha1.OperatorVersionFuse),
 	)
@@ -125,17 +125,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	productNamespace := r.Config.GetNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -212,6 +202,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Fuse namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 // CreateResource Creates a generic kubernetes resource from a template
 func (r *Reconciler) createResource(ctx context.Context, resourceName string, serverClient k8sclient.Client) (runtime.Object, error) {
 	if r.extraParams == nil {
@@ -453,14 +461,18 @@ func (r *Reconciler) reconcileBlackboxTargets(ctx context.Context, installation
 
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
+
+// PreUpgradeBackupExecutor returns the backup of the fuse postgres, taken
+// before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
+	return preUpgradeBackupExecutor(r.installation)
+}
+
 func preUpgradeBackupExecutor(rhmi *integreatlyv1alpha1.RHMI) backup.BackupExecutor {
 	pgName := fmt.Sprintf("%s%s", constants.FusePostgresPrefix, rhmi.Name)
-	if rhmi.Spec.UseClusterStorage != "false" {
-		return backup.NewNoopBackupExecutor()
-	}
 
-	return backup.NewAWSBackupExecutor(
-		rhmi.Namespace,
+	return backup.NewInstallationBackupExecutor(
+		rhmi,
 		pgName,
 		backup.PostgresSnapshotType,
 	)
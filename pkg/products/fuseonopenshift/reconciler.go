@@ -107,10 +107,6 @@ type Reconciler struct {
 	baseURL       string
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
 func NewReconciler(configManager config.ConfigReadWriter, installation *integreatlyv1alpha1.RHMI, mpm marketplace.MarketplaceInterface, recorder record.EventRecorder, httpClient *http.Client, baseURL string, logger l.Logger) (*Reconciler, error) {
 	config, err := configManager.ReadFuseOnOpenshift()
 	if err != nil {
@@ -146,9 +142,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductFuseOnOpenshift],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductFuseOnOpenshift),
 		string(integreatlyv1alpha1.VersionFuseOnOpenshift),
 		string(integreatlyv1alpha1.OperatorVersionFuse),
 	)
@@ -181,6 +177,45 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Fuse on OpenShift image streams and templates, and
+// hands them back to the cluster samples operator. They are read from the
+// templates config map, which is removed last
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	cfgMap, err := r.getTemplatesConfigMap(ctx, serverClient)
+	if k8errors.IsNotFound(err) {
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get configmap %s from %s namespace: %w", cfgMap.Name, cfgMap.Namespace, err)
+	}
+
+	imageStreams, err := r.imageStreams(cfgMap)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	templates, err := r.templates(cfgMap)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	for _, objects := range []map[string]runtime.Object{imageStreams, templates} {
+		for name, obj := range objects {
+			if err := serverClient.Delete(ctx, obj); err != nil && !k8errors.IsNotFound(err) {
+				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to remove %s: %w", name, err)
+			}
+		}
+	}
+
+	if err := r.removeFromClusterSampleCR(ctx, serverClient, r.getKeysFromMap(imageStreams), r.getKeysFromMap(templates)); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	if err := serverClient.Delete(ctx, cfgMap); err != nil && !k8errors.IsNotFound(err) {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to remove configmap %s: %w", cfgMap.Name, err)
+	}
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileConfigMap(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	r.log.Info("Reconciling Fuse on OpenShift templates config map")
 	cfgMap := &corev1.ConfigMap{
@@ -255,11 +290,63 @@ func (r *Reconciler) reconcileImageStreams(ctx context.Context, serverClient k8s
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get configmap %s from %s namespace: %w", cfgMap.Name, cfgMap.Data, err)
 	}
 
+	imageStreams, err := r.imageStreams(cfgMap)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	imageStreamNames := r.getKeysFromMap(imageStreams)
+
+	// Update the sample cluster sample operator CR to skip the Fuse on OpenShift image streams
+	if err := r.updateClusterSampleCR(ctx, serverClient, "SkippedImagestreams", imageStreamNames); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to update SkippedImagestreams in cluster sample custom resource: %w", err)
+	}
+
+	for isName, isObj := range imageStreams {
+		if err := r.createResourceIfNotExist(ctx, serverClient, isObj); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to create image stream %s: %w", isName, err)
+		}
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+func (r *Reconciler) reconcileTemplates(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
+	r.log.Info("Reconciling Fuse on OpenShift templates")
+	cfgMap, err := r.getTemplatesConfigMap(ctx, serverClient)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get configmap %s from %s namespace: %w", cfgMap.Name, cfgMap.Data, err)
+	}
+
+	templates, err := r.templates(cfgMap)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	templateNames := r.getKeysFromMap(templates)
+
+	// Update sample cluster operator CR to skip Fuse on OpenShift quickstart templates
+	if err := r.updateClusterSampleCR(ctx, serverClient, "SkippedTemplates", templateNames); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to update SkippedTemplates in cluster sample custom resource: %w", err)
+	}
+
+	for name, obj := range templates {
+		if err := r.createResourceIfNotExist(ctx, serverClient, obj); err != nil {
+			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to create image stream %s: %w", name, err)
+		}
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
+// imageStreams returns the Fuse on OpenShift image streams in the templates
+// config map, by name
+func (r *Reconciler) imageStreams(cfgMap *corev1.ConfigMap) (map[string]runtime.Object, error) {
 	content := []byte(cfgMap.Data[imageStreamFileName])
 
 	var fileContent map[string]interface{}
 	if err := json.Unmarshal(content, &fileContent); err != nil {
-		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to unmarshal contents of %s: %w", imageStreamFileName, err)
+		return nil, fmt.Errorf("failed to unmarshal contents of %s: %w", imageStreamFileName, err)
 	}
 
 	// The content of the imagestream file is an object of kind List
@@ -269,42 +356,30 @@ func (r *Reconciler) reconcileImageStreams(ctx context.Context, serverClient k8s
 	for _, is := range isList {
 		jsonData, err := json.Marshal(is)
 		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to marshal data %s: %w", imageStreamFileName, err)
+			return nil, fmt.Errorf("failed to marshal data %s: %w", imageStreamFileName, err)
 		}
 
 		imageStreamRuntimeObj, err := resources.LoadKubernetesResource(jsonData, r.Config.GetNamespace())
 		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to load kubernetes imagestream resource: %w", err)
+			return nil, fmt.Errorf("failed to load kubernetes imagestream resource: %w", err)
 		}
 
 		// Get unstructured of image stream so we can retrieve the image stream name
 		imageStreamUnstructured, err := resources.UnstructuredFromRuntimeObject(imageStreamRuntimeObj)
 		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to parse runtime object to unstructured for imagestream: %w", err)
+			return nil, fmt.Errorf("failed to parse runtime object to unstructured for imagestream: %w", err)
 		}
 
 		imageStreamName := imageStreamUnstructured.GetName()
 		imageStreams[imageStreamName] = imageStreamRuntimeObj
 	}
 
-	imageStreamNames := r.getKeysFromMap(imageStreams)
-
-	// Update the sample cluster sample operator CR to skip the Fuse on OpenShift image streams
-	if err := r.updateClusterSampleCR(ctx, serverClient, "SkippedImagestreams", imageStreamNames); err != nil {
-		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to update SkippedImagestreams in cluster sample custom resource: %w", err)
-	}
-
-	for isName, isObj := range imageStreams {
-		if err := r.createResourceIfNotExist(ctx, serverClient, isObj); err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to create image stream %s: %w", isName, err)
-		}
-	}
-
-	return integreatlyv1alpha1.PhaseCompleted, nil
+	return imageStreams, nil
 }
 
-func (r *Reconciler) reconcileTemplates(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
-	r.log.Info("Reconciling Fuse on OpenShift templates")
+// templates returns the Fuse on OpenShift templates in the templates config
+// map, by name
+func (r *Reconciler) templates(cfgMap *corev1.ConfigMap) (map[string]runtime.Object, error) {
 	var templateFiles []string
 	templates := make(map[string]runtime.Object)
 
@@ -313,48 +388,31 @@ func (r *Reconciler) reconcileTemplates(ctx context.Context, serverClient k8scli
 	templateFiles = append(templateFiles, quickstartSpringBoot2Templates...)
 
 	for _, fileName := range templateFiles {
-		cfgMap, err := r.getTemplatesConfigMap(ctx, serverClient)
-		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get configmap %s from %s namespace: %w", cfgMap.Name, cfgMap.Data, err)
-		}
-
 		content := []byte(cfgMap.Data[fileName])
 
+		var err error
 		if filepath.Ext(fileName) == ".yml" || filepath.Ext(fileName) == ".yaml" {
 			content, err = yaml.ToJSON(content)
 			if err != nil {
-				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to convert yaml to json %s: %w", fileName, err)
+				return nil, fmt.Errorf("failed to convert yaml to json %s: %w", fileName, err)
 			}
 		}
 
 		templateRuntimeObj, err := resources.LoadKubernetesResource(content, r.Config.GetNamespace())
 		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to load resource %s: %w", fileName, err)
+			return nil, fmt.Errorf("failed to load resource %s: %w", fileName, err)
 		}
 
 		templateUnstructured, err := resources.UnstructuredFromRuntimeObject(templateRuntimeObj)
 		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to parse object: %w", err)
+			return nil, fmt.Errorf("failed to parse object: %w", err)
 		}
 
 		templateName := templateUnstructured.GetName()
 		templates[templateName] = templateRuntimeObj
 	}
 
-	templateNames := r.getKeysFromMap(templates)
-
-	// Update sample cluster operator CR to skip Fuse on OpenShift quickstart templates
-	if err := r.updateClusterSampleCR(ctx, serverClient, "SkippedTemplates", templateNames); err != nil {
-		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to update SkippedTemplates in cluster sample custom resource: %w", err)
-	}
-
-	for name, obj := range templates {
-		if err := r.createResourceIfNotExist(ctx, serverClient, obj); err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to create image stream %s: %w", name, err)
-		}
-	}
-
-	return integreatlyv1alpha1.PhaseCompleted, nil
+	return templates, nil
 }
 
 func (r *Reconciler) getTemplatesConfigMap(ctx context.Context, serverClient k8sclient.Client) (*corev1.ConfigMap, error) {
@@ -458,6 +516,35 @@ func (r *Reconciler) updateClusterSampleCR(ctx context.Context, serverClient k8s
 	return nil
 }
 
+// removeFromClusterSampleCR stops skipping the image streams and templates
+// in the cluster sample operator CR, so that it manages them again
+func (r *Reconciler) removeFromClusterSampleCR(ctx context.Context, serverClient k8sclient.Client, imageStreams, templates []string) error {
+	clusterSampleCR := &samplesv1.Config{}
+	if err := serverClient.Get(ctx, k8sclient.ObjectKey{Name: "cluster"}, clusterSampleCR); err != nil {
+		if k8errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get cluster sample custom resource: %w", err)
+	}
+
+	remove := func(list, values []string) []string {
+		kept := []string{}
+		for _, v := range list {
+			if !r.contains(values, v) {
+				kept = append(kept, v)
+			}
+		}
+		return kept
+	}
+	clusterSampleCR.Spec.SkippedImagestreams = remove(clusterSampleCR.Spec.SkippedImagestreams, imageStreams)
+	clusterSampleCR.Spec.SkippedTemplates = remove(clusterSampleCR.Spec.SkippedTemplates, templates)
+
+	if err := serverClient.Update(ctx, clusterSampleCR); err != nil {
+		return fmt.Errorf("failed to update cluster sample custom resource: %w", err)
+	}
+	return nil
+}
+
 func (r *Reconciler) getKeysFromMap(mapObj map[string]runtime.Object) []string {
 	var keys []string
 
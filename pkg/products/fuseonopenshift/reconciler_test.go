@@ -15,6 +15,7 @@ import (
 
 	moqclient "github.com/integr8ly/integreatly-operator/pkg/client"
 	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -309,6 +310,59 @@ func TestFuseOnOpenShift(t *testing.T) {
 	}
 }
 
+func TestFuseOnOpenShiftUninstall(t *testing.T) {
+	scheme := scheme.Scheme
+	if err := integreatlyv1alpha1.AddToSchemes.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to initialize scheme: %s", err)
+	}
+
+	sampleClusterConfig := &samplesv1.Config{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: samplesv1.ConfigSpec{
+			SkippedImagestreams: []string{"other-imagestream"},
+		},
+	}
+	server := getFakeServer(t)
+	defer server.Close()
+
+	installation := &integreatlyv1alpha1.RHMI{}
+	client := fakeclient.NewFakeClient(sampleClusterConfig)
+	reconciler, err := NewReconciler(getFakeConfig(), installation, &marketplace.MarketplaceInterfaceMock{}, setupRecorder(), server.Client(), server.URL+"/", getLogger())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status, err := reconciler.Reconcile(context.TODO(), installation, &integreatlyv1alpha1.RHMIProductStatus{}, client, &quota.ProductConfigMock{}); err != nil || status != integreatlyv1alpha1.PhaseCompleted {
+		t.Fatalf("expected the reconcile to complete, got %s, %v", status, err)
+	}
+	imageStream := &imagev1.ImageStream{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: "fis-java-openshift", Namespace: fuseOnOpenshiftNs}, imageStream); err != nil {
+		t.Fatalf("expected the image stream to be created: %v", err)
+	}
+
+	status, err := reconciler.Uninstall(context.TODO(), installation, client)
+	if err != nil || status != integreatlyv1alpha1.PhaseCompleted {
+		t.Fatalf("expected the uninstall to complete, got %s, %v", status, err)
+	}
+
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: "fis-java-openshift", Namespace: fuseOnOpenshiftNs}, imageStream); !k8serr.IsNotFound(err) {
+		t.Fatalf("expected the image stream to be removed, got %v", err)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: templatesConfigMapName, Namespace: OperatorNamespace}, &corev1.ConfigMap{}); !k8serr.IsNotFound(err) {
+		t.Fatalf("expected the templates config map to be removed, got %v", err)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, sampleClusterConfig); err != nil {
+		t.Fatal(err)
+	}
+	if len(sampleClusterConfig.Spec.SkippedImagestreams) != 1 || sampleClusterConfig.Spec.SkippedImagestreams[0] != "other-imagestream" || len(sampleClusterConfig.Spec.SkippedTemplates) != 0 {
+		t.Fatalf("expected the samples operator to manage the Fuse resources again, got %+v", sampleClusterConfig.Spec)
+	}
+
+	// A second uninstall finds nothing left to remove
+	if status, err := reconciler.Uninstall(context.TODO(), installation, client); err != nil || status != integreatlyv1alpha1.PhaseCompleted {
+		t.Fatalf("expected the uninstall to complete, got %s, %v", status, err)
+	}
+}
+
 func getLogger() l.Logger {
 	return l.NewLoggerWithContext(l.Fields{l.ProductLogContext: integreatlyv1alpha1.ProductApicurioRegistry})
 }
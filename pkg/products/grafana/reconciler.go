@@ -28,7 +28,6 @@ import (
 	v1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -56,13 +55,9 @@ type Reconciler struct {
 	recorder      record.EventRecorder
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductGrafana],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductGrafana),
 		string(integreatlyv1alpha1.VersionGrafana),
 		string(integreatlyv1alpha1.OperatorVersionGrafana),
 	)
@@ -104,21 +99,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	productNamespace := r.Config.GetNamespace()
 
 	phase, err := r.ReconcileFinalizer(ctx, client, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		phase, err := resources.RemoveNamespace(ctx, installation, client, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		if err := r.deleteConsoleLink(ctx, client); err != nil {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, client)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -192,6 +173,28 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Grafana namespaces and its console link
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	phase, err := resources.RemoveNamespace(ctx, installation, client, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	if err := r.deleteConsoleLink(ctx, client); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileSecrets(ctx context.Context, client k8sclient.Client, installation *integreatlyv1alpha1.RHMI, cr *grafanav1alpha1.Grafana) (integreatlyv1alpha1.StatusPhase, error) {
 	secret := &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -474,14 +477,16 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, serverClient k8s
 		ctx,
 		target,
 		[]string{productNamespace},
-		r.preUpgradeBackupExecutor(),
+		r.PreUpgradeBackupExecutor(),
 		serverClient,
 		catalogSourceReconciler,
 		r.log,
 	)
 }
 
-func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
+// PreUpgradeBackupExecutor returns the backup taken before upgrades and by
+// RHMIBackup. Grafana has no data to back up
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
 	return backup.NewNoopBackupExecutor()
 }
 
@@ -524,7 +529,7 @@ func (r *Reconciler) reconcileHost(ctx context.Context, serverClient k8sclient.C
 
 func GetGrafanaConsoleURL(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (string, error) {
 
-	grafanaConsoleURL := installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductGrafana].Host
+	grafanaConsoleURL := installation.GetProductStatusObject(integreatlyv1alpha1.ProductGrafana).Host
 	if grafanaConsoleURL != "" {
 		return grafanaConsoleURL, nil
 	}
@@ -10,6 +10,7 @@ import (
 	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
 
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -20,14 +21,17 @@ var (
 )
 
 func (r *Reconciler) newAlertsReconciler(grafanaDashboardURL string) (resources.AlertReconciler, error) {
-
-	requestsAllowedPerSecond, err := r.getRateLimitInSeconds(r.RateLimitConfig.Unit, r.RateLimitConfig.RequestsPerUnit)
-	if err != nil {
-		return nil, err
-	}
-	alerts, err := mapAlertsConfiguration(r.log, r.Config.GetNamespace(), r.RateLimitConfig.Unit, r.RateLimitConfig.RequestsPerUnit, requestsAllowedPerSecond, r.AlertsConfig, grafanaDashboardURL, r.installation.Spec.Type)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create alerts from configuration: %w", err)
+	alerts := []resources.AlertConfiguration{}
+	for _, window := range getAlertWindows(r.RateLimitConfig) {
+		requestsAllowedPerSecond, err := r.getRateLimitInSeconds(window.Unit, window.RequestsPerUnit)
+		if err != nil {
+			return nil, err
+		}
+		windowAlerts, err := mapAlertsConfiguration(r.log, r.Config.GetNamespace(), window, requestsAllowedPerSecond, r.AlertsConfig, grafanaDashboardURL, r.installation.Spec.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create alerts from configuration: %w", err)
+		}
+		alerts = append(alerts, windowAlerts...)
 	}
 
 	return &resources.AlertReconcilerImpl{
@@ -38,24 +42,61 @@ func (r *Reconciler) newAlertsReconciler(grafanaDashboardURL string) (resources.
 	}, nil
 }
 
+// alertWindow is a window of the rate limit the alerts are computed for
+type alertWindow struct {
+	marin3rconfig.RateLimitWindow
+	// totalRequestsMetric counts the requests limited by the window
+	totalRequestsMetric string
+	// nameSuffix and labels tell apart the alerts of the additional windows
+	nameSuffix string
+	labels     map[string]string
+}
+
+// getAlertWindows returns the window of the limit per unit, followed by the
+// additional windows of the rate limit
+func getAlertWindows(rateLimit marin3rconfig.RateLimitConfig) []alertWindow {
+	windows := make([]alertWindow, 0, len(rateLimit.Windows)+1)
+	for i, window := range rateLimit.AllWindows() {
+		if i == 0 {
+			windows = append(windows, alertWindow{
+				RateLimitWindow:     window,
+				totalRequestsMetric: totalRequestsMetric,
+			})
+			continue
+		}
+		windows = append(windows, alertWindow{
+			RateLimitWindow: window,
+			totalRequestsMetric: fmt.Sprintf("ratelimit_service_rate_limit_apicast_ratelimit_generic_key_%s_total_hits",
+				ratelimit.WindowDescriptorValue(window.Unit)),
+			nameSuffix: fmt.Sprintf("-%s", window.Unit),
+			labels:     map[string]string{"window": window.Unit},
+		})
+	}
+	return windows
+}
+
 // mapAlertsConfiguration maps each value from alertsConfig into a
-// resources.AlertConfiguration object, resulting into a list of the
-// prometheus alerts to be created
-func mapAlertsConfiguration(logger l.Logger, namespace, rateLimitUnit string, rateLimitRequestsPerUnit uint32, requestsAllowedPerSecond float64, alertsConfig map[string]*marin3rconfig.AlertConfig, grafanaDashboardURL string, installationName string) ([]resources.AlertConfiguration, error) {
+// resources.AlertConfiguration object for the window, resulting into a list
+// of the prometheus alerts to be created
+func mapAlertsConfiguration(logger l.Logger, namespace string, window alertWindow, requestsAllowedPerSecond float64, alertsConfig map[string]*marin3rconfig.AlertConfig, grafanaDashboardURL string, installationName string) ([]resources.AlertConfiguration, error) {
 	result := make([]resources.AlertConfiguration, 0, len(alertsConfig))
 
 	for alertName, alertConfig := range alertsConfig {
 		switch alertConfig.Type {
 		case marin3rconfig.AlertTypeSpike:
+			spikeRange, spikeLimit, err := spikeRangeLimit(window.RateLimitWindow)
+			if err != nil {
+				return nil, err
+			}
 			expr := fmt.Sprintf(
-				"max_over_time((increase(ratelimit_service_rate_limit_apicast_ratelimit_generic_key_slowpath_total_hits[1m]))[%s:]) > %d",
-				alertConfig.Period, rateLimitRequestsPerUnit)
+				"max_over_time((increase(%s[%s]))[%s:]) > %d",
+				window.totalRequestsMetric, spikeRange, alertConfig.Period, spikeLimit)
 			annotations := map[string]string{
-				"message":        fmt.Sprintf("hard limit of %d breached at least once in the last %s", rateLimitRequestsPerUnit, alertConfig.Period),
+				"message":        fmt.Sprintf("hard limit of %d per %s breached at least once in the last %s", window.RequestsPerUnit, window.Unit, alertConfig.Period),
 				"grafanaConsole": grafanaDashboardURL,
 			}
-			alert := mapSpikeAlert(alertConfig, alertName, namespace, expr, annotations, installationName)
-			result = append(result, alert)
+			alert := mapSpikeAlert(alertConfig, alertName+window.nameSuffix, namespace, expr, annotations, installationName)
+			result = append(result, withAlertLabels(alert, window.labels))
 		case marin3rconfig.AlertTypeThreshold:
 
 			usageFrequencyMins, err := intervalToMinutes(alertConfig.Period)
@@ -72,8 +113,8 @@ func mapAlertsConfiguration(logger l.Logger, namespace, rateLimitUnit string, ra
 				return nil, err
 			}
 
-			lowerExpr := increaseExpr(totalRequestsMetric, alertConfig.Period, ">=", requestsAllowedOverTimePeriod, &minRateValue)
-			upperExpr := increaseExpr(totalRequestsMetric, alertConfig.Period, "<=", requestsAllowedOverTimePeriod, maxRateValue)
+			lowerExpr := increaseExpr(window.totalRequestsMetric, alertConfig.Period, ">=", requestsAllowedOverTimePeriod, &minRateValue)
+			upperExpr := increaseExpr(window.totalRequestsMetric, alertConfig.Period, "<=", requestsAllowedOverTimePeriod, maxRateValue)
 
 			// Get the complete expression by ANDing the lower and the upper if the
 			// upper limit is set, if not, assign the lower one
@@ -86,13 +127,13 @@ func mapAlertsConfiguration(logger l.Logger, namespace, rateLimitUnit string, ra
 			annotations := map[string]string{
 				"message": fmt.Sprintf(
 					"Total API usage in your API Management service is between %s and %s of the allowable threshold, %d requests per %s, during the last %s",
-					alertConfig.Threshold.MinRate, upperMessage, rateLimitRequestsPerUnit, rateLimitUnit, alertConfig.Period,
+					alertConfig.Threshold.MinRate, upperMessage, window.RequestsPerUnit, window.Unit, alertConfig.Period,
 				),
 				"grafanaConsole": grafanaDashboardURL,
 			}
-			alert := mapThresholdAlert(alertConfig, alertName, namespace, expr, annotations, installationName)
+			alert := mapThresholdAlert(alertConfig, alertName+window.nameSuffix, namespace, expr, annotations, installationName)
 
-			result = append(result, alert)
+			result = append(result, withAlertLabels(alert, window.labels))
 		default:
 			logger.Infof("Unsupported Alert Type found", l.Fields{"alertName": alertName})
 		}
@@ -101,6 +142,34 @@ func mapAlertsConfiguration(logger l.Logger, namespace, rateLimitUnit string, ra
 	return result, nil
 }
 
+// spikeRangeLimit returns the range of the increase of the requests
+// compared to the limit of the window by the spike alerts, and the limit in
+// that range. The rate of the requests isn't observable below a minute, the
+// windows of a second are compared over a minute
+func spikeRangeLimit(window marin3rconfig.RateLimitWindow) (string, int, error) {
+	switch window.Unit {
+	case marin3rconfig.Second:
+		return "1m", int(window.RequestsPerUnit) * 60, nil
+	case marin3rconfig.Minute:
+		return "1m", int(window.RequestsPerUnit), nil
+	case marin3rconfig.Hour:
+		return "1h", int(window.RequestsPerUnit), nil
+	case marin3rconfig.Day:
+		return "1d", int(window.RequestsPerUnit), nil
+	default:
+		return "", 0, fmt.Errorf("unexpected rate limit unit %s, while creating 3scale api usage alerts", window.Unit)
+	}
+}
+
+func withAlertLabels(alert resources.AlertConfiguration, labels map[string]string) resources.AlertConfiguration {
+	for i := range alert.Rules {
+		for name, value := range labels {
+			alert.Rules[i].Labels[name] = value
+		}
+	}
+	return alert
+}
+
 func mapSpikeAlert(alertConfig *marin3rconfig.AlertConfig, alertName string, namespace string, expr string, annotations map[string]string, installationName string) resources.AlertConfiguration {
 	return resources.AlertConfiguration{
 		AlertName: alertName,
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+
 	corev1 "k8s.io/api/core/v1"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -23,6 +25,63 @@ const (
 type RateLimitConfig struct {
 	Unit            string `json:"unit"`
 	RequestsPerUnit uint32 `json:"requests_per_unit"`
+	// ShadowMode counts the requests over the limit in the metrics of the
+	// rate limit service without rejecting them. It requires a rate limit
+	// image set through RELATED_IMAGE_RATELIMIT, the default one doesn't
+	// support it
+	ShadowMode bool `json:"shadow_mode,omitempty"`
+	// Windows are the limits of additional windows, enforced together with
+	// the limit per unit. For example a burst limit per second on top of a
+	// sustained limit per day
+	Windows []RateLimitWindow `json:"windows,omitempty"`
+}
+
+// RateLimitWindow is a limit of the requests per unit
+type RateLimitWindow struct {
+	Unit            string `json:"unit"`
+	RequestsPerUnit uint32 `json:"requests_per_unit"`
+}
+
+// AllWindows returns the limit per unit followed by the limits of the
+// additional windows
+func (r RateLimitConfig) AllWindows() []RateLimitWindow {
+	return append([]RateLimitWindow{{
+		Unit:            r.Unit,
+		RequestsPerUnit: r.RequestsPerUnit,
+	}}, r.Windows...)
+}
+
+// LowestRate returns the lowest rate of the windows, converted to requests
+// per unit to. It's the sustained rate the limits allow
+func (r RateLimitConfig) LowestRate(to string) (float64, error) {
+	lowest := math.Inf(1)
+	for _, window := range r.AllWindows() {
+		rate, err := ConvertRate(window.Unit, to, int(window.RequestsPerUnit))
+		if err != nil {
+			return 0, err
+		}
+		lowest = math.Min(lowest, rate)
+	}
+	return lowest, nil
+}
+
+// ValidateWindows returns an error if a window has an unknown unit, no
+// requests, or the unit of another window
+func (r RateLimitConfig) ValidateWindows() error {
+	units := map[string]bool{r.Unit: true}
+	for i, window := range r.Windows {
+		if _, ok := conversionFactors[window.Unit]; !ok {
+			return fmt.Errorf("windows[%d].unit: unknown unit %s", i, window.Unit)
+		}
+		if window.RequestsPerUnit == 0 {
+			return fmt.Errorf("windows[%d].requests_per_unit: must be greater than 0", i)
+		}
+		if units[window.Unit] {
+			return fmt.Errorf("windows[%d].unit: %s is duplicated", i, window.Unit)
+		}
+		units[window.Unit] = true
+	}
+	return nil
 }
 
 type AlertConfig struct {
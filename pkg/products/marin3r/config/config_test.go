@@ -98,3 +98,77 @@ func testScheme() *runtime.Scheme {
 	corev1.AddToScheme(scheme)
 	return scheme
 }
+
+func TestRateLimitWindows(t *testing.T) {
+	scenarios := []struct {
+		Name               string
+		RateLimit          RateLimitConfig
+		ExpectedLowestRate float64
+		ExpectedError      string
+	}{
+		{
+			Name:               "Single window",
+			RateLimit:          RateLimitConfig{Unit: "minute", RequestsPerUnit: 100},
+			ExpectedLowestRate: 100,
+		},
+		{
+			Name: "Burst and sustained windows",
+			RateLimit: RateLimitConfig{
+				Unit:            "second",
+				RequestsPerUnit: 100,
+				Windows:         []RateLimitWindow{{Unit: "day", RequestsPerUnit: 1440000}},
+			},
+			ExpectedLowestRate: 1000,
+		},
+		{
+			Name: "Duplicated unit",
+			RateLimit: RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+				Windows:         []RateLimitWindow{{Unit: "minute", RequestsPerUnit: 10}},
+			},
+			ExpectedError: "windows[0].unit: minute is duplicated",
+		},
+		{
+			Name: "Unknown unit",
+			RateLimit: RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+				Windows:         []RateLimitWindow{{Unit: "week", RequestsPerUnit: 10}},
+			},
+			ExpectedError: "windows[0].unit: unknown unit week",
+		},
+		{
+			Name: "No requests",
+			RateLimit: RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+				Windows:         []RateLimitWindow{{Unit: "day"}},
+			},
+			ExpectedError: "windows[0].requests_per_unit: must be greater than 0",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			err := scenario.RateLimit.ValidateWindows()
+			if scenario.ExpectedError != "" {
+				if err == nil || err.Error() != scenario.ExpectedError {
+					t.Fatalf("expected error %q, got %v", scenario.ExpectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			lowestRate, err := scenario.RateLimit.LowestRate(Minute)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lowestRate != scenario.ExpectedLowestRate {
+				t.Errorf("expected lowest rate %f, got %f", scenario.ExpectedLowestRate, lowestRate)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	DescriptorConfigMapName = "rate-limit-descriptors"
+
+	// DescriptorMatchAccount matches the requests to the APIcast of a 3scale
+	// account, by the host of its routes
+	DescriptorMatchAccount = "account"
+	// DescriptorMatchService matches the requests of a 3scale service, by
+	// the service_id parameter of the requests to the backend listener
+	DescriptorMatchService = "service"
+	// DescriptorMatchHeader matches the requests by the value of a header.
+	// Without a value, each value of the header has its own limit
+	DescriptorMatchHeader = "header"
+	// DescriptorMatchPath matches the requests by the prefix of their path
+	DescriptorMatchPath = "path"
+
+	// HeaderMatchDescriptorKey is the key of the descriptor entries of the
+	// descriptors that match a value
+	HeaderMatchDescriptorKey = "header_match"
+)
+
+// DescriptorConfig is a rate limit descriptor with its own limit, applied
+// on top of the global rate limit. The limits of the nested descriptors
+// apply to the requests that match the descriptor and theirs
+type DescriptorConfig struct {
+	// Name identifies the descriptor among its siblings
+	Name        string             `json:"name"`
+	Match       DescriptorMatch    `json:"match"`
+	RateLimit   *RateLimitConfig   `json:"rate_limit,omitempty"`
+	Descriptors []DescriptorConfig `json:"descriptors,omitempty"`
+}
+
+type DescriptorMatch struct {
+	Type string `json:"type"`
+	// Header is the name of the header matched by the header type
+	Header string `json:"header,omitempty"`
+	// Value is the account name, service ID, header value or path prefix
+	// matched
+	Value string `json:"value,omitempty"`
+}
+
+// GetDescriptorsConfig returns the rate limit descriptors, or none if they
+// aren't configured
+func GetDescriptorsConfig(ctx context.Context, client k8sclient.Client, namespace string) ([]DescriptorConfig, error) {
+	descriptors := []DescriptorConfig{}
+	err := getFromJSONConfigMap(
+		ctx, client,
+		DescriptorConfigMapName, namespace, "descriptors",
+		&descriptors,
+	)
+	if k8serr.IsNotFound(err) {
+		return []DescriptorConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateDescriptors(descriptors, "descriptors"); err != nil {
+		return nil, fmt.Errorf("invalid %s ConfigMap: %w", DescriptorConfigMapName, err)
+	}
+	return descriptors, nil
+}
+
+// DescriptorEntry returns the key and value of the descriptor entry the
+// requests matched by the descriptor are sent with. The value is empty when
+// each value of a header has its own limit
+func (d DescriptorConfig) DescriptorEntry() (string, string) {
+	if d.PerHeaderValue() {
+		return d.Name, ""
+	}
+	return HeaderMatchDescriptorKey, d.Name
+}
+
+// PerHeaderValue returns true if each value of the header matched by the
+// descriptor has its own limit
+func (d DescriptorConfig) PerHeaderValue() bool {
+	return d.Match.Type == DescriptorMatchHeader && d.Match.Value == ""
+}
+
+func validateDescriptors(descriptors []DescriptorConfig, field string) error {
+	names := map[string]bool{}
+	for i, descriptor := range descriptors {
+		descriptorField := fmt.Sprintf("%s[%d]", field, i)
+		if descriptor.Name == "" {
+			return fmt.Errorf("%s.name: required", descriptorField)
+		}
+		if names[descriptor.Name] {
+			return fmt.Errorf("%s.name: %s is duplicated", descriptorField, descriptor.Name)
+		}
+		names[descriptor.Name] = true
+
+		switch descriptor.Match.Type {
+		case DescriptorMatchAccount, DescriptorMatchService, DescriptorMatchPath:
+			if descriptor.Match.Value == "" {
+				return fmt.Errorf("%s.match.value: required for %s", descriptorField, descriptor.Match.Type)
+			}
+		case DescriptorMatchHeader:
+			if descriptor.Match.Header == "" {
+				return fmt.Errorf("%s.match.header: required for %s", descriptorField, descriptor.Match.Type)
+			}
+		default:
+			return fmt.Errorf("%s.match.type: must be one of %s, %s, %s or %s", descriptorField,
+				DescriptorMatchAccount, DescriptorMatchService, DescriptorMatchHeader, DescriptorMatchPath)
+		}
+
+		if rateLimit := descriptor.RateLimit; rateLimit != nil {
+			if _, ok := conversionFactors[rateLimit.Unit]; !ok {
+				return fmt.Errorf("%s.rate_limit.unit: unknown unit %s", descriptorField, rateLimit.Unit)
+			}
+			if rateLimit.RequestsPerUnit == 0 {
+				return fmt.Errorf("%s.rate_limit.requests_per_unit: must be 1 or greater", descriptorField)
+			}
+			if err := rateLimit.ValidateWindows(); err != nil {
+				return fmt.Errorf("%s.rate_limit.%w", descriptorField, err)
+			}
+		}
+
+		if err := validateDescriptors(descriptor.Descriptors, descriptorField+".descriptors"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
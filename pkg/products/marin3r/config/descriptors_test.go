@@ -0,0 +1,208 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDescriptorsConfig(t *testing.T) {
+	scheme := testScheme()
+
+	descriptorsConfigMap := func(descriptors string) runtime.Object {
+		return &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "rate-limit-descriptors",
+				Namespace: "redhat-test-operator",
+			},
+			Data: map[string]string{
+				"descriptors": descriptors,
+			},
+		}
+	}
+
+	scenarios := []struct {
+		Name        string
+		InitialObjs []runtime.Object
+		Assert      func([]DescriptorConfig, error) error
+	}{
+		{
+			Name: "Success",
+			InitialObjs: []runtime.Object{
+				descriptorsConfigMap(`
+					[
+						{
+							"name": "tenant-a",
+							"match": {"type": "account", "value": "tenant-a"},
+							"rate_limit": {"unit": "minute", "requests_per_unit": 100},
+							"descriptors": [
+								{
+									"name": "per-client",
+									"match": {"type": "header", "header": "x-client-id"},
+									"rate_limit": {"unit": "second", "requests_per_unit": 5}
+								}
+							]
+						}
+					]
+				`),
+			},
+			Assert: func(descriptors []DescriptorConfig, err error) error {
+				if err != nil {
+					return fmt.Errorf("unexpected error: %v", err)
+				}
+
+				expected := []DescriptorConfig{
+					{
+						Name:      "tenant-a",
+						Match:     DescriptorMatch{Type: DescriptorMatchAccount, Value: "tenant-a"},
+						RateLimit: &RateLimitConfig{Unit: "minute", RequestsPerUnit: 100},
+						Descriptors: []DescriptorConfig{
+							{
+								Name:      "per-client",
+								Match:     DescriptorMatch{Type: DescriptorMatchHeader, Header: "x-client-id"},
+								RateLimit: &RateLimitConfig{Unit: "second", RequestsPerUnit: 5},
+							},
+						},
+					},
+				}
+				if !reflect.DeepEqual(descriptors, expected) {
+					return fmt.Errorf("obtained invalid config. Expected %+v, but got %+v", expected, descriptors)
+				}
+
+				key, value := descriptors[0].Descriptors[0].DescriptorEntry()
+				if key != "per-client" || value != "" {
+					return fmt.Errorf("expected each value of the header to have its own limit, got %s: %s", key, value)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name: "Not configured",
+			Assert: func(descriptors []DescriptorConfig, err error) error {
+				if err != nil {
+					return fmt.Errorf("unexpected error: %v", err)
+				}
+				if len(descriptors) != 0 {
+					return fmt.Errorf("expected no descriptors, got %+v", descriptors)
+				}
+
+				return nil
+			},
+		},
+		{
+			Name: "Duplicated names",
+			InitialObjs: []runtime.Object{
+				descriptorsConfigMap(`
+					[
+						{"name": "svc", "match": {"type": "service", "value": "1"}},
+						{"name": "svc", "match": {"type": "service", "value": "2"}}
+					]
+				`),
+			},
+			Assert: expectDescriptorsError("descriptors[1].name: svc is duplicated"),
+		},
+		{
+			Name: "Missing match value",
+			InitialObjs: []runtime.Object{
+				descriptorsConfigMap(`
+					[
+						{
+							"name": "tenant-a",
+							"match": {"type": "account", "value": "tenant-a"},
+							"descriptors": [{"name": "api", "match": {"type": "path"}}]
+						}
+					]
+				`),
+			},
+			Assert: expectDescriptorsError("descriptors[0].descriptors[0].match.value: required for path"),
+		},
+		{
+			Name: "Unknown rate limit unit",
+			InitialObjs: []runtime.Object{
+				descriptorsConfigMap(`
+					[
+						{
+							"name": "svc",
+							"match": {"type": "service", "value": "1"},
+							"rate_limit": {"unit": "week", "requests_per_unit": 1}
+						}
+					]
+				`),
+			},
+			Assert: expectDescriptorsError("descriptors[0].rate_limit.unit: unknown unit week"),
+		},
+		{
+			Name: "Duplicated window unit",
+			InitialObjs: []runtime.Object{
+				descriptorsConfigMap(`
+					[
+						{
+							"name": "svc",
+							"match": {"type": "service", "value": "1"},
+							"rate_limit": {
+								"unit": "minute",
+								"requests_per_unit": 10,
+								"windows": [{"unit": "minute", "requests_per_unit": 5}]
+							}
+						}
+					]
+				`),
+			},
+			Assert: expectDescriptorsError("descriptors[0].rate_limit.windows[0].unit: minute is duplicated"),
+		},
+		{
+			Name: "Window without requests",
+			InitialObjs: []runtime.Object{
+				descriptorsConfigMap(`
+					[
+						{
+							"name": "tenant-a",
+							"match": {"type": "account", "value": "tenant-a"},
+							"descriptors": [
+								{
+									"name": "api",
+									"match": {"type": "path", "value": "/api"},
+									"rate_limit": {
+										"unit": "minute",
+										"requests_per_unit": 10,
+										"windows": [{"unit": "day", "requests_per_unit": 0}]
+									}
+								}
+							]
+						}
+					]
+				`),
+			},
+			Assert: expectDescriptorsError("descriptors[0].descriptors[0].rate_limit.windows[0].requests_per_unit: must be greater than 0"),
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, scenario.InitialObjs...)
+			descriptors, err := GetDescriptorsConfig(context.TODO(), client, "redhat-test-operator")
+
+			if err := scenario.Assert(descriptors, err); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func expectDescriptorsError(message string) func([]DescriptorConfig, error) error {
+	return func(_ []DescriptorConfig, err error) error {
+		if err == nil || !strings.Contains(err.Error(), message) {
+			return fmt.Errorf("expected error containing %q, got %v", message, err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ExemptionsConfigMapName = "rate-limit-exemptions"
+
+	// ExemptRequestsMetric counts the requests exempted from the rate limits.
+	// They don't reach the rate limit service, so they're counted by the
+	// envoy sidecars, on the exempt clusters: apicast-ratelimit-exempt and
+	// backend-listener-ratelimit-exempt in the envoy_cluster_name label
+	ExemptRequestsMetric = "envoy_cluster_upstream_rq_total"
+)
+
+// ExemptionsConfig is the traffic that isn't rate limited
+type ExemptionsConfig struct {
+	// SourceCIDRs are the ranges of the source addresses of the connections.
+	// Traffic through the OpenShift routes reaches envoy from the router, so
+	// only the connections from within the cluster can be exempted by source
+	SourceCIDRs []string `json:"source_cidrs,omitempty"`
+	// Hosts are the hosts of the requests
+	Hosts []string `json:"hosts,omitempty"`
+	// Services are the IDs of the 3scale services, matched by the
+	// service_id parameter of the requests to the backend listener
+	Services []string `json:"services,omitempty"`
+	// Paths are the prefixes of the paths of the requests, such as health
+	// checks
+	Paths []string `json:"paths,omitempty"`
+}
+
+// GetExemptionsConfig returns the traffic exempted from the rate limits, or
+// none if it isn't configured
+func GetExemptionsConfig(ctx context.Context, client k8sclient.Client, namespace string) (*ExemptionsConfig, error) {
+	exemptions := &ExemptionsConfig{}
+	err := getFromJSONConfigMap(
+		ctx, client,
+		ExemptionsConfigMapName, namespace, "exemptions",
+		exemptions,
+	)
+	if k8serr.IsNotFound(err) {
+		return &ExemptionsConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exemptions.validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s ConfigMap: %w", ExemptionsConfigMapName, err)
+	}
+	return exemptions, nil
+}
+
+// IsEmpty returns true if no traffic is exempted
+func (e *ExemptionsConfig) IsEmpty() bool {
+	return e == nil || len(e.SourceCIDRs)+len(e.Hosts)+len(e.Services)+len(e.Paths) == 0
+}
+
+func (e *ExemptionsConfig) validate() error {
+	for i, cidr := range e.SourceCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("source_cidrs[%d]: %w", i, err)
+		}
+	}
+	for i, host := range e.Hosts {
+		if host == "" {
+			return fmt.Errorf("hosts[%d]: must not be empty", i)
+		}
+	}
+	for i, service := range e.Services {
+		if service == "" {
+			return fmt.Errorf("services[%d]: must not be empty", i)
+		}
+	}
+	for i, path := range e.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("paths[%d]: must start with /", i)
+		}
+	}
+	return nil
+}
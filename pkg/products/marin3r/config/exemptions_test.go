@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetExemptionsConfig(t *testing.T) {
+	scheme := testScheme()
+
+	exemptionsConfigMap := func(exemptions string) runtime.Object {
+		return &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      "rate-limit-exemptions",
+				Namespace: "redhat-test-operator",
+			},
+			Data: map[string]string{
+				"exemptions": exemptions,
+			},
+		}
+	}
+
+	scenarios := []struct {
+		Name          string
+		InitialObjs   []runtime.Object
+		Expected      *ExemptionsConfig
+		ExpectedError string
+	}{
+		{
+			Name: "Success",
+			InitialObjs: []runtime.Object{
+				exemptionsConfigMap(`
+					{
+						"source_cidrs": ["10.128.0.0/14"],
+						"hosts": ["status.example.com"],
+						"services": ["2"],
+						"paths": ["/healthz"]
+					}
+				`),
+			},
+			Expected: &ExemptionsConfig{
+				SourceCIDRs: []string{"10.128.0.0/14"},
+				Hosts:       []string{"status.example.com"},
+				Services:    []string{"2"},
+				Paths:       []string{"/healthz"},
+			},
+		},
+		{
+			Name:     "Not configured",
+			Expected: &ExemptionsConfig{},
+		},
+		{
+			Name:          "Invalid source CIDR",
+			InitialObjs:   []runtime.Object{exemptionsConfigMap(`{"source_cidrs": ["10.128.0.0"]}`)},
+			ExpectedError: "source_cidrs[0]",
+		},
+		{
+			Name:          "Relative path",
+			InitialObjs:   []runtime.Object{exemptionsConfigMap(`{"paths": ["healthz"]}`)},
+			ExpectedError: "paths[0]: must start with /",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, scenario.InitialObjs...)
+			exemptions, err := GetExemptionsConfig(context.TODO(), client, "redhat-test-operator")
+
+			if scenario.ExpectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), scenario.ExpectedError) {
+					t.Fatalf("expected error containing %q, got %v", scenario.ExpectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(exemptions, scenario.Expected) {
+				t.Fatalf("obtained invalid config. Expected %+v, but got %+v", scenario.Expected, exemptions)
+			}
+			if exemptions.IsEmpty() != (scenario.Name == "Not configured") {
+				t.Fatalf("unexpected IsEmpty %t for %+v", exemptions.IsEmpty(), exemptions)
+			}
+		})
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"crypto/md5"
 	"fmt"
+	"os"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
 	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8sError "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,6 +28,7 @@ type RateLimitServiceReconciler struct {
 	Installation    *integreatlyv1alpha1.RHMI
 	StatsdConfig    *StatsdConfig
 	RateLimitConfig marin3rconfig.RateLimitConfig
+	Descriptors     []marin3rconfig.DescriptorConfig
 }
 
 type StatsdConfig struct {
@@ -35,6 +39,13 @@ type StatsdConfig struct {
 const (
 	RateLimitingConfigMapName     = "ratelimit-config"
 	RateLimitingConfigMapDataName = "apicast-ratelimiting.yaml"
+
+	// RateLimitImageEnv overrides the image of the rate limit service
+	RateLimitImageEnv = "RELATED_IMAGE_RATELIMIT"
+	// defaultRateLimitImage is the image of the rate limit service unless
+	// overridden. It predates the shadow_mode key of the config, and fails
+	// to load a config that sets it
+	defaultRateLimitImage = "quay.io/integreatly/ratelimit:v1.4.0"
 )
 
 func NewRateLimitServiceReconciler(config marin3rconfig.RateLimitConfig, installation *integreatlyv1alpha1.RHMI, namespace, redisSecretName string) *RateLimitServiceReconciler {
@@ -59,6 +70,7 @@ type yamlDescriptor struct {
 	Value       string           `yaml:"value"`
 	RateLimit   *yamlRateLimit   `yaml:"rate_limit"`
 	Descriptors []yamlDescriptor `yaml:"descriptors"`
+	ShadowMode  bool             `yaml:"shadow_mode,omitempty"`
 }
 
 type yamlRoot struct {
@@ -70,6 +82,12 @@ type yamlRoot struct {
 // It reconciles a ConfigMap to configure the service, a Deployment to run it, and
 // exposes it as a Service
 func (r *RateLimitServiceReconciler) ReconcileRateLimitService(ctx context.Context, client k8sclient.Client, productConfig quota.ProductConfig) (integreatlyv1alpha1.StatusPhase, error) {
+	if r.usesShadowMode() && rateLimitImage() == defaultRateLimitImage {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf(
+			"shadow mode isn't supported by the rate limit image %s, set %s to an image that supports it",
+			defaultRateLimitImage, RateLimitImageEnv)
+	}
+
 	phase, err := r.reconcileConfigMap(ctx, client)
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, err
@@ -89,6 +107,12 @@ func (r *RateLimitServiceReconciler) WithStatsdConfig(config StatsdConfig) *Rate
 	return r
 }
 
+// WithDescriptors mutates r setting r.Descriptors to the value of descriptors
+func (r *RateLimitServiceReconciler) WithDescriptors(descriptors []marin3rconfig.DescriptorConfig) *RateLimitServiceReconciler {
+	r.Descriptors = descriptors
+	return r
+}
+
 func (r *RateLimitServiceReconciler) reconcileConfigMap(ctx context.Context, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	cm := &corev1.ConfigMap{
 		ObjectMeta: v1.ObjectMeta{
@@ -97,26 +121,12 @@ func (r *RateLimitServiceReconciler) reconcileConfigMap(ctx context.Context, cli
 		},
 	}
 
-	_, err := controllerutil.CreateOrUpdate(ctx, client, cm, func() error {
-		stagingconfig := yamlRoot{
-			Domain: "apicast-ratelimit",
-			Descriptors: []yamlDescriptor{
-				{
-					Key:   "generic_key",
-					Value: "slowpath",
-					RateLimit: &yamlRateLimit{
-						Unit:            r.RateLimitConfig.Unit,
-						RequestsPerUnit: r.RateLimitConfig.RequestsPerUnit,
-					},
-				},
-			},
-		}
-
-		stagingConfigYamlMarshalled, err := yaml.Marshal(stagingconfig)
-		if err != nil {
-			return fmt.Errorf("failed to marshall rate limit config: %v", err)
-		}
+	config, err := r.getConfig()
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
 
+	_, err = controllerutil.CreateOrUpdate(ctx, client, cm, func() error {
 		if cm.Data == nil {
 			cm.Data = map[string]string{}
 		}
@@ -124,7 +134,7 @@ func (r *RateLimitServiceReconciler) reconcileConfigMap(ctx context.Context, cli
 			cm.Labels = map[string]string{}
 		}
 
-		cm.Data[RateLimitingConfigMapDataName] = string(stagingConfigYamlMarshalled)
+		cm.Data[RateLimitingConfigMapDataName] = string(config)
 		cm.Labels["app"] = quota.RateLimitName
 		cm.Labels["part-of"] = "3scale-saas"
 		return nil
@@ -136,6 +146,57 @@ func (r *RateLimitServiceReconciler) reconcileConfigMap(ctx context.Context, cli
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// rateLimitImage returns the image of the rate limit service
+func rateLimitImage() string {
+	if image := os.Getenv(RateLimitImageEnv); image != "" {
+		return image
+	}
+	return defaultRateLimitImage
+}
+
+// usesShadowMode returns true if the global rate limit or a descriptor is in
+// shadow mode
+func (r *RateLimitServiceReconciler) usesShadowMode() bool {
+	return r.RateLimitConfig.ShadowMode || descriptorsUseShadowMode(r.Descriptors)
+}
+
+func descriptorsUseShadowMode(descriptors []marin3rconfig.DescriptorConfig) bool {
+	for _, descriptor := range descriptors {
+		if descriptor.RateLimit != nil && descriptor.RateLimit.ShadowMode {
+			return true
+		}
+		if descriptorsUseShadowMode(descriptor.Descriptors) {
+			return true
+		}
+	}
+	return false
+}
+
+// getConfig returns the config of the rate limit service, marshalled as YAML
+func (r *RateLimitServiceReconciler) getConfig() ([]byte, error) {
+	stagingconfig := yamlRoot{
+		Domain: "apicast-ratelimit",
+		Descriptors: []yamlDescriptor{
+			{
+				Key:   "generic_key",
+				Value: "slowpath",
+				RateLimit: &yamlRateLimit{
+					Unit:            r.RateLimitConfig.Unit,
+					RequestsPerUnit: r.RateLimitConfig.RequestsPerUnit,
+				},
+				ShadowMode: r.RateLimitConfig.ShadowMode,
+			},
+		},
+	}
+	stagingconfig.Descriptors = append(stagingconfig.Descriptors, r.additionalDescriptors()...)
+
+	stagingConfigYamlMarshalled, err := yaml.Marshal(stagingconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshall rate limit config: %v", err)
+	}
+	return stagingConfigYamlMarshalled, nil
+}
+
 func (r *RateLimitServiceReconciler) reconcileDeployment(ctx context.Context, client k8sclient.Client, productConfig quota.ProductConfig) (integreatlyv1alpha1.StatusPhase, error) {
 	redisSecret, err := r.getRedisSecret(ctx, client)
 	if err != nil {
@@ -165,6 +226,11 @@ func (r *RateLimitServiceReconciler) reconcileDeployment(ctx context.Context, cl
 		}
 	}
 
+	config, err := r.getConfig()
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
 	_, err = controllerutil.CreateOrUpdate(ctx, client, deployment, func() error {
 		if deployment.Labels == nil {
 			deployment.Labels = map[string]string{}
@@ -248,7 +314,7 @@ func (r *RateLimitServiceReconciler) reconcileDeployment(ctx context.Context, cl
 						Items: []corev1.KeyToPath{
 							{
 								Key:  "apicast-ratelimiting.yaml",
-								Path: fmt.Sprintf("apicast-ratelimiting-%s.yaml", uniqueKey(r.RateLimitConfig)),
+								Path: fmt.Sprintf("apicast-ratelimiting-%s.yaml", uniqueKey(config)),
 							},
 						},
 					},
@@ -259,7 +325,7 @@ func (r *RateLimitServiceReconciler) reconcileDeployment(ctx context.Context, cl
 			deployment.Spec.Template.Spec.Containers = []corev1.Container{{}}
 		}
 		deployment.Spec.Template.Spec.Containers[0].Name = quota.RateLimitName
-		deployment.Spec.Template.Spec.Containers[0].Image = "quay.io/integreatly/ratelimit:v1.4.0"
+		deployment.Spec.Template.Spec.Containers[0].Image = rateLimitImage()
 		deployment.Spec.Template.Spec.Containers[0].Command = []string{quota.RateLimitName}
 		deployment.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
 			{
@@ -305,6 +371,17 @@ func (r *RateLimitServiceReconciler) reconcileDeployment(ctx context.Context, cl
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
+
+	// The autoscaler scales the deployment directly, its replicas are only
+	// kept within the bounds of the quota by Configure
+	if _, err := quota.ReconcileAutoscaler(ctx, client, productConfig, quota.RateLimitName, r.Namespace, autoscalingv1.CrossVersionObjectReference{
+		APIVersion: appsv1.SchemeGroupVersion.String(),
+		Kind:       "Deployment",
+		Name:       deployment.Name,
+	}); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
@@ -366,11 +443,62 @@ func (r *RateLimitServiceReconciler) getRedisSecret(ctx context.Context, client
 	return secret, err
 }
 
-// uniqueKey generates a unique string for each possible rate limit configuration
-// combination
-func uniqueKey(r marin3rconfig.RateLimitConfig) string {
-	str := fmt.Sprintf("%s/%d", r.Unit, r.RequestsPerUnit)
-	return fmt.Sprintf("%x", md5.Sum([]byte(str)))
+// additionalDescriptors returns the descriptors of the rate limit service
+// config besides the global rate limit: the descriptors of the additional
+// windows of the global rate limit and the descriptors with their own limits
+func (r *RateLimitServiceReconciler) additionalDescriptors() []yamlDescriptor {
+	descriptors := windowYamlDescriptors(r.RateLimitConfig)
+	return append(descriptors, toYamlDescriptors(r.Descriptors)...)
+}
+
+// windowYamlDescriptors returns the descriptors of the additional windows of
+// the rate limit, keyed by the generic key of the window
+func windowYamlDescriptors(rateLimit marin3rconfig.RateLimitConfig) []yamlDescriptor {
+	descriptors := []yamlDescriptor{}
+	for _, window := range rateLimit.Windows {
+		descriptors = append(descriptors, yamlDescriptor{
+			Key:   "generic_key",
+			Value: ratelimit.WindowDescriptorValue(window.Unit),
+			RateLimit: &yamlRateLimit{
+				Unit:            window.Unit,
+				RequestsPerUnit: window.RequestsPerUnit,
+			},
+			ShadowMode: rateLimit.ShadowMode,
+		})
+	}
+	return descriptors
+}
+
+// toYamlDescriptors maps the descriptors with their own limits to the
+// descriptors of the rate limit service config, keyed by the descriptor
+// entries of the envoy rate limit actions. The additional windows of the
+// limit of a descriptor are nested in it, before its nested descriptors
+func toYamlDescriptors(descriptors []marin3rconfig.DescriptorConfig) []yamlDescriptor {
+	result := make([]yamlDescriptor, 0, len(descriptors))
+	for _, descriptor := range descriptors {
+		key, value := descriptor.DescriptorEntry()
+		yd := yamlDescriptor{
+			Key:         key,
+			Value:       value,
+			Descriptors: toYamlDescriptors(descriptor.Descriptors),
+		}
+		if descriptor.RateLimit != nil {
+			yd.RateLimit = &yamlRateLimit{
+				Unit:            descriptor.RateLimit.Unit,
+				RequestsPerUnit: descriptor.RateLimit.RequestsPerUnit,
+			}
+			yd.ShadowMode = descriptor.RateLimit.ShadowMode
+			yd.Descriptors = append(windowYamlDescriptors(*descriptor.RateLimit), yd.Descriptors...)
+		}
+		result = append(result, yd)
+	}
+	return result
+}
+
+// uniqueKey generates a unique string for each possible rate limit configuration,
+// from the config marshalled as YAML
+func uniqueKey(config []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(config))
 }
 
 func GetRateLimitFromConfig(c *corev1.ConfigMap) (*yamlRateLimit, error) {
@@ -3,13 +3,17 @@ package marin3r
 import (
 	"context"
 	"fmt"
-	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	"os"
+	"reflect"
 	"testing"
 
 	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +30,7 @@ func TestRateLimitService(t *testing.T) {
 		Reconciler    *RateLimitServiceReconciler
 		ProductConfig quota.ProductConfig
 		InitObjs      []runtime.Object
+		Image         string
 		Assert        func(k8sclient.Client, integreatlyv1alpha1.StatusPhase, error) error
 	}{
 		{
@@ -39,6 +44,9 @@ func TestRateLimitService(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 			InitObjs: []runtime.Object{
 				&corev1.Secret{
@@ -129,6 +137,9 @@ func TestRateLimitService(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 			Assert: allOf(
 				assertNoError,
@@ -171,6 +182,9 @@ func TestRateLimitService(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 			Assert: allOf(
 				assertNoError,
@@ -218,6 +232,9 @@ func TestRateLimitService(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 			Assert: allOf(
 				assertNoError,
@@ -230,10 +247,243 @@ func TestRateLimitService(t *testing.T) {
 				}),
 			),
 		},
+		{
+			Name: "Shadow mode rejected by the default image",
+			Reconciler: NewRateLimitServiceReconciler(marin3rconfig.RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+				ShadowMode:      true,
+			},
+				&integreatlyv1alpha1.RHMI{}, "redhat-test-marin3r", "ratelimit-redis"),
+			Assert: allOf(
+				assertPhase(integreatlyv1alpha1.PhaseFailed),
+				func(_ k8sclient.Client, _ integreatlyv1alpha1.StatusPhase, err error) error {
+					if err == nil {
+						return fmt.Errorf("expected shadow mode to be rejected with the default image")
+					}
+					return nil
+				},
+			),
+		},
+		{
+			Name:  "Descriptors rendered into the config",
+			Image: "quay.io/integreatly/ratelimit:shadow-mode",
+			Reconciler: NewRateLimitServiceReconciler(marin3rconfig.RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+			},
+				&integreatlyv1alpha1.RHMI{}, "redhat-test-marin3r", "ratelimit-redis").
+				WithDescriptors([]marin3rconfig.DescriptorConfig{
+					{
+						Name:      "tenant-a",
+						Match:     marin3rconfig.DescriptorMatch{Type: marin3rconfig.DescriptorMatchAccount, Value: "tenant-a"},
+						RateLimit: &marin3rconfig.RateLimitConfig{Unit: "minute", RequestsPerUnit: 10},
+						Descriptors: []marin3rconfig.DescriptorConfig{
+							{
+								Name:      "per-client",
+								Match:     marin3rconfig.DescriptorMatch{Type: marin3rconfig.DescriptorMatchHeader, Header: "x-client-id"},
+								RateLimit: &marin3rconfig.RateLimitConfig{Unit: "second", RequestsPerUnit: 1, ShadowMode: true},
+							},
+						},
+					},
+				}),
+			ProductConfig: &quota.ProductConfigMock{
+				ConfigureFunc: func(obj metav1.Object) error {
+					return nil
+				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
+			},
+			InitObjs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ratelimit-redis",
+						Namespace: "redhat-test-marin3r",
+					},
+					Data: map[string][]byte{
+						"URL": []byte("test-url"),
+					},
+				},
+			},
+			Assert: allOf(
+				assertNoError,
+				assertPhase(integreatlyv1alpha1.PhaseCompleted),
+				func(client k8sclient.Client, phase integreatlyv1alpha1.StatusPhase, reconcileError error) error {
+					configMap := &corev1.ConfigMap{}
+					if err := client.Get(context.TODO(), k8sclient.ObjectKey{
+						Name:      RateLimitingConfigMapName,
+						Namespace: "redhat-test-marin3r",
+					}, configMap); err != nil {
+						return fmt.Errorf("failed to obtain expected ConfigMap: %v", err)
+					}
+
+					config := yamlRoot{}
+					if err := yaml.Unmarshal([]byte(configMap.Data[RateLimitingConfigMapDataName]), &config); err != nil {
+						return fmt.Errorf("failed to unmarshal rate limit config: %v", err)
+					}
+					if len(config.Descriptors) != 2 || config.Descriptors[0].Value != "slowpath" || config.Descriptors[0].RateLimit.RequestsPerUnit != 100 {
+						return fmt.Errorf("expected the global descriptor followed by the tenant descriptor, got %+v", config.Descriptors)
+					}
+
+					tenant := config.Descriptors[1]
+					if tenant.Key != "header_match" || tenant.Value != "tenant-a" || tenant.RateLimit.RequestsPerUnit != 10 {
+						return fmt.Errorf("unexpected tenant descriptor %+v", tenant)
+					}
+					if len(tenant.Descriptors) != 1 || tenant.Descriptors[0].Key != "per-client" || tenant.Descriptors[0].Value != "" || tenant.Descriptors[0].RateLimit.Unit != "second" {
+						return fmt.Errorf("unexpected nested descriptors %+v", tenant.Descriptors)
+					}
+					if tenant.ShadowMode || !tenant.Descriptors[0].ShadowMode {
+						return fmt.Errorf("expected only the nested descriptor in shadow mode, got %+v", tenant)
+					}
+
+					return nil
+				},
+			),
+		},
+		{
+			Name: "Windows rendered as descriptors",
+			Reconciler: NewRateLimitServiceReconciler(marin3rconfig.RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+				Windows: []marin3rconfig.RateLimitWindow{
+					{Unit: "second", RequestsPerUnit: 10},
+					{Unit: "day", RequestsPerUnit: 5000000},
+				},
+			},
+				&integreatlyv1alpha1.RHMI{}, "redhat-test-marin3r", "ratelimit-redis"),
+			ProductConfig: &quota.ProductConfigMock{
+				ConfigureFunc: func(obj metav1.Object) error {
+					return nil
+				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
+			},
+			InitObjs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ratelimit-redis",
+						Namespace: "redhat-test-marin3r",
+					},
+					Data: map[string][]byte{
+						"URL": []byte("test-url"),
+					},
+				},
+			},
+			Assert: allOf(
+				assertNoError,
+				assertPhase(integreatlyv1alpha1.PhaseCompleted),
+				func(client k8sclient.Client, phase integreatlyv1alpha1.StatusPhase, reconcileError error) error {
+					configMap := &corev1.ConfigMap{}
+					if err := client.Get(context.TODO(), k8sclient.ObjectKey{
+						Name:      RateLimitingConfigMapName,
+						Namespace: "redhat-test-marin3r",
+					}, configMap); err != nil {
+						return fmt.Errorf("failed to obtain expected ConfigMap: %v", err)
+					}
+
+					config := yamlRoot{}
+					if err := yaml.Unmarshal([]byte(configMap.Data[RateLimitingConfigMapDataName]), &config); err != nil {
+						return fmt.Errorf("failed to unmarshal rate limit config: %v", err)
+					}
+					expected := []yamlDescriptor{
+						{Key: "generic_key", Value: "slowpath", RateLimit: &yamlRateLimit{Unit: "minute", RequestsPerUnit: 100}, Descriptors: []yamlDescriptor{}},
+						{Key: "generic_key", Value: "slowpath_second", RateLimit: &yamlRateLimit{Unit: "second", RequestsPerUnit: 10}, Descriptors: []yamlDescriptor{}},
+						{Key: "generic_key", Value: "slowpath_day", RateLimit: &yamlRateLimit{Unit: "day", RequestsPerUnit: 5000000}, Descriptors: []yamlDescriptor{}},
+					}
+					if !reflect.DeepEqual(config.Descriptors, expected) {
+						return fmt.Errorf("unexpected descriptors. Expected %+v, got %+v", expected, config.Descriptors)
+					}
+
+					return nil
+				},
+			),
+		},
+		{
+			Name: "Descriptor windows rendered as nested descriptors",
+			Reconciler: NewRateLimitServiceReconciler(marin3rconfig.RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+			},
+				&integreatlyv1alpha1.RHMI{}, "redhat-test-marin3r", "ratelimit-redis").
+				WithDescriptors([]marin3rconfig.DescriptorConfig{
+					{
+						Name:  "tenant-a",
+						Match: marin3rconfig.DescriptorMatch{Type: marin3rconfig.DescriptorMatchAccount, Value: "tenant-a"},
+						RateLimit: &marin3rconfig.RateLimitConfig{
+							Unit:            "minute",
+							RequestsPerUnit: 10,
+							Windows:         []marin3rconfig.RateLimitWindow{{Unit: "day", RequestsPerUnit: 1000}},
+						},
+						Descriptors: []marin3rconfig.DescriptorConfig{
+							{
+								Name:      "per-client",
+								Match:     marin3rconfig.DescriptorMatch{Type: marin3rconfig.DescriptorMatchHeader, Header: "x-client-id"},
+								RateLimit: &marin3rconfig.RateLimitConfig{Unit: "minute", RequestsPerUnit: 1},
+							},
+						},
+					},
+				}),
+			ProductConfig: &quota.ProductConfigMock{
+				ConfigureFunc: func(obj metav1.Object) error {
+					return nil
+				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
+			},
+			InitObjs: []runtime.Object{
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ratelimit-redis",
+						Namespace: "redhat-test-marin3r",
+					},
+					Data: map[string][]byte{
+						"URL": []byte("test-url"),
+					},
+				},
+			},
+			Assert: allOf(
+				assertNoError,
+				assertPhase(integreatlyv1alpha1.PhaseCompleted),
+				func(client k8sclient.Client, phase integreatlyv1alpha1.StatusPhase, reconcileError error) error {
+					configMap := &corev1.ConfigMap{}
+					if err := client.Get(context.TODO(), k8sclient.ObjectKey{
+						Name:      RateLimitingConfigMapName,
+						Namespace: "redhat-test-marin3r",
+					}, configMap); err != nil {
+						return fmt.Errorf("failed to obtain expected ConfigMap: %v", err)
+					}
+
+					config := yamlRoot{}
+					if err := yaml.Unmarshal([]byte(configMap.Data[RateLimitingConfigMapDataName]), &config); err != nil {
+						return fmt.Errorf("failed to unmarshal rate limit config: %v", err)
+					}
+					if len(config.Descriptors) != 2 {
+						return fmt.Errorf("expected the global and tenant-a descriptors, got %+v", config.Descriptors)
+					}
+					expected := []yamlDescriptor{
+						{Key: "generic_key", Value: "slowpath_day", RateLimit: &yamlRateLimit{Unit: "day", RequestsPerUnit: 1000}, Descriptors: []yamlDescriptor{}},
+						{Key: "per-client", Value: "", RateLimit: &yamlRateLimit{Unit: "minute", RequestsPerUnit: 1}, Descriptors: []yamlDescriptor{}},
+					}
+					if nested := config.Descriptors[1].Descriptors; !reflect.DeepEqual(nested, expected) {
+						return fmt.Errorf("unexpected nested descriptors of tenant-a. Expected %+v, got %+v", expected, nested)
+					}
+
+					return nil
+				},
+			),
+		},
 	}
 
 	for _, scenario := range scenarios {
 		t.Run(scenario.Name, func(t *testing.T) {
+			if scenario.Image != "" {
+				os.Setenv(RateLimitImageEnv, scenario.Image)
+				defer os.Unsetenv(RateLimitImageEnv)
+			}
+
 			client := fake.NewFakeClientWithScheme(scheme, scenario.InitObjs...)
 			phase, err := scenario.Reconciler.ReconcileRateLimitService(context.TODO(), client, scenario.ProductConfig)
 
@@ -248,6 +498,7 @@ func newScheme() *runtime.Scheme {
 	scheme := runtime.NewScheme()
 	corev1.AddToScheme(scheme)
 	appsv1.AddToScheme(scheme)
+	autoscalingv1.AddToScheme(scheme)
 
 	return scheme
 }
@@ -318,3 +569,84 @@ func assertEnvs(assertions map[string]func(string) error) func(*appsv1.Deploymen
 		return nil
 	}
 }
+
+func TestUniqueKey(t *testing.T) {
+	newReconciler := func(config marin3rconfig.RateLimitConfig) *RateLimitServiceReconciler {
+		return NewRateLimitServiceReconciler(config, &integreatlyv1alpha1.RHMI{}, "redhat-test-marin3r", "ratelimit-redis").
+			WithDescriptors([]marin3rconfig.DescriptorConfig{
+				{
+					Name:      "tenant-a",
+					Match:     marin3rconfig.DescriptorMatch{Type: marin3rconfig.DescriptorMatchAccount, Value: "tenant-a"},
+					RateLimit: &marin3rconfig.RateLimitConfig{Unit: "minute", RequestsPerUnit: 10},
+				},
+			})
+	}
+	key := func(r *RateLimitServiceReconciler) string {
+		config, err := r.getConfig()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return uniqueKey(config)
+	}
+
+	enforced := marin3rconfig.RateLimitConfig{Unit: "minute", RequestsPerUnit: 10}
+	shadow := marin3rconfig.RateLimitConfig{Unit: "minute", RequestsPerUnit: 10, ShadowMode: true}
+
+	if key(newReconciler(enforced)) != key(newReconciler(enforced)) {
+		t.Error("expected equal configs to have the same key")
+	}
+	if key(newReconciler(enforced)) == key(newReconciler(shadow)) {
+		t.Error("expected the rate limit config to be reloaded when the shadow mode changes")
+	}
+}
+
+func TestGetConfigShadowMode(t *testing.T) {
+	scenarios := []struct {
+		Name       string
+		ShadowMode bool
+		Expected   string
+	}{
+		{
+			Name: "Enforced",
+			Expected: `domain: apicast-ratelimit
+descriptors:
+- key: generic_key
+  value: slowpath
+  rate_limit:
+    requests_per_unit: 10
+    unit: minute
+  descriptors: []
+`,
+		},
+		{
+			Name:       "Shadow mode",
+			ShadowMode: true,
+			Expected: `domain: apicast-ratelimit
+descriptors:
+- key: generic_key
+  value: slowpath
+  rate_limit:
+    requests_per_unit: 10
+    unit: minute
+  descriptors: []
+  shadow_mode: true
+`,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			config, err := NewRateLimitServiceReconciler(marin3rconfig.RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 10,
+				ShadowMode:      scenario.ShadowMode,
+			}, &integreatlyv1alpha1.RHMI{}, "redhat-test-marin3r", "ratelimit-redis").getConfig()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(config) != scenario.Expected {
+				t.Errorf("unexpected config.\nExpected:\n%s\nGot:\n%s", scenario.Expected, config)
+			}
+		})
+	}
+}
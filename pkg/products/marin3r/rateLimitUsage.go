@@ -0,0 +1,442 @@
+package marin3r
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/rhmi"
+	"gopkg.in/yaml.v2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// RateLimitUsagePath is the path of the rate limit usage endpoint
+	RateLimitUsagePath = "/ratelimit/usage"
+
+	rateLimitUsageTimeout = 10 * time.Second
+)
+
+// RateLimitCounters reads the counters of the rate limit service
+type RateLimitCounters interface {
+	// GetCounters returns the value of the counters whose key starts with
+	// prefix, by key
+	GetCounters(ctx context.Context, prefix string) (map[string]uint64, error)
+}
+
+// RateLimitUsageReport is the usage of the rate limits in their current
+// window
+type RateLimitUsageReport struct {
+	Domain      string           `json:"domain"`
+	Time        time.Time        `json:"time"`
+	Descriptors []RateLimitUsage `json:"descriptors"`
+}
+
+// RateLimitUsage is the usage of a rate limit by the requests of a descriptor.
+// The descriptors limited per header value, such as the consumers
+// identified by a header, have a usage for each value
+type RateLimitUsage struct {
+	// Descriptor identifies the descriptor as key=value/key=value...
+	Descriptor string                `json:"descriptor"`
+	Entries    []RateLimitUsageEntry `json:"entries"`
+	Unit       string                `json:"unit"`
+	Limit      uint32                `json:"limit"`
+	Used       uint64                `json:"used"`
+	Remaining  uint64                `json:"remaining"`
+	ResetTime  time.Time             `json:"reset_time"`
+	ShadowMode bool                  `json:"shadow_mode,omitempty"`
+}
+
+type RateLimitUsageEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// limitedDescriptor is a descriptor of the rate limit service config with
+// a limit, with the entries of its parents. The entries without a value
+// match every value
+type limitedDescriptor struct {
+	entries    []RateLimitUsageEntry
+	rateLimit  yamlRateLimit
+	shadowMode bool
+}
+
+// GetRateLimitUsage returns the usage of the rate limits of the config in
+// their current window, read from the counters of the rate limit service
+func GetRateLimitUsage(ctx context.Context, config *corev1.ConfigMap, counters RateLimitCounters, now time.Time) (*RateLimitUsageReport, error) {
+	root := yamlRoot{}
+	if err := yaml.Unmarshal([]byte(config.Data[RateLimitingConfigMapDataName]), &root); err != nil {
+		return nil, fmt.Errorf("error unmarshalling ratelimiting config from configmap '%s': %w", config.Name, err)
+	}
+
+	values, err := counters.GetCounters(ctx, root.Domain+"_")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RateLimitUsageReport{
+		Domain:      root.Domain,
+		Time:        now,
+		Descriptors: []RateLimitUsage{},
+	}
+	for _, descriptor := range limitedDescriptors(root.Descriptors, []RateLimitUsageEntry{}) {
+		unitSeconds, ok := unitToSeconds(descriptor.rateLimit.Unit)
+		if !ok {
+			return nil, fmt.Errorf("unknown rate limit unit %s", descriptor.rateLimit.Unit)
+		}
+		// The rate limit service keys the counters by
+		// <domain>_<key>_<value>_..._<start of the window>
+		windowStart := now.Unix() / unitSeconds * unitSeconds
+		suffix := fmt.Sprintf("%d", windowStart)
+
+		for _, entries := range descriptorUsageEntries(root.Domain, descriptor.entries, suffix, values) {
+			used := values[counterKey(root.Domain, entries, suffix)]
+			remaining := uint64(0)
+			if used < uint64(descriptor.rateLimit.RequestsPerUnit) {
+				remaining = uint64(descriptor.rateLimit.RequestsPerUnit) - used
+			}
+			report.Descriptors = append(report.Descriptors, RateLimitUsage{
+				Descriptor: descriptorName(entries),
+				Entries:    entries,
+				Unit:       descriptor.rateLimit.Unit,
+				Limit:      descriptor.rateLimit.RequestsPerUnit,
+				Used:       used,
+				Remaining:  remaining,
+				ResetTime:  time.Unix(windowStart+unitSeconds, 0).UTC(),
+				ShadowMode: descriptor.shadowMode,
+			})
+		}
+	}
+
+	return report, nil
+}
+
+// Filter returns the usages whose descriptor starts with descriptor, and
+// that have an entry with the value consumer. Empty arguments match every
+// usage
+func (r *RateLimitUsageReport) Filter(descriptor, consumer string) *RateLimitUsageReport {
+	filtered := &RateLimitUsageReport{
+		Domain:      r.Domain,
+		Time:        r.Time,
+		Descriptors: []RateLimitUsage{},
+	}
+	for _, usage := range r.Descriptors {
+		if !strings.HasPrefix(usage.Descriptor, descriptor) {
+			continue
+		}
+		if consumer != "" && !hasEntryValue(usage.Entries, consumer) {
+			continue
+		}
+		filtered.Descriptors = append(filtered.Descriptors, usage)
+	}
+	return filtered
+}
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// RateLimitUsageServer serves the usage of the rate limits of the
+// installation as JSON. The requests are authenticated by their bearer token,
+// and their user must be allowed to get the RateLimitUsagePath non-resource
+// URL
+type RateLimitUsageServer struct {
+	Addr           string
+	Client         k8sclient.Client
+	WatchNamespace string
+	Log            l.Logger
+
+	newCounters func(redisURL string) RateLimitCounters
+	now         func() time.Time
+}
+
+func NewRateLimitUsageServer(addr string, client k8sclient.Client, watchNamespace string, log l.Logger) *RateLimitUsageServer {
+	return &RateLimitUsageServer{
+		Addr:           addr,
+		Client:         client,
+		WatchNamespace: watchNamespace,
+		Log:            log,
+		newCounters: func(redisURL string) RateLimitCounters {
+			return &ratelimit.RedisCounters{URL: redisURL}
+		},
+		now: time.Now,
+	}
+}
+
+// Start serves the usage endpoint until stop is closed. It implements the
+// Runnable interface of the manager
+func (s *RateLimitUsageServer) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.Handle(RateLimitUsagePath, s)
+	server := &http.Server{Addr: s.Addr, Handler: mux}
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.Log.Infof("Serving rate limit usage", l.Fields{"addr": s.Addr, "path": RateLimitUsagePath})
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), rateLimitUsageTimeout)
+		defer cancel()
+		return server.Shutdown(ctx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection returns true, only the leader serves the usage
+func (s *RateLimitUsageServer) NeedLeaderElection() bool {
+	return true
+}
+
+// ServeHTTP reports the usage of the rate limits. The descriptor and consumer
+// query parameters filter the report, see RateLimitUsageReport.Filter
+func (s *RateLimitUsageServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), rateLimitUsageTimeout)
+	defer cancel()
+
+	if status, err := s.authorize(ctx, req); err != nil {
+		s.Log.Warningf("Rate limit usage request rejected", l.Fields{"reason": err.Error()})
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	report, status, err := s.getReport(ctx)
+	if err != nil {
+		s.Log.Error("Failed to report rate limit usage", err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+	report = report.Filter(req.URL.Query().Get("descriptor"), req.URL.Query().Get("consumer"))
+
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		s.Log.Error("Failed to write rate limit usage", err)
+	}
+}
+
+// authorize authenticates the bearer token of the request through a
+// TokenReview, and checks that its user can get RateLimitUsagePath through a
+// SubjectAccessReview, as kube-rbac-proxy does. It returns the HTTP status of
+// the rejection
+func (s *RateLimitUsageServer) authorize(ctx context.Context, req *http.Request) (int, error) {
+	authorization := req.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == "" || token == authorization {
+		return http.StatusUnauthorized, errors.New("no bearer token")
+	}
+
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if err := s.Client.Create(ctx, tokenReview); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to review token: %w", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, fmt.Errorf("invalid token: %s", tokenReview.Status.Error)
+	}
+
+	user := tokenReview.Status.User
+	extra := map[string]authorizationv1.ExtraValue{}
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	accessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: RateLimitUsagePath,
+				Verb: "get",
+			},
+		},
+	}
+	if err := s.Client.Create(ctx, accessReview); err != nil {
+		return http.StatusInternalServerError, fmt.Errorf("failed to review access: %w", err)
+	}
+	if !accessReview.Status.Allowed {
+		return http.StatusForbidden, fmt.Errorf("user %s is not allowed to get %s", user.Username, RateLimitUsagePath)
+	}
+
+	return http.StatusOK, nil
+}
+
+func (s *RateLimitUsageServer) getReport(ctx context.Context) (*RateLimitUsageReport, int, error) {
+	installation, err := rhmi.GetRhmiCr(s.Client, ctx, s.WatchNamespace, s.Log)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to get installation: %w", err)
+	}
+	if installation == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("installation not found in namespace %s", s.WatchNamespace)
+	}
+	namespace := installation.Spec.NamespacePrefix + defaultInstallationNamespace
+
+	config := &corev1.ConfigMap{}
+	if err := s.Client.Get(ctx, k8sclient.ObjectKey{Name: RateLimitingConfigMapName, Namespace: namespace}, config); err != nil {
+		return nil, http.StatusServiceUnavailable, fmt.Errorf("failed to get rate limit config: %w", err)
+	}
+	redisSecret, err := NewRateLimitServiceReconciler(marin3rconfig.RateLimitConfig{}, installation, namespace, externalRedisSecretName).
+		getRedisSecret(ctx, s.Client)
+	if err != nil {
+		return nil, http.StatusServiceUnavailable, fmt.Errorf("failed to get rate limit redis secret: %w", err)
+	}
+
+	report, err := GetRateLimitUsage(ctx, config, s.newCounters(string(redisSecret.Data["URL"])), s.now())
+	if err != nil {
+		return nil, http.StatusBadGateway, err
+	}
+	return report, http.StatusOK, nil
+}
+
+func limitedDescriptors(descriptors []yamlDescriptor, parentEntries []RateLimitUsageEntry) []limitedDescriptor {
+	result := []limitedDescriptor{}
+	for _, descriptor := range descriptors {
+		entries := append(append([]RateLimitUsageEntry{}, parentEntries...), RateLimitUsageEntry{
+			Key:   descriptor.Key,
+			Value: descriptor.Value,
+		})
+		if descriptor.RateLimit != nil {
+			result = append(result, limitedDescriptor{
+				entries:    entries,
+				rateLimit:  *descriptor.RateLimit,
+				shadowMode: descriptor.ShadowMode,
+			})
+		}
+		result = append(result, limitedDescriptors(descriptor.Descriptors, entries)...)
+	}
+	return result
+}
+
+// descriptorUsageEntries returns the entries of the usages of the
+// descriptor. A descriptor with a value for each entry has a single usage,
+// counted or not. A descriptor limited per value has a usage for each value
+// counted in the window
+func descriptorUsageEntries(domain string, entries []RateLimitUsageEntry, suffix string, counters map[string]uint64) [][]RateLimitUsageEntry {
+	if !hasEntryValue(entries, "") {
+		return [][]RateLimitUsageEntry{entries}
+	}
+
+	prefix := domain + "_"
+	result := [][]RateLimitUsageEntry{}
+	for key := range counters {
+		if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "_"+suffix) {
+			continue
+		}
+		matched, ok := matchCounterEntries(strings.TrimSuffix(strings.TrimPrefix(key, prefix), suffix), entries)
+		if ok {
+			result = append(result, matched)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return descriptorName(result[i]) < descriptorName(result[j])
+	})
+	return result
+}
+
+// matchCounterEntries matches the <key>_<value>_... part of a counter key to
+// the entries, returning them with the values of the key
+func matchCounterEntries(s string, entries []RateLimitUsageEntry) ([]RateLimitUsageEntry, bool) {
+	if len(entries) == 0 {
+		return []RateLimitUsageEntry{}, s == ""
+	}
+
+	entry := entries[0]
+	if !strings.HasPrefix(s, entry.Key+"_") {
+		return nil, false
+	}
+	s = strings.TrimPrefix(s, entry.Key+"_")
+
+	if entry.Value != "" {
+		if !strings.HasPrefix(s, entry.Value+"_") {
+			return nil, false
+		}
+		rest, ok := matchCounterEntries(strings.TrimPrefix(s, entry.Value+"_"), entries[1:])
+		if !ok {
+			return nil, false
+		}
+		return append([]RateLimitUsageEntry{entry}, rest...), true
+	}
+
+	// The values may contain _, try every value that leaves a match for
+	// the remaining entries
+	for i := 0; i < len(s); i++ {
+		if s[i] != '_' {
+			continue
+		}
+		rest, ok := matchCounterEntries(s[i+1:], entries[1:])
+		if ok {
+			return append([]RateLimitUsageEntry{{Key: entry.Key, Value: s[:i]}}, rest...), true
+		}
+	}
+	return nil, false
+}
+
+func counterKey(domain string, entries []RateLimitUsageEntry, suffix string) string {
+	b := &strings.Builder{}
+	b.WriteString(domain)
+	b.WriteByte('_')
+	for _, entry := range entries {
+		b.WriteString(entry.Key)
+		b.WriteByte('_')
+		b.WriteString(entry.Value)
+		b.WriteByte('_')
+	}
+	b.WriteString(suffix)
+	return b.String()
+}
+
+func descriptorName(entries []RateLimitUsageEntry) string {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		parts = append(parts, fmt.Sprintf("%s=%s", entry.Key, entry.Value))
+	}
+	return strings.Join(parts, "/")
+}
+
+func hasEntryValue(entries []RateLimitUsageEntry, value string) bool {
+	for _, entry := range entries {
+		if entry.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func unitToSeconds(unit string) (int64, bool) {
+	switch unit {
+	case marin3rconfig.Second:
+		return 1, true
+	case marin3rconfig.Minute:
+		return 60, true
+	case marin3rconfig.Hour:
+		return 60 * 60, true
+	case marin3rconfig.Day:
+		return 60 * 60 * 24, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,294 @@
+package marin3r
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"gopkg.in/yaml.v2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type fakeRateLimitCounters map[string]uint64
+
+func (c fakeRateLimitCounters) GetCounters(_ context.Context, _ string) (map[string]uint64, error) {
+	return c, nil
+}
+
+// reviewingClient answers the token and access reviews of the usage server:
+// tokens authenticate the user of the same name, and only allowedUser can
+// get the usage
+type reviewingClient struct {
+	k8sclient.Client
+	allowedUser string
+}
+
+func (c *reviewingClient) Create(ctx context.Context, obj runtime.Object, opts ...k8sclient.CreateOption) error {
+	switch review := obj.(type) {
+	case *authenticationv1.TokenReview:
+		if review.Spec.Token != "invalid" {
+			review.Status.Authenticated = true
+			review.Status.User.Username = review.Spec.Token
+		}
+		return nil
+	case *authorizationv1.SubjectAccessReview:
+		attributes := review.Spec.NonResourceAttributes
+		review.Status.Allowed = review.Spec.User == c.allowedUser &&
+			attributes != nil && attributes.Path == RateLimitUsagePath && attributes.Verb == "get"
+		return nil
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func getRateLimitUsageConfigMap(t *testing.T, namespace string) *corev1.ConfigMap {
+	config, err := yaml.Marshal(yamlRoot{
+		Domain: "apicast-ratelimit",
+		Descriptors: []yamlDescriptor{
+			{
+				Key:       "generic_key",
+				Value:     "slowpath",
+				RateLimit: &yamlRateLimit{Unit: "minute", RequestsPerUnit: 100},
+			},
+			{
+				Key:   "tenant-a",
+				Value: "tenant-a",
+				Descriptors: []yamlDescriptor{
+					{
+						Key:        "per_client",
+						RateLimit:  &yamlRateLimit{Unit: "second", RequestsPerUnit: 5},
+						ShadowMode: true,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal rate limit config: %v", err)
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RateLimitingConfigMapName,
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			RateLimitingConfigMapDataName: string(config),
+		},
+	}
+}
+
+func TestGetRateLimitUsage(t *testing.T) {
+	now := time.Unix(1600000030, 0)
+
+	counters := fakeRateLimitCounters{
+		"apicast-ratelimit_generic_key_slowpath_1600000020":                    120,
+		"apicast-ratelimit_generic_key_slowpath_1599999960":                    80,
+		"apicast-ratelimit_tenant-a_tenant-a_per_client_client_1_1600000030":   3,
+		"apicast-ratelimit_tenant-a_tenant-a_per_client_client-2_1600000030":   1,
+		"apicast-ratelimit_tenant-a_tenant-a_per_client_client-old_1600000029": 2,
+	}
+
+	report, err := GetRateLimitUsage(context.TODO(), getRateLimitUsageConfigMap(t, "redhat-test-marin3r"), counters, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []RateLimitUsage{
+		{
+			Descriptor: "generic_key=slowpath",
+			Entries:    []RateLimitUsageEntry{{Key: "generic_key", Value: "slowpath"}},
+			Unit:       "minute",
+			Limit:      100,
+			Used:       120,
+			Remaining:  0,
+			ResetTime:  time.Unix(1600000080, 0).UTC(),
+		},
+		{
+			Descriptor: "tenant-a=tenant-a/per_client=client-2",
+			Entries:    []RateLimitUsageEntry{{Key: "tenant-a", Value: "tenant-a"}, {Key: "per_client", Value: "client-2"}},
+			Unit:       "second",
+			Limit:      5,
+			Used:       1,
+			Remaining:  4,
+			ResetTime:  time.Unix(1600000031, 0).UTC(),
+			ShadowMode: true,
+		},
+		{
+			Descriptor: "tenant-a=tenant-a/per_client=client_1",
+			Entries:    []RateLimitUsageEntry{{Key: "tenant-a", Value: "tenant-a"}, {Key: "per_client", Value: "client_1"}},
+			Unit:       "second",
+			Limit:      5,
+			Used:       3,
+			Remaining:  2,
+			ResetTime:  time.Unix(1600000031, 0).UTC(),
+			ShadowMode: true,
+		},
+	}
+	if report.Domain != "apicast-ratelimit" {
+		t.Errorf("expected domain apicast-ratelimit, got %s", report.Domain)
+	}
+	if !reflect.DeepEqual(report.Descriptors, expected) {
+		t.Errorf("unexpected usage.\nExpected: %+v\nGot: %+v", expected, report.Descriptors)
+	}
+
+	filtered := report.Filter("tenant-a=", "client_1")
+	if len(filtered.Descriptors) != 1 || filtered.Descriptors[0].Descriptor != "tenant-a=tenant-a/per_client=client_1" {
+		t.Errorf("unexpected filtered usage %+v", filtered.Descriptors)
+	}
+}
+
+func TestRateLimitUsageServer(t *testing.T) {
+	scheme := newScheme()
+	if err := integreatlyv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+
+	installation := &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rhoam",
+			Namespace: "redhat-test-operator",
+		},
+		Spec: integreatlyv1alpha1.RHMISpec{
+			NamespacePrefix: "redhat-test-",
+		},
+	}
+	redisSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis",
+			Namespace: "redhat-test-marin3r",
+		},
+		Data: map[string][]byte{
+			"URL": []byte("redis:6379"),
+		},
+	}
+
+	scenarios := []struct {
+		Name           string
+		InitObjs       []runtime.Object
+		Method         string
+		Query          string
+		Token          string
+		ExpectedStatus int
+		Assert         func(*RateLimitUsageReport) error
+	}{
+		{
+			Name:           "Usage of the installation",
+			InitObjs:       []runtime.Object{installation, redisSecret, getRateLimitUsageConfigMap(t, "redhat-test-marin3r")},
+			Method:         http.MethodGet,
+			Token:          "usage-reader",
+			ExpectedStatus: http.StatusOK,
+			Assert: func(report *RateLimitUsageReport) error {
+				if len(report.Descriptors) != 2 {
+					return fmt.Errorf("expected 2 usages, got %+v", report.Descriptors)
+				}
+				return nil
+			},
+		},
+		{
+			Name:           "Usage of a consumer",
+			InitObjs:       []runtime.Object{installation, redisSecret, getRateLimitUsageConfigMap(t, "redhat-test-marin3r")},
+			Method:         http.MethodGet,
+			Token:          "usage-reader",
+			Query:          "?consumer=client-1",
+			ExpectedStatus: http.StatusOK,
+			Assert: func(report *RateLimitUsageReport) error {
+				if len(report.Descriptors) != 1 || report.Descriptors[0].Used != 3 {
+					return fmt.Errorf("expected the usage of client-1, got %+v", report.Descriptors)
+				}
+				return nil
+			},
+		},
+		{
+			Name:           "Rate limit service not deployed",
+			InitObjs:       []runtime.Object{installation},
+			Method:         http.MethodGet,
+			Token:          "usage-reader",
+			ExpectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			Name:           "No installation",
+			Method:         http.MethodGet,
+			Token:          "usage-reader",
+			ExpectedStatus: http.StatusNotFound,
+		},
+		{
+			Name:           "No token",
+			InitObjs:       []runtime.Object{installation, redisSecret, getRateLimitUsageConfigMap(t, "redhat-test-marin3r")},
+			Method:         http.MethodGet,
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "Invalid token",
+			InitObjs:       []runtime.Object{installation, redisSecret, getRateLimitUsageConfigMap(t, "redhat-test-marin3r")},
+			Method:         http.MethodGet,
+			Token:          "invalid",
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		{
+			Name:           "User not allowed",
+			InitObjs:       []runtime.Object{installation, redisSecret, getRateLimitUsageConfigMap(t, "redhat-test-marin3r")},
+			Method:         http.MethodGet,
+			Token:          "developer",
+			ExpectedStatus: http.StatusForbidden,
+		},
+		{
+			Name:           "Method not allowed",
+			Method:         http.MethodPost,
+			ExpectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		t.Run(scenario.Name, func(t *testing.T) {
+			client := &reviewingClient{
+				Client:      fake.NewFakeClientWithScheme(scheme, scenario.InitObjs...),
+				allowedUser: "usage-reader",
+			}
+			server := NewRateLimitUsageServer(":0", client, "redhat-test-operator", getLogger())
+			server.now = func() time.Time { return time.Unix(1600000030, 0) }
+			server.newCounters = func(redisURL string) RateLimitCounters {
+				if redisURL != "redis:6379" {
+					t.Errorf("unexpected redis URL %s", redisURL)
+				}
+				return fakeRateLimitCounters{
+					"apicast-ratelimit_generic_key_slowpath_1600000020":                  10,
+					"apicast-ratelimit_tenant-a_tenant-a_per_client_client-1_1600000030": 3,
+				}
+			}
+
+			request := httptest.NewRequest(scenario.Method, RateLimitUsagePath+scenario.Query, nil)
+			if scenario.Token != "" {
+				request.Header.Set("Authorization", "Bearer "+scenario.Token)
+			}
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, request)
+
+			if recorder.Code != scenario.ExpectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", scenario.ExpectedStatus, recorder.Code, recorder.Body.String())
+			}
+			if scenario.Assert == nil {
+				return
+			}
+
+			report := &RateLimitUsageReport{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), report); err != nil {
+				t.Fatalf("invalid usage report: %v", err)
+			}
+			if err := scenario.Assert(report); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
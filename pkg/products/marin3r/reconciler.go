@@ -34,7 +34,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -61,13 +60,9 @@ type Reconciler struct {
 	recorder        record.EventRecorder
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductMarin3r],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductMarin3r),
 		string(integreatlyv1alpha1.VersionMarin3r),
 		string(integreatlyv1alpha1.OperatorVersionMarin3r),
 	)
@@ -113,46 +108,35 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	productNamespace := r.Config.GetNamespace()
 
 	phase, err := r.ReconcileFinalizer(ctx, client, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		threescaleConfig, err := r.ConfigManager.ReadThreeScale()
-		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, errors.Wrap(err, "could not read 3scale config from marin3r reconciler")
-		}
-
-		enabledNamespaces := []string{threescaleConfig.GetNamespace()}
-		phase, err := ratelimit.DeleteEnvoyConfigsInNamespaces(ctx, client, enabledNamespaces...)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		if err := r.deleteDiscoveryService(ctx, client); err != nil {
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete discovery service: %v", err)
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, client, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, client)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
 		return phase, err
 	}
 	r.RateLimitConfig = productConfig.GetRateLimitConfig()
+	if err := r.RateLimitConfig.ValidateWindows(); err != nil {
+		events.HandleError(r.recorder, installation, phase, "Invalid rate limit windows", err)
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
 
 	alertsConfig, err := marin3rconfig.GetAlertConfig(ctx, client, r.installation.Namespace)
 	if err != nil {
 		events.HandleError(r.recorder, installation, phase, "Failed to obtain rate limit alerts config", err)
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
+	// The alerts of the quota override the alerts of the same name
+	for name, alert := range productConfig.GetAlertLimits() {
+		alertsConfig[name] = alert
+	}
 	r.AlertsConfig = alertsConfig
 
+	descriptors, err := marin3rconfig.GetDescriptorsConfig(ctx, client, r.installation.Namespace)
+	if err != nil {
+		events.HandleError(r.recorder, installation, phase, "Failed to obtain rate limit descriptors config", err)
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
 	phase, err = r.ReconcileNamespace(ctx, operatorNamespace, installation, client, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, fmt.Sprintf("Failed to reconcile %s ns", operatorNamespace), err)
@@ -204,6 +188,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 
 	phase, err = NewRateLimitServiceReconciler(r.RateLimitConfig, installation, productNamespace, externalRedisSecretName).
 		WithStatsdConfig(statsdConfig).
+		WithDescriptors(descriptors).
 		ReconcileRateLimitService(ctx, client, productConfig)
 	if err != nil {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile rate limit service", err)
@@ -251,6 +236,40 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the envoy configs of the 3scale namespace, the discovery
+// service and the marin3r namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, client k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	threescaleConfig, err := r.ConfigManager.ReadThreeScale()
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, errors.Wrap(err, "could not read 3scale config from marin3r reconciler")
+	}
+
+	enabledNamespaces := []string{threescaleConfig.GetNamespace()}
+	phase, err := ratelimit.DeleteEnvoyConfigsInNamespaces(ctx, client, enabledNamespaces...)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	if err := r.deleteDiscoveryService(ctx, client); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete discovery service: %v", err)
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, client, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, client, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileAlerts(ctx context.Context, client k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
 
 	grafanaConsoleURL, err := grafana.GetGrafanaConsoleURL(ctx, client, installation)
@@ -416,25 +435,40 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, serverClient k8s
 		ctx,
 		target,
 		[]string{},
-		r.preUpgradeBackupExecutor(),
+		r.PreUpgradeBackupExecutor(),
 		serverClient,
 		catalogSourceReconciler,
 		r.log,
 	)
 }
 
-func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
-	if r.installation.Spec.UseClusterStorage != "false" {
-		return backup.NewNoopBackupExecutor()
-	}
-
-	return backup.NewAWSBackupExecutor(
-		r.installation.Namespace,
+// PreUpgradeBackupExecutor returns the backup of the rate limit redis,
+// taken before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
+	return backup.NewInstallationBackupExecutor(
+		r.installation,
 		fmt.Sprintf("%s%s", constants.RateLimitRedisPrefix, r.installation.Name),
 		backup.RedisSnapshotType,
 	)
 }
 
+// BackupSanityChecks returns the check run against the rate limit redis
+// restored to verify its backups, which holds the counters of the rate limit
+// service while 3scale receives requests
+func (r *Reconciler) BackupSanityChecks() map[string]integreatlyv1alpha1.BackupSanityCheck {
+	return map[string]integreatlyv1alpha1.BackupSanityCheck{
+		fmt.Sprintf("%s%s", constants.RateLimitRedisPrefix, r.installation.Name): {Query: "DBSIZE", Min: 1},
+	}
+}
+
+// RestoreWorkloads returns the rate limit service, stopped while its redis is
+// restored by RHMIRestore
+func (r *Reconciler) RestoreWorkloads() []backup.Workload {
+	return []backup.Workload{
+		backup.NewDeploymentWorkload(quota.RateLimitName, r.Config.GetNamespace(), map[string]string{"app": quota.RateLimitName}),
+	}
+}
+
 func (r *Reconciler) reconcilePromStatsdExporter(ctx context.Context, client k8sclient.Client, namespace string) (integreatlyv1alpha1.StatusPhase, error) {
 	r.log.Info("Start reconcilePromStatsdExporter for marin3r")
 
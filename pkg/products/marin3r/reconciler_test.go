@@ -2,6 +2,7 @@ package marin3r
 
 import (
 	"context"
+	"fmt"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
 	"testing"
@@ -203,6 +204,58 @@ func TestAlertCreation(t *testing.T) {
 			},
 			want: integreatlyv1alpha1.PhaseInProgress,
 		},
+		{
+			name: "returns alerts for each rate limit window",
+			serverClient: func() k8sclient.Client {
+				return fakeclient.NewFakeClientWithScheme(scheme, getRateLimitConfigMap(), getGrafanaRoute())
+			},
+			reconciler: func() *Reconciler {
+				reconciler := getBasicReconciler()
+				reconciler.RateLimitConfig.Windows = []marin3rconfig.RateLimitWindow{
+					{Unit: "second", RequestsPerUnit: 100},
+					{Unit: "day", RequestsPerUnit: 5000000},
+				}
+				return reconciler
+			},
+			want: integreatlyv1alpha1.PhaseCompleted,
+			wantFn: func(c k8sclient.Client) error {
+				expectedRules := map[string]struct {
+					expr   string
+					window string
+				}{
+					"rate-limit-spike": {
+						expr: "max_over_time((increase(ratelimit_service_rate_limit_apicast_ratelimit_generic_key_slowpath_total_hits[1m]))[30m:]) > 1",
+					},
+					"rate-limit-spike-second": {
+						expr:   "max_over_time((increase(ratelimit_service_rate_limit_apicast_ratelimit_generic_key_slowpath_second_total_hits[1m]))[30m:]) > 6000",
+						window: "second",
+					},
+					"rate-limit-spike-day": {
+						expr:   "max_over_time((increase(ratelimit_service_rate_limit_apicast_ratelimit_generic_key_slowpath_day_total_hits[1d]))[30m:]) > 5000000",
+						window: "day",
+					},
+					"api-usage-alert-level3-second": {
+						expr:   "(sum(increase(ratelimit_service_rate_limit_apicast_ratelimit_generic_key_slowpath_second_total_hits[30m]) >= (180000.000000 / 100 * 95)))",
+						window: "second",
+					},
+				}
+
+				for name, expected := range expectedRules {
+					rule := &prometheusmonitoringv1.PrometheusRule{}
+					if err := c.Get(context.TODO(), k8sclient.ObjectKey{Name: name, Namespace: defaultInstallationNamespace}, rule); err != nil {
+						return fmt.Errorf("failed to get rule %s: %w", name, err)
+					}
+					alert := rule.Spec.Groups[0].Rules[0]
+					if alert.Expr.String() != expected.expr {
+						return fmt.Errorf("unexpected expression of rule %s: %s", name, alert.Expr.String())
+					}
+					if alert.Labels["window"] != expected.window {
+						return fmt.Errorf("unexpected window of rule %s: %s", name, alert.Labels["window"])
+					}
+				}
+				return nil
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
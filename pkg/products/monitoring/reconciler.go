@@ -96,10 +96,6 @@ type Reconciler struct {
 	recorder record.EventRecorder
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
 func NewReconciler(configManager config.ConfigReadWriter, installation *integreatlyv1alpha1.RHMI, mpm marketplace.MarketplaceInterface, recorder record.EventRecorder, logger l.Logger, productDeclaration *marketplace.ProductDeclaration) (*Reconciler, error) {
 	if productDeclaration == nil {
 		return nil, fmt.Errorf("no product declaration found for monitoring")
@@ -139,9 +135,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.MonitoringStage].Products[integreatlyv1alpha1.ProductMonitoring],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductMonitoring),
 		string(integreatlyv1alpha1.VersionMonitoring),
 		string(integreatlyv1alpha1.OperatorVersionMonitoring),
 	)
@@ -150,74 +146,7 @@ func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool
 func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1alpha1.RHMI, product *integreatlyv1alpha1.RHMIProductStatus, serverClient k8sclient.Client, _ quota.ProductConfig) (integreatlyv1alpha1.StatusPhase, error) {
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		r.Log.Info("Phase: Monitoring ReconcileFinalizer")
-		// Check if namespace is still present before trying to delete it resources
-		_, err := resources.GetNS(ctx, operatorNamespace, serverClient)
-		if k8serr.IsNotFound(err) {
-			//namespace is gone, return complete
-			return integreatlyv1alpha1.PhaseCompleted, nil
-		}
-
-		r.Log.Info("Phase: Monitoring ReconcileFinalizer list blackboxtargets")
-		blackboxtargets := &monitoring.BlackboxTargetList{}
-		blackboxtargetsListOpts := []k8sclient.ListOption{
-			k8sclient.MatchingLabels(map[string]string{r.Config.GetLabelSelectorKey(): r.Config.GetLabelSelector()}),
-		}
-		err = serverClient.List(ctx, blackboxtargets, blackboxtargetsListOpts...)
-		if err != nil {
-			r.Log.Info("Phase: Monitoring ReconcileFinalizer blackboxtargets error")
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list blackbox targets: %w", err)
-		}
-		if len(blackboxtargets.Items) > 0 {
-			r.Log.Info("Phase: Monitoring ReconcileFinalizer blackboxtargets list > 0")
-			// do something to delete these dashboards
-			for _, bbt := range blackboxtargets.Items {
-				r.Log.Infof("Phase: Monitoring ReconcileFinalizer try delete blackboxtarget", l.Fields{"target": bbt.Name})
-				b := &monitoring.BlackboxTarget{}
-				err = serverClient.Get(ctx, k8sclient.ObjectKey{Name: bbt.Name, Namespace: operatorNamespace}, b)
-				if k8serr.IsNotFound(err) {
-					continue
-				}
-				if err != nil {
-					return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("Failed to get %s blackbox target: %w", bbt.Name, err)
-				}
-
-				err = serverClient.Delete(ctx, b)
-				if err != nil && !k8serr.IsNotFound(err) {
-					return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete %s blackbox target: %w", b.Name, err)
-				}
-			}
-			return integreatlyv1alpha1.PhaseInProgress, nil
-		}
-
-		m := &monitoring.ApplicationMonitoring{}
-		err = serverClient.Get(ctx, k8sclient.ObjectKey{Name: defaultMonitoringName, Namespace: operatorNamespace}, m)
-		if err != nil && !k8serr.IsNotFound(err) {
-			r.Log.Info("Phase: Monitoring ReconcileFinalizer error fetch ApplicationMonitoring CR")
-			return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get %s application monitoring custom resource: %w", defaultMonitoringName, err)
-		}
-		if !k8serr.IsNotFound(err) {
-			if m.DeletionTimestamp == nil {
-				r.Log.Info("Phase: Monitoring ReconcileFinalizer delete ApplicationMonitoring CR")
-				err = serverClient.Delete(ctx, m)
-				if err != nil && !k8serr.IsNotFound(err) {
-					return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete %s application monitoring custom resource: %w", defaultMonitoringName, err)
-				}
-			}
-			return integreatlyv1alpha1.PhaseInProgress, nil
-		}
-
-		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, r.Config.GetFederationNamespace(), r.Log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.Log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		return integreatlyv1alpha1.PhaseInProgress, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.Log)
 
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
@@ -345,6 +274,81 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the blackbox targets and the application monitoring
+// resource, then the monitoring namespaces
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+
+	r.Log.Info("Phase: Monitoring ReconcileFinalizer")
+	// Check if namespace is still present before trying to delete it resources
+	_, err := resources.GetNS(ctx, operatorNamespace, serverClient)
+	if k8serr.IsNotFound(err) {
+		//namespace is gone, return complete
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+
+	r.Log.Info("Phase: Monitoring ReconcileFinalizer list blackboxtargets")
+	blackboxtargets := &monitoring.BlackboxTargetList{}
+	blackboxtargetsListOpts := []k8sclient.ListOption{
+		k8sclient.MatchingLabels(map[string]string{r.Config.GetLabelSelectorKey(): r.Config.GetLabelSelector()}),
+	}
+	err = serverClient.List(ctx, blackboxtargets, blackboxtargetsListOpts...)
+	if err != nil {
+		r.Log.Info("Phase: Monitoring ReconcileFinalizer blackboxtargets error")
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to list blackbox targets: %w", err)
+	}
+	if len(blackboxtargets.Items) > 0 {
+		r.Log.Info("Phase: Monitoring ReconcileFinalizer blackboxtargets list > 0")
+		// do something to delete these dashboards
+		for _, bbt := range blackboxtargets.Items {
+			r.Log.Infof("Phase: Monitoring ReconcileFinalizer try delete blackboxtarget", l.Fields{"target": bbt.Name})
+			b := &monitoring.BlackboxTarget{}
+			err = serverClient.Get(ctx, k8sclient.ObjectKey{Name: bbt.Name, Namespace: operatorNamespace}, b)
+			if k8serr.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("Failed to get %s blackbox target: %w", bbt.Name, err)
+			}
+
+			err = serverClient.Delete(ctx, b)
+			if err != nil && !k8serr.IsNotFound(err) {
+				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete %s blackbox target: %w", b.Name, err)
+			}
+		}
+		return integreatlyv1alpha1.PhaseInProgress, nil
+	}
+
+	m := &monitoring.ApplicationMonitoring{}
+	err = serverClient.Get(ctx, k8sclient.ObjectKey{Name: defaultMonitoringName, Namespace: operatorNamespace}, m)
+	if err != nil && !k8serr.IsNotFound(err) {
+		r.Log.Info("Phase: Monitoring ReconcileFinalizer error fetch ApplicationMonitoring CR")
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to get %s application monitoring custom resource: %w", defaultMonitoringName, err)
+	}
+	if !k8serr.IsNotFound(err) {
+		if m.DeletionTimestamp == nil {
+			r.Log.Info("Phase: Monitoring ReconcileFinalizer delete ApplicationMonitoring CR")
+			err = serverClient.Delete(ctx, m)
+			if err != nil && !k8serr.IsNotFound(err) {
+				return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to delete %s application monitoring custom resource: %w", defaultMonitoringName, err)
+			}
+		}
+		return integreatlyv1alpha1.PhaseInProgress, nil
+	}
+
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, r.Config.GetFederationNamespace(), r.Log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.Log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	return integreatlyv1alpha1.PhaseInProgress, nil
+}
+
 // make the federation namespace discoverable by cluster monitoring
 func (r *Reconciler) createFederationNamespace(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
 	namespace, err := resources.GetNS(ctx, r.Config.GetFederationNamespace(), serverClient)
@@ -22,7 +22,6 @@ import (
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -52,13 +51,9 @@ type Reconciler struct {
 	recorder record.EventRecorder
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
-	return nil
-}
-
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.MonitoringStage].Products[integreatlyv1alpha1.ProductMonitoringSpec],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductMonitoringSpec),
 		string(integreatlyv1alpha1.VersionMonitoringSpec),
 		"",
 	)
@@ -90,27 +85,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 // Reconcile method for monitorspec
 func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1alpha1.RHMI,
 	product *integreatlyv1alpha1.RHMIProductStatus, serverClient k8sclient.Client, _ quota.ProductConfig) (integreatlyv1alpha1.StatusPhase, error) {
-	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()),
-		func() (integreatlyv1alpha1.StatusPhase, error) {
-			r.Log.Info("Phase: Monitoringspec ReconcileFinalizer")
-
-			// Check if namespace is still present before trying to delete it resources
-			_, err := resources.GetNS(ctx, r.Config.GetNamespace(), serverClient)
-			if err != nil && k8serr.IsNotFound(err) {
-				r.Log.Info("Spec phase completed")
-				//namespace is gone, return complete
-				return integreatlyv1alpha1.PhaseCompleted, nil
-			}
-			phase, err := resources.RemoveNamespace(ctx, installation, serverClient, r.Config.GetNamespace(), r.Log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				if err != nil {
-					r.Log.Error("Spec phase removal failure", err)
-				}
-				return phase, err
-			}
-			return integreatlyv1alpha1.PhaseInProgress, nil
-		},
-		r.Log)
+	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
+		return r.Uninstall(ctx, installation, serverClient)
+	}, r.Log)
 
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		if err != nil {
@@ -155,6 +132,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the monitoring spec namespace
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	r.Log.Info("Phase: Monitoringspec ReconcileFinalizer")
+
+	// Check if namespace is still present before trying to delete it resources
+	_, err := resources.GetNS(ctx, r.Config.GetNamespace(), serverClient)
+	if err != nil && k8serr.IsNotFound(err) {
+		r.Log.Info("Spec phase completed")
+		//namespace is gone, return complete
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, r.Config.GetNamespace(), r.Log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		if err != nil {
+			r.Log.Error("Spec phase removal failure", err)
+		}
+		return phase, err
+	}
+	return integreatlyv1alpha1.PhaseInProgress, nil
+}
+
 // make the federation namespace discoverable by cluster monitoring
 func (r *Reconciler) createNamespace(ctx context.Context, serverClient k8sclient.Client,
 	installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+
+	oauthClient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//go:generate moq -out Reconciler_moq.go . Interface
+
+// Interface is implemented by the reconciler of each product
+type Interface interface {
+	//Reconcile is the primary entry point of your reconciler, on each reconcile loop of the integreatly-operator,
+	//all the logic in here should be written with the assumption that resources may or may not exist, and can
+	//be created or updated based on their current state.
+	//
+	//## Parameters
+	//There are several parameters passed into this function:
+	//
+	//### ctx
+	// This must be used in all network requests performed by the reconciler, as the integreatly-operator maintains this context
+	// and may kill it if an uninstall is detected.
+	//
+	//### installation
+	//This is the CR we are basing the install from, it has values that are occasionally required by reconcilers, for example
+	//the namespace prefix.
+	//
+	//### product
+	//This is a pointer to the this reconciler's product in the status block of the CR, it can be used to set values
+	//such as version, host and operator version.
+	//### serverClient
+	//This is the client to the cluster, and is used for getting, creating, updating and deleting resources in the cluster.
+	//
+	//## Return Values
+	//The return values from this method are `state` and `err`:
+	//
+	//### State
+	//This is communicated back to the user via the status block of the RHMI CR, this is usually either in progress
+	//or complete. It can go to `fail` if something has broken, but this will not prevent the installation_controller
+	//from calling the Reconcile function in the future, which may allow the reconciler to fix whatever issue had
+	//occurred (i.e. the service had not come up yet, so there were network errors accessing it's API).
+	//
+	//### Err
+	//This is how we can communicate to the user via the status block of the RHMI CR what is causing a product to
+	//enter a failed state, and is written into the `status.lastError` of the CR along with any other errors from
+	//other reconcilers.
+	Reconcile(ctx context.Context, installation *integreatlyv1alpha1.RHMI, product *integreatlyv1alpha1.RHMIProductStatus, serverClient k8sclient.Client, productConfig quota.ProductConfig) (newPhase integreatlyv1alpha1.StatusPhase, err error)
+}
+
+// ReconcilerOptions are the dependencies shared by every product reconciler,
+// they are built by products.NewReconciler and passed to the product factory
+type ReconcilerOptions struct {
+	RestConfig         *rest.Config
+	ConfigManager      config.ConfigReadWriter
+	Installation       *integreatlyv1alpha1.RHMI
+	MPM                marketplace.MarketplaceInterface
+	Recorder           record.EventRecorder
+	OauthResolver      *resources.OauthResolver
+	ProductDeclaration *marketplace.ProductDeclaration
+	Log                l.Logger
+
+	// KeycloakClientFactory builds the clients of the Keycloak API
+	KeycloakClientFactory keycloakCommon.KeycloakClientFactory
+	// WrapTransport, if set, wraps the transport of the HTTP clients of the
+	// product APIs, such as the 3scale API
+	WrapTransport func(rt http.RoundTripper) http.RoundTripper
+}
+
+// HTTPClient returns an HTTP client for the APIs of the product, with the
+// transport wrapped by WrapTransport
+func (o *ReconcilerOptions) HTTPClient(client *http.Client) *http.Client {
+	if o.WrapTransport != nil {
+		client.Transport = o.WrapTransport(client.Transport)
+	}
+	return client
+}
+
+// OauthClient returns a client of the OpenShift OAuth API
+func (o *ReconcilerOptions) OauthClient() (oauthClient.OauthV1Interface, error) {
+	oauthv1Client, err := oauthClient.NewForConfig(o.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	oauthv1Client.RESTClient().(*rest.RESTClient).Client.Timeout = 10 * time.Second
+	return oauthv1Client, nil
+}
+
+// ReconcilerFactory builds the reconciler of a product
+type ReconcilerFactory func(opts *ReconcilerOptions) (Interface, error)
+
+// Registration describes a product the operator can reconcile
+type Registration struct {
+	Factory ReconcilerFactory
+
+	// Dependencies are the products that must be installed before the
+	// product is reconciled
+	Dependencies []integreatlyv1alpha1.ProductName
+
+	// PreflightObject returns the object that shows the product is already
+	// installed in the namespace ns, outside of the installation. For
+	// example, codeready looks for a deployment named "codeready", and the
+	// installation stalls until that product is removed. The product isn't
+	// checked when it's nil, or returns nil
+	PreflightObject func(ns string) runtime.Object
+
+	// VerifyVersion returns true if the versions of the product installed
+	// are the ones defined in the operator. The versions aren't checked
+	// when it's nil
+	VerifyVersion func(installation *integreatlyv1alpha1.RHMI) bool
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[integreatlyv1alpha1.ProductName]Registration{}
+)
+
+// Register makes a product available to the installation stages. It is
+// meant to be called from an init function of the product package, and
+// panics if the product is registered twice or has no factory. The package
+// must be imported by the operator for its init function to run, as the
+// built-in products are in pkg/products
+func Register(product integreatlyv1alpha1.ProductName, registration Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if registration.Factory == nil {
+		panic(fmt.Sprintf("products: no reconciler factory for product %s", product))
+	}
+	if _, ok := registry[product]; ok {
+		panic(fmt.Sprintf("products: product %s is already registered", product))
+	}
+	registry[product] = registration
+}
+
+// Get returns the registration of the product
+func Get(product integreatlyv1alpha1.ProductName) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	registration, ok := registry[product]
+	return registration, ok
+}
+
+// Products returns the names of the registered products, sorted
+func Products() []integreatlyv1alpha1.ProductName {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]integreatlyv1alpha1.ProductName, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// Registrations returns a copy of the registrations of every product
+func Registrations() map[integreatlyv1alpha1.ProductName]Registration {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	registrations := make(map[integreatlyv1alpha1.ProductName]Registration, len(registry))
+	for name, registration := range registry {
+		registrations[name] = registration
+	}
+	return registrations
+}
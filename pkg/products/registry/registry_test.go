@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+func TestRegister(t *testing.T) {
+	addon := integreatlyv1alpha1.ProductName("test-addon")
+	registration := Registration{
+		Factory: func(opts *ReconcilerOptions) (Interface, error) {
+			return &InterfaceMock{}, nil
+		},
+		Dependencies: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
+	}
+
+	Register(addon, registration)
+
+	got, ok := Get(addon)
+	if !ok {
+		t.Fatalf("expected product %s to be registered", addon)
+	}
+	if len(got.Dependencies) != 1 || got.Dependencies[0] != integreatlyv1alpha1.ProductRHSSO {
+		t.Fatalf("unexpected dependencies %v", got.Dependencies)
+	}
+	reconciler, err := got.Factory(&ReconcilerOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error building the reconciler: %v", err)
+	}
+	if _, ok := reconciler.(*InterfaceMock); !ok {
+		t.Fatalf("expected the reconciler of the registered factory, got %T", reconciler)
+	}
+
+	found := false
+	for _, product := range Products() {
+		if product == addon {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected %s in the registered products", addon)
+	}
+
+	if _, ok := Get("not-registered"); ok {
+		t.Fatal("expected no registration for an unknown product")
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	factory := func(opts *ReconcilerOptions) (Interface, error) {
+		return &InterfaceMock{}, nil
+	}
+	Register("test-duplicate", Registration{Factory: factory})
+
+	cases := []struct {
+		Name         string
+		Product      integreatlyv1alpha1.ProductName
+		Registration Registration
+	}{
+		{
+			Name:         "test duplicate product registration panics",
+			Product:      "test-duplicate",
+			Registration: Registration{Factory: factory},
+		},
+		{
+			Name:         "test registration without factory panics",
+			Product:      "test-no-factory",
+			Registration: Registration{},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected Register to panic")
+				}
+			}()
+			Register(tc.Product, tc.Registration)
+		})
+	}
+}
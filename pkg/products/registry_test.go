@@ -0,0 +1,102 @@
+package products
+
+import (
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestBuiltinProductsRegistered(t *testing.T) {
+	builtin := []integreatlyv1alpha1.ProductName{
+		integreatlyv1alpha1.Product3Scale,
+		integreatlyv1alpha1.ProductAMQOnline,
+		integreatlyv1alpha1.ProductAMQStreams,
+		integreatlyv1alpha1.ProductApicurioRegistry,
+		integreatlyv1alpha1.ProductApicurito,
+		integreatlyv1alpha1.ProductCloudResources,
+		integreatlyv1alpha1.ProductCodeReadyWorkspaces,
+		integreatlyv1alpha1.ProductDataSync,
+		integreatlyv1alpha1.ProductFuse,
+		integreatlyv1alpha1.ProductFuseOnOpenshift,
+		integreatlyv1alpha1.ProductGrafana,
+		integreatlyv1alpha1.ProductMarin3r,
+		integreatlyv1alpha1.ProductMonitoring,
+		integreatlyv1alpha1.ProductMonitoringSpec,
+		integreatlyv1alpha1.ProductRHSSO,
+		integreatlyv1alpha1.ProductRHSSOUser,
+		integreatlyv1alpha1.ProductSolutionExplorer,
+		integreatlyv1alpha1.ProductUps,
+	}
+
+	for _, product := range builtin {
+		if _, ok := registry.Get(product); !ok {
+			t.Errorf("expected built-in product %s to be registered", product)
+		}
+	}
+}
+
+func TestPreflightObject(t *testing.T) {
+	cases := []struct {
+		Name     string
+		Product  integreatlyv1alpha1.ProductName
+		Expected string
+	}{
+		{
+			Name:     "test product with a preflight object",
+			Product:  integreatlyv1alpha1.ProductCodeReadyWorkspaces,
+			Expected: "codeready",
+		},
+		{
+			Name:    "test product without a preflight object",
+			Product: integreatlyv1alpha1.ProductGrafana,
+		},
+		{
+			Name:    "test unknown product",
+			Product: "not-registered",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			search := PreflightObject(tc.Product, "test-ns")
+			if tc.Expected == "" {
+				if search != nil {
+					t.Fatalf("expected no preflight object, got %v", search)
+				}
+				return
+			}
+			deployment, ok := search.(*appsv1.Deployment)
+			if !ok || deployment.Name != tc.Expected || deployment.Namespace != "test-ns" {
+				t.Fatalf("expected deployment %s in test-ns, got %v", tc.Expected, search)
+			}
+		})
+	}
+}
+
+func TestVerifyVersion(t *testing.T) {
+	installation := &integreatlyv1alpha1.RHMI{}
+	installation.Status.Stages = map[integreatlyv1alpha1.StageName]integreatlyv1alpha1.RHMIStageStatus{
+		integreatlyv1alpha1.ProductsStage: {
+			Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.RHMIProductStatus{
+				integreatlyv1alpha1.ProductGrafana: {
+					Name:            integreatlyv1alpha1.ProductGrafana,
+					Version:         "0.0.1",
+					OperatorVersion: "0.0.1",
+				},
+			},
+		},
+	}
+
+	if VerifyVersion(integreatlyv1alpha1.ProductGrafana, installation) {
+		t.Fatal("expected the version of grafana not to match")
+	}
+	if !VerifyVersion(integreatlyv1alpha1.ProductAMQStreams, installation) {
+		t.Fatal("expected a product without a version check to match")
+	}
+	if !VerifyVersion("not-registered", installation) {
+		t.Fatal("expected an unknown product to match")
+	}
+}
@@ -23,11 +23,11 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
-	"github.com/keycloak/keycloak-operator/pkg/common"
 
 	usersv1 "github.com/openshift/api/user/v1"
 	oauthClient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
@@ -81,9 +81,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.AuthenticationStage].Products[integreatlyv1alpha1.ProductRHSSO],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductRHSSO),
 		string(integreatlyv1alpha1.VersionRHSSO),
 		string(integreatlyv1alpha1.OperatorVersionRHSSO),
 	)
@@ -95,38 +95,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	productNamespace := r.Config.GetNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		// Check if namespace is still present before trying to delete it resources
-		_, err := resources.GetNS(ctx, productNamespace, serverClient)
-		if !k8serr.IsNotFound(err) {
-			phase, err := r.CleanupKeycloakResources(ctx, installation, serverClient, productNamespace)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-
-			phase, err = resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.Log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-		_, err = resources.GetNS(ctx, operatorNamespace, serverClient)
-		if !k8serr.IsNotFound(err) {
-			phase, err := resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.Log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-		err = resources.RemoveOauthClient(r.Oauthv1Client, r.GetOAuthClientName(r.Config), r.Log)
-		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-
-		//if both namespaces are deleted, return complete
-		_, operatorNSErr := resources.GetNS(ctx, operatorNamespace, serverClient)
-		_, nsErr := resources.GetNS(ctx, productNamespace, serverClient)
-		if k8serr.IsNotFound(operatorNSErr) && k8serr.IsNotFound(nsErr) {
-			return integreatlyv1alpha1.PhaseCompleted, nil
-		}
-		return integreatlyv1alpha1.PhaseInProgress, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.Log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.Recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -174,7 +143,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 		return phase, err
 	}
 
-	phase, err = r.ReconcileStatefulSet(ctx, serverClient, r.Config.RHSSOCommon)
+	phase, err = r.ReconcileStatefulSet(ctx, serverClient, r.Config.GetProductName(), r.Config.RHSSOCommon)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.Recorder, installation, phase, "Failed to reconsile RHSSO pod priority", err)
 		return phase, err
@@ -222,6 +191,46 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Keycloak resources, the RHSSO namespaces and the
+// OAuth client
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	// Check if namespace is still present before trying to delete it resources
+	_, err := resources.GetNS(ctx, productNamespace, serverClient)
+	if !k8serr.IsNotFound(err) {
+		phase, err := r.CleanupKeycloakResources(ctx, installation, serverClient, productNamespace)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+
+		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.Log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+	_, err = resources.GetNS(ctx, operatorNamespace, serverClient)
+	if !k8serr.IsNotFound(err) {
+		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.Log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+	err = resources.RemoveOauthClient(r.Oauthv1Client, r.GetOAuthClientName(r.Config), r.Log)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	//if both namespaces are deleted, return complete
+	_, operatorNSErr := resources.GetNS(ctx, operatorNamespace, serverClient)
+	_, nsErr := resources.GetNS(ctx, productNamespace, serverClient)
+	if k8serr.IsNotFound(operatorNSErr) && k8serr.IsNotFound(nsErr) {
+		return integreatlyv1alpha1.PhaseCompleted, nil
+	}
+	return integreatlyv1alpha1.PhaseInProgress, nil
+}
+
 func (r *Reconciler) reconcileComponents(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	r.Log.Info("Reconciling Keycloak components")
 	kc := &keycloak.Keycloak{
@@ -408,8 +417,7 @@ func (r *Reconciler) setupGithubIDP(ctx context.Context, kc *keycloak.Keycloak,
 	r.Log.Info("Syncing github identity provider to the keycloak realm")
 
 	// Get an authenticated keycloak api client for the instance
-	keycloakFactory := common.LocalConfigKeycloakFactory{}
-	authenticated, err := keycloakFactory.AuthenticatedClient(*kc)
+	authenticated, err := r.KeycloakClientFactory.AuthenticatedClient(*kc)
 	if err != nil {
 		return fmt.Errorf("Unable to authenticate to the Keycloak API: %s", err)
 	}
@@ -620,3 +628,21 @@ func GetInstanceLabels() map[string]string {
 		SSOLabelKey: SSOLabelValue,
 	}
 }
+
+// PreUpgradeBackupExecutor returns the backup of the RHSSO postgres, taken
+// before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
+	return r.PreUpgradeBackupsExecutor(postgresResourceName)
+}
+
+// RestoreWorkloads returns the workloads stopped while the RHSSO postgres is
+// restored by RHMIRestore
+func (r *Reconciler) RestoreWorkloads() []backup.Workload {
+	return r.KeycloakRestoreWorkloads(keycloakName, r.Config.GetNamespace())
+}
+
+// BackupSanityChecks returns the check run against the RHSSO postgres
+// restored to verify its backups
+func (r *Reconciler) BackupSanityChecks() map[string]integreatlyv1alpha1.BackupSanityCheck {
+	return r.KeycloakSanityChecks(postgresResourceName)
+}
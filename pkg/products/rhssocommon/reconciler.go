@@ -15,6 +15,7 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 	userHelper "github.com/integr8ly/integreatly-operator/pkg/resources/user"
 	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
@@ -26,6 +27,7 @@ import (
 	oauthClient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 	k8sappsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -39,6 +41,8 @@ var (
 	idpAlias        = "openshift-v4"
 	manifestPackage = "integreatly-rhsso"
 	podMonitorName  = "keycloak-pod-monitor"
+
+	updateStatefulSetAction = "update keycloak statefulset pod template"
 )
 
 type Reconciler struct {
@@ -81,7 +85,9 @@ func SetNameSpaces(installation *integreatlyv1alpha1.RHMI, config *config.RHSSOC
 	}
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+// PreflightObject returns the deployment config of RHSSO, it is the preflight
+// object of both RHSSO products
+func PreflightObject(ns string) runtime.Object {
 	return &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "sso",
@@ -380,17 +386,36 @@ func (r *Reconciler) ReconcileCloudResources(dbPRefix string, defaultNamespace s
 }
 
 func (r *Reconciler) PreUpgradeBackupsExecutor(resourceName string) backup.BackupExecutor {
-	if r.Installation.Spec.UseClusterStorage != "false" {
-		return backup.NewNoopBackupExecutor()
-	}
-
-	return backup.NewAWSBackupExecutor(
-		r.Installation.Namespace,
+	return backup.NewInstallationBackupExecutor(
+		r.Installation,
 		resourceName,
 		backup.PostgresSnapshotType,
 	)
 }
 
+// KeycloakRestoreWorkloads returns the keycloak scaled down while its postgres is
+// restored. It's scaled through the Keycloak CR, as the keycloak operator
+// reverts changes to the statefulset
+func (r *Reconciler) KeycloakRestoreWorkloads(keycloakName, namespace string) []backup.Workload {
+	return []backup.Workload{
+		{
+			GroupVersionKind: keycloak.SchemeGroupVersion.WithKind("Keycloak"),
+			Name:             keycloakName,
+			Namespace:        namespace,
+			ReplicasPath:     "spec.instances",
+			PodSelector:      map[string]string{"app": "keycloak", "component": "keycloak"},
+		},
+	}
+}
+
+// KeycloakSanityChecks returns the check run against the keycloak postgres
+// restored to verify its backups, which must have the realms
+func (r *Reconciler) KeycloakSanityChecks(postgresResourceName string) map[string]integreatlyv1alpha1.BackupSanityCheck {
+	return map[string]integreatlyv1alpha1.BackupSanityCheck{
+		postgresResourceName: {Query: "SELECT count(*) FROM realm", Min: 1},
+	}
+}
+
 func (r *Reconciler) ReconcileSubscription(ctx context.Context, serverClient k8sclient.Client, inst *integreatlyv1alpha1.RHMI, productNamespace string, operatorNamespace string, resourceName string) (integreatlyv1alpha1.StatusPhase, error) {
 	target := marketplace.Target{
 		SubscriptionName: constants.RHSSOSubscriptionName,
@@ -418,7 +443,10 @@ func (r *Reconciler) ReconcileSubscription(ctx context.Context, serverClient k8s
 	)
 }
 
-func (r *Reconciler) ReconcileStatefulSet(ctx context.Context, serverClient k8sclient.Client, config *config.RHSSOCommon) (integreatlyv1alpha1.StatusPhase, error) {
+// ReconcileStatefulSet updates the pod template of the keycloak statefulset.
+// Changes to the template restart the keycloak pods, so they are deferred
+// until the maintenance window
+func (r *Reconciler) ReconcileStatefulSet(ctx context.Context, serverClient k8sclient.Client, productName integreatlyv1alpha1.ProductName, config *config.RHSSOCommon) (integreatlyv1alpha1.StatusPhase, error) {
 	statefulSet := &k8sappsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "keycloak",
@@ -432,15 +460,35 @@ func (r *Reconciler) ReconcileStatefulSet(ctx context.Context, serverClient k8sc
 		mutatePodPriority = resources.MutatePodPriority(r.Installation.Spec.PriorityClassName)
 	}
 
+	mutation := resources.AllMutationsOf(
+		resources.MutateMultiAZAntiAffinity(ctx, serverClient, "app"),
+		resources.MutateZoneTopologySpreadConstraints("app"),
+		mutatePodPriority,
+	)
+
 	return resources.UpdatePodTemplateIfExists(
 		ctx,
 		serverClient,
 		resources.SelectFromStatefulSet,
-		resources.AllMutationsOf(
-			resources.MutateMultiAZAntiAffinity(ctx, serverClient, "app"),
-			resources.MutateZoneTopologySpreadConstraints("app"),
-			mutatePodPriority,
-		),
+		func(obj metav1.Object, podTemplate *corev1.PodTemplateSpec) error {
+			current := podTemplate.DeepCopy()
+			if err := mutation(obj, podTemplate); err != nil {
+				return err
+			}
+			if equality.Semantic.DeepEqual(current, podTemplate) {
+				return nil
+			}
+
+			allowed, err := maintenance.GateFromContext(ctx).Allow(ctx, productName, updateStatefulSetAction)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				r.Log.Info("Update of the keycloak statefulset deferred until the maintenance window")
+				current.DeepCopyInto(podTemplate)
+			}
+			return nil
+		},
 		statefulSet,
 	)
 }
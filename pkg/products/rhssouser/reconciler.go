@@ -5,6 +5,8 @@ import (
 	"fmt"
 	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/quota"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 
@@ -33,6 +35,7 @@ import (
 
 	oauthClient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
 
+	"github.com/integr8ly/integreatly-operator/pkg/resources/backup"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -115,9 +118,9 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.ProductRHSSOUser],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductRHSSOUser),
 		string(integreatlyv1alpha1.VersionRHSSOUser),
 		string(integreatlyv1alpha1.OperatorVersionRHSSOUser),
 	)
@@ -129,37 +132,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	operatorNamespace := r.Config.GetOperatorNamespace()
 	productNamespace := r.Config.GetNamespace()
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		// Check if namespace is still present before trying to delete it resources
-		_, err := resources.GetNS(ctx, productNamespace, serverClient)
-		if !k8serr.IsNotFound(err) {
-			phase, err := r.CleanupKeycloakResources(ctx, installation, serverClient, productNamespace)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-
-			phase, err = resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.Log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-
-		_, err = resources.GetNS(ctx, operatorNamespace, serverClient)
-		if !k8serr.IsNotFound(err) {
-			phase, err := resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.Log)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-		err = resources.RemoveOauthClient(r.Oauthv1Client, r.GetOAuthClientName(r.Config), r.Log)
-		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-
-		if err := r.deleteConsoleLink(ctx, serverClient); err != nil {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.Log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.Recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -214,7 +187,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 		return phase, err
 	}
 
-	phase, err = r.ReconcileStatefulSet(ctx, serverClient, r.Config.RHSSOCommon)
+	phase, err = r.ReconcileStatefulSet(ctx, serverClient, r.Config.GetProductName(), r.Config.RHSSOCommon)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.Recorder, installation, phase, "Failed to reconsile RHSSO pod priority", err)
 		return phase, err
@@ -267,6 +240,45 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the Keycloak resources of the user SSO, its namespaces,
+// OAuth client and console link
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	// Check if namespace is still present before trying to delete it resources
+	_, err := resources.GetNS(ctx, productNamespace, serverClient)
+	if !k8serr.IsNotFound(err) {
+		phase, err := r.CleanupKeycloakResources(ctx, installation, serverClient, productNamespace)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+
+		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.Log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+
+	_, err = resources.GetNS(ctx, operatorNamespace, serverClient)
+	if !k8serr.IsNotFound(err) {
+		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.Log)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+	err = resources.RemoveOauthClient(r.Oauthv1Client, r.GetOAuthClientName(r.Config), r.Log)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	if err := r.deleteConsoleLink(ctx, serverClient); err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileComponents(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client, productConfig quota.ProductConfig) (integreatlyv1alpha1.StatusPhase, error) {
 	r.Log.Info("Reconciling Keycloak components")
 	kc := &keycloak.Keycloak{
@@ -288,6 +300,20 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 		}
 	}
 
+	var autoscaledInstances int32
+	if installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeManagedApi) {
+		// The keycloak operator reverts the replicas of the stateful set to
+		// the instances of the Keycloak, so it follows the autoscaler
+		autoscaledInstances, err = quota.ReconcileAutoscaler(ctx, serverClient, productConfig, quota.KeycloakName, r.Config.GetNamespace(), autoscalingv1.CrossVersionObjectReference{
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+			Kind:       "StatefulSet",
+			Name:       "keycloak",
+		})
+		if err != nil {
+			return integreatlyv1alpha1.PhaseFailed, err
+		}
+	}
+
 	or, err := controllerutil.CreateOrUpdate(ctx, serverClient, kc, func() error {
 		owner.AddIntegreatlyOwnerAnnotations(kc, installation)
 		kc.Spec.Extensions = []string{
@@ -326,6 +352,9 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, installation *inte
 			if err != nil {
 				return err
 			}
+			if autoscaledInstances > 0 {
+				kc.Spec.Instances = int(autoscaledInstances)
+			}
 		}
 		return nil
 	})
@@ -1263,3 +1292,21 @@ func (r *Reconciler) deleteConsoleLink(ctx context.Context, serverClient k8sclie
 
 	return nil
 }
+
+// PreUpgradeBackupExecutor returns the backup of the user SSO postgres, taken
+// before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
+	return r.PreUpgradeBackupsExecutor(postgresResourceName)
+}
+
+// RestoreWorkloads returns the workloads stopped while the user SSO postgres is
+// restored by RHMIRestore
+func (r *Reconciler) RestoreWorkloads() []backup.Workload {
+	return r.KeycloakRestoreWorkloads(keycloakName, r.Config.GetNamespace())
+}
+
+// BackupSanityChecks returns the check run against the user SSO postgres
+// restored to verify its backups
+func (r *Reconciler) BackupSanityChecks() map[string]integreatlyv1alpha1.BackupSanityCheck {
+	return r.KeycloakSanityChecks(postgresResourceName)
+}
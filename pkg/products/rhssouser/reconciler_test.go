@@ -40,6 +40,7 @@ import (
 	coreosv1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1"
 	operatorsv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 
 	crov1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
 	croTypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
@@ -121,6 +122,10 @@ func getBuildScheme() (*runtime.Scheme, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = autoscalingv1.SchemeBuilder.AddToScheme(scheme)
+	if err != nil {
+		return nil, err
+	}
 	err = coreosv1.SchemeBuilder.AddToScheme(scheme)
 	if err != nil {
 		return nil, err
@@ -348,6 +353,9 @@ func TestReconciler_reconcileComponents(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 		},
 		{
@@ -380,6 +388,9 @@ func TestReconciler_reconcileComponents(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 		},
 	}
@@ -609,6 +620,9 @@ func TestReconciler_full_RHMI_Reconcile(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 		},
 	}
@@ -842,6 +856,9 @@ func TestReconciler_full_RHOAM_Reconcile(t *testing.T) {
 				ConfigureFunc: func(obj metav1.Object) error {
 					return nil
 				},
+				GetAutoscalingFunc: func(ddcssName string) (*quota.AutoscalingConfig, bool) {
+					return nil, false
+				},
 			},
 		},
 	}
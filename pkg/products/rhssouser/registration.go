@@ -0,0 +1,22 @@
+package rhssouser
+
+import (
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+	"github.com/integr8ly/integreatly-operator/pkg/products/rhssocommon"
+)
+
+func init() {
+	registry.Register(integreatlyv1alpha1.ProductRHSSOUser, registry.Registration{
+		Dependencies: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
+		Factory: func(opts *registry.ReconcilerOptions) (registry.Interface, error) {
+			oauthv1Client, err := opts.OauthClient()
+			if err != nil {
+				return nil, err
+			}
+			return NewReconciler(opts.ConfigManager, opts.Installation, oauthv1Client, opts.MPM, opts.Recorder, opts.RestConfig.Host, opts.KeycloakClientFactory, opts.Log, opts.ProductDeclaration)
+		},
+		PreflightObject: rhssocommon.PreflightObject,
+		VerifyVersion:   verifyVersion,
+	})
+}
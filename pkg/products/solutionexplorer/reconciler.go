@@ -123,7 +123,7 @@ func NewReconciler(configManager config.ConfigReadWriter, installation *integrea
 	}, nil
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+func preflightObject(ns string) runtime.Object {
 	return &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "tutorial-web-app",
@@ -132,9 +132,9 @@ func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
 	}
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.SolutionExplorerStage].Products[integreatlyv1alpha1.ProductSolutionExplorer],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.ProductSolutionExplorer),
 		string(integreatlyv1alpha1.VersionSolutionExplorer),
 		string(integreatlyv1alpha1.OperatorVersionSolutionExplorer),
 	)
@@ -147,31 +147,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	productNamespace := r.Config.GetNamespace()
 
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		cl := &consolev1.ConsoleLink{
-			ObjectMeta: metav1.ObjectMeta{
-				Name: "rhmi-solution-explorer",
-			},
-		}
-
-		err = serverClient.Delete(ctx, cl)
-		if err != nil && !k8serr.IsNotFound(err) {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-
-		err = resources.RemoveOauthClient(r.oauthv1Client, r.getOAuthClientName(), r.log)
-		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -256,6 +232,39 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the solution explorer namespaces, its console link and
+// its OAuth client
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+	phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	cl := &consolev1.ConsoleLink{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "rhmi-solution-explorer",
+		},
+	}
+
+	err = serverClient.Delete(ctx, cl)
+	if err != nil && !k8serr.IsNotFound(err) {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	err = resources.RemoveOauthClient(r.oauthv1Client, r.getOAuthClientName(), r.log)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 func (r *Reconciler) reconcileConsoleLink(ctx context.Context, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
 	cl := &consolev1.ConsoleLink{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,38 @@
+package solutionexplorer
+
+import (
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+)
+
+// solutionExplorerDependencies are the products listed by the solution
+// explorer. Only the ones that are part of the installation type are waited
+// for
+var solutionExplorerDependencies = []integreatlyv1alpha1.ProductName{
+	integreatlyv1alpha1.ProductRHSSO,
+	integreatlyv1alpha1.ProductRHSSOUser,
+	integreatlyv1alpha1.Product3Scale,
+	integreatlyv1alpha1.ProductAMQOnline,
+	integreatlyv1alpha1.ProductAMQStreams,
+	integreatlyv1alpha1.ProductApicurito,
+	integreatlyv1alpha1.ProductCodeReadyWorkspaces,
+	integreatlyv1alpha1.ProductDataSync,
+	integreatlyv1alpha1.ProductFuse,
+	integreatlyv1alpha1.ProductFuseOnOpenshift,
+	integreatlyv1alpha1.ProductUps,
+}
+
+func init() {
+	registry.Register(integreatlyv1alpha1.ProductSolutionExplorer, registry.Registration{
+		Dependencies: solutionExplorerDependencies,
+		Factory: func(opts *registry.ReconcilerOptions) (registry.Interface, error) {
+			oauthv1Client, err := opts.OauthClient()
+			if err != nil {
+				return nil, err
+			}
+			return NewReconciler(opts.ConfigManager, opts.Installation, oauthv1Client, opts.MPM, opts.OauthResolver, opts.Recorder, opts.Log, opts.ProductDeclaration)
+		},
+		PreflightObject: preflightObject,
+		VerifyVersion:   verifyVersion,
+	})
+}
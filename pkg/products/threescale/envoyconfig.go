@@ -11,6 +11,7 @@ import (
 	lua "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/lua/v3"
 	ptypes "github.com/golang/protobuf/ptypes"
 	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
 	structpb "google.golang.org/protobuf/types/known/structpb"
 )
@@ -51,6 +52,16 @@ var tsRatelimitDescriptor = route.RateLimit{
 	}},
 }
 
+/*
+	Defines the rate limits of the routes: the 3scale rate limit descriptor
+	and the descriptors of the additional windows, shared by all the
+	requests, and the rate limits of the descriptors with their own limits
+*/
+func getRateLimits(windows []marin3rconfig.RateLimitWindow, descriptors []marin3rconfig.DescriptorConfig) []*route.RateLimit {
+	rateLimits := append([]*route.RateLimit{&tsRatelimitDescriptor}, ratelimit.WindowRateLimits(windows)...)
+	return append(rateLimits, ratelimit.DescriptorRateLimits(descriptors)...)
+}
+
 /*
 	Defines http filters for the rate limit service
 	   httpFilters:
@@ -204,31 +215,32 @@ virtualHosts:
 			- genericKey:
 				descriptorValue: slowpath
 			stage: 0
+		- &windowRateLimits
+		- &descriptorRateLimits
+	- &exemptVirtualHost
 */
-func getAPICastVirtualHosts(clusterName string) []*v2route.VirtualHost {
+func getAPICastVirtualHosts(clusterName string, windows []marin3rconfig.RateLimitWindow, descriptors []marin3rconfig.DescriptorConfig, exemptions *marin3rconfig.ExemptionsConfig) []*v2route.VirtualHost {
 	virtualHost := v2route.VirtualHost{
 		Name:    clusterName,
 		Domains: []string{"*"},
 
-		Routes: []*v2route.Route{
-			{
-				Match: &v2route.RouteMatch{
-					PathSpecifier: &v2route.RouteMatch_Prefix{
-						Prefix: "/",
-					},
+		Routes: append(ratelimit.ExemptRoutes(clusterName, exemptions), &v2route.Route{
+			Match: &v2route.RouteMatch{
+				PathSpecifier: &v2route.RouteMatch_Prefix{
+					Prefix: "/",
 				},
-				Action: &v2route.Route_Route{
-					Route: &v2route.RouteAction{
-						ClusterSpecifier: &route.RouteAction_Cluster{
-							Cluster: clusterName,
-						},
-						RateLimits: []*route.RateLimit{&tsRatelimitDescriptor},
+			},
+			Action: &v2route.Route_Route{
+				Route: &v2route.RouteAction{
+					ClusterSpecifier: &route.RouteAction_Cluster{
+						Cluster: clusterName,
 					},
+					RateLimits: getRateLimits(windows, descriptors),
 				},
 			},
-		},
+		}),
 	}
-	return []*v2route.VirtualHost{&virtualHost}
+	return withExemptVirtualHost([]*v2route.VirtualHost{&virtualHost}, clusterName, exemptions)
 }
 
 /**
@@ -241,36 +253,107 @@ virtual_hosts:
 		route:
 			cluster: backend-listener-ratelimit
 			rate_limits:
+			- &tsRatelimitDescriptor
+			- &windowRateLimits
+			- &descriptorRateLimits
+	- &exemptVirtualHost
 **/
-func getBackendListenerVitualHosts(clusterName string) []*v2route.VirtualHost {
+func getBackendListenerVitualHosts(clusterName string, windows []marin3rconfig.RateLimitWindow, descriptors []marin3rconfig.DescriptorConfig, exemptions *marin3rconfig.ExemptionsConfig) []*v2route.VirtualHost {
 	virtualHosts := []*v2route.VirtualHost{
 		{
 			Name:    clusterName,
 			Domains: []string{"*"},
 
-			Routes: []*v2route.Route{
-				{
-					Match: &v2route.RouteMatch{
-						PathSpecifier: &v2route.RouteMatch_Prefix{
-							Prefix: "/",
-						},
+			Routes: append(ratelimit.ExemptRoutes(clusterName, exemptions), &v2route.Route{
+				Match: &v2route.RouteMatch{
+					PathSpecifier: &v2route.RouteMatch_Prefix{
+						Prefix: "/",
 					},
-					Action: &v2route.Route_Route{
-						Route: &v2route.RouteAction{
-							ClusterSpecifier: &route.RouteAction_Cluster{
-								Cluster: clusterName,
-							},
-							RateLimits: []*route.RateLimit{&tsRatelimitDescriptor},
+				},
+				Action: &v2route.Route_Route{
+					Route: &v2route.RouteAction{
+						ClusterSpecifier: &route.RouteAction_Cluster{
+							Cluster: clusterName,
 						},
+						RateLimits: getRateLimits(windows, descriptors),
 					},
 				},
-			},
+			}),
 		},
 	}
 
+	return withExemptVirtualHost(virtualHosts, clusterName, exemptions)
+}
+
+// withExemptVirtualHost adds the virtual host of the exempted hosts, which
+// takes precedence over the wildcard virtual host
+func withExemptVirtualHost(virtualHosts []*v2route.VirtualHost, clusterName string, exemptions *marin3rconfig.ExemptionsConfig) []*v2route.VirtualHost {
+	if exemptVirtualHost := ratelimit.ExemptVirtualHost(clusterName, exemptions); exemptVirtualHost != nil {
+		virtualHosts = append(virtualHosts, exemptVirtualHost)
+	}
 	return virtualHosts
 }
 
+// withExemptCluster adds the exempt cluster of the cluster, which the
+// exempted requests are routed to so that envoy counts them on their own
+func withExemptCluster(clusters []*envoyapi.Cluster, cluster *envoyapi.Cluster, exemptions *marin3rconfig.ExemptionsConfig) []*envoyapi.Cluster {
+	if exemptCluster := ratelimit.ExemptClusterResource(cluster, exemptions); exemptCluster != nil {
+		clusters = append(clusters, exemptCluster)
+	}
+	return clusters
+}
+
+/**
+	filterChains:
+	- filterChainMatch:
+		sourcePrefixRanges: &exemptedSourceCIDRs
+	  filters:
+	  - name: envoy.http_connection_manager
+		typedConfig:
+		  httpFilters: &httpFilters without envoy.rate_limit
+		  routeConfig:
+			virtualHosts:
+			- domains: ["*"]
+			  name: clusterName-exempt-source
+			  routes:
+			  - match:
+				  prefix: /
+				route:
+				  cluster: clusterName-exempt
+**/
+func getExemptFilterChain(clusterName string, httpFilters []*hcm.HttpFilter, exemptions *marin3rconfig.ExemptionsConfig) (*listener.FilterChain, error) {
+	if exemptions == nil || len(exemptions.SourceCIDRs) == 0 {
+		return nil, nil
+	}
+
+	// The exempted connections don't go through the rate limit filter
+	exemptHTTPFilters := []*hcm.HttpFilter{}
+	for _, httpFilter := range httpFilters {
+		if httpFilter.Name != tsHTTPRateLimitFilter.Name {
+			exemptHTTPFilters = append(exemptHTTPFilters, httpFilter)
+		}
+	}
+
+	filters, err := getListenerResourceFilters(
+		[]*v2route.VirtualHost{ratelimit.ExemptAllVirtualHost(clusterName+"-exempt-source", []string{"*"}, clusterName)},
+		exemptHTTPFilters,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.ExemptFilterChain(exemptions, filters)
+}
+
+// withExemptFilterChain adds the filter chain of the exempted source
+// addresses to the listener. Envoy selects it over the filter chain without
+// match for the connections from these addresses
+func withExemptFilterChain(envoyListener *envoyapi.Listener, exemptFilterChain *listener.FilterChain) *envoyapi.Listener {
+	if exemptFilterChain != nil {
+		envoyListener.FilterChains = append(envoyListener.FilterChains, exemptFilterChain)
+	}
+	return envoyListener
+}
+
 /**
         - name: envoy.http_connection_manager
           typedConfig:
@@ -0,0 +1,112 @@
+package threescale
+
+import (
+	"testing"
+
+	envoyapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
+)
+
+func TestExemptCluster(t *testing.T) {
+	exemptClusterName := ApicastClusterName + "-exempt"
+
+	cases := []struct {
+		Name            string
+		Exemptions      *marin3rconfig.ExemptionsConfig
+		ExpectedCluster bool
+	}{
+		{
+			Name:       "test no exempt cluster without exemptions",
+			Exemptions: &marin3rconfig.ExemptionsConfig{},
+		},
+		{
+			Name: "test exempted requests are routed to the exempt cluster",
+			Exemptions: &marin3rconfig.ExemptionsConfig{
+				Hosts:    []string{"internal.example.com"},
+				Services: []string{"2"},
+				Paths:    []string{"/healthz"},
+			},
+			ExpectedCluster: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			cluster := ratelimit.CreateClusterResource(ApicastContainerAddress, ApicastClusterName, ApicastContainerPort)
+			clusters := withExemptCluster([]*envoyapi.Cluster{cluster}, cluster, tc.Exemptions)
+
+			var exemptCluster *envoyapi.Cluster
+			for _, c := range clusters {
+				if c.Name == exemptClusterName {
+					exemptCluster = c
+				}
+			}
+			if (exemptCluster != nil) != tc.ExpectedCluster {
+				t.Fatalf("expected exempt cluster %t, got clusters %v", tc.ExpectedCluster, clusters)
+			}
+			if cluster.Name != ApicastClusterName {
+				t.Fatalf("expected the rate limited cluster to be unchanged, got %s", cluster.Name)
+			}
+			if !tc.ExpectedCluster {
+				return
+			}
+			if exemptCluster.LoadAssignment.ClusterName != exemptClusterName {
+				t.Fatalf("expected the load assignment of %s, got %s", exemptClusterName, exemptCluster.LoadAssignment.ClusterName)
+			}
+			if len(exemptCluster.LoadAssignment.Endpoints) != len(cluster.LoadAssignment.Endpoints) {
+				t.Fatalf("expected the endpoints of %s, got %v", ApicastClusterName, exemptCluster.LoadAssignment.Endpoints)
+			}
+
+			// Every exempted route goes to the exempt cluster, the rate
+			// limited route to the cluster
+			for _, virtualHost := range getAPICastVirtualHosts(ApicastClusterName, nil, nil, tc.Exemptions) {
+				for _, route := range virtualHost.Routes {
+					expected := exemptClusterName
+					if len(route.GetRoute().GetRateLimits()) > 0 {
+						expected = ApicastClusterName
+					}
+					if cluster := route.GetRoute().GetCluster(); cluster != expected {
+						t.Fatalf("expected route %v of virtual host %s to go to %s, got %s", route.Match, virtualHost.Name, expected, cluster)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestDescriptorWindowRateLimits(t *testing.T) {
+	descriptors := []marin3rconfig.DescriptorConfig{
+		{
+			Name:  "tenant-a",
+			Match: marin3rconfig.DescriptorMatch{Type: marin3rconfig.DescriptorMatchAccount, Value: "tenant-a"},
+			RateLimit: &marin3rconfig.RateLimitConfig{
+				Unit:            "minute",
+				RequestsPerUnit: 10,
+				Windows:         []marin3rconfig.RateLimitWindow{{Unit: "day", RequestsPerUnit: 1000}},
+			},
+		},
+	}
+
+	// The 3scale descriptor, then the descriptor and its window
+	rateLimits := getRateLimits(nil, descriptors)
+	if len(rateLimits) != 3 {
+		t.Fatalf("expected 3 rate limits, got %v", rateLimits)
+	}
+
+	descriptorActions := rateLimits[1].Actions
+	if len(descriptorActions) != 1 || descriptorActions[0].GetHeaderValueMatch().GetDescriptorValue() != "tenant-a" {
+		t.Fatalf("unexpected actions of the descriptor %v", descriptorActions)
+	}
+
+	windowActions := rateLimits[2].Actions
+	if len(windowActions) != 2 {
+		t.Fatalf("expected the actions of the descriptor and its window, got %v", windowActions)
+	}
+	if windowActions[0].GetHeaderValueMatch().GetDescriptorValue() != "tenant-a" {
+		t.Fatalf("expected the action of the descriptor first, got %v", windowActions[0])
+	}
+	if windowActions[1].GetGenericKey().GetDescriptorValue() != "slowpath_day" {
+		t.Fatalf("expected the action of the window, got %v", windowActions[1])
+	}
+}
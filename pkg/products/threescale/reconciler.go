@@ -21,7 +21,9 @@ import (
 	consolev1 "github.com/openshift/api/console/v1"
 	oauthv1 "github.com/openshift/api/oauth/v1"
 
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/events"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
 	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
 
 	"github.com/integr8ly/integreatly-operator/pkg/products/monitoring"
@@ -48,11 +50,13 @@ import (
 	"github.com/integr8ly/integreatly-operator/pkg/resources/marketplace"
 
 	"github.com/integr8ly/integreatly-operator/pkg/resources/constants"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/dryrun"
 	appsv1 "github.com/openshift/api/apps/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	usersv1 "github.com/openshift/api/user/v1"
 	appsv1Client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
 	oauthClient "github.com/openshift/client-go/oauth/clientset/versioned/typed/oauth/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -84,6 +88,7 @@ const (
 	apicastRatelimiting              = "apicast-ratelimit"
 	backendListenerEnvoyConfigNodeID = "backend-listener-envoyconfig"
 	registrySecretName               = "threescale-registry-auth"
+	rolloutSystemAction              = "rollout system-app and system-sidekiq"
 
 	threeScaleIcon = "data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciIHZpZXdCb3g9IjAgMCAxMDAgMTAwIj48ZGVmcz48c3R5bGU+LmNscy0xe2ZpbGw6I2Q3MWUwMDt9LmNscy0ye2ZpbGw6I2MyMWEwMDt9LmNscy0ze2ZpbGw6I2ZmZjt9PC9zdHlsZT48L2RlZnM+PHRpdGxlPnByb2R1Y3RpY29uc18xMDE3X1JHQl9BUEkgZmluYWwgY29sb3I8L3RpdGxlPjxnIGlkPSJMYXllcl8xIiBkYXRhLW5hbWU9IkxheWVyIDEiPjxjaXJjbGUgY2xhc3M9ImNscy0xIiBjeD0iNTAiIGN5PSI1MCIgcj0iNTAiIHRyYW5zZm9ybT0idHJhbnNsYXRlKC0yMC43MSA1MCkgcm90YXRlKC00NSkiLz48cGF0aCBjbGFzcz0iY2xzLTIiIGQ9Ik04NS4zNiwxNC42NEE1MCw1MCwwLDAsMSwxNC42NCw4NS4zNloiLz48cGF0aCBjbGFzcz0iY2xzLTMiIGQ9Ik01MC4yNSwzMC44M2EyLjY5LDIuNjksMCwxLDAtMi42OC0yLjY5QTIuNjUsMi42NSwwLDAsMCw1MC4yNSwzMC44M1pNNDMuMzYsMzkuNGEzLjM1LDMuMzUsMCwwLDAsMy4zMiwzLjM0LDMuMzQsMy4zNCwwLDAsMCwwLTYuNjdBMy4zNSwzLjM1LDAsMCwwLDQzLjM2LDM5LjRabTMuOTIsOS44OUEyLjY4LDIuNjgsMCwxLDAsNDQuNiw1MiwyLjcsMi43LDAsMCwwLDQ3LjI4LDQ5LjI5Wk0zMi42MywyOS42NWEzLjI2LDMuMjYsMCwxLDAtMy4yNC0zLjI2QTMuMjYsMy4yNiwwLDAsMCwzMi42MywyOS42NVpNNDAuNTMsMzRhMi43NywyLjc3LDAsMCwwLDAtNS41MywyLjc5LDIuNzksMCwwLDAtMi43NiwyLjc3QTIuODUsMi44NSwwLDAsMCw0MC41MywzNFptMS43Ni05LjMxYTQuNCw0LjQsMCwxLDAtNC4zOC00LjRBNC4zNyw0LjM3LDAsMCwwLDQyLjI5LDI0LjcxWk0zMi43OCw0OWE3LDcsMCwxLDAtNy03QTcsNywwLDAsMCwzMi43OCw0OVptMzIuMTMtNy43YTQuMjMsNC4yMywwLDAsMCw0LjMsNC4zMSw0LjMxLDQuMzEsMCwxLDAtNC4zLTQuMzFabTYuOSwxMC4wNmEzLjA4LDMuMDgsMCwxLDAsMy4wOC0zLjA5QTMuMDksMy4wOSwwLDAsMCw3MS44MSw1MS4zOFpNNzMuOSwzNC43N2E0LjMxLDQuMzEsMCwxLDAtNC4zLTQuMzFBNC4yOCw0LjI4LDAsMCwwLDczLjksMzQuNzdaTTUyLjE2LDQ1LjA2YTMuNjUsMy42NSwwLDEsMCwzLjY1LTMuNjZBMy42NCwzLjY0LDAsMCwwLDUyLjE2LDQ1LjA2Wk01NSwyMmEzLjE3LDMuMTcsMCwwLDAsMy4xNi0zLjE3QTMuMjMsMy4yMywwLDAsMCw1NSwxNS42MywzLjE3LDMuMTcsMCwwLDAsNTUsMjJabS0uNDcsMTAuMDlBNS4zNyw1LjM3LDAsMCwwLDYwLDM3LjU0YTUuNDgsNS40OCwwLDEsMC01LjQ1LTUuNDhaTTY2LjI1LDI1LjVhMi42OSwyLjY5LDAsMSwwLTIuNjgtMi42OUEyLjY1LDIuNjUsMCwwLDAsNjYuMjUsMjUuNVpNNDUuNyw2My4xYTMuNDIsMy40MiwwLDEsMC0zLjQxLTMuNDJBMy40MywzLjQzLDAsMCwwLDQ1LjcsNjMuMVptMTQsMTEuMTlhNC40LDQuNCwwLDEsMCw0LjM4LDQuNEE0LjM3LDQuMzcsMCwwLDAsNTkuNzMsNzQuMjlaTTYyLjMsNTAuNTFhOS4yLDkuMiwwLDEsMCw5LjE2LDkuMkE5LjIyLDkuMjIsMCwwLDAsNjIuMyw1MC41MVpNNTAuMSw2Ni43N2EyLjY5LDIuNjksMCwxLDAsMi42OCwyLjY5QTIuNywyLjcsMCwwLDAsNTAuMSw2Ni43N1pNODEuMjUsNDEuMTJhMi43LDIuNywwLDAsMC0yLjY4LDIuNjksMi42NSwyLjY1LDAsMCwwLDIuNjgsMi42OSwyLjY5LDIuNjksMCwwLDAsMC01LjM3Wk00NC40OSw3Ni40N2EzLjczLDMuNzMsMCwwLDAtMy43MywzLjc0LDMuNzcsMy43NywwLDEsMCwzLjczLTMuNzRaTTc5LjA2LDU2LjcyYTQsNCwwLDEsMCw0LDRBNCw0LDAsMCwwLDc5LjA2LDU2LjcyWm0tNiwxMS43OEEzLjA5LDMuMDksMCwwLDAsNzAsNzEuNmEzLDMsMCwwLDAsMy4wOCwzLjA5LDMuMDksMy4wOSwwLDAsMCwwLTYuMTlaTTI4LjMsNjhhNC4xNiw0LjE2LDAsMCwwLTQuMTQsNC4xNUE0LjIxLDQuMjEsMCwwLDAsMjguMyw3Ni4zYTQuMTUsNC4xNSwwLDAsMCwwLTguM1ptLTguMjItOWEzLDMsMCwxLDAsMywzQTMuMDUsMy4wNSwwLDAsMCwyMC4wOCw1OVptMS44NC05Ljc0YTMsMywwLDEsMCwzLDNBMy4wNSwzLjA1LDAsMCwwLDIxLjkxLDQ5LjIyWk0yMi4zNyw0MmEzLjI0LDMuMjQsMCwxLDAtMy4yNCwzLjI2QTMuMjYsMy4yNiwwLDAsMCwyMi4zNyw0MlpNNDMuMTEsNzAuMmEzLjgsMy44LDAsMCwwLTMuODEtMy43NCwzLjczLDMuNzMsMCwwLDAtMy43MywzLjc0QTMuOCwzLjgsMCwwLDAsMzkuMyw3NCwzLjg3LDMuODcsMCwwLDAsNDMuMTEsNzAuMlpNMzcuNTYsNTguNDNhNC42OCw0LjY4LDAsMCwwLTQuNjItNC42NCw0LjYzLDQuNjMsMCwwLDAtNC42Miw0LjY0LDQuNTgsNC41OCwwLDAsMCw0LjYyLDQuNjRBNC42Myw0LjYzLDAsMCwwLDM3LjU2LDU4LjQzWk0yMy4xMSwzMy44MmEyLjUyLDIuNTIsMCwxLDAtMi41MS0yLjUyQTIuNTMsMi41MywwLDAsMCwyMy4xMSwzMy44MloiLz48L2c+PC9zdmc+"
 
@@ -158,7 +163,7 @@ type Reconciler struct {
 	log         l.Logger
 }
 
-func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
+func preflightObject(ns string) runtime.Object {
 	return &appsv1.DeploymentConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "system-app",
@@ -167,9 +172,9 @@ func (r *Reconciler) GetPreflightObject(ns string) runtime.Object {
 	}
 }
 
-func (r *Reconciler) VerifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
+func verifyVersion(installation *integreatlyv1alpha1.RHMI) bool {
 	return version.VerifyProductAndOperatorVersion(
-		installation.Status.Stages[integreatlyv1alpha1.ProductsStage].Products[integreatlyv1alpha1.Product3Scale],
+		*installation.GetProductStatusObject(integreatlyv1alpha1.Product3Scale),
 		string(integreatlyv1alpha1.Version3Scale),
 		string(integreatlyv1alpha1.OperatorVersion3Scale),
 	)
@@ -182,34 +187,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	productNamespace := r.Config.GetNamespace()
 
 	phase, err := r.ReconcileFinalizer(ctx, serverClient, installation, string(r.Config.GetProductName()), func() (integreatlyv1alpha1.StatusPhase, error) {
-		if installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeManagedApi) {
-			phase, err := ratelimit.DeleteEnvoyConfigsInNamespaces(ctx, serverClient, productNamespace)
-			if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-				return phase, err
-			}
-		}
-
-		phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		err = resources.RemoveOauthClient(r.oauthv1Client, r.getOAuthClientName(), r.log)
-		if err != nil {
-			return integreatlyv1alpha1.PhaseFailed, err
-		}
-
-		phase, err = r.deleteConsoleLink(ctx, serverClient)
-		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
-			return phase, err
-		}
-
-		return integreatlyv1alpha1.PhaseCompleted, nil
+		return r.Uninstall(ctx, installation, serverClient)
 	}, r.log)
 	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 		events.HandleError(r.recorder, installation, phase, "Failed to reconcile finalizer", err)
@@ -355,7 +333,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 
 	if installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeManagedApi) {
 
-		phase, err = r.reconcileRatelimitingTo3scaleComponents(ctx, serverClient, r.installation)
+		phase, err = r.reconcileRatelimitingTo3scaleComponents(ctx, serverClient, r.installation, productConfig.GetRateLimitConfig())
 		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
 			events.HandleError(r.recorder, installation, phase, "Failed to reconcile rate limiting to 3scale components", err)
 			return phase, err
@@ -412,6 +390,42 @@ func (r *Reconciler) Reconcile(ctx context.Context, installation *integreatlyv1a
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
+// Uninstall removes the 3scale envoy configs, namespaces, OAuth client and
+// console link
+func (r *Reconciler) Uninstall(ctx context.Context, installation *integreatlyv1alpha1.RHMI, serverClient k8sclient.Client) (integreatlyv1alpha1.StatusPhase, error) {
+	operatorNamespace := r.Config.GetOperatorNamespace()
+	productNamespace := r.Config.GetNamespace()
+
+	if installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeManagedApi) {
+		phase, err := ratelimit.DeleteEnvoyConfigsInNamespaces(ctx, serverClient, productNamespace)
+		if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+			return phase, err
+		}
+	}
+
+	phase, err := resources.RemoveNamespace(ctx, installation, serverClient, productNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	phase, err = resources.RemoveNamespace(ctx, installation, serverClient, operatorNamespace, r.log)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	err = resources.RemoveOauthClient(r.oauthv1Client, r.getOAuthClientName(), r.log)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+
+	phase, err = r.deleteConsoleLink(ctx, serverClient)
+	if err != nil || phase != integreatlyv1alpha1.PhaseCompleted {
+		return phase, err
+	}
+
+	return integreatlyv1alpha1.PhaseCompleted, nil
+}
+
 // restores seed and master api cast secrets if available
 func (r *Reconciler) restoreSystemSecrets(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
 	for _, secretName := range []string{systemSeedSecretName, systemMasterApiCastSecretName} {
@@ -475,14 +489,13 @@ func (r *Reconciler) reconcileSMTPCredentials(ctx context.Context, serverClient
 	}
 
 	// reconcile the smtp configmap for 3scale
+	smtpUpdated := false
 	_, err = controllerutil.CreateOrUpdate(ctx, serverClient, smtpConfigSecret, func() error {
 		owner.AddIntegreatlyOwnerAnnotations(smtpConfigSecret, r.installation)
 		if smtpConfigSecret.Data == nil {
 			smtpConfigSecret.Data = map[string][]byte{}
 		}
 
-		smtpUpdated := false
-
 		if string(credSec.Data["host"]) != string(smtpConfigSecret.Data["address"]) {
 			smtpConfigSecret.Data["address"] = credSec.Data["host"]
 			smtpUpdated = true
@@ -512,24 +525,16 @@ func (r *Reconciler) reconcileSMTPCredentials(ctx context.Context, serverClient
 			smtpUpdated = true
 		}
 
-		if smtpUpdated {
-			err = r.RolloutDeployment(ctx, "system-app")
-			if err != nil {
-				r.log.Error("Rollout system-app deployment", err)
-			}
-
-			err = r.RolloutDeployment(ctx, "system-sidekiq")
-			if err != nil {
-				r.log.Error("Rollout system-sidekiq deployment", err)
-			}
-		}
-
 		return nil
 	})
 	if err != nil {
 		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to create or update 3scale smtp configmap: %w", err)
 	}
 
+	if err := r.rolloutSystemDeployments(ctx, smtpUpdated); err != nil {
+		r.log.Error("Rollout system deployments", err)
+	}
+
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
@@ -596,6 +601,14 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, serverClient k8scl
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
+	autoscaledReplicas := map[string]int32{}
+	if r.installation.Spec.Type == string(integreatlyv1alpha1.InstallationTypeManagedApi) {
+		autoscaledReplicas, err = r.reconcileAutoscalers(ctx, serverClient, productConfig)
+		if err != nil {
+			return integreatlyv1alpha1.PhaseFailed, err
+		}
+	}
+
 	status, err := controllerutil.CreateOrUpdate(ctx, serverClient, apim, func() error {
 
 		apim.Spec.HighAvailability = &threescalev1.HighAvailabilitySpec{Enabled: true}
@@ -699,6 +712,12 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, serverClient k8scl
 			if err != nil {
 				return err
 			}
+
+			// The 3scale operator reverts the replicas of the deployment
+			// configs to the APIManager, so it follows the autoscalers
+			setAutoscaledReplicas(apim.Spec.Apicast.ProductionSpec.Replicas, autoscaledReplicas[quota.ApicastProductionName])
+			setAutoscaledReplicas(apim.Spec.Backend.ListenerSpec.Replicas, autoscaledReplicas[quota.BackendListenerName])
+			setAutoscaledReplicas(apim.Spec.Backend.WorkerSpec.Replicas, autoscaledReplicas[quota.BackendWorkerName])
 		}
 
 		owner.AddIntegreatlyOwnerAnnotations(apim, r.installation)
@@ -748,6 +767,36 @@ func (r *Reconciler) reconcileComponents(ctx context.Context, serverClient k8scl
 	return integreatlyv1alpha1.PhaseInProgress, nil
 }
 
+// reconcileAutoscalers reconciles the autoscalers of the deployment configs
+// autoscaled by the quota, and returns the replicas they desire
+func (r *Reconciler) reconcileAutoscalers(ctx context.Context, serverClient k8sclient.Client, productConfig quota.ProductConfig) (map[string]int32, error) {
+	deploymentConfigs := map[string]string{
+		quota.ApicastProductionName: "apicast-production",
+		quota.BackendListenerName:   "backend-listener",
+		quota.BackendWorkerName:     "backend-worker",
+	}
+
+	replicas := map[string]int32{}
+	for name, dc := range deploymentConfigs {
+		desired, err := quota.ReconcileAutoscaler(ctx, serverClient, productConfig, name, r.Config.GetNamespace(), autoscalingv1.CrossVersionObjectReference{
+			APIVersion: appsv1.GroupVersion.String(),
+			Kind:       "DeploymentConfig",
+			Name:       dc,
+		})
+		if err != nil {
+			return nil, err
+		}
+		replicas[name] = desired
+	}
+	return replicas, nil
+}
+
+func setAutoscaledReplicas(replicas *int64, desired int32) {
+	if desired > 0 {
+		*replicas = int64(desired)
+	}
+}
+
 func (r *Reconciler) routesExist(ctx context.Context, serverClient k8sclient.Client) (bool, error) {
 	expectedRoutes := 4
 	opts := k8sclient.ListOptions{
@@ -790,7 +839,15 @@ func (r *Reconciler) resyncRoutes(ctx context.Context, client k8sclient.Client)
 		return integreatlyv1alpha1.PhaseInProgress, nil
 	}
 
-	stdout, stderr, err := resources.ExecuteRemoteCommand(ns, podname, "bundle exec rake zync:resync:domains", r.log)
+	command := "bundle exec rake zync:resync:domains"
+	// The command runs outside of the client, so it's only recorded in dry
+	// run mode
+	if dryRunClient, ok := client.(*dryrun.Client); ok {
+		dryRunClient.RecordExec(ns, podname, command)
+		return integreatlyv1alpha1.PhaseInProgress, nil
+	}
+
+	stdout, stderr, err := resources.ExecuteRemoteCommand(ns, podname, command, r.log)
 	if err != nil {
 		r.log.Error("Failed to resync 3Scale routes", err)
 		return integreatlyv1alpha1.PhaseFailed, nil
@@ -1334,30 +1391,69 @@ func (r *Reconciler) updateKeycloakUsersAttributeWith3ScaleUserId(ctx context.Co
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
-func (r *Reconciler) preUpgradeBackupExecutor() backup.BackupExecutor {
-	if r.installation.Spec.UseClusterStorage != "false" {
-		return backup.NewNoopBackupExecutor()
-	}
-
+// PreUpgradeBackupExecutor returns the backup of the 3scale postgres and redis
+// instances, taken before upgrades and by RHMIBackup
+func (r *Reconciler) PreUpgradeBackupExecutor() backup.BackupExecutor {
 	return backup.NewConcurrentBackupExecutor(
-		backup.NewAWSBackupExecutor(
-			r.installation.Namespace,
+		backup.NewInstallationBackupExecutor(
+			r.installation,
 			"threescale-postgres-rhmi",
 			backup.PostgresSnapshotType,
 		),
-		backup.NewAWSBackupExecutor(
-			r.installation.Namespace,
+		backup.NewInstallationBackupExecutor(
+			r.installation,
 			"threescale-backend-redis-rhmi",
 			backup.RedisSnapshotType,
 		),
-		backup.NewAWSBackupExecutor(
-			r.installation.Namespace,
+		backup.NewInstallationBackupExecutor(
+			r.installation,
 			"threescale-redis-rhmi",
 			backup.RedisSnapshotType,
 		),
 	)
 }
 
+// BackupSanityChecks returns the checks run against the 3scale instances
+// restored to verify their backups. The postgres must have the system
+// accounts, and the redis instances hold the backend configuration and the
+// system queues, which are never empty once 3scale is installed
+func (r *Reconciler) BackupSanityChecks() map[string]integreatlyv1alpha1.BackupSanityCheck {
+	return map[string]integreatlyv1alpha1.BackupSanityCheck{
+		"threescale-postgres-rhmi":      {Query: "SELECT count(*) FROM accounts", Min: 1},
+		"threescale-backend-redis-rhmi": {Query: "DBSIZE", Min: 1},
+		"threescale-redis-rhmi":         {Query: "DBSIZE", Min: 1},
+	}
+}
+
+// RestoreWorkloads returns the 3scale components using the postgres and redis
+// instances, stopped while they're restored by RHMIRestore. They're scaled
+// through the APIManager, as the 3scale operator reverts changes to the
+// deployment configs
+func (r *Reconciler) RestoreWorkloads() []backup.Workload {
+	components := []struct {
+		path             string
+		deploymentConfig string
+	}{
+		{path: "spec.system.appSpec.replicas", deploymentConfig: "system-app"},
+		{path: "spec.system.sidekiqSpec.replicas", deploymentConfig: "system-sidekiq"},
+		{path: "spec.backend.listenerSpec.replicas", deploymentConfig: "backend-listener"},
+		{path: "spec.backend.workerSpec.replicas", deploymentConfig: "backend-worker"},
+		{path: "spec.backend.cronSpec.replicas", deploymentConfig: "backend-cron"},
+	}
+
+	workloads := []backup.Workload{}
+	for _, component := range components {
+		workloads = append(workloads, backup.Workload{
+			GroupVersionKind: threescalev1.SchemeGroupVersion.WithKind("APIManager"),
+			Name:             apiManagerName,
+			Namespace:        r.Config.GetNamespace(),
+			ReplicasPath:     component.path,
+			PodSelector:      map[string]string{"deploymentConfig": component.deploymentConfig},
+		})
+	}
+	return workloads
+}
+
 func syncOpenshiftAdminMembership(openshiftAdminGroup *usersv1.Group, newTsUsers *Users, systemAdminUsername string, isWorkshop bool, tsClient ThreeScaleInterface, accessToken string) error {
 	for _, tsUser := range newTsUsers.Users {
 		// skip if ts user is the system user admin
@@ -1412,18 +1508,9 @@ func (r *Reconciler) reconcileServiceDiscovery(ctx context.Context, serverClient
 		return integreatlyv1alpha1.PhaseInProgress, err
 	}
 
-	if status != controllerutil.OperationResultNone {
-		err = r.RolloutDeployment(ctx, "system-app")
-		if err != nil {
-			r.log.Info("Failed to rollout deployment (system-app):" + err.Error())
-			return integreatlyv1alpha1.PhaseInProgress, err
-		}
-
-		err = r.RolloutDeployment(ctx, "system-sidekiq")
-		if err != nil {
-			r.log.Info("Failed to rollout deployment (system-sidekiq)" + err.Error())
-			return integreatlyv1alpha1.PhaseInProgress, err
-		}
+	if err := r.rolloutSystemDeployments(ctx, status != controllerutil.OperationResultNone); err != nil {
+		r.log.Info("Failed to rollout system deployments:" + err.Error())
+		return integreatlyv1alpha1.PhaseInProgress, err
 	}
 
 	return integreatlyv1alpha1.PhaseCompleted, nil
@@ -1566,6 +1653,33 @@ func (r *Reconciler) GetAdminToken(ctx context.Context, serverClient k8sclient.C
 	return &accessToken, nil
 }
 
+// rolloutSystemDeployments rolls out system-app and system-sidekiq to load a
+// change of their configuration. Outside of the maintenance window the
+// rollout is deferred, and retried by the following reconciles until it runs
+func (r *Reconciler) rolloutSystemDeployments(ctx context.Context, configChanged bool) error {
+	gate := maintenance.GateFromContext(ctx)
+	if !configChanged && !gate.IsPending(integreatlyv1alpha1.Product3Scale, rolloutSystemAction) {
+		return nil
+	}
+
+	allowed, err := gate.Allow(ctx, integreatlyv1alpha1.Product3Scale, rolloutSystemAction)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		r.log.Info("Rollout of the system deployments deferred until the maintenance window")
+		return nil
+	}
+
+	if err := r.RolloutDeployment(ctx, "system-app"); err != nil {
+		return fmt.Errorf("failed to rollout deployment (system-app): %w", err)
+	}
+	if err := r.RolloutDeployment(ctx, "system-sidekiq"); err != nil {
+		return fmt.Errorf("failed to rollout deployment (system-sidekiq): %w", err)
+	}
+	return nil
+}
+
 func (r *Reconciler) RolloutDeployment(ctx context.Context, name string) error {
 	_, err := r.appsv1Client.DeploymentConfigs(r.Config.GetNamespace()).Instantiate(ctx, name, &appsv1.DeploymentRequest{
 		Name:   name,
@@ -1911,7 +2025,7 @@ func (r *Reconciler) reconcileSubscription(ctx context.Context, serverClient k8s
 		ctx,
 		target,
 		[]string{productNamespace},
-		r.preUpgradeBackupExecutor(),
+		r.PreUpgradeBackupExecutor(),
 		serverClient,
 		catalogSourceReconciler,
 		r.log,
@@ -2119,7 +2233,7 @@ func (r *Reconciler) ensureDeploymentConfigsReady(ctx context.Context, serverCli
 	return integreatlyv1alpha1.PhaseCompleted, nil
 }
 
-func (r *Reconciler) reconcileRatelimitingTo3scaleComponents(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI) (integreatlyv1alpha1.StatusPhase, error) {
+func (r *Reconciler) reconcileRatelimitingTo3scaleComponents(ctx context.Context, serverClient k8sclient.Client, installation *integreatlyv1alpha1.RHMI, rateLimitConfig marin3rconfig.RateLimitConfig) (integreatlyv1alpha1.StatusPhase, error) {
 
 	r.log.Info("Reconciling rate limiting settings to 3scale components")
 
@@ -2177,6 +2291,16 @@ func (r *Reconciler) reconcileRatelimitingTo3scaleComponents(ctx context.Context
 		return integreatlyv1alpha1.PhaseFailed, err
 	}
 
+	descriptors, err := marin3rconfig.GetDescriptorsConfig(ctx, serverClient, installation.Namespace)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to obtain rate limit descriptors config: %w", err)
+	}
+
+	exemptions, err := marin3rconfig.GetExemptionsConfig(ctx, serverClient, installation.Namespace)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, fmt.Errorf("failed to obtain rate limit exemptions config: %w", err)
+	}
+
 	// rate limit cluster
 	ratelimitClusterResource := ratelimit.CreateClusterResource(
 		ratelimitServiceCR.Spec.ClusterIP,
@@ -2194,19 +2318,23 @@ func (r *Reconciler) reconcileRatelimitingTo3scaleComponents(ctx context.Context
 
 	// apicast listener
 	apiCastFilters, _ := getListenerResourceFilters(
-		getAPICastVirtualHosts(ApicastClusterName),
+		getAPICastVirtualHosts(ApicastClusterName, rateLimitConfig.Windows, descriptors, exemptions),
 		getAPICastHTTPFilters(),
 	)
-	apiCastListenerResource := ratelimit.CreateListenerResource(
+	apiCastExemptFilterChain, err := getExemptFilterChain(ApicastClusterName, getAPICastHTTPFilters(), exemptions)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	apiCastListenerResource := withExemptFilterChain(ratelimit.CreateListenerResource(
 		ApicastListenerName,
 		ApicastEnvoyProxyAddress,
 		ApicastEnvoyProxyPort,
 		apiCastFilters,
-	)
+	), apiCastExemptFilterChain)
 
 	// create envoy config for apicast
 	apiCastProxyConfig := ratelimit.NewEnvoyConfig(ApicastClusterName, r.Config.GetNamespace(), ApicastNodeID)
-	err = apiCastProxyConfig.CreateEnvoyConfig(ctx, serverClient, []*envoyapi.Cluster{apiCastClusterResource, ratelimitClusterResource}, []*envoyapi.Listener{apiCastListenerResource}, installation)
+	err = apiCastProxyConfig.CreateEnvoyConfig(ctx, serverClient, withExemptCluster([]*envoyapi.Cluster{apiCastClusterResource, ratelimitClusterResource}, apiCastClusterResource, exemptions), []*envoyapi.Listener{apiCastListenerResource}, installation)
 	if err != nil {
 		r.log.Errorf("Failed to create envoyconfig for apicast", l.Fields{"APICast": ApicastClusterName}, err)
 		return integreatlyv1alpha1.PhaseFailed, err
@@ -2222,19 +2350,23 @@ func (r *Reconciler) reconcileRatelimitingTo3scaleComponents(ctx context.Context
 	backendHTTPFilters, _ := getBackendListenerHTTPFilters()
 	// backend listener listener
 	backendFilters, _ := getListenerResourceFilters(
-		getBackendListenerVitualHosts(BackendClusterName),
+		getBackendListenerVitualHosts(BackendClusterName, rateLimitConfig.Windows, descriptors, exemptions),
 		backendHTTPFilters,
 	)
-	backendListenerResource := ratelimit.CreateListenerResource(
+	backendExemptFilterChain, err := getExemptFilterChain(BackendClusterName, backendHTTPFilters, exemptions)
+	if err != nil {
+		return integreatlyv1alpha1.PhaseFailed, err
+	}
+	backendListenerResource := withExemptFilterChain(ratelimit.CreateListenerResource(
 		BackendListenerName,
 		BackendEnvoyProxyAddress,
 		BackendEnvoyProxyPort,
 		backendFilters,
-	)
+	), backendExemptFilterChain)
 
 	// create envoy config for backend listener
 	backendProxyConfig := ratelimit.NewEnvoyConfig(BackendClusterName, r.Config.GetNamespace(), BackendNodeID)
-	err = backendProxyConfig.CreateEnvoyConfig(ctx, serverClient, []*envoyapi.Cluster{backendClusterResource, ratelimitClusterResource}, []*envoyapi.Listener{backendListenerResource}, installation)
+	err = backendProxyConfig.CreateEnvoyConfig(ctx, serverClient, withExemptCluster([]*envoyapi.Cluster{backendClusterResource, ratelimitClusterResource}, backendClusterResource, exemptions), []*envoyapi.Listener{backendListenerResource}, installation)
 	if err != nil {
 		r.log.Errorf("Failed to create envoyconfig for backend-listener", l.Fields{"BackendListener": BackendClusterName}, err)
 		return integreatlyv1alpha1.PhaseFailed, err
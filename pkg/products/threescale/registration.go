@@ -0,0 +1,45 @@
+package threescale
+
+import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/products/registry"
+
+	appsv1Client "github.com/openshift/client-go/apps/clientset/versioned/typed/apps/v1"
+	"k8s.io/client-go/rest"
+)
+
+func init() {
+	registry.Register(integreatlyv1alpha1.Product3Scale, registry.Registration{
+		Dependencies: []integreatlyv1alpha1.ProductName{integreatlyv1alpha1.ProductRHSSO},
+		Factory: func(opts *registry.ReconcilerOptions) (registry.Interface, error) {
+			client, err := appsv1Client.NewForConfig(opts.RestConfig)
+			if err != nil {
+				return nil, err
+			}
+			client.RESTClient().(*rest.RESTClient).Client.Timeout = 10 * time.Second
+
+			oauthv1Client, err := opts.OauthClient()
+			if err != nil {
+				return nil, err
+			}
+
+			httpc := opts.HTTPClient(&http.Client{
+				Timeout: time.Second * 10,
+				Transport: &http.Transport{
+					DisableKeepAlives: true,
+					IdleConnTimeout:   time.Second * 10,
+					TLSClientConfig:   &tls.Config{InsecureSkipVerify: opts.Installation.Spec.SelfSignedCerts},
+				},
+			})
+
+			tsClient := NewThreeScaleClient(httpc, opts.Installation.Spec.RoutingSubdomain)
+			return NewReconciler(opts.ConfigManager, opts.Installation, client, oauthv1Client, tsClient, opts.MPM, opts.Recorder, opts.Log, opts.ProductDeclaration)
+		},
+		PreflightObject: preflightObject,
+		VerifyVersion:   verifyVersion,
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
 	crotypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,6 +33,10 @@ func NewAWSBackupExecutor(snapshotNamespace, resourceName string, snapshotType A
 	}
 }
 
+// preUpgradeSnapshotInfix separates the name of the resource from the time in
+// the names of the pre-upgrade snapshots
+const preUpgradeSnapshotInfix = "-preupgrade-snapshot-"
+
 // AWSSnapshotType represents the type of snapshot to create
 type AWSSnapshotType string
 
@@ -45,15 +50,42 @@ const (
 // PerformBackup creates a snapshot CR and waits until the status of the CR
 // is `complete`
 func (e *AWSBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.Duration) error {
-	log.Infof("Performing backup on AWS", l.Fields{"snapshotType": e.SnapshotType, "resourceName": e.ResourceName})
+	snapshotName := fmt.Sprintf("%s%s%s", e.ResourceName, preUpgradeSnapshotInfix, time.Now().Format("2006-01-02-150405"))
+	return e.PerformNamedBackup(client, snapshotName, timeout)
+}
+
+// ComponentName returns the name of the AWS resource
+func (e *AWSBackupExecutor) ComponentName() string {
+	return e.ResourceName
+}
+
+// BackupType returns the kind of snapshot CR created
+func (e *AWSBackupExecutor) BackupType() string {
+	return string(e.SnapshotType)
+}
+
+// PerformNamedBackup creates a snapshot CR named snapshotName and waits until
+// the status of the CR is `complete`
+func (e *AWSBackupExecutor) PerformNamedBackup(client k8sclient.Client, snapshotName string, timeout time.Duration) error {
+	if err := e.StartNamedBackup(client, snapshotName); err != nil {
+		return err
+	}
+	return waitForBackup(client, e, snapshotName, timeout)
+}
 
-	snapshotName := fmt.Sprintf("%s-preupgrade-snapshot-%s", e.ResourceName, time.Now().Format("2006-01-02-150405"))
+// StartNamedBackup creates a snapshot CR named snapshotName, unless it
+// already exists
+func (e *AWSBackupExecutor) StartNamedBackup(client k8sclient.Client, snapshotName string) error {
+	log.Infof("Performing backup on AWS", l.Fields{"snapshotType": e.SnapshotType, "resourceName": e.ResourceName, "snapshotName": snapshotName})
 
 	// Initialize the snapshot CR based on the snapshot type
 	var snapshotCR runtime.Object
 	commonObjectMeta := v1.ObjectMeta{
 		Namespace: e.SnapshotNamespace,
 		Name:      snapshotName,
+		// the label marks the snapshot as created by the operator, only
+		// those are pruned
+		Labels: map[string]string{"integreatly": "yes", backupComponentKey: e.ResourceName},
 	}
 
 	switch e.SnapshotType {
@@ -78,11 +110,17 @@ func (e *AWSBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.
 
 	// Create the CR
 	err := client.Create(context.TODO(), snapshotCR)
-	if err != nil {
+	if err != nil && !k8serr.IsAlreadyExists(err) {
 		return fmt.Errorf("Error creating %s for backup of resource %s: %v",
 			e.SnapshotType, e.ResourceName, err)
 	}
 
+	return nil
+}
+
+// CheckNamedBackup returns true once the status of the snapshot CR
+// snapshotName is `complete`, and an error if the snapshot failed
+func (e *AWSBackupExecutor) CheckNamedBackup(client k8sclient.Client, snapshotName string) (bool, error) {
 	// Initialize the CR to query it's completion
 	var queryCR runtime.Object
 	switch e.SnapshotType {
@@ -90,49 +128,38 @@ func (e *AWSBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.
 		queryCR = &v1alpha1.PostgresSnapshot{}
 	case RedisSnapshotType:
 		queryCR = &v1alpha1.RedisSnapshot{}
+	default:
+		return false, fmt.Errorf("Unsupported value for AWSShapshotType. Expected %s or %s, got %s",
+			PostgresSnapshotType, RedisSnapshotType, e.SnapshotType)
 	}
 
-	// Request the CR status until it's complete or it times out
-	started := time.Now()
-	for {
-		// If it times out, return an error
-		if time.Now().After(started.Add(timeout)) {
-			return fmt.Errorf("Snapshot of %s %s timed out", e.ResourceName, e.SnapshotType)
-		}
-
-		// Get the CR
-		err = client.Get(context.TODO(), types.NamespacedName{
-			Name:      snapshotName,
-			Namespace: e.SnapshotNamespace,
-		}, queryCR)
-		if err != nil {
-			return fmt.Errorf("Error occurred querying snapshot for backup %s", e.ResourceName)
-		}
-
-		// Get the phase
-		var phase crotypes.StatusPhase
-		var message crotypes.StatusMessage
-		switch e.SnapshotType {
-		case PostgresSnapshotType:
-			typedSnapshotCR := queryCR.(*v1alpha1.PostgresSnapshot)
-			phase = typedSnapshotCR.Status.Phase
-			message = typedSnapshotCR.Status.Message
-		case RedisSnapshotType:
-			typedSnapshotCR := queryCR.(*v1alpha1.RedisSnapshot)
-			phase = typedSnapshotCR.Status.Phase
-			message = typedSnapshotCR.Status.Message
-		}
+	// Get the CR
+	err := client.Get(context.TODO(), types.NamespacedName{
+		Name:      snapshotName,
+		Namespace: e.SnapshotNamespace,
+	}, queryCR)
+	if err != nil {
+		return false, fmt.Errorf("Error occurred querying snapshot for backup %s", e.ResourceName)
+	}
 
-		// If the snapshot failed, return an error with the message
-		if phase == crotypes.PhaseFailed {
-			return fmt.Errorf("Snapshot failed: %s", message)
-		}
+	// Get the phase
+	var phase crotypes.StatusPhase
+	var message crotypes.StatusMessage
+	switch e.SnapshotType {
+	case PostgresSnapshotType:
+		typedSnapshotCR := queryCR.(*v1alpha1.PostgresSnapshot)
+		phase = typedSnapshotCR.Status.Phase
+		message = typedSnapshotCR.Status.Message
+	case RedisSnapshotType:
+		typedSnapshotCR := queryCR.(*v1alpha1.RedisSnapshot)
+		phase = typedSnapshotCR.Status.Phase
+		message = typedSnapshotCR.Status.Message
+	}
 
-		// If it's complete, break the loop
-		if phase == crotypes.PhaseComplete {
-			break
-		}
+	// If the snapshot failed, return an error with the message
+	if phase == crotypes.PhaseFailed {
+		return false, fmt.Errorf("Snapshot failed: %s", message)
 	}
 
-	return nil
+	return phase == crotypes.PhaseComplete, nil
 }
@@ -0,0 +1,526 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/elasticache"
+	"github.com/aws/aws-sdk-go/service/elasticache/elasticacheiface"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	crotypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// awsCredentialsSecretName is the secret with the AWS credentials of
+	// the cloud resource operator, in the namespace of its CRs
+	awsCredentialsSecretName = "cloud-resources-aws-credentials"
+	// awsStrategiesConfigMapName is the config map with the AWS strategies
+	// of the cloud resource operator
+	awsStrategiesConfigMapName = "cloud-resources-aws-strategies"
+
+	// restoreAnnotation records the progress of the restore of a Postgres
+	// or Redis CR
+	restoreAnnotation = "integreatly.org/restore"
+
+	rdsStatusAvailable         = "available"
+	rdsStatusDeleting          = "deleting"
+	elasticacheStatusAvailable = "available"
+	elasticacheStatusDeleting  = "deleting"
+)
+
+// AWSClients are the clients of the AWS APIs used to restore snapshots
+type AWSClients struct {
+	RDS         rdsiface.RDSAPI
+	ElastiCache elasticacheiface.ElastiCacheAPI
+}
+
+// AWSRestoreExecutor restores the snapshots created by the
+// AWSBackupExecutor. The instance of the resource is replaced with one
+// created from the snapshot, keeping its identifier so the connection
+// details of the resource don't change. The progress of the restore is
+// recorded in an annotation of the Postgres or Redis CR, so it's resumed
+// after a restart of the operator
+type AWSRestoreExecutor struct {
+	SnapshotNamespace string          // Namespace of the snapshot CR
+	ResourceName      string          // AWS Resource name
+	SnapshotType      AWSSnapshotType // Type of snapshot CR to restore
+
+	// NewAWSClients returns the clients of the AWS APIs for the tier of the
+	// resource
+	NewAWSClients func(ctx context.Context, client k8sclient.Client, namespace string, resourceType providers.ResourceType, tier string) (*AWSClients, error)
+}
+
+func NewAWSRestoreExecutor(snapshotNamespace, resourceName string, snapshotType AWSSnapshotType) RestoreExecutor {
+	return &AWSRestoreExecutor{
+		SnapshotNamespace: snapshotNamespace,
+		ResourceName:      resourceName,
+		SnapshotType:      snapshotType,
+		NewAWSClients:     newAWSClients,
+	}
+}
+
+// awsRestorePhase is the progress of the restore of an AWS instance
+type awsRestorePhase string
+
+const (
+	// awsRestoreDeleting waits for the deletion of the current instance
+	awsRestoreDeleting awsRestorePhase = "deleting"
+	// awsRestoreRestoring waits for the instance restored from the snapshot
+	// to be available
+	awsRestoreRestoring awsRestorePhase = "restoring"
+	// awsRestoreFailed is set when the current instance couldn't be
+	// deleted, it's left untouched
+	awsRestoreFailed awsRestorePhase = "failed"
+)
+
+// awsRestoreState is recorded in the restoreAnnotation of the Postgres or
+// Redis CR while its instance is restored. The settings of the instance are
+// recorded before it's deleted, as they can't be read from AWS afterwards
+type awsRestoreState struct {
+	Snapshot string          `json:"snapshot"`
+	Phase    awsRestorePhase `json:"phase"`
+	Error    string          `json:"error,omitempty"`
+
+	Postgres *rds.RestoreDBInstanceFromDBSnapshotInput `json:"postgres,omitempty"`
+	Redis    *elasticache.CreateReplicationGroupInput  `json:"redis,omitempty"`
+}
+
+// ComponentName returns the name of the AWS resource
+func (e *AWSRestoreExecutor) ComponentName() string {
+	return e.ResourceName
+}
+
+// StartRestore records the settings of the instance of the resource, and
+// stops its reconcile by the cloud resource operator, which would create an
+// empty instance while it's replaced. The instance is replaced by
+// CheckRestore
+func (e *AWSRestoreExecutor) StartRestore(client k8sclient.Client, snapshotName string) error {
+	ctx := context.TODO()
+
+	cr, err := e.cloudResource(client)
+	if err != nil {
+		return err
+	}
+	state, err := getRestoreState(cr)
+	if err != nil {
+		return err
+	}
+	if state != nil && state.Phase != awsRestoreFailed {
+		if state.Snapshot == snapshotName {
+			return nil
+		}
+		return fmt.Errorf("%s %s is being restored from %s", e.SnapshotType, e.ResourceName, state.Snapshot)
+	}
+
+	log.Infof("Performing restore on AWS", l.Fields{"snapshotType": e.SnapshotType, "resourceName": e.ResourceName, "snapshotName": snapshotName})
+
+	awsClients, err := e.awsClients(ctx, client, cr)
+	if err != nil {
+		return err
+	}
+
+	state = &awsRestoreState{Snapshot: snapshotName, Phase: awsRestoreDeleting}
+	switch resource := cr.(type) {
+	case *v1alpha1.Postgres:
+		state.Postgres, err = e.postgresRestoreInput(client, resource, awsClients, snapshotName)
+	case *v1alpha1.Redis:
+		state.Redis, err = e.redisRestoreInput(client, resource, awsClients, snapshotName)
+	}
+	if err != nil {
+		return err
+	}
+
+	return setRestoreState(client, cr, state)
+}
+
+// CheckRestore replaces the instance of the resource once it's deleted, and
+// returns true once the instance restored from the snapshot is available.
+// The reconcile of the resource by the cloud resource operator is resumed
+// then, or when the current instance couldn't be deleted
+func (e *AWSRestoreExecutor) CheckRestore(client k8sclient.Client, snapshotName string) (bool, error) {
+	ctx := context.TODO()
+
+	cr, err := e.cloudResource(client)
+	if err != nil {
+		return false, err
+	}
+	state, err := getRestoreState(cr)
+	if err != nil {
+		return false, err
+	}
+	if state == nil {
+		// The annotation is removed once the restore completes
+		return true, nil
+	}
+	if state.Snapshot != snapshotName {
+		return false, fmt.Errorf("%s %s is being restored from %s", e.SnapshotType, e.ResourceName, state.Snapshot)
+	}
+	if state.Phase == awsRestoreFailed {
+		return false, errors.New(state.Error)
+	}
+
+	awsClients, err := e.awsClients(ctx, client, cr)
+	if err != nil {
+		return false, err
+	}
+
+	var phase awsRestorePhase
+	var available bool
+	switch {
+	case state.Postgres != nil:
+		phase, available, err = checkPostgresRestore(awsClients.RDS, state)
+	case state.Redis != nil:
+		phase, available, err = checkRedisRestore(awsClients.ElastiCache, state)
+	default:
+		err = fmt.Errorf("restore of %s %s has no instance settings", e.SnapshotType, e.ResourceName)
+	}
+
+	switch {
+	case phase == awsRestoreFailed:
+		// The instance is untouched, its reconcile is resumed
+		state.Phase = awsRestoreFailed
+		state.Error = err.Error()
+		if saveErr := setRestoreState(client, cr, state); saveErr != nil {
+			return false, fmt.Errorf("%v, and %w", err, saveErr)
+		}
+		return false, err
+	case err != nil:
+		// From here on the instance may be gone, the creation of the
+		// instance stays skipped so it can be restored manually
+		return false, err
+	case available:
+		log.Infof("Restore on AWS completed", l.Fields{"snapshotType": e.SnapshotType, "resourceName": e.ResourceName, "snapshotName": snapshotName})
+		return true, setRestoreState(client, cr, nil)
+	case phase != state.Phase:
+		state.Phase = phase
+		return false, setRestoreState(client, cr, state)
+	}
+	return false, nil
+}
+
+// checkPostgresRestore deletes the RDS instance, then restores it from the
+// snapshot once it's gone. It returns the new phase of the restore, and true
+// once the restored instance is available
+func checkPostgresRestore(rdsClient rdsiface.RDSAPI, state *awsRestoreState) (awsRestorePhase, bool, error) {
+	input := state.Postgres
+	instanceID := aws.StringValue(input.DBInstanceIdentifier)
+
+	instances, err := rdsClient.DescribeDBInstances(&rds.DescribeDBInstancesInput{DBInstanceIdentifier: input.DBInstanceIdentifier})
+	if isAWSErrorCode(err, rds.ErrCodeDBInstanceNotFoundFault) || (err == nil && len(instances.DBInstances) == 0) {
+		// Either the deletion completed, or the restored instance is not
+		// listed yet
+		_, err := rdsClient.RestoreDBInstanceFromDBSnapshot(input)
+		if err != nil && !isAWSErrorCode(err, rds.ErrCodeDBInstanceAlreadyExistsFault) {
+			return state.Phase, false, fmt.Errorf("failed to restore RDS instance %s from snapshot %s: %w", instanceID, aws.StringValue(input.DBSnapshotIdentifier), err)
+		}
+		return awsRestoreRestoring, false, nil
+	}
+	if err != nil {
+		return state.Phase, false, fmt.Errorf("failed to describe RDS instance %s: %w", instanceID, err)
+	}
+	instance := instances.DBInstances[0]
+
+	if state.Phase == awsRestoreRestoring {
+		return awsRestoreRestoring, aws.StringValue(instance.DBInstanceStatus) == rdsStatusAvailable, nil
+	}
+	if aws.StringValue(instance.DBInstanceStatus) == rdsStatusDeleting {
+		return awsRestoreDeleting, false, nil
+	}
+
+	deletionProtection := aws.BoolValue(instance.DeletionProtection)
+	if deletionProtection {
+		_, err := rdsClient.ModifyDBInstance(&rds.ModifyDBInstanceInput{
+			DBInstanceIdentifier: input.DBInstanceIdentifier,
+			DeletionProtection:   aws.Bool(false),
+			ApplyImmediately:     aws.Bool(true),
+		})
+		if err != nil {
+			return awsRestoreFailed, false, fmt.Errorf("failed to remove the deletion protection of RDS instance %s: %w", instanceID, err)
+		}
+	}
+
+	_, err = rdsClient.DeleteDBInstance(&rds.DeleteDBInstanceInput{
+		DBInstanceIdentifier:   input.DBInstanceIdentifier,
+		SkipFinalSnapshot:      aws.Bool(true),
+		DeleteAutomatedBackups: aws.Bool(false),
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to delete RDS instance %s: %w", instanceID, err)
+		if deletionProtection {
+			_, protectErr := rdsClient.ModifyDBInstance(&rds.ModifyDBInstanceInput{
+				DBInstanceIdentifier: input.DBInstanceIdentifier,
+				DeletionProtection:   aws.Bool(true),
+				ApplyImmediately:     aws.Bool(true),
+			})
+			if protectErr != nil {
+				err = fmt.Errorf("%v, and failed to restore its deletion protection: %w", err, protectErr)
+			}
+		}
+		return awsRestoreFailed, false, err
+	}
+	return awsRestoreDeleting, false, nil
+}
+
+// checkRedisRestore deletes the ElastiCache replication group, then
+// restores it from the snapshot once it's gone. It returns the new phase of
+// the restore, and true once the restored group is available
+func checkRedisRestore(elasticacheClient elasticacheiface.ElastiCacheAPI, state *awsRestoreState) (awsRestorePhase, bool, error) {
+	input := state.Redis
+	groupID := aws.StringValue(input.ReplicationGroupId)
+
+	groups, err := elasticacheClient.DescribeReplicationGroups(&elasticache.DescribeReplicationGroupsInput{ReplicationGroupId: input.ReplicationGroupId})
+	if isAWSErrorCode(err, elasticache.ErrCodeReplicationGroupNotFoundFault) || (err == nil && len(groups.ReplicationGroups) == 0) {
+		_, err := elasticacheClient.CreateReplicationGroup(input)
+		if err != nil && !isAWSErrorCode(err, elasticache.ErrCodeReplicationGroupAlreadyExistsFault) {
+			return state.Phase, false, fmt.Errorf("failed to restore ElastiCache replication group %s from snapshot %s: %w", groupID, aws.StringValue(input.SnapshotName), err)
+		}
+		return awsRestoreRestoring, false, nil
+	}
+	if err != nil {
+		return state.Phase, false, fmt.Errorf("failed to describe ElastiCache replication group %s: %w", groupID, err)
+	}
+	group := groups.ReplicationGroups[0]
+
+	if state.Phase == awsRestoreRestoring {
+		return awsRestoreRestoring, aws.StringValue(group.Status) == elasticacheStatusAvailable, nil
+	}
+	if aws.StringValue(group.Status) == elasticacheStatusDeleting {
+		return awsRestoreDeleting, false, nil
+	}
+
+	_, err = elasticacheClient.DeleteReplicationGroup(&elasticache.DeleteReplicationGroupInput{
+		ReplicationGroupId:   input.ReplicationGroupId,
+		RetainPrimaryCluster: aws.Bool(false),
+	})
+	if err != nil {
+		return awsRestoreFailed, false, fmt.Errorf("failed to delete ElastiCache replication group %s: %w", groupID, err)
+	}
+	return awsRestoreDeleting, false, nil
+}
+
+// postgresRestoreInput returns the restore of the RDS instance of the
+// Postgres CR from the snapshot, with the settings of the current instance
+func (e *AWSRestoreExecutor) postgresRestoreInput(client k8sclient.Client, postgres *v1alpha1.Postgres, awsClients *AWSClients, snapshotName string) (*rds.RestoreDBInstanceFromDBSnapshotInput, error) {
+	snapshot := &v1alpha1.PostgresSnapshot{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: e.SnapshotNamespace}, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to get PostgresSnapshot %s: %w", snapshotName, err)
+	}
+	if snapshot.Status.Phase != crotypes.PhaseComplete || snapshot.Status.SnapshotID == "" {
+		return nil, fmt.Errorf("PostgresSnapshot %s is not complete", snapshotName)
+	}
+
+	instanceID := postgres.GetAnnotations()[croAWS.ResourceIdentifierAnnotation]
+	if instanceID == "" {
+		return nil, fmt.Errorf("Postgres %s has no AWS instance", e.ResourceName)
+	}
+
+	instances, err := awsClients.RDS.DescribeDBInstances(&rds.DescribeDBInstancesInput{DBInstanceIdentifier: aws.String(instanceID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe RDS instance %s: %w", instanceID, err)
+	}
+	if len(instances.DBInstances) == 0 {
+		return nil, fmt.Errorf("RDS instance %s not found", instanceID)
+	}
+	instance := instances.DBInstances[0]
+
+	securityGroups := []*string{}
+	for _, group := range instance.VpcSecurityGroups {
+		securityGroups = append(securityGroups, group.VpcSecurityGroupId)
+	}
+	var subnetGroup *string
+	if instance.DBSubnetGroup != nil {
+		subnetGroup = instance.DBSubnetGroup.DBSubnetGroupName
+	}
+
+	return &rds.RestoreDBInstanceFromDBSnapshotInput{
+		DBInstanceIdentifier: aws.String(instanceID),
+		DBSnapshotIdentifier: aws.String(snapshot.Status.SnapshotID),
+		DBInstanceClass:      instance.DBInstanceClass,
+		DBSubnetGroupName:    subnetGroup,
+		VpcSecurityGroupIds:  securityGroups,
+		MultiAZ:              instance.MultiAZ,
+		DeletionProtection:   aws.Bool(aws.BoolValue(instance.DeletionProtection)),
+	}, nil
+}
+
+// redisRestoreInput returns the restore of the ElastiCache replication group
+// of the Redis CR from the snapshot, with the settings of the current group
+func (e *AWSRestoreExecutor) redisRestoreInput(client k8sclient.Client, redis *v1alpha1.Redis, awsClients *AWSClients, snapshotName string) (*elasticache.CreateReplicationGroupInput, error) {
+	snapshot := &v1alpha1.RedisSnapshot{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: e.SnapshotNamespace}, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to get RedisSnapshot %s: %w", snapshotName, err)
+	}
+	if snapshot.Status.Phase != crotypes.PhaseComplete || snapshot.Status.SnapshotID == "" {
+		return nil, fmt.Errorf("RedisSnapshot %s is not complete", snapshotName)
+	}
+
+	groupID := redis.GetAnnotations()[croAWS.ResourceIdentifierAnnotation]
+	if groupID == "" {
+		return nil, fmt.Errorf("Redis %s has no AWS replication group", e.ResourceName)
+	}
+
+	groups, err := awsClients.ElastiCache.DescribeReplicationGroups(&elasticache.DescribeReplicationGroupsInput{ReplicationGroupId: aws.String(groupID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe ElastiCache replication group %s: %w", groupID, err)
+	}
+	if len(groups.ReplicationGroups) == 0 || len(groups.ReplicationGroups[0].MemberClusters) == 0 {
+		return nil, fmt.Errorf("ElastiCache replication group %s not found", groupID)
+	}
+	group := groups.ReplicationGroups[0]
+
+	// The subnet group, security groups and engine version are only known
+	// by the cache clusters of the group
+	clusters, err := awsClients.ElastiCache.DescribeCacheClusters(&elasticache.DescribeCacheClustersInput{CacheClusterId: group.MemberClusters[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the cache clusters of replication group %s: %w", groupID, err)
+	}
+	if len(clusters.CacheClusters) == 0 {
+		return nil, fmt.Errorf("no cache clusters found in replication group %s", groupID)
+	}
+	cluster := clusters.CacheClusters[0]
+
+	securityGroups := []*string{}
+	for _, group := range cluster.SecurityGroups {
+		securityGroups = append(securityGroups, group.SecurityGroupId)
+	}
+
+	return &elasticache.CreateReplicationGroupInput{
+		ReplicationGroupId:          aws.String(groupID),
+		ReplicationGroupDescription: group.Description,
+		SnapshotName:                aws.String(snapshot.Status.SnapshotID),
+		CacheNodeType:               group.CacheNodeType,
+		Engine:                      cluster.Engine,
+		EngineVersion:               cluster.EngineVersion,
+		NumCacheClusters:            aws.Int64(int64(len(group.MemberClusters))),
+		AutomaticFailoverEnabled:    aws.Bool(aws.StringValue(group.AutomaticFailover) == elasticache.AutomaticFailoverStatusEnabled),
+		CacheSubnetGroupName:        cluster.CacheSubnetGroupName,
+		SecurityGroupIds:            securityGroups,
+	}, nil
+}
+
+// cloudResource returns the Postgres or Redis CR of the resource
+func (e *AWSRestoreExecutor) cloudResource(client k8sclient.Client) (cloudResource, error) {
+	var cr cloudResource
+	switch e.SnapshotType {
+	case PostgresSnapshotType:
+		cr = &v1alpha1.Postgres{}
+	case RedisSnapshotType:
+		cr = &v1alpha1.Redis{}
+	default:
+		return nil, fmt.Errorf("Unsupported value for AWSShapshotType. Expected %s or %s, got %s",
+			PostgresSnapshotType, RedisSnapshotType, e.SnapshotType)
+	}
+
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: e.ResourceName, Namespace: e.SnapshotNamespace}, cr); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s: %w", e.SnapshotType, e.ResourceName, err)
+	}
+	return cr, nil
+}
+
+// awsClients returns the AWS clients for the tier of the Postgres or Redis CR
+func (e *AWSRestoreExecutor) awsClients(ctx context.Context, client k8sclient.Client, cr cloudResource) (*AWSClients, error) {
+	switch resource := cr.(type) {
+	case *v1alpha1.Postgres:
+		return e.NewAWSClients(ctx, client, e.SnapshotNamespace, providers.PostgresResourceType, resource.Spec.Tier)
+	case *v1alpha1.Redis:
+		return e.NewAWSClients(ctx, client, e.SnapshotNamespace, providers.RedisResourceType, resource.Spec.Tier)
+	default:
+		return nil, fmt.Errorf("unsupported cloud resource %T", cr)
+	}
+}
+
+// cloudResource is a Postgres or Redis CR
+type cloudResource interface {
+	runtime.Object
+	metav1.Object
+}
+
+// getRestoreState returns the restore recorded in the Postgres or Redis CR,
+// nil if it's not being restored
+func getRestoreState(cr cloudResource) (*awsRestoreState, error) {
+	value, ok := cr.GetAnnotations()[restoreAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	state := &awsRestoreState{}
+	if err := json.Unmarshal([]byte(value), state); err != nil {
+		return nil, fmt.Errorf("failed to read the restore of %s: %w", cr.GetName(), err)
+	}
+	return state, nil
+}
+
+// setRestoreState records the restore in the Postgres or Redis CR, or
+// removes it when the state is nil. The reconcile of the AWS instance by the
+// cloud resource operator is stopped while the restore is in progress
+func setRestoreState(client k8sclient.Client, cr cloudResource, state *awsRestoreState) error {
+	annotations := cr.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	skipCreate := state != nil && state.Phase != awsRestoreFailed
+	if state == nil {
+		delete(annotations, restoreAnnotation)
+	} else {
+		value, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("failed to record the restore of %s: %w", cr.GetName(), err)
+		}
+		annotations[restoreAnnotation] = string(value)
+	}
+	cr.SetAnnotations(annotations)
+
+	switch resource := cr.(type) {
+	case *v1alpha1.Postgres:
+		resource.Spec.SkipCreate = skipCreate
+	case *v1alpha1.Redis:
+		resource.Spec.SkipCreate = skipCreate
+	}
+	if err := client.Update(context.TODO(), cr); err != nil {
+		return fmt.Errorf("failed to record the restore of %s: %w", cr.GetName(), err)
+	}
+	return nil
+}
+
+func isAWSErrorCode(err error, code string) bool {
+	awsErr, ok := err.(awserr.Error)
+	return ok && awsErr.Code() == code
+}
+
+// newAWSClients creates the AWS clients using the credentials and the
+// strategy of the cloud resource operator
+func newAWSClients(ctx context.Context, client k8sclient.Client, namespace string, resourceType providers.ResourceType, tier string) (*AWSClients, error) {
+	secret := &corev1.Secret{}
+	if err := client.Get(ctx, types.NamespacedName{Name: awsCredentialsSecretName, Namespace: namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the AWS credentials: %w", err)
+	}
+
+	strategy, err := croAWS.NewConfigMapConfigManager(awsStrategiesConfigMapName, namespace, client).ReadStorageStrategy(ctx, resourceType, tier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the AWS strategy of %s tier %s: %w", resourceType, tier, err)
+	}
+
+	sess, err := croAWS.CreateSessionFromStrategy(ctx, client, string(secret.Data["aws_access_key_id"]), string(secret.Data["aws_secret_access_key"]), strategy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the AWS session: %w", err)
+	}
+
+	return &AWSClients{
+		RDS:         rds.New(sess),
+		ElastiCache: elasticache.New(sess),
+	}, nil
+}
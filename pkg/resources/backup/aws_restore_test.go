@@ -0,0 +1,209 @@
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockRDS is an RDS API with a single instance. Deleting the instance
+// removes it, and restoring it from a snapshot creates it again
+type mockRDS struct {
+	rdsiface.RDSAPI
+
+	instance      *rds.DBInstance
+	restoredFrom  string
+	deleteErr     error
+	skipCreateSet func() bool
+}
+
+func (m *mockRDS) DescribeDBInstances(input *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+	if m.instance == nil {
+		return nil, awserr.New(rds.ErrCodeDBInstanceNotFoundFault, "not found", nil)
+	}
+	return &rds.DescribeDBInstancesOutput{DBInstances: []*rds.DBInstance{m.instance}}, nil
+}
+
+func (m *mockRDS) ModifyDBInstance(input *rds.ModifyDBInstanceInput) (*rds.ModifyDBInstanceOutput, error) {
+	m.instance.DeletionProtection = input.DeletionProtection
+	return &rds.ModifyDBInstanceOutput{}, nil
+}
+
+func (m *mockRDS) DeleteDBInstance(input *rds.DeleteDBInstanceInput) (*rds.DeleteDBInstanceOutput, error) {
+	if m.deleteErr != nil {
+		return nil, m.deleteErr
+	}
+	if !m.skipCreateSet() {
+		return nil, awserr.New("InvalidState", "the cloud resource operator would recreate the instance", nil)
+	}
+	if aws.BoolValue(m.instance.DeletionProtection) {
+		return nil, awserr.New("InvalidParameterCombination", "deletion protection is enabled", nil)
+	}
+	m.instance = nil
+	return &rds.DeleteDBInstanceOutput{}, nil
+}
+
+func (m *mockRDS) RestoreDBInstanceFromDBSnapshot(input *rds.RestoreDBInstanceFromDBSnapshotInput) (*rds.RestoreDBInstanceFromDBSnapshotOutput, error) {
+	m.restoredFrom = aws.StringValue(input.DBSnapshotIdentifier)
+	m.instance = &rds.DBInstance{
+		DBInstanceIdentifier: input.DBInstanceIdentifier,
+		DBInstanceStatus:     aws.String(rdsStatusAvailable),
+		DeletionProtection:   input.DeletionProtection,
+	}
+	return &rds.RestoreDBInstanceFromDBSnapshotOutput{}, nil
+}
+
+func TestAWSRestoreExecutorPostgres(t *testing.T) {
+	scheme, err := buildSchemeForAWSBackup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	namespace := "testing-namespaces-operator"
+	resourceName := "test-rhmi-postgres"
+
+	cases := []struct {
+		Name            string
+		SnapshotPhase   types.StatusPhase
+		DeleteErr       error
+		ExpectedError   string
+		ExpectedRestore string
+	}{
+		{
+			Name:            "test postgres is restored from the snapshot",
+			SnapshotPhase:   types.PhaseComplete,
+			ExpectedRestore: "rds-snapshot-id",
+		},
+		{
+			Name:          "test incomplete snapshot is not restored",
+			SnapshotPhase: types.PhaseInProgress,
+			ExpectedError: "is not complete",
+		},
+		{
+			Name:          "test failed deletion resumes the postgres reconcile",
+			SnapshotPhase: types.PhaseComplete,
+			DeleteErr:     awserr.New("InvalidState", "instance is being modified", nil),
+			ExpectedError: "failed to delete RDS instance",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			snapshot := &v1alpha1.PostgresSnapshot{
+				ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: namespace},
+				Spec:       v1alpha1.PostgresSnapshotSpec{ResourceName: resourceName},
+				Status:     types.ResourceTypeSnapshotStatus{Phase: tc.SnapshotPhase, SnapshotID: "rds-snapshot-id"},
+			}
+			postgres := &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        resourceName,
+					Namespace:   namespace,
+					Annotations: map[string]string{croAWS.ResourceIdentifierAnnotation: "rds-instance-id"},
+				},
+			}
+			client := fake.NewFakeClientWithScheme(scheme, snapshot, postgres)
+
+			skipCreateSet := func() bool {
+				current := &v1alpha1.Postgres{}
+				if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: resourceName, Namespace: namespace}, current); err != nil {
+					t.Fatal(err)
+				}
+				return current.Spec.SkipCreate
+			}
+			mock := &mockRDS{
+				instance: &rds.DBInstance{
+					DBInstanceIdentifier: aws.String("rds-instance-id"),
+					DBInstanceStatus:     aws.String(rdsStatusAvailable),
+					DeletionProtection:   aws.Bool(true),
+				},
+				deleteErr:     tc.DeleteErr,
+				skipCreateSet: skipCreateSet,
+			}
+
+			executor := &AWSRestoreExecutor{
+				SnapshotNamespace: namespace,
+				ResourceName:      resourceName,
+				SnapshotType:      PostgresSnapshotType,
+				NewAWSClients: func(_ context.Context, _ k8sclient.Client, _ string, _ providers.ResourceType, _ string) (*AWSClients, error) {
+					return &AWSClients{RDS: mock}, nil
+				},
+			}
+
+			// Each check runs as a separate reconcile would, reading the
+			// progress of the restore from the Postgres CR
+			err := executor.StartRestore(client, snapshot.Name)
+			for i := 0; err == nil; i++ {
+				if i > 10 {
+					t.Fatal("expected the restore to finish")
+				}
+				var done bool
+				if done, err = executor.CheckRestore(client, snapshot.Name); done {
+					break
+				}
+			}
+			if tc.ExpectedError == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.ExpectedError != "" && (err == nil || !strings.Contains(err.Error(), tc.ExpectedError)) {
+				t.Fatalf("expected error containing %q, got %v", tc.ExpectedError, err)
+			}
+
+			if mock.restoredFrom != tc.ExpectedRestore {
+				t.Fatalf("expected the instance to be restored from %q, got %q", tc.ExpectedRestore, mock.restoredFrom)
+			}
+			if skipCreateSet() {
+				t.Fatal("expected skipCreate to be unset once the restore finished")
+			}
+			current := &v1alpha1.Postgres{}
+			if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: resourceName, Namespace: namespace}, current); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := current.Annotations[restoreAnnotation]; ok != (tc.DeleteErr != nil) {
+				t.Fatalf("expected the restore to be recorded only when it failed, got annotations %v", current.Annotations)
+			}
+			if mock.instance == nil || !aws.BoolValue(mock.instance.DeletionProtection) {
+				t.Fatal("expected the instance to keep its deletion protection")
+			}
+		})
+	}
+}
+
+func TestAWSRestoreExecutorStartedTwice(t *testing.T) {
+	scheme, err := buildSchemeForAWSBackup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postgres := &v1alpha1.Postgres{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-rhmi-postgres",
+			Namespace: "ns",
+			Annotations: map[string]string{
+				restoreAnnotation: `{"snapshot":"first-snapshot","phase":"deleting"}`,
+			},
+		},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, postgres)
+	executor := NewAWSRestoreExecutor("ns", "test-rhmi-postgres", PostgresSnapshotType)
+
+	if err := executor.StartRestore(client, "first-snapshot"); err != nil {
+		t.Fatalf("expected the restore in progress to be left as it is, got %v", err)
+	}
+	if err := executor.StartRestore(client, "second-snapshot"); err == nil || !strings.Contains(err.Error(), "is being restored from first-snapshot") {
+		t.Fatalf("expected the restore from another snapshot to be refused, got %v", err)
+	}
+}
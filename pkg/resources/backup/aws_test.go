@@ -223,6 +223,48 @@ func TestAWSSnapshotRedis_FailedJob(t *testing.T) {
 	}
 }
 
+// TestAWSSnapshotStartAndCheck tests that a snapshot started in the
+// background is only created once, and is checked until it completes
+func TestAWSSnapshotStartAndCheck(t *testing.T) {
+	scheme, err := buildSchemeForAWSBackup()
+	if err != nil {
+		t.Fatalf("Error building scheme: %v", err)
+	}
+
+	namespace := "testing-namespaces-operator"
+	client := fake.NewFakeClientWithScheme(scheme)
+	executor := &AWSBackupExecutor{
+		SnapshotNamespace: namespace,
+		ResourceName:      "test-rhmi-redis",
+		SnapshotType:      RedisSnapshotType,
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := executor.StartNamedBackup(client, "test-snapshot"); err != nil {
+			t.Fatalf("Unexpected error starting the snapshot: %v", err)
+		}
+	}
+
+	done, err := executor.CheckNamedBackup(client, "test-snapshot")
+	if err != nil || done {
+		t.Fatalf("Expected the snapshot to be in progress, got %t and error %v", done, err)
+	}
+
+	snapshot := &v1alpha1.RedisSnapshot{}
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: "test-snapshot", Namespace: namespace}, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	snapshot.Status.Phase = types.PhaseComplete
+	if err := client.Status().Update(context.TODO(), snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err = executor.CheckNamedBackup(client, "test-snapshot")
+	if err != nil || !done {
+		t.Fatalf("Expected the snapshot to be complete, got %t and error %v", done, err)
+	}
+}
+
 func buildSchemeForAWSBackup() (*runtime.Scheme, error) {
 	scheme := runtime.NewScheme()
 	err := v1alpha1.SchemeBuilder.AddToScheme(scheme)
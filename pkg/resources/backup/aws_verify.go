@@ -0,0 +1,363 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	crotypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AWSBackupVerifier verifies the snapshots created by the AWSBackupExecutor.
+// The snapshot is restored into a throwaway Postgres or Redis CR, with a tier
+// of the AWS strategies of the cloud resource operator copied from the tier
+// of the resource. The tier of a Redis CR creates its replication group from
+// the snapshot. The cloud resource operator can't create RDS instances from
+// snapshots, so the instance is restored from the snapshot first, then
+// adopted by the Postgres CR as its tier has the identifier of the instance
+type AWSBackupVerifier struct {
+	SnapshotNamespace string          // Namespace of the snapshot CR
+	ResourceName      string          // AWS Resource name
+	SnapshotType      AWSSnapshotType // Type of snapshot CR to verify
+
+	// NewAWSClients returns the clients of the AWS APIs for the tier of the
+	// resource
+	NewAWSClients func(ctx context.Context, client k8sclient.Client, namespace string, resourceType providers.ResourceType, tier string) (*AWSClients, error)
+}
+
+func NewAWSBackupVerifier(snapshotNamespace, resourceName string, snapshotType AWSSnapshotType) BackupVerifier {
+	return &AWSBackupVerifier{
+		SnapshotNamespace: snapshotNamespace,
+		ResourceName:      resourceName,
+		SnapshotType:      snapshotType,
+		NewAWSClients:     newAWSClients,
+	}
+}
+
+// StartVerification adds the tier of the throwaway instance restored from
+// the snapshot CR snapshotName, and creates its Postgres or Redis CR, unless
+// it already exists
+func (v *AWSBackupVerifier) StartVerification(client k8sclient.Client, snapshotName string) error {
+	log.Infof("Verifying backup on AWS", l.Fields{"snapshotType": v.SnapshotType, "resourceName": v.ResourceName, "snapshotName": snapshotName})
+
+	source, cr, err := v.resources(client, snapshotName)
+	if err != nil {
+		return err
+	}
+	err = client.Get(context.TODO(), types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}, cr)
+	if err == nil {
+		return nil
+	}
+	if !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to get the throwaway instance %s: %w", cr.GetName(), err)
+	}
+
+	// A tier left by a previous verification only takes space in the
+	// strategies, the verification goes on
+	if err := pruneVerificationTiers(client, v.SnapshotNamespace); err != nil {
+		log.Error("Failed to remove the tiers of the previous backup verifications", err)
+	}
+
+	switch source := source.(type) {
+	case *v1alpha1.Postgres:
+		err = v.restorePostgres(client, source, cr.GetName(), snapshotName)
+	case *v1alpha1.Redis:
+		err = v.addRedisTier(client, source, cr.GetName(), snapshotName)
+	}
+	if err != nil {
+		return err
+	}
+	return createThrowawayResource(client, source, cr, cr.GetName())
+}
+
+// CheckVerification runs the check against the throwaway instance once the
+// cloud resource operator completes it
+func (v *AWSBackupVerifier) CheckVerification(client k8sclient.Client, snapshotName string, check integreatlyv1alpha1.BackupSanityCheck) (bool, int64, error) {
+	source, cr, err := v.resources(client, snapshotName)
+	if err != nil {
+		return false, 0, err
+	}
+	target, err := restoredInstance(client, cr)
+	if err != nil || target == nil {
+		return false, 0, err
+	}
+
+	// The RDS instance has the credentials of the instance the snapshot was
+	// taken from, not the ones generated by the cloud resource operator
+	if postgres, ok := source.(*v1alpha1.Postgres); ok {
+		credentials, err := connectionSecret(client, postgres.Status.SecretRef)
+		if err != nil {
+			return false, 0, err
+		}
+		target.credentials = map[string]string{
+			"user":     credentials["username"],
+			"password": credentials["password"],
+			"database": credentials["database"],
+		}
+	}
+
+	return runSanityCheck(client, v.SnapshotNamespace, cr.GetName(), *target, check)
+}
+
+// CleanupVerification removes the Job of the check and the throwaway
+// Postgres or Redis CR. Its tier is removed by a later verification, once
+// the cloud resource operator removed the instance
+func (v *AWSBackupVerifier) CleanupVerification(client k8sclient.Client, snapshotName string) error {
+	_, cr, err := v.resources(client, snapshotName)
+	if err != nil {
+		return err
+	}
+	if err := cleanupSanityCheck(client, v.SnapshotNamespace, cr.GetName()); err != nil {
+		return err
+	}
+	return deleteThrowawayResource(client, cr)
+}
+
+// resources returns the Postgres or Redis CR of the resource, and the one of
+// the throwaway instance restored from the snapshot
+func (v *AWSBackupVerifier) resources(client k8sclient.Client, snapshotName string) (cloudResource, cloudResource, error) {
+	source, err := v.restorer().cloudResource(client)
+	if err != nil {
+		return nil, nil, err
+	}
+	cr, err := throwawayResource(source, verificationName(snapshotName))
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, cr, nil
+}
+
+// restorer returns the restore of the snapshots of the resource, which
+// reads the settings of its instance
+func (v *AWSBackupVerifier) restorer() *AWSRestoreExecutor {
+	return &AWSRestoreExecutor{
+		SnapshotNamespace: v.SnapshotNamespace,
+		ResourceName:      v.ResourceName,
+		SnapshotType:      v.SnapshotType,
+		NewAWSClients:     v.NewAWSClients,
+	}
+}
+
+// restorePostgres adds the tier adopting the RDS instance name, then
+// restores the instance from the snapshot in the network of the instance of
+// the resource. The instance is removed by the cloud resource operator with
+// the Postgres CR, without a final snapshot
+func (v *AWSBackupVerifier) restorePostgres(client k8sclient.Client, postgres *v1alpha1.Postgres, name, snapshotName string) error {
+	restorer := v.restorer()
+	awsClients, err := restorer.awsClients(context.TODO(), client, postgres)
+	if err != nil {
+		return err
+	}
+	input, err := restorer.postgresRestoreInput(client, postgres, awsClients, snapshotName)
+	if err != nil {
+		return err
+	}
+
+	err = addVerificationTier(client, v.SnapshotNamespace, providers.PostgresResourceType, postgres.Spec.Tier, name,
+		map[string]interface{}{
+			"DBInstanceIdentifier": name,
+			"MultiAZ":              false,
+			"DeletionProtection":   false,
+		},
+		map[string]interface{}{
+			"SkipFinalSnapshot":      true,
+			"DeleteAutomatedBackups": true,
+		})
+	if err != nil {
+		return err
+	}
+
+	input.DBInstanceIdentifier = aws.String(name)
+	input.MultiAZ = aws.Bool(false)
+	input.DeletionProtection = aws.Bool(false)
+	input.Tags = []*rds.Tag{{Key: aws.String(verificationLabel), Value: aws.String(snapshotName)}}
+	if _, err := awsClients.RDS.RestoreDBInstanceFromDBSnapshot(input); err != nil && !isAWSErrorCode(err, rds.ErrCodeDBInstanceAlreadyExistsFault) {
+		return fmt.Errorf("failed to restore PostgresSnapshot %s into RDS instance %s: %w", snapshotName, name, err)
+	}
+	return nil
+}
+
+// addRedisTier adds the tier creating the replication group name from the
+// snapshot
+func (v *AWSBackupVerifier) addRedisTier(client k8sclient.Client, redis *v1alpha1.Redis, name, snapshotName string) error {
+	snapshot := &v1alpha1.RedisSnapshot{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: snapshotName, Namespace: v.SnapshotNamespace}, snapshot); err != nil {
+		return fmt.Errorf("failed to get RedisSnapshot %s: %w", snapshotName, err)
+	}
+	if snapshot.Status.Phase != crotypes.PhaseComplete || snapshot.Status.SnapshotID == "" {
+		return fmt.Errorf("RedisSnapshot %s is not complete", snapshotName)
+	}
+
+	return addVerificationTier(client, v.SnapshotNamespace, providers.RedisResourceType, redis.Spec.Tier, name,
+		map[string]interface{}{
+			"ReplicationGroupId":          name,
+			"ReplicationGroupDescription": fmt.Sprintf("Verification of %s", snapshotName),
+			"SnapshotName":                snapshot.Status.SnapshotID,
+		}, nil)
+}
+
+// addVerificationTier adds the tier of a throwaway instance to the AWS
+// strategies of the cloud resource operator. It's a copy of the tier of the
+// resource, with the given keys of the create and delete strategies
+// overridden, and the network of the tier of the resource
+func addVerificationTier(client k8sclient.Client, namespace string, resourceType providers.ResourceType, sourceTier, tier string, createStrategy, deleteStrategy map[string]interface{}) error {
+	cfgMap := &corev1.ConfigMap{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: awsStrategiesConfigMapName, Namespace: namespace}, cfgMap); err != nil {
+		return fmt.Errorf("failed to get the AWS strategies: %w", err)
+	}
+
+	strategies := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(cfgMap.Data[string(resourceType)]), &strategies); err != nil {
+		return fmt.Errorf("failed to read the AWS strategies of %s: %w", resourceType, err)
+	}
+	source, ok := strategies[sourceTier]
+	if !ok {
+		return fmt.Errorf("no AWS strategy found for %s tier %s", resourceType, sourceTier)
+	}
+	strategy := &croAWS.StrategyConfig{}
+	if err := json.Unmarshal(source, strategy); err != nil {
+		return fmt.Errorf("failed to read the AWS strategy of %s tier %s: %w", resourceType, sourceTier, err)
+	}
+
+	var err error
+	if strategy.CreateStrategy, err = overrideStrategy(strategy.CreateStrategy, createStrategy); err != nil {
+		return fmt.Errorf("failed to override the create strategy of %s tier %s: %w", resourceType, sourceTier, err)
+	}
+	if strategy.DeleteStrategy, err = overrideStrategy(strategy.DeleteStrategy, deleteStrategy); err != nil {
+		return fmt.Errorf("failed to override the delete strategy of %s tier %s: %w", resourceType, sourceTier, err)
+	}
+	if strategies[tier], err = json.Marshal(strategy); err != nil {
+		return err
+	}
+	data, err := json.Marshal(strategies)
+	if err != nil {
+		return err
+	}
+	cfgMap.Data[string(resourceType)] = string(data)
+
+	// The standalone network of the instance is read from the network tier
+	// named after the tier of the instance
+	if networkData, ok := cfgMap.Data[string(providers.NetworkResourceType)]; ok {
+		networks := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(networkData), &networks); err != nil {
+			return fmt.Errorf("failed to read the AWS network strategies: %w", err)
+		}
+		if network, ok := networks[sourceTier]; ok {
+			networks[tier] = network
+			data, err := json.Marshal(networks)
+			if err != nil {
+				return err
+			}
+			cfgMap.Data[string(providers.NetworkResourceType)] = string(data)
+		}
+	}
+
+	log.Infof("Adding AWS strategy tier", l.Fields{"resourceType": resourceType, "tier": tier, "sourceTier": sourceTier})
+	if err := client.Update(context.TODO(), cfgMap); err != nil {
+		return fmt.Errorf("failed to add the AWS strategy of %s tier %s: %w", resourceType, tier, err)
+	}
+	return nil
+}
+
+// pruneVerificationTiers removes the tiers of the throwaway instances whose
+// Postgres or Redis CR no longer exists. The cloud resource operator reads
+// the tier until the CR is removed
+func pruneVerificationTiers(client k8sclient.Client, namespace string) error {
+	cfgMap := &corev1.ConfigMap{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: awsStrategiesConfigMapName, Namespace: namespace}, cfgMap); err != nil {
+		return fmt.Errorf("failed to get the AWS strategies: %w", err)
+	}
+
+	resources := map[providers.ResourceType]func() cloudResource{
+		providers.PostgresResourceType: func() cloudResource { return &v1alpha1.Postgres{} },
+		providers.RedisResourceType:    func() cloudResource { return &v1alpha1.Redis{} },
+	}
+	pruned := []string{}
+	for resourceType, newResource := range resources {
+		strategies := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(cfgMap.Data[string(resourceType)]), &strategies); err != nil {
+			return fmt.Errorf("failed to read the AWS strategies of %s: %w", resourceType, err)
+		}
+
+		prunedType := false
+		for tier := range strategies {
+			if !strings.HasPrefix(tier, verificationPrefix) {
+				continue
+			}
+			err := client.Get(context.TODO(), types.NamespacedName{Name: tier, Namespace: namespace}, newResource())
+			if err == nil {
+				continue
+			}
+			if !k8serr.IsNotFound(err) {
+				return fmt.Errorf("failed to get the throwaway instance %s: %w", tier, err)
+			}
+			delete(strategies, tier)
+			pruned = append(pruned, tier)
+			prunedType = true
+		}
+		if !prunedType {
+			continue
+		}
+		data, err := json.Marshal(strategies)
+		if err != nil {
+			return err
+		}
+		cfgMap.Data[string(resourceType)] = string(data)
+	}
+	if len(pruned) == 0 {
+		return nil
+	}
+
+	if networkData, ok := cfgMap.Data[string(providers.NetworkResourceType)]; ok {
+		networks := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(networkData), &networks); err != nil {
+			return fmt.Errorf("failed to read the AWS network strategies: %w", err)
+		}
+		for _, tier := range pruned {
+			delete(networks, tier)
+		}
+		data, err := json.Marshal(networks)
+		if err != nil {
+			return err
+		}
+		cfgMap.Data[string(providers.NetworkResourceType)] = string(data)
+	}
+
+	log.Infof("Removing AWS strategy tiers of previous backup verifications", l.Fields{"tiers": pruned})
+	return client.Update(context.TODO(), cfgMap)
+}
+
+// overrideStrategy returns the create or delete strategy with the given keys
+// overridden
+func overrideStrategy(strategy json.RawMessage, overrides map[string]interface{}) (json.RawMessage, error) {
+	if len(overrides) == 0 {
+		return strategy, nil
+	}
+
+	values := map[string]interface{}{}
+	if len(strategy) > 0 {
+		if err := json.Unmarshal(strategy, &values); err != nil {
+			return nil, err
+		}
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	for key, value := range overrides {
+		values[key] = value
+	}
+	return json.Marshal(values)
+}
@@ -0,0 +1,445 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/rds/rdsiface"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	croAWS "github.com/integr8ly/cloud-resource-operator/pkg/providers/aws"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// mockVerifyRDS is an RDS API with the source instance. Restoring a
+// snapshot records the restore
+type mockVerifyRDS struct {
+	rdsiface.RDSAPI
+
+	instances map[string]*rds.DBInstance
+	restored  *rds.RestoreDBInstanceFromDBSnapshotInput
+}
+
+func (m *mockVerifyRDS) DescribeDBInstances(input *rds.DescribeDBInstancesInput) (*rds.DescribeDBInstancesOutput, error) {
+	instance, ok := m.instances[aws.StringValue(input.DBInstanceIdentifier)]
+	if !ok {
+		return nil, awserr.New(rds.ErrCodeDBInstanceNotFoundFault, "not found", nil)
+	}
+	return &rds.DescribeDBInstancesOutput{DBInstances: []*rds.DBInstance{instance}}, nil
+}
+
+func (m *mockVerifyRDS) RestoreDBInstanceFromDBSnapshot(input *rds.RestoreDBInstanceFromDBSnapshotInput) (*rds.RestoreDBInstanceFromDBSnapshotOutput, error) {
+	m.restored = input
+	return &rds.RestoreDBInstanceFromDBSnapshotOutput{}, nil
+}
+
+// sanityCheckClient completes the Jobs it creates, with a pod terminated
+// with the given message
+type sanityCheckClient struct {
+	jobClient
+	message string
+}
+
+func (c *sanityCheckClient) Create(ctx context.Context, obj runtime.Object, opts ...k8sclient.CreateOption) error {
+	if err := c.jobClient.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	job, ok := obj.(*batchv1.Job)
+	if !ok {
+		return nil
+	}
+	return c.jobClient.Create(ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: job.Name + "-pod", Namespace: job.Namespace, Labels: map[string]string{"job-name": job.Name}},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Message: c.message}},
+			}},
+		},
+	})
+}
+
+func buildSchemeForVerification(t *testing.T) *runtime.Scheme {
+	scheme := buildSchemeForClusterStorageBackup(t)
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+// awsStrategies returns the AWS strategies of the cloud resource operator,
+// with a production tier for each type of resource
+func awsStrategies(namespace string) *corev1.ConfigMap {
+	tier := `{"production": {"region": "eu-west-1", "createStrategy": {"CacheNodeType": "cache.t3.micro", "DBInstanceClass": "db.t3.small"}, "deleteStrategy": {}}}`
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: awsStrategiesConfigMapName, Namespace: namespace},
+		Data: map[string]string{
+			string(providers.PostgresResourceType): tier,
+			string(providers.RedisResourceType):    tier,
+			string(providers.NetworkResourceType):  `{"production": {"createStrategy": {"CidrBlock": "10.1.0.0/26"}}}`,
+		},
+	}
+}
+
+// strategyTier returns the create and delete strategies of the tier, nil if
+// the tier doesn't exist
+func strategyTier(t *testing.T, client k8sclient.Client, namespace string, resourceType providers.ResourceType, tier string) (map[string]interface{}, map[string]interface{}) {
+	cfgMap := &corev1.ConfigMap{}
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: awsStrategiesConfigMapName, Namespace: namespace}, cfgMap); err != nil {
+		t.Fatal(err)
+	}
+	strategies := map[string]*croAWS.StrategyConfig{}
+	if err := json.Unmarshal([]byte(cfgMap.Data[string(resourceType)]), &strategies); err != nil {
+		t.Fatal(err)
+	}
+	strategy, ok := strategies[tier]
+	if !ok {
+		return nil, nil
+	}
+	createStrategy, deleteStrategy := map[string]interface{}{}, map[string]interface{}{}
+	if err := json.Unmarshal(strategy.CreateStrategy, &createStrategy); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(strategy.DeleteStrategy, &deleteStrategy); err != nil {
+		t.Fatal(err)
+	}
+	return createStrategy, deleteStrategy
+}
+
+// completeThrowawayResource completes the throwaway instance as the cloud
+// resource operator would, with a connection secret with the given data
+func completeThrowawayResource(t *testing.T, client k8sclient.Client, cr cloudResource, data map[string][]byte) {
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}, cr); err != nil {
+		t.Fatal(err)
+	}
+	_, status := resourceSpec(cr)
+	status.Phase = types.PhaseComplete
+	status.SecretRef = &types.SecretRef{Name: cr.GetName(), Namespace: cr.GetNamespace()}
+	if err := client.Update(context.TODO(), cr); err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cr.GetName(), Namespace: cr.GetNamespace()},
+		Data:       data,
+	}
+	if err := client.Create(context.TODO(), secret); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// expectCleanedUp fails unless the throwaway CR and the resources of the
+// sanity check were removed
+func expectCleanedUp(t *testing.T, client k8sclient.Client, cr cloudResource) {
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}, cr); !k8serr.IsNotFound(err) {
+		t.Fatalf("expected the throwaway instance %s to be removed, got %v", cr.GetName(), err)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := client.List(context.TODO(), jobs, k8sclient.InNamespace(cr.GetNamespace())); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) > 0 {
+		t.Fatalf("expected the Jobs of the verification to be removed, got %d Jobs", len(jobs.Items))
+	}
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: sanityCheckSecretName(cr.GetName()), Namespace: cr.GetNamespace()}, secret); !k8serr.IsNotFound(err) {
+		t.Fatalf("expected the credentials of the sanity check to be removed, got %v", err)
+	}
+}
+
+func TestAWSBackupVerifierPostgres(t *testing.T) {
+	scheme := buildSchemeForVerification(t)
+
+	namespace := "redhat-rhmi-operator"
+	resourceName := "threescale-postgres-rhmi"
+	check := integreatlyv1alpha1.BackupSanityCheck{Query: "SELECT count(*) FROM accounts", Min: 1}
+
+	cases := []struct {
+		Name           string
+		SnapshotPhase  types.StatusPhase
+		Message        string
+		ExpectedResult int64
+		ExpectedError  string
+	}{
+		{
+			Name:           "test snapshot is verified",
+			SnapshotPhase:  types.PhaseComplete,
+			Message:        "12\n",
+			ExpectedResult: 12,
+		},
+		{
+			Name:           "test restored instance without enough data",
+			SnapshotPhase:  types.PhaseComplete,
+			Message:        "0\n",
+			ExpectedResult: 0,
+			ExpectedError:  "expected at least 1",
+		},
+		{
+			Name:          "test unexpected result",
+			SnapshotPhase: types.PhaseComplete,
+			Message:       "ERROR: relation \"accounts\" does not exist",
+			ExpectedError: "expected a number",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			snapshot := &v1alpha1.PostgresSnapshot{
+				ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: namespace},
+				Spec:       v1alpha1.PostgresSnapshotSpec{ResourceName: resourceName},
+				Status:     types.ResourceTypeSnapshotStatus{Phase: tc.SnapshotPhase, SnapshotID: "rds-snapshot-id"},
+			}
+			postgres := &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        resourceName,
+					Namespace:   namespace,
+					Labels:      map[string]string{"productName": "3scale"},
+					Annotations: map[string]string{croAWS.ResourceIdentifierAnnotation: "rds-instance-id"},
+				},
+				Spec:   types.ResourceTypeSpec{Type: "managed", Tier: "production"},
+				Status: types.ResourceTypeStatus{SecretRef: &types.SecretRef{Name: "threescale-postgres", Namespace: "redhat-rhmi-3scale"}},
+			}
+			connection := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "threescale-postgres", Namespace: "redhat-rhmi-3scale"},
+				Data: map[string][]byte{
+					"username": []byte("user"),
+					"password": []byte("password"),
+					"database": []byte("system"),
+				},
+			}
+			client := &sanityCheckClient{
+				jobClient: jobClient{Client: fake.NewFakeClientWithScheme(scheme, snapshot, postgres, connection, awsStrategies(namespace))},
+				message:   tc.Message,
+			}
+			mock := &mockVerifyRDS{
+				instances: map[string]*rds.DBInstance{
+					"rds-instance-id": {
+						DBInstanceIdentifier: aws.String("rds-instance-id"),
+						DBInstanceClass:      aws.String("db.t3.small"),
+						DBInstanceStatus:     aws.String(rdsStatusAvailable),
+						DBSubnetGroup:        &rds.DBSubnetGroup{DBSubnetGroupName: aws.String("rhmi-subnets")},
+						VpcSecurityGroups:    []*rds.VpcSecurityGroupMembership{{VpcSecurityGroupId: aws.String("sg-1")}},
+					},
+				},
+			}
+
+			verifier := &AWSBackupVerifier{
+				SnapshotNamespace: namespace,
+				ResourceName:      resourceName,
+				SnapshotType:      PostgresSnapshotType,
+				NewAWSClients: func(_ context.Context, _ k8sclient.Client, _ string, _ providers.ResourceType, _ string) (*AWSClients, error) {
+					return &AWSClients{RDS: mock}, nil
+				},
+			}
+
+			if err := verifier.StartVerification(client, snapshot.Name); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			verifyID := verificationName(snapshot.Name)
+
+			// The instance is restored from the snapshot, and adopted by
+			// the throwaway Postgres CR through its tier
+			if mock.restored == nil || aws.StringValue(mock.restored.DBSnapshotIdentifier) != "rds-snapshot-id" || aws.StringValue(mock.restored.DBInstanceIdentifier) != verifyID {
+				t.Fatalf("expected RDS instance %s to be restored from rds-snapshot-id, got %v", verifyID, mock.restored)
+			}
+			createStrategy, deleteStrategy := strategyTier(t, client, namespace, providers.PostgresResourceType, verifyID)
+			if createStrategy["DBInstanceIdentifier"] != verifyID || createStrategy["DBInstanceClass"] != "db.t3.small" || createStrategy["DeletionProtection"] != false {
+				t.Fatalf("expected tier %s to adopt the restored instance, got %v", verifyID, createStrategy)
+			}
+			if deleteStrategy["SkipFinalSnapshot"] != true {
+				t.Fatalf("expected tier %s to skip the final snapshot, got %v", verifyID, deleteStrategy)
+			}
+			cr := &v1alpha1.Postgres{}
+			if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: verifyID, Namespace: namespace}, cr); err != nil {
+				t.Fatal(err)
+			}
+			if cr.Spec.Tier != verifyID || cr.Spec.Type != "managed" || cr.Labels["productName"] != "3scale" {
+				t.Fatalf("unexpected throwaway Postgres %+v", cr)
+			}
+
+			done, _, err := verifier.CheckVerification(client, snapshot.Name, check)
+			if err != nil || done {
+				t.Fatalf("expected the verification to wait for the throwaway instance, got %t, %v", done, err)
+			}
+
+			completeThrowawayResource(t, client, cr, map[string][]byte{
+				"host":     []byte("verify.rds.amazonaws.com"),
+				"port":     []byte("5432"),
+				"username": []byte("postgres"),
+				"password": []byte("generated"),
+			})
+			done, result, err := verifier.CheckVerification(client, snapshot.Name, check)
+			if tc.ExpectedError == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.ExpectedError != "" && (err == nil || !strings.Contains(err.Error(), tc.ExpectedError)) {
+				t.Fatalf("expected error containing %q, got %v", tc.ExpectedError, err)
+			}
+			if !done || result != tc.ExpectedResult {
+				t.Fatalf("expected the check to complete with %d, got %t, %d", tc.ExpectedResult, done, result)
+			}
+
+			// The check connects with the credentials of the instance the
+			// snapshot was taken from
+			credentials := &corev1.Secret{}
+			if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: sanityCheckSecretName(verifyID), Namespace: namespace}, credentials); err != nil {
+				t.Fatal(err)
+			}
+			if credentials.StringData["password"] != "password" || credentials.StringData["database"] != "system" {
+				t.Fatalf("expected the credentials of the source instance, got %v", credentials.StringData)
+			}
+
+			if err := verifier.CleanupVerification(client, snapshot.Name); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectCleanedUp(t, client, cr)
+		})
+	}
+}
+
+func TestAWSBackupVerifierIncompleteSnapshot(t *testing.T) {
+	scheme := buildSchemeForVerification(t)
+	namespace := "redhat-rhmi-operator"
+
+	snapshot := &v1alpha1.RedisSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: namespace},
+		Status:     types.ResourceTypeSnapshotStatus{Phase: types.PhaseInProgress},
+	}
+	redis := &v1alpha1.Redis{
+		ObjectMeta: metav1.ObjectMeta{Name: "threescale-redis-rhmi", Namespace: namespace},
+		Spec:       types.ResourceTypeSpec{Type: "managed", Tier: "production"},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, snapshot, redis, awsStrategies(namespace))
+
+	verifier := NewAWSBackupVerifier(namespace, redis.Name, RedisSnapshotType)
+	if err := verifier.StartVerification(client, snapshot.Name); err == nil || !strings.Contains(err.Error(), "is not complete") {
+		t.Fatalf("expected the incomplete snapshot not to be verified, got %v", err)
+	}
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: verificationName(snapshot.Name), Namespace: namespace}, &v1alpha1.Redis{}); !k8serr.IsNotFound(err) {
+		t.Fatalf("expected no throwaway instance, got %v", err)
+	}
+}
+
+func TestAWSBackupVerifierRedis(t *testing.T) {
+	scheme := buildSchemeForVerification(t)
+	namespace := "redhat-rhmi-operator"
+
+	snapshot := &v1alpha1.RedisSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "snapshot", Namespace: namespace},
+		Status:     types.ResourceTypeSnapshotStatus{Phase: types.PhaseComplete, SnapshotID: "elasticache-snapshot-id"},
+	}
+	redis := &v1alpha1.Redis{
+		ObjectMeta: metav1.ObjectMeta{Name: "threescale-redis-rhmi", Namespace: namespace},
+		Spec:       types.ResourceTypeSpec{Type: "managed", Tier: "production"},
+	}
+	// A throwaway instance of a previous verification was removed, its
+	// tier is left in the strategies
+	strategies := awsStrategies(namespace)
+	strategies.Data[string(providers.RedisResourceType)] = `{"production": {"createStrategy": {"CacheNodeType": "cache.t3.micro"}, "deleteStrategy": {}}, "rhmi-verify-0123456789": {"createStrategy": {}, "deleteStrategy": {}}}`
+	client := &sanityCheckClient{
+		jobClient: jobClient{Client: fake.NewFakeClientWithScheme(scheme, snapshot, redis, strategies)},
+		message:   "(integer) 3\n",
+	}
+
+	// The replication group is created from the snapshot by the cloud
+	// resource operator
+	verifier := &AWSBackupVerifier{
+		SnapshotNamespace: namespace,
+		ResourceName:      redis.Name,
+		SnapshotType:      RedisSnapshotType,
+		NewAWSClients: func(_ context.Context, _ k8sclient.Client, _ string, _ providers.ResourceType, _ string) (*AWSClients, error) {
+			return nil, errors.New("unexpected call to the AWS APIs")
+		},
+	}
+	if err := verifier.StartVerification(client, snapshot.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	verifyID := verificationName(snapshot.Name)
+
+	createStrategy, _ := strategyTier(t, client, namespace, providers.RedisResourceType, verifyID)
+	if createStrategy["SnapshotName"] != "elasticache-snapshot-id" || createStrategy["ReplicationGroupId"] != verifyID || createStrategy["CacheNodeType"] != "cache.t3.micro" {
+		t.Fatalf("expected tier %s to create the replication group from the snapshot, got %v", verifyID, createStrategy)
+	}
+	if stale, _ := strategyTier(t, client, namespace, providers.RedisResourceType, "rhmi-verify-0123456789"); stale != nil {
+		t.Fatal("expected the tier of the removed throwaway instance to be pruned")
+	}
+	cfgMap := &corev1.ConfigMap{}
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: awsStrategiesConfigMapName, Namespace: namespace}, cfgMap); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(cfgMap.Data[string(providers.NetworkResourceType)], verifyID) {
+		t.Fatalf("expected a network tier %s, got %s", verifyID, cfgMap.Data[string(providers.NetworkResourceType)])
+	}
+
+	cr := &v1alpha1.Redis{}
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: verifyID, Namespace: namespace}, cr); err != nil {
+		t.Fatal(err)
+	}
+	completeThrowawayResource(t, client, cr, map[string][]byte{
+		"uri":  []byte("verify.cache.amazonaws.com"),
+		"port": []byte("6379"),
+	})
+
+	done, result, err := verifier.CheckVerification(client, snapshot.Name, DefaultSanityCheck(string(RedisSnapshotType)))
+	if err != nil || !done || result != 3 {
+		t.Fatalf("expected the check to complete with 3, got %t, %d, %v", done, result, err)
+	}
+	job := &batchv1.Job{}
+	if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: verifyID, Namespace: namespace}, job); err != nil {
+		t.Fatal(err)
+	}
+	if env := job.Spec.Template.Spec.Containers[0].Env; env[1].Value != "verify.cache.amazonaws.com" {
+		t.Fatalf("expected the check to connect to the throwaway instance, got %v", env)
+	}
+
+	if err := verifier.CleanupVerification(client, snapshot.Name); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectCleanedUp(t, client, cr)
+}
+
+func TestNewBackupVerifier(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Executor      ComponentBackupExecutor
+		ExpectedError bool
+	}{
+		{
+			Name:     "test snapshots are verified",
+			Executor: NewAWSBackupExecutor("redhat-rhmi-operator", "test", RedisSnapshotType).(ComponentBackupExecutor),
+		},
+		{
+			Name:     "test dumps are verified",
+			Executor: NewClusterStorageBackupExecutor("redhat-rhmi-operator", "test", PostgresDumpType, ClusterStorageDestination{}).(ComponentBackupExecutor),
+		},
+		{
+			Name:          "test Jobs are not verified",
+			Executor:      unsupportedBackupExecutor{},
+			ExpectedError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := NewBackupVerifier(tc.Executor)
+			if tc.ExpectedError != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.ExpectedError, err)
+			}
+		})
+	}
+}
@@ -9,12 +9,71 @@ import (
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// DefaultTimeout is the time a backup is given to complete
+const DefaultTimeout = time.Minute * 20
+
 // BackupExecutor knows how to perform backups and wait for their successful
 // completion
 type BackupExecutor interface {
 	PerformBackup(client k8sclient.Client, timeout time.Duration) error
 }
 
+// ComponentBackupExecutor is a BackupExecutor that backs up a single
+// resource, and lets the caller name the backup it creates
+type ComponentBackupExecutor interface {
+	BackupExecutor
+	// ComponentName returns the name of the resource backed up
+	ComponentName() string
+	// BackupType returns the kind of resource created by the backup
+	BackupType() string
+	// PerformNamedBackup performs the backup creating a resource named
+	// backupName and waits for its completion
+	PerformNamedBackup(client k8sclient.Client, backupName string, timeout time.Duration) error
+	// StartNamedBackup starts the backup creating a resource named
+	// backupName, without waiting for it. Starting a backup that was
+	// already started does nothing
+	StartNamedBackup(client k8sclient.Client, backupName string) error
+	// CheckNamedBackup returns true once the backup backupName completed,
+	// or an error if it failed
+	CheckNamedBackup(client k8sclient.Client, backupName string) (bool, error)
+}
+
+// waitForBackup checks the backup started by the executor until either it
+// finishes, or it times out
+func waitForBackup(client k8sclient.Client, executor ComponentBackupExecutor, backupName string, timeout time.Duration) error {
+	started := time.Now()
+	for {
+		done, err := executor.CheckNamedBackup(client, backupName)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if time.Now().After(started.Add(timeout)) {
+			return fmt.Errorf("Timed out when waiting for backup %s of %s to finish", backupName, executor.ComponentName())
+		}
+		time.Sleep(backupPollInterval)
+	}
+}
+
+// Components returns the executors the backup is made of. Concurrent
+// executors are flattened and the executors that do nothing are left out
+func Components(executor BackupExecutor) []BackupExecutor {
+	switch e := executor.(type) {
+	case nil, *NoopBackupExecutor:
+		return nil
+	case *ConcurrentBackupExecutor:
+		components := []BackupExecutor{}
+		for _, each := range e.Executors {
+			components = append(components, Components(each)...)
+		}
+		return components
+	default:
+		return []BackupExecutor{executor}
+	}
+}
+
 // NoopBackupExecutor does nothing. For components that do not require backups
 type NoopBackupExecutor struct{}
 
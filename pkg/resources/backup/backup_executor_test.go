@@ -53,3 +53,33 @@ func (e mockBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.
 	time.Sleep(e.SleepTime)
 	return nil
 }
+
+func TestComponents(t *testing.T) {
+	postgres := NewAWSBackupExecutor("ns", "test-postgres", PostgresSnapshotType)
+	redis := NewAWSBackupExecutor("ns", "test-redis", RedisSnapshotType)
+	pv := NewCronJobBackupExecutor("test-pv-backup", "ns", "test-preupgrade-pv-backup")
+
+	executor := NewConcurrentBackupExecutor(
+		postgres,
+		NewNoopBackupExecutor(),
+		NewConcurrentBackupExecutor(redis, pv),
+	)
+
+	components := Components(executor)
+	if len(components) != 3 {
+		t.Fatalf("expected 3 components, got %d", len(components))
+	}
+	for i, expected := range []string{"test-postgres", "test-redis", "test-pv-backup"} {
+		component, ok := components[i].(ComponentBackupExecutor)
+		if !ok {
+			t.Fatalf("expected component %d to be a ComponentBackupExecutor", i)
+		}
+		if component.ComponentName() != expected {
+			t.Fatalf("expected component %s, got %s", expected, component.ComponentName())
+		}
+	}
+
+	if components := Components(NewNoopBackupExecutor()); len(components) != 0 {
+		t.Fatalf("expected no components for a noop backup, got %d", len(components))
+	}
+}
@@ -0,0 +1,448 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterStorageBackupType represents the type of in-cluster instance dumped
+type ClusterStorageBackupType string
+
+const (
+	// PostgresDumpType dumps an in-cluster postgres with pg_dump
+	PostgresDumpType ClusterStorageBackupType = "PostgresDump"
+	// RedisDumpType dumps an in-cluster redis to an RDB file
+	RedisDumpType ClusterStorageBackupType = "RedisDump"
+)
+
+const (
+	// DefaultClusterStorageRetention is the number of dumps kept for each
+	// instance when no retention is configured
+	DefaultClusterStorageRetention = 3
+
+	postgresImage = "registry.redhat.io/rhscl/postgresql-10-rhel7"
+	redisImage    = "registry.redhat.io/rhscl/redis-32-rhel7"
+	// backupImage ships s3cmd, used to upload the dumps to a bucket
+	backupImage = "quay.io/integreatly/backup-container:1.0.16"
+
+	// Suffix of the credentials secret of an in-cluster postgres, created
+	// by the openshift provider of the cloud resource operator
+	postgresCredentialsSuffix = "postgres-credentials"
+	postgresPort              = 5432
+	redisPort                 = 6379
+
+	backupPVCSize      = "5Gi"
+	backupVolumeName   = "backup"
+	backupMountPath    = "/backup"
+	s3BackupPrefix     = "cluster-storage-backups"
+	backupComponentKey = "integreatly.org/backup-component"
+	maxJobNameLength   = 63
+	jobNameHashLength  = 10
+)
+
+var backupPollInterval = 5 * time.Second
+
+// ClusterStorageDestination is where the dumps are written, and how many of
+// them are kept
+type ClusterStorageDestination struct {
+	// PVCName of the claim the dumps are written to. When empty, a claim
+	// named <instance>-backups is created
+	PVCName string
+	// S3SecretName of the secret with the details of the bucket the dumps
+	// are uploaded to, in the format of the cloud resource operator. Takes
+	// precedence over the claim
+	S3SecretName string
+	// Retention is the number of dumps kept, all of them are kept when it
+	// isn't positive
+	Retention int
+}
+
+// ClusterStorageBackupExecutor knows how to back up the postgres and redis
+// instances created in the cluster by the cloud resource operator, by running
+// a Job that dumps them to a claim or a bucket
+type ClusterStorageBackupExecutor struct {
+	Namespace    string                   // Namespace of the Postgres or Redis CR, where the Job runs
+	ResourceName string                   // Name of the Postgres or Redis CR
+	DumpType     ClusterStorageBackupType // Type of instance dumped
+	Destination  ClusterStorageDestination
+}
+
+func NewClusterStorageBackupExecutor(namespace, resourceName string, backupType ClusterStorageBackupType, destination ClusterStorageDestination) BackupExecutor {
+	return &ClusterStorageBackupExecutor{
+		Namespace:    namespace,
+		ResourceName: resourceName,
+		DumpType:     backupType,
+		Destination:  destination,
+	}
+}
+
+// PerformBackup dumps the instance and waits for the dump to complete
+func (e *ClusterStorageBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.Duration) error {
+	backupName := fmt.Sprintf("%s-preupgrade-dump-%s", e.ResourceName, time.Now().Format("2006-01-02-150405"))
+	return e.PerformNamedBackup(client, backupName, timeout)
+}
+
+// ComponentName returns the name of the Postgres or Redis CR
+func (e *ClusterStorageBackupExecutor) ComponentName() string {
+	return e.ResourceName
+}
+
+// BackupType returns the type of dump created
+func (e *ClusterStorageBackupExecutor) BackupType() string {
+	return string(e.DumpType)
+}
+
+// PerformNamedBackup runs a Job writing the dump backupName, waits for its
+// completion and removes the dumps and Jobs beyond the retention
+func (e *ClusterStorageBackupExecutor) PerformNamedBackup(client k8sclient.Client, backupName string, timeout time.Duration) error {
+	if err := e.StartNamedBackup(client, backupName); err != nil {
+		return err
+	}
+	return waitForBackup(client, e, backupName, timeout)
+}
+
+// StartNamedBackup creates the Job writing the dump backupName, unless it
+// already exists
+func (e *ClusterStorageBackupExecutor) StartNamedBackup(client k8sclient.Client, backupName string) error {
+	log.Infof("Performing backup of cluster storage", l.Fields{"backupType": e.DumpType, "resourceName": e.ResourceName, "backupName": backupName})
+
+	if e.Destination.S3SecretName == "" {
+		if err := e.reconcilePVC(client); err != nil {
+			return err
+		}
+	}
+
+	job, err := e.backupJob(backupName)
+	if err != nil {
+		return err
+	}
+	if err := client.Create(context.TODO(), job); err != nil && !k8serr.IsAlreadyExists(err) {
+		return fmt.Errorf("Error creating Job for backup of %s %s: %w", e.DumpType, e.ResourceName, err)
+	}
+	return nil
+}
+
+// CheckNamedBackup returns true once the Job writing the dump backupName
+// completed, and an error if it failed. The Jobs beyond the retention are
+// removed once it completes
+func (e *ClusterStorageBackupExecutor) CheckNamedBackup(client k8sclient.Client, backupName string) (bool, error) {
+	done, err := checkJob(client, jobName(backupName), e.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("Error performing backup of %s %s: %w", e.DumpType, e.ResourceName, err)
+	}
+	if !done {
+		return false, nil
+	}
+
+	// The dumps beyond the retention were removed by the Job, remove
+	// their Jobs too
+	if err := e.pruneJobs(client); err != nil {
+		log.Error(fmt.Sprintf("Failed to remove the previous backup Jobs of %s", e.ResourceName), err)
+	}
+	return true, nil
+}
+
+// pvcName returns the claim the dumps of the instance are written to
+func (e *ClusterStorageBackupExecutor) pvcName() string {
+	if e.Destination.PVCName != "" {
+		return e.Destination.PVCName
+	}
+	return fmt.Sprintf("%s-backups", e.ResourceName)
+}
+
+// reconcilePVC creates the claim of the dumps, unless one was configured
+func (e *ClusterStorageBackupExecutor) reconcilePVC(client k8sclient.Client) error {
+	if e.Destination.PVCName != "" {
+		return nil
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      e.pvcName(),
+			Namespace: e.Namespace,
+			Labels:    map[string]string{"integreatly": "yes", backupComponentKey: e.ResourceName},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(backupPVCSize),
+				},
+			},
+		},
+	}
+	if err := client.Create(context.TODO(), pvc); err != nil && !k8serr.IsAlreadyExists(err) {
+		return fmt.Errorf("Error creating PersistentVolumeClaim %s for backup of %s: %w", pvc.Name, e.ResourceName, err)
+	}
+	return nil
+}
+
+// backupJob builds the Job dumping the instance. The dump is written by an
+// init container to the backup volume, then the container of the Job uploads
+// it when the destination is a bucket, and removes the dumps beyond the
+// retention
+func (e *ClusterStorageBackupExecutor) backupJob(backupName string) (*batchv1.Job, error) {
+	host := clusterStorageHost(e.Namespace, e.ResourceName)
+	dumpEnv := dumpEnvVars(e.ResourceName, e.DumpType, backupName)
+
+	var dump corev1.Container
+	switch e.DumpType {
+	case PostgresDumpType:
+		dump = corev1.Container{
+			Name:    "dump",
+			Image:   postgresImage,
+			Command: []string{"/bin/sh", "-c", `mkdir -p "$DUMP_DIR" && pg_dump -Fc -f "$DUMP_DIR/$DUMP_FILE.tmp" && mv "$DUMP_DIR/$DUMP_FILE.tmp" "$DUMP_DIR/$DUMP_FILE"`},
+			Env:     append(dumpEnv, postgresEnvVars(host, e.ResourceName)...),
+		}
+	case RedisDumpType:
+		// redis-cli --rdb has redis save the dataset in the background, as
+		// with BGSAVE, and transfers the RDB file to the Job
+		dump = corev1.Container{
+			Name:    "dump",
+			Image:   redisImage,
+			Command: []string{"/bin/sh", "-c", `mkdir -p "$DUMP_DIR" && redis-cli -h "$REDIS_HOST" -p "$REDIS_PORT" --rdb "$DUMP_DIR/$DUMP_FILE.tmp" && mv "$DUMP_DIR/$DUMP_FILE.tmp" "$DUMP_DIR/$DUMP_FILE"`},
+			Env: append(dumpEnv,
+				corev1.EnvVar{Name: "REDIS_HOST", Value: host},
+				corev1.EnvVar{Name: "REDIS_PORT", Value: strconv.Itoa(redisPort)},
+			),
+		}
+	default:
+		return nil, fmt.Errorf("Unsupported value for ClusterStorageBackupType. Expected %s or %s, got %s",
+			PostgresDumpType, RedisDumpType, e.DumpType)
+	}
+	dump.ImagePullPolicy = corev1.PullIfNotPresent
+	dump.VolumeMounts = []corev1.VolumeMount{{Name: backupVolumeName, MountPath: backupMountPath}}
+
+	retentionEnv := corev1.EnvVar{Name: "RETENTION", Value: strconv.Itoa(e.Destination.Retention)}
+	var store corev1.Container
+	var volume corev1.Volume
+	if e.Destination.S3SecretName != "" {
+		store = corev1.Container{
+			Name:    "upload",
+			Image:   backupImage,
+			Command: []string{"/bin/sh", "-c", s3UploadScript},
+			Env:     append(append(dumpEnv, retentionEnv), s3EnvVars(e.ResourceName, e.Destination.S3SecretName)...),
+		}
+		volume = corev1.Volume{
+			Name:         backupVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		}
+	} else {
+		store = corev1.Container{
+			Name:    "retention",
+			Image:   dump.Image,
+			Command: []string{"/bin/sh", "-c", pvcRetentionScript},
+			Env:     append(dumpEnv, retentionEnv),
+		}
+		volume = corev1.Volume{
+			Name: backupVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: e.pvcName()},
+			},
+		}
+	}
+	store.ImagePullPolicy = corev1.PullIfNotPresent
+	store.VolumeMounts = dump.VolumeMounts
+
+	labels := map[string]string{"integreatly": "yes", backupComponentKey: e.ResourceName}
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName(backupName),
+			Namespace: e.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:  corev1.RestartPolicyNever,
+					InitContainers: []corev1.Container{dump},
+					Containers:     []corev1.Container{store},
+					Volumes:        []corev1.Volume{volume},
+				},
+			},
+		},
+	}, nil
+}
+
+// pvcRetentionScript removes the oldest dumps in the claim beyond the retention
+const pvcRetentionScript = `set -e
+if [ "$RETENTION" -le 0 ]; then exit 0; fi
+cd "$DUMP_DIR"
+ls -1t | grep -v '\.tmp$' | tail -n +$((RETENTION + 1)) | xargs -r rm -f --
+`
+
+// s3cmdOptionsScript sets the options of s3cmd from the details of the bucket
+const s3cmdOptionsScript = `set -e
+opts="--access_key=$AWS_ACCESS_KEY_ID --secret_key=$AWS_SECRET_ACCESS_KEY"
+if [ -n "$AWS_S3_REGION" ]; then opts="$opts --region=$AWS_S3_REGION"; fi
+if [ -n "$AWS_S3_ENDPOINT" ]; then opts="$opts --host=$AWS_S3_ENDPOINT --host-bucket=$AWS_S3_ENDPOINT"; fi
+`
+
+// s3UploadScript uploads the dump to the bucket, then removes the oldest dumps
+// in the bucket beyond the retention
+const s3UploadScript = s3cmdOptionsScript + `s3cmd $opts put "$DUMP_DIR/$DUMP_FILE" "s3://$AWS_S3_BUCKET_NAME/$S3_PREFIX/$DUMP_FILE"
+if [ "$RETENTION" -le 0 ]; then exit 0; fi
+s3cmd $opts ls "s3://$AWS_S3_BUCKET_NAME/$S3_PREFIX/" | sort | head -n -"$RETENTION" | awk '{print $4}' | while read -r object; do
+  s3cmd $opts del "$object"
+done
+`
+
+// pruneJobs removes the oldest backup Jobs of the instance beyond the
+// retention
+func (e *ClusterStorageBackupExecutor) pruneJobs(client k8sclient.Client) error {
+	if e.Destination.Retention <= 0 {
+		return nil
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := client.List(context.TODO(), jobs, k8sclient.InNamespace(e.Namespace), k8sclient.MatchingLabels{backupComponentKey: e.ResourceName}); err != nil {
+		return err
+	}
+	if len(jobs.Items) <= e.Destination.Retention {
+		return nil
+	}
+
+	sort.Slice(jobs.Items, func(i, j int) bool {
+		return jobs.Items[j].CreationTimestamp.Before(&jobs.Items[i].CreationTimestamp)
+	})
+	for i := e.Destination.Retention; i < len(jobs.Items); i++ {
+		job := jobs.Items[i]
+		log.Infof("Removing backup Job beyond the retention", l.Fields{"resourceName": e.ResourceName, "job": job.Name})
+		if err := client.Delete(context.TODO(), &job, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serr.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkJob returns true once the Job completed, and an error if it failed
+func checkJob(client k8sclient.Client, name, namespace string) (bool, error) {
+	job := &batchv1.Job{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, job); err != nil {
+		return false, fmt.Errorf("Error querying Job %s in namespace %s: %w", name, namespace, err)
+	}
+
+	// If the completion time field is set, the job finished succesfully
+	if job.Status.CompletionTime != nil {
+		return true, nil
+	}
+	return false, getJobError(job)
+}
+
+// jobName returns the name of the Job writing the dump backupName. Names
+// longer than a label value are shortened keeping them unique, as the Job
+// labels its pods with its name
+func jobName(backupName string) string {
+	if len(backupName) <= maxJobNameLength {
+		return backupName
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(backupName)))[:jobNameHashLength]
+	return fmt.Sprintf("%s-%s", backupName[:maxJobNameLength-jobNameHashLength-1], hash)
+}
+
+// clusterStorageHost returns the host of the service of an in-cluster
+// postgres or redis
+func clusterStorageHost(namespace, resourceName string) string {
+	return fmt.Sprintf("%s.%s.svc", resourceName, namespace)
+}
+
+// dumpEnvVars returns the location of the dump backupName of the instance in
+// the backup volume
+func dumpEnvVars(resourceName string, dumpType ClusterStorageBackupType, backupName string) []corev1.EnvVar {
+	dumpFile := backupName + ".dump"
+	if dumpType == RedisDumpType {
+		dumpFile = backupName + ".rdb"
+	}
+	return []corev1.EnvVar{
+		{Name: "DUMP_DIR", Value: fmt.Sprintf("%s/%s", backupMountPath, resourceName)},
+		{Name: "DUMP_FILE", Value: dumpFile},
+	}
+}
+
+// postgresEnvVars returns the connection details of an in-cluster postgres,
+// read from its credentials secret
+func postgresEnvVars(host, resourceName string) []corev1.EnvVar {
+	credentialsSecret := fmt.Sprintf("%s-%s", resourceName, postgresCredentialsSuffix)
+	return []corev1.EnvVar{
+		{Name: "PGHOST", Value: host},
+		{Name: "PGPORT", Value: strconv.Itoa(postgresPort)},
+		secretEnvVar("PGUSER", credentialsSecret, "user", false),
+		secretEnvVar("PGPASSWORD", credentialsSecret, "password", false),
+		secretEnvVar("PGDATABASE", credentialsSecret, "database", false),
+	}
+}
+
+// s3EnvVars returns the details of the bucket the dumps of the instance are
+// uploaded to
+func s3EnvVars(resourceName, secretName string) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "S3_PREFIX", Value: fmt.Sprintf("%s/%s", s3BackupPrefix, resourceName)},
+		secretEnvVar("AWS_ACCESS_KEY_ID", secretName, "credentialKeyID", false),
+		secretEnvVar("AWS_SECRET_ACCESS_KEY", secretName, "credentialSecretKey", false),
+		secretEnvVar("AWS_S3_BUCKET_NAME", secretName, "bucketName", false),
+		secretEnvVar("AWS_S3_REGION", secretName, "bucketRegion", true),
+		secretEnvVar("AWS_S3_ENDPOINT", secretName, "endpoint", true),
+	}
+}
+
+func secretEnvVar(name, secretName, key string, optional bool) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+				Optional:             &optional,
+			},
+		},
+	}
+}
+
+// NewInstallationBackupExecutor returns the backup of a postgres or redis
+// instance of the installation. It's a snapshot of the instance on AWS, or a
+// dump of it when the installation uses cluster storage
+func NewInstallationBackupExecutor(installation *integreatlyv1alpha1.RHMI, resourceName string, snapshotType AWSSnapshotType) BackupExecutor {
+	if installation.Spec.UseClusterStorage == "false" {
+		return NewAWSBackupExecutor(installation.Namespace, resourceName, snapshotType)
+	}
+
+	dumpType := PostgresDumpType
+	if snapshotType == RedisSnapshotType {
+		dumpType = RedisDumpType
+	}
+	return NewClusterStorageBackupExecutor(installation.Namespace, resourceName, dumpType, clusterStorageDestination(installation.Spec.ClusterStorageBackup))
+}
+
+// clusterStorageDestination returns the destination of the dumps configured
+// in the installation, defaulting the retention
+func clusterStorageDestination(spec *integreatlyv1alpha1.ClusterStorageBackupSpec) ClusterStorageDestination {
+	destination := ClusterStorageDestination{Retention: DefaultClusterStorageRetention}
+	if spec == nil {
+		return destination
+	}
+
+	destination.PVCName = spec.PVCName
+	destination.S3SecretName = spec.S3Secret
+	if spec.Retention != nil {
+		destination.Retention = *spec.Retention
+	}
+	return destination
+}
@@ -0,0 +1,195 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// restoreComponentKey labels the Jobs restoring a dump, they are not
+	// counted in the retention of the backup Jobs
+	restoreComponentKey = "integreatly.org/restore-component"
+
+	restoreVolumeName = "restore"
+	restoreMountPath  = "/restore"
+	// restoreRedisPort is the port of the redis loading the dump in the
+	// restore Job, the instance replicates its dataset from it
+	restoreRedisPort = 6380
+)
+
+// ClusterStorageRestoreExecutor restores the dumps created by the
+// ClusterStorageBackupExecutor, by running a Job that loads the dump into
+// the in-cluster instance
+type ClusterStorageRestoreExecutor struct {
+	Namespace    string                   // Namespace of the Postgres or Redis CR, where the Job runs
+	ResourceName string                   // Name of the Postgres or Redis CR
+	DumpType     ClusterStorageBackupType // Type of instance restored
+	Destination  ClusterStorageDestination
+}
+
+func NewClusterStorageRestoreExecutor(namespace, resourceName string, dumpType ClusterStorageBackupType, destination ClusterStorageDestination) RestoreExecutor {
+	return &ClusterStorageRestoreExecutor{
+		Namespace:    namespace,
+		ResourceName: resourceName,
+		DumpType:     dumpType,
+		Destination:  destination,
+	}
+}
+
+// ComponentName returns the name of the Postgres or Redis CR
+func (e *ClusterStorageRestoreExecutor) ComponentName() string {
+	return e.ResourceName
+}
+
+// StartRestore creates the Job loading the dump backupName into the
+// instance, unless it already exists
+func (e *ClusterStorageRestoreExecutor) StartRestore(client k8sclient.Client, backupName string) error {
+	log.Infof("Performing restore of cluster storage", l.Fields{"backupType": e.DumpType, "resourceName": e.ResourceName, "backupName": backupName})
+
+	job, err := e.restoreJob(backupName)
+	if err != nil {
+		return err
+	}
+	if err := client.Create(context.TODO(), job); err != nil && !k8serr.IsAlreadyExists(err) {
+		return fmt.Errorf("Error creating Job for restore of %s %s: %w", e.DumpType, e.ResourceName, err)
+	}
+	return nil
+}
+
+// CheckRestore returns true once the Job loading the dump backupName
+// completed, and an error if it failed
+func (e *ClusterStorageRestoreExecutor) CheckRestore(client k8sclient.Client, backupName string) (bool, error) {
+	done, err := checkJob(client, restoreJobName(backupName), e.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("Error performing restore of %s %s: %w", e.DumpType, e.ResourceName, err)
+	}
+	return done, nil
+}
+
+// restoreJob builds the Job loading the dump into the instance
+func (e *ClusterStorageRestoreExecutor) restoreJob(backupName string) (*batchv1.Job, error) {
+	return e.loadJob(backupName, e.ResourceName, restoreJobName(backupName))
+}
+
+// loadJob builds the Job name loading the dump of the instance into the
+// in-cluster instance of the Postgres or Redis CR targetName. When the
+// destination is a bucket, the dump is downloaded by an init container to
+// the backup volume first
+func (e *ClusterStorageRestoreExecutor) loadJob(backupName, targetName, name string) (*batchv1.Job, error) {
+	host := clusterStorageHost(e.Namespace, targetName)
+	dumpEnv := dumpEnvVars(e.ResourceName, e.DumpType, backupName)
+	volumeMounts := []corev1.VolumeMount{{Name: backupVolumeName, MountPath: backupMountPath}}
+
+	var restore corev1.Container
+	volumes := []corev1.Volume{}
+	switch e.DumpType {
+	case PostgresDumpType:
+		restore = corev1.Container{
+			Name:    "restore",
+			Image:   postgresImage,
+			Command: []string{"/bin/sh", "-c", `pg_restore --clean --if-exists --no-owner --single-transaction -d "$PGDATABASE" "$DUMP_DIR/$DUMP_FILE"`},
+			Env:     append(dumpEnv, postgresEnvVars(host, targetName)...),
+		}
+	case RedisDumpType:
+		restore = corev1.Container{
+			Name:    "restore",
+			Image:   redisImage,
+			Command: []string{"/bin/sh", "-c", redisRestoreScript},
+			Env: append(dumpEnv,
+				corev1.EnvVar{Name: "REDIS_HOST", Value: host},
+				corev1.EnvVar{Name: "REDIS_PORT", Value: strconv.Itoa(redisPort)},
+				corev1.EnvVar{Name: "RESTORE_DIR", Value: restoreMountPath},
+				corev1.EnvVar{Name: "RESTORE_PORT", Value: strconv.Itoa(restoreRedisPort)},
+				corev1.EnvVar{
+					Name:      "POD_IP",
+					ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"}},
+				},
+			),
+		}
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: restoreVolumeName, MountPath: restoreMountPath})
+		volumes = append(volumes, corev1.Volume{
+			Name:         restoreVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	default:
+		return nil, fmt.Errorf("Unsupported value for ClusterStorageBackupType. Expected %s or %s, got %s",
+			PostgresDumpType, RedisDumpType, e.DumpType)
+	}
+	restore.ImagePullPolicy = corev1.PullIfNotPresent
+	restore.VolumeMounts = volumeMounts
+
+	initContainers := []corev1.Container{}
+	if e.Destination.S3SecretName != "" {
+		initContainers = append(initContainers, corev1.Container{
+			Name:            "download",
+			Image:           backupImage,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/bin/sh", "-c", s3DownloadScript},
+			Env:             append(dumpEnv, s3EnvVars(e.ResourceName, e.Destination.S3SecretName)...),
+			VolumeMounts:    []corev1.VolumeMount{{Name: backupVolumeName, MountPath: backupMountPath}},
+		})
+		volumes = append(volumes, corev1.Volume{
+			Name:         backupVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	} else {
+		claimName := e.Destination.PVCName
+		if claimName == "" {
+			claimName = fmt.Sprintf("%s-backups", e.ResourceName)
+		}
+		volumes = append(volumes, corev1.Volume{
+			Name: backupVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+			},
+		})
+	}
+
+	labels := map[string]string{"integreatly": "yes", restoreComponentKey: e.ResourceName}
+	backoffLimit := int32(0)
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: e.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:  corev1.RestartPolicyNever,
+					InitContainers: initContainers,
+					Containers:     []corev1.Container{restore},
+					Volumes:        volumes,
+				},
+			},
+		},
+	}, nil
+}
+
+// s3DownloadScript downloads the dump from the bucket to the backup volume
+const s3DownloadScript = s3cmdOptionsScript + `mkdir -p "$DUMP_DIR"
+s3cmd $opts get --force "s3://$AWS_S3_BUCKET_NAME/$S3_PREFIX/$DUMP_FILE" "$DUMP_DIR/$DUMP_FILE"
+`
+
+// redisRestoreScript loads the RDB file into a redis running in the Job, and
+// has the instance replicate its dataset from it. A full resync replaces the
+// dataset of the instance, which is then detached from the Job
+const redisRestoreScript = `set -e
+cp "$DUMP_DIR/$DUMP_FILE" "$RESTORE_DIR/dump.rdb"
+redis-server --port "$RESTORE_PORT" --dir "$RESTORE_DIR" --dbfilename dump.rdb --save "" --appendonly no --protected-mode no --daemonize yes
+until redis-cli -p "$RESTORE_PORT" ping | grep -q PONG; do sleep 1; done
+redis-cli -h "$REDIS_HOST" -p "$REDIS_PORT" slaveof "$POD_IP" "$RESTORE_PORT"
+until redis-cli -h "$REDIS_HOST" -p "$REDIS_PORT" info replication | grep -q master_link_status:up; do sleep 1; done
+redis-cli -h "$REDIS_HOST" -p "$REDIS_PORT" slaveof no one
+`
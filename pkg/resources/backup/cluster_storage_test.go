@@ -0,0 +1,257 @@
+package backup
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// jobClient finishes the Jobs it creates, failing them if failJobs is set.
+// The creation time is set as the API server would
+type jobClient struct {
+	k8sclient.Client
+	failJobs bool
+}
+
+func (c *jobClient) Create(ctx context.Context, obj runtime.Object, opts ...k8sclient.CreateOption) error {
+	if job, ok := obj.(*batchv1.Job); ok {
+		job.CreationTimestamp = metav1.Now()
+		if c.failJobs {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}}
+		} else {
+			now := metav1.Now()
+			job.Status.CompletionTime = &now
+		}
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func buildSchemeForClusterStorageBackup(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func backupJob(name string, created time.Time) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "redhat-rhmi-operator",
+			Labels:            map[string]string{backupComponentKey: "threescale-postgres-rhmi"},
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+}
+
+func TestClusterStorageBackupExecutor(t *testing.T) {
+	backupPollInterval = time.Millisecond
+	scheme := buildSchemeForClusterStorageBackup(t)
+	namespace := "redhat-rhmi-operator"
+
+	cases := []struct {
+		Name              string
+		Executor          *ClusterStorageBackupExecutor
+		Objects           []runtime.Object
+		FailJobs          bool
+		ExpectedError     string
+		ExpectedPVC       string
+		ExpectedContainer string
+		ExpectedJobs      int
+	}{
+		{
+			Name: "test postgres is dumped to a claim",
+			Executor: &ClusterStorageBackupExecutor{
+				Namespace:    namespace,
+				ResourceName: "threescale-postgres-rhmi",
+				DumpType:     PostgresDumpType,
+				Destination:  ClusterStorageDestination{Retention: 2},
+			},
+			Objects: []runtime.Object{
+				backupJob("old-backup", time.Now().Add(-2*time.Hour)),
+				backupJob("previous-backup", time.Now().Add(-time.Hour)),
+			},
+			ExpectedPVC:       "threescale-postgres-rhmi-backups",
+			ExpectedContainer: "retention",
+			ExpectedJobs:      2,
+		},
+		{
+			Name: "test redis is dumped to a bucket",
+			Executor: &ClusterStorageBackupExecutor{
+				Namespace:    namespace,
+				ResourceName: "threescale-redis-rhmi",
+				DumpType:     RedisDumpType,
+				Destination:  ClusterStorageDestination{S3SecretName: "backups-s3-credentials", Retention: 2},
+			},
+			ExpectedContainer: "upload",
+			ExpectedJobs:      1,
+		},
+		{
+			Name: "test all the jobs are kept without retention",
+			Executor: &ClusterStorageBackupExecutor{
+				Namespace:    namespace,
+				ResourceName: "threescale-postgres-rhmi",
+				DumpType:     PostgresDumpType,
+				Destination:  ClusterStorageDestination{PVCName: "rhmi-backups"},
+			},
+			Objects: []runtime.Object{
+				backupJob("old-backup", time.Now().Add(-2*time.Hour)),
+				backupJob("previous-backup", time.Now().Add(-time.Hour)),
+			},
+			ExpectedContainer: "retention",
+			ExpectedJobs:      3,
+		},
+		{
+			Name: "test failed dump",
+			Executor: &ClusterStorageBackupExecutor{
+				Namespace:    namespace,
+				ResourceName: "threescale-postgres-rhmi",
+				DumpType:     PostgresDumpType,
+				Destination:  ClusterStorageDestination{Retention: 2},
+			},
+			FailJobs:          true,
+			ExpectedError:     "BackoffLimitExceeded",
+			ExpectedPVC:       "threescale-postgres-rhmi-backups",
+			ExpectedContainer: "retention",
+			ExpectedJobs:      1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := &jobClient{
+				Client:   fake.NewFakeClientWithScheme(scheme, tc.Objects...),
+				failJobs: tc.FailJobs,
+			}
+
+			err := tc.Executor.PerformNamedBackup(client, "test-backup", time.Second)
+			if tc.ExpectedError == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.ExpectedError != "" && (err == nil || !strings.Contains(err.Error(), tc.ExpectedError)) {
+				t.Fatalf("expected error containing %q, got %v", tc.ExpectedError, err)
+			}
+
+			job := &batchv1.Job{}
+			if err := client.Get(context.TODO(), types.NamespacedName{Name: "test-backup", Namespace: namespace}, job); err != nil {
+				t.Fatalf("expected the backup Job to be created: %v", err)
+			}
+			podSpec := job.Spec.Template.Spec
+			if len(podSpec.InitContainers) != 1 || len(podSpec.Containers) != 1 || podSpec.Containers[0].Name != tc.ExpectedContainer {
+				t.Fatalf("expected a dump init container and a %s container, got %v", tc.ExpectedContainer, podSpec)
+			}
+			claim := podSpec.Volumes[0].PersistentVolumeClaim
+			if tc.Executor.Destination.S3SecretName != "" && claim != nil {
+				t.Fatalf("expected the dump to be written to an empty dir, got claim %s", claim.ClaimName)
+			}
+			if tc.Executor.Destination.S3SecretName == "" && (claim == nil || claim.ClaimName != tc.Executor.pvcName()) {
+				t.Fatalf("expected the dump to be written to claim %s, got %v", tc.Executor.pvcName(), podSpec.Volumes[0])
+			}
+
+			if tc.ExpectedPVC != "" {
+				pvc := &corev1.PersistentVolumeClaim{}
+				if err := client.Get(context.TODO(), types.NamespacedName{Name: tc.ExpectedPVC, Namespace: namespace}, pvc); err != nil {
+					t.Fatalf("expected claim %s to be created: %v", tc.ExpectedPVC, err)
+				}
+			}
+
+			jobs := &batchv1.JobList{}
+			if err := client.List(context.TODO(), jobs, k8sclient.MatchingLabels{backupComponentKey: tc.Executor.ResourceName}); err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs.Items) != tc.ExpectedJobs {
+				t.Fatalf("expected %d backup Jobs to be kept, got %d", tc.ExpectedJobs, len(jobs.Items))
+			}
+			for _, job := range jobs.Items {
+				if job.Name == "old-backup" && tc.Executor.Destination.Retention > 0 {
+					t.Fatal("expected the oldest backup Job to be removed")
+				}
+			}
+		})
+	}
+}
+
+func TestNewInstallationBackupExecutor(t *testing.T) {
+	retention := 5
+	cases := []struct {
+		Name         string
+		Installation *integreatlyv1alpha1.RHMI
+		SnapshotType AWSSnapshotType
+		Expected     BackupExecutor
+	}{
+		{
+			Name: "test snapshot on AWS",
+			Installation: &integreatlyv1alpha1.RHMI{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "redhat-rhmi-operator"},
+				Spec:       integreatlyv1alpha1.RHMISpec{UseClusterStorage: "false"},
+			},
+			SnapshotType: RedisSnapshotType,
+			Expected:     NewAWSBackupExecutor("redhat-rhmi-operator", "test", RedisSnapshotType),
+		},
+		{
+			Name: "test dump with the default destination",
+			Installation: &integreatlyv1alpha1.RHMI{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "redhat-rhmi-operator"},
+				Spec:       integreatlyv1alpha1.RHMISpec{UseClusterStorage: "true"},
+			},
+			SnapshotType: PostgresSnapshotType,
+			Expected: NewClusterStorageBackupExecutor("redhat-rhmi-operator", "test", PostgresDumpType, ClusterStorageDestination{
+				Retention: DefaultClusterStorageRetention,
+			}),
+		},
+		{
+			Name: "test dump with the destination of the installation",
+			Installation: &integreatlyv1alpha1.RHMI{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "redhat-rhmi-operator"},
+				Spec: integreatlyv1alpha1.RHMISpec{
+					ClusterStorageBackup: &integreatlyv1alpha1.ClusterStorageBackupSpec{
+						S3Secret:  "backups-s3-credentials",
+						Retention: &retention,
+					},
+				},
+			},
+			SnapshotType: RedisSnapshotType,
+			Expected: NewClusterStorageBackupExecutor("redhat-rhmi-operator", "test", RedisDumpType, ClusterStorageDestination{
+				S3SecretName: "backups-s3-credentials",
+				Retention:    5,
+			}),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			executor := NewInstallationBackupExecutor(tc.Installation, "test", tc.SnapshotType)
+			if !reflect.DeepEqual(executor, tc.Expected) {
+				t.Fatalf("expected %+v, got %+v", tc.Expected, executor)
+			}
+		})
+	}
+}
+
+func TestJobName(t *testing.T) {
+	if name := jobName("threescale-redis-rhmi-test"); name != "threescale-redis-rhmi-test" {
+		t.Fatalf("expected short names to be kept, got %s", name)
+	}
+
+	long := jobName("threescale-backend-redis-rhmi-preupgrade-dump-2021-01-01-000000-1")
+	other := jobName("threescale-backend-redis-rhmi-preupgrade-dump-2021-01-01-000000-2")
+	if len(long) > maxJobNameLength || long == other {
+		t.Fatalf("expected long names to be shortened uniquely, got %s and %s", long, other)
+	}
+}
@@ -0,0 +1,142 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	batchv1 "k8s.io/api/batch/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ClusterStorageBackupVerifier verifies the dumps created by the
+// ClusterStorageBackupExecutor. A throwaway Postgres or Redis CR is created
+// with the deployment type and tier of the resource, and the dump is loaded
+// into its in-cluster instance by a Job once the cloud resource operator
+// completes it
+type ClusterStorageBackupVerifier struct {
+	Namespace    string                   // Namespace of the Postgres or Redis CR, where the Jobs run
+	ResourceName string                   // Name of the Postgres or Redis CR
+	DumpType     ClusterStorageBackupType // Type of instance dumped
+	Destination  ClusterStorageDestination
+}
+
+func NewClusterStorageBackupVerifier(namespace, resourceName string, dumpType ClusterStorageBackupType, destination ClusterStorageDestination) BackupVerifier {
+	return &ClusterStorageBackupVerifier{
+		Namespace:    namespace,
+		ResourceName: resourceName,
+		DumpType:     dumpType,
+		Destination:  destination,
+	}
+}
+
+// StartVerification creates the throwaway Postgres or Redis CR the dump
+// backupName is loaded into, unless it already exists
+func (v *ClusterStorageBackupVerifier) StartVerification(client k8sclient.Client, backupName string) error {
+	log.Infof("Verifying backup of cluster storage", l.Fields{"backupType": v.DumpType, "resourceName": v.ResourceName, "backupName": backupName})
+
+	source, cr, err := v.resources(client, backupName)
+	if err != nil {
+		return err
+	}
+	sourceSpec, _ := resourceSpec(source)
+	return createThrowawayResource(client, source, cr, sourceSpec.Tier)
+}
+
+// CheckVerification loads the dump into the throwaway instance once the
+// cloud resource operator completes it, then runs the check against it
+func (v *ClusterStorageBackupVerifier) CheckVerification(client k8sclient.Client, backupName string, check integreatlyv1alpha1.BackupSanityCheck) (bool, int64, error) {
+	_, cr, err := v.resources(client, backupName)
+	if err != nil {
+		return false, 0, err
+	}
+	target, err := restoredInstance(client, cr)
+	if err != nil || target == nil {
+		return false, 0, err
+	}
+
+	done, err := v.loadDump(client, backupName, cr.GetName())
+	if err != nil || !done {
+		return false, 0, err
+	}
+	return runSanityCheck(client, v.Namespace, cr.GetName(), *target, check)
+}
+
+// CleanupVerification removes the Jobs run against the throwaway instance,
+// and its Postgres or Redis CR
+func (v *ClusterStorageBackupVerifier) CleanupVerification(client k8sclient.Client, backupName string) error {
+	_, cr, err := v.resources(client, backupName)
+	if err != nil {
+		return err
+	}
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: loadJobName(cr.GetName()), Namespace: v.Namespace}}
+	if err := client.Delete(context.TODO(), job, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to remove the Job %s loading the dump %s: %w", job.Name, backupName, err)
+	}
+	if err := cleanupSanityCheck(client, v.Namespace, cr.GetName()); err != nil {
+		return err
+	}
+	return deleteThrowawayResource(client, cr)
+}
+
+// loadDump creates the Job loading the dump backupName into the throwaway
+// instance name, unless it already exists, and returns true once it
+// completed
+func (v *ClusterStorageBackupVerifier) loadDump(client k8sclient.Client, backupName, name string) (bool, error) {
+	restorer := &ClusterStorageRestoreExecutor{
+		Namespace:    v.Namespace,
+		ResourceName: v.ResourceName,
+		DumpType:     v.DumpType,
+		Destination:  v.Destination,
+	}
+	job, err := restorer.loadJob(backupName, name, loadJobName(name))
+	if err != nil {
+		return false, err
+	}
+	if err := client.Create(context.TODO(), job); err != nil && !k8serr.IsAlreadyExists(err) {
+		return false, fmt.Errorf("Error creating Job loading the dump %s into %s: %w", backupName, name, err)
+	}
+
+	done, err := checkJob(client, job.Name, v.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("Error loading the dump %s into %s: %w", backupName, name, err)
+	}
+	return done, nil
+}
+
+// resources returns the Postgres or Redis CR of the resource, and the one of
+// the throwaway instance the dump is loaded into
+func (v *ClusterStorageBackupVerifier) resources(client k8sclient.Client, backupName string) (cloudResource, cloudResource, error) {
+	var source cloudResource
+	switch v.DumpType {
+	case PostgresDumpType:
+		source = &v1alpha1.Postgres{}
+	case RedisDumpType:
+		source = &v1alpha1.Redis{}
+	default:
+		return nil, nil, fmt.Errorf("Unsupported value for ClusterStorageBackupType. Expected %s or %s, got %s",
+			PostgresDumpType, RedisDumpType, v.DumpType)
+	}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: v.ResourceName, Namespace: v.Namespace}, source); err != nil {
+		return nil, nil, fmt.Errorf("failed to get %s %s: %w", v.DumpType, v.ResourceName, err)
+	}
+
+	cr, err := throwawayResource(source, verificationName(backupName))
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, cr, nil
+}
+
+// loadJobName returns the name of the Job loading the dump into the
+// throwaway instance name
+func loadJobName(name string) string {
+	return name + "-load"
+}
@@ -0,0 +1,143 @@
+package backup
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterStorageBackupVerifier(t *testing.T) {
+	scheme := buildSchemeForVerification(t)
+	namespace := "redhat-rhmi-operator"
+
+	cases := []struct {
+		Name           string
+		DumpType       ClusterStorageBackupType
+		Source         cloudResource
+		Connection     map[string][]byte
+		FailJobs       bool
+		Message        string
+		ExpectedResult int64
+		ExpectedError  string
+	}{
+		{
+			Name:     "test postgres dump is verified",
+			DumpType: PostgresDumpType,
+			Source: &v1alpha1.Postgres{
+				ObjectMeta: metav1.ObjectMeta{Name: "threescale-postgres-rhmi", Namespace: namespace},
+				Spec:       types.ResourceTypeSpec{Type: "workshop", Tier: "development"},
+			},
+			Connection: map[string][]byte{
+				"host":     []byte("rhmi-verify-backup." + namespace + ".svc"),
+				"port":     []byte("5432"),
+				"username": []byte("user"),
+				"password": []byte("password"),
+				"database": []byte("system"),
+			},
+			Message:        "12\n",
+			ExpectedResult: 12,
+		},
+		{
+			Name:     "test redis dump is verified",
+			DumpType: RedisDumpType,
+			Source: &v1alpha1.Redis{
+				ObjectMeta: metav1.ObjectMeta{Name: "threescale-redis-rhmi", Namespace: namespace},
+				Spec:       types.ResourceTypeSpec{Type: "workshop", Tier: "development"},
+			},
+			Connection: map[string][]byte{
+				"uri":  []byte("rhmi-verify-backup." + namespace + ".svc"),
+				"port": []byte("6379"),
+			},
+			Message:        "(integer) 3\n",
+			ExpectedResult: 3,
+		},
+		{
+			Name:     "test dump failing to load",
+			DumpType: RedisDumpType,
+			Source: &v1alpha1.Redis{
+				ObjectMeta: metav1.ObjectMeta{Name: "threescale-redis-rhmi", Namespace: namespace},
+				Spec:       types.ResourceTypeSpec{Type: "workshop", Tier: "development"},
+			},
+			Connection: map[string][]byte{
+				"uri":  []byte("rhmi-verify-backup." + namespace + ".svc"),
+				"port": []byte("6379"),
+			},
+			FailJobs:      true,
+			ExpectedError: "Error loading the dump",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := &sanityCheckClient{
+				jobClient: jobClient{Client: fake.NewFakeClientWithScheme(scheme, tc.Source), failJobs: tc.FailJobs},
+				message:   tc.Message,
+			}
+			verifier := NewClusterStorageBackupVerifier(namespace, tc.Source.GetName(), tc.DumpType, ClusterStorageDestination{})
+
+			if err := verifier.StartVerification(client, "backup"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			verifyID := verificationName("backup")
+
+			// The throwaway instance is created with the deployment type
+			// and tier of the resource
+			cr, err := throwawayResource(tc.Source, verifyID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: verifyID, Namespace: namespace}, cr); err != nil {
+				t.Fatal(err)
+			}
+			if spec, _ := resourceSpec(cr); spec.Type != "workshop" || spec.Tier != "development" {
+				t.Fatalf("expected the throwaway instance to match the resource, got %+v", spec)
+			}
+
+			done, _, err := verifier.CheckVerification(client, "backup", DefaultSanityCheck(string(tc.DumpType)))
+			if err != nil || done {
+				t.Fatalf("expected the verification to wait for the throwaway instance, got %t, %v", done, err)
+			}
+
+			completeThrowawayResource(t, client, cr, tc.Connection)
+			done, result, err := verifier.CheckVerification(client, "backup", DefaultSanityCheck(string(tc.DumpType)))
+			if tc.ExpectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.ExpectedError) {
+					t.Fatalf("expected error containing %q, got %v", tc.ExpectedError, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !done || result != tc.ExpectedResult {
+					t.Fatalf("expected the check to complete with %d, got %t, %d", tc.ExpectedResult, done, result)
+				}
+			}
+
+			// The dump is loaded into the throwaway instance
+			load := &batchv1.Job{}
+			if err := client.Get(context.TODO(), k8stypes.NamespacedName{Name: loadJobName(verifyID), Namespace: namespace}, load); err != nil {
+				t.Fatal(err)
+			}
+			loaded := false
+			for _, env := range load.Spec.Template.Spec.Containers[0].Env {
+				loaded = loaded || env.Value == clusterStorageHost(namespace, verifyID)
+			}
+			if !loaded {
+				t.Fatalf("expected the dump to be loaded into %s, got %v", verifyID, load.Spec.Template.Spec.Containers[0].Env)
+			}
+
+			if err := verifier.CleanupVerification(client, "backup"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expectCleanedUp(t, client, cr)
+		})
+	}
+}
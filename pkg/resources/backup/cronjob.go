@@ -9,6 +9,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	apiv1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,10 +32,34 @@ func NewCronJobBackupExecutor(cronJobName, namespace, jobGenerateName string) Ba
 }
 
 func (e *CronJobBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.Duration) error {
-	log.Infof("Performing backup by creating Job", l.Fields{"cronJob": e.CronJobName, "ns": e.Namespace})
-
 	// Generate the job name
 	jobName := fmt.Sprintf("%s-%s", e.JobGenerateName, time.Now().Format("2006-01-02-150405"))
+	return e.PerformNamedBackup(client, jobName, timeout)
+}
+
+// ComponentName returns the name of the CronJob that performs the backup
+func (e *CronJobBackupExecutor) ComponentName() string {
+	return e.CronJobName
+}
+
+// BackupType returns the kind of resource created, a Job
+func (e *CronJobBackupExecutor) BackupType() string {
+	return "Job"
+}
+
+// PerformNamedBackup creates a Job named jobName from the CronJob and waits for
+// its completion
+func (e *CronJobBackupExecutor) PerformNamedBackup(client k8sclient.Client, jobName string, timeout time.Duration) error {
+	if err := e.StartNamedBackup(client, jobName); err != nil {
+		return err
+	}
+	return waitForBackup(client, e, jobName, timeout)
+}
+
+// StartNamedBackup creates a Job named jobName from the CronJob, unless it
+// already exists
+func (e *CronJobBackupExecutor) StartNamedBackup(client k8sclient.Client, jobName string) error {
+	log.Infof("Performing backup by creating Job", l.Fields{"cronJob": e.CronJobName, "ns": e.Namespace, "job": jobName})
 
 	// Get the CronJob to run
 	cronJob := &batchv1beta1.CronJob{}
@@ -55,34 +80,33 @@ func (e *CronJobBackupExecutor) PerformBackup(client k8sclient.Client, timeout t
 		},
 		Spec: jobTemplate.Spec,
 	}
-	if err := client.Create(context.TODO(), job); err != nil {
+	if err := client.Create(context.TODO(), job); err != nil && !k8serr.IsAlreadyExists(err) {
 		return fmt.Errorf("Error creating Job from CronJob %s in namespace %s: %v",
 			e.CronJobName, e.Namespace, err)
 	}
 
-	// Query the newly created job until either it finishes, or it times out
-	timeStarted := time.Now()
-	for {
-		if time.Now().After(timeStarted.Add(timeout)) {
-			return fmt.Errorf("Timed out when waiting for Job %s to finish", "")
-		}
+	return nil
+}
 
-		queryJob := &batchv1.Job{}
-		err = client.Get(context.TODO(), types.NamespacedName{Name: jobName, Namespace: e.Namespace}, queryJob)
-		if err != nil {
-			return fmt.Errorf("Error querying newly created Job %s in namespace %s: %v", "", e.Namespace, err)
-		}
+// CheckNamedBackup returns true once the Job jobName completed, and an error
+// if it failed
+func (e *CronJobBackupExecutor) CheckNamedBackup(client k8sclient.Client, jobName string) (bool, error) {
+	queryJob := &batchv1.Job{}
+	err := client.Get(context.TODO(), types.NamespacedName{Name: jobName, Namespace: e.Namespace}, queryJob)
+	if err != nil {
+		return false, fmt.Errorf("Error querying newly created Job %s in namespace %s: %v", jobName, e.Namespace, err)
+	}
 
-		// If the completion time field is set, the job finished succesfully
-		if queryJob.Status.CompletionTime != nil {
-			return nil
-		}
+	// If the completion time field is set, the job finished succesfully
+	if queryJob.Status.CompletionTime != nil {
+		return true, nil
+	}
 
-		// Check if the job finished with errors, if it did, return the error
-		if err := getJobError(queryJob); err != nil {
-			return fmt.Errorf("Error performing backup job: %w", err)
-		}
+	// Check if the job finished with errors, if it did, return the error
+	if err := getJobError(queryJob); err != nil {
+		return false, fmt.Errorf("Error performing backup job: %w", err)
 	}
+	return false, nil
 }
 
 func getJobError(job *batchv1.Job) error {
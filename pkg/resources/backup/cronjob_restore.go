@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// restoreCronJobSuffix is the suffix of the suspended CronJob restoring the
+// backups of a backup CronJob
+const restoreCronJobSuffix = "-restore"
+
+// CronJobRestoreExecutor restores the backups created by the
+// CronJobBackupExecutor, by creating a Job from the restore CronJob of the
+// product. The restore CronJob is named after the backup CronJob with the
+// restoreCronJobSuffix, and its Job restores the backup written by the Job
+// named in the BACKUP_JOB environment variable
+type CronJobRestoreExecutor struct {
+	CronJobName string // Name of the CronJob that performs the restore
+	Namespace   string // Namespace where the CronJob is (and the job is created)
+}
+
+func NewCronJobRestoreExecutor(backupCronJobName, namespace string) RestoreExecutor {
+	return &CronJobRestoreExecutor{
+		CronJobName: backupCronJobName + restoreCronJobSuffix,
+		Namespace:   namespace,
+	}
+}
+
+// ComponentName returns the name of the CronJob that performs the restore
+func (e *CronJobRestoreExecutor) ComponentName() string {
+	return e.CronJobName
+}
+
+// StartRestore creates a Job from the restore CronJob restoring the backup
+// written by the Job jobName, unless it already exists
+func (e *CronJobRestoreExecutor) StartRestore(client k8sclient.Client, jobName string) error {
+	log.Infof("Performing restore by creating Job", l.Fields{"cronJob": e.CronJobName, "ns": e.Namespace, "backupJob": jobName})
+
+	cronJob := &batchv1beta1.CronJob{}
+	err := client.Get(context.TODO(), types.NamespacedName{Name: e.CronJobName, Namespace: e.Namespace}, cronJob)
+	if k8serr.IsNotFound(err) {
+		return fmt.Errorf("no restore CronJob %s in namespace %s, the backup %s must be restored manually", e.CronJobName, e.Namespace, jobName)
+	}
+	if err != nil {
+		return fmt.Errorf("Error obtaining CronJob %s in namespace %s: %v", e.CronJobName, e.Namespace, err)
+	}
+
+	spec := cronJob.Spec.JobTemplate.Spec.DeepCopy()
+	backupJobEnv := corev1.EnvVar{Name: "BACKUP_JOB", Value: jobName}
+	for i := range spec.Template.Spec.InitContainers {
+		spec.Template.Spec.InitContainers[i].Env = append(spec.Template.Spec.InitContainers[i].Env, backupJobEnv)
+	}
+	for i := range spec.Template.Spec.Containers {
+		spec.Template.Spec.Containers[i].Env = append(spec.Template.Spec.Containers[i].Env, backupJobEnv)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: e.Namespace,
+			Name:      restoreJobName(jobName),
+		},
+		Spec: *spec,
+	}
+	if err := client.Create(context.TODO(), job); err != nil && !k8serr.IsAlreadyExists(err) {
+		return fmt.Errorf("Error creating Job from CronJob %s in namespace %s: %v", e.CronJobName, e.Namespace, err)
+	}
+	return nil
+}
+
+// CheckRestore returns true once the Job restoring the backup jobName
+// completed, and an error if it failed
+func (e *CronJobRestoreExecutor) CheckRestore(client k8sclient.Client, jobName string) (bool, error) {
+	done, err := checkJob(client, restoreJobName(jobName), e.Namespace)
+	if err != nil {
+		return false, fmt.Errorf("Error performing restore job: %w", err)
+	}
+	return done, nil
+}
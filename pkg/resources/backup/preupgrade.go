@@ -0,0 +1,355 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultPreUpgradeRetryInterval is the time before the first retry of
+	// a failed pre-upgrade backup
+	DefaultPreUpgradeRetryInterval = 5 * time.Minute
+	// maxPreUpgradeRetryInterval caps the doubling of the retry interval
+	maxPreUpgradeRetryInterval = time.Hour
+)
+
+// PreUpgradeGate takes the backups of the products before they're upgraded,
+// following the pre-upgrade backup policy of the RHMIConfig. Failed backups
+// are retried in later reconciles, and the upgrade is blocked or continues
+// once the retries are exhausted. The outcome of each backup is emitted as
+// an event, and listed in the status of the installation. The backups run
+// in the background: each attempt starts them, and the following reconciles
+// check them until they finish or time out, so that no reconcile waits for
+// them.
+//
+// The gate is scoped to a product with ForProduct. A nil or unscoped
+// PreUpgradeGate blocks the upgrade until the backup completes
+type PreUpgradeGate struct {
+	recorder     record.EventRecorder
+	installation *integreatlyv1alpha1.RHMI
+	now          func() time.Time
+	product      integreatlyv1alpha1.ProductName
+
+	// statuses are shared by the gates of every product
+	statuses *preUpgradeStatuses
+}
+
+type preUpgradeStatuses struct {
+	mu     sync.Mutex
+	byName map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.PreUpgradeBackupStatus
+}
+
+// NewPreUpgradeGate returns the gate of the installation. The outcomes of
+// the backups in previous reconciles are read from the status of the
+// installation
+func NewPreUpgradeGate(recorder record.EventRecorder, installation *integreatlyv1alpha1.RHMI) *PreUpgradeGate {
+	gate := &PreUpgradeGate{
+		recorder:     recorder,
+		installation: installation,
+		now:          time.Now,
+		statuses: &preUpgradeStatuses{
+			byName: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.PreUpgradeBackupStatus{},
+		},
+	}
+	for _, status := range installation.Status.PreUpgradeBackups {
+		gate.statuses.byName[status.Product] = status
+	}
+	return gate
+}
+
+// ForProduct returns the gate of the backups of the product
+func (g *PreUpgradeGate) ForProduct(product integreatlyv1alpha1.ProductName) *PreUpgradeGate {
+	if g == nil {
+		return nil
+	}
+	productGate := *g
+	productGate.product = product
+	return &productGate
+}
+
+// Backup takes the backup of the product before the upgrade of the install
+// plan, and returns true once the upgrade can be approved. It returns false
+// without error while the backup runs, or while a retry of the backup is
+// pending
+func (g *PreUpgradeGate) Backup(ctx context.Context, client k8sclient.Client, installPlan string, executor BackupExecutor) (bool, error) {
+	if g == nil || g.product == "" {
+		if err := executor.PerformBackup(client, DefaultTimeout); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	product := g.product
+	policy, err := g.policy(ctx, client, product)
+	if err != nil {
+		return false, err
+	}
+
+	now := g.now()
+	status := g.status(product, installPlan)
+	log.Infof("Pre-upgrade backup", l.Fields{"product": product, "installPlan": installPlan, "phase": status.Phase, "attempts": status.Attempts, "backup": status.Backup})
+
+	if *policy.Skip {
+		if status.Phase != integreatlyv1alpha1.PhaseCompleted {
+			status.Phase = integreatlyv1alpha1.PhaseCompleted
+			status.Message = "backup skipped by the pre-upgrade backup policy"
+			g.setStatus(status)
+			g.event("Normal", fmt.Sprintf("Pre-upgrade backup of %s skipped by the pre-upgrade backup policy", product))
+		}
+		return true, nil
+	}
+
+	components, err := namedComponents(executor)
+	if err != nil {
+		return false, err
+	}
+
+	switch status.Phase {
+	case integreatlyv1alpha1.PhaseCompleted:
+		// The backup completed, but the upgrade wasn't approved
+		return true, nil
+	case integreatlyv1alpha1.PhaseFailed, integreatlyv1alpha1.PhaseBlocked:
+		if status.Attempts > *policy.Retries {
+			return g.exhausted(status, policy)
+		}
+		if now.Before(status.LastAttempt.Add(retryInterval(policy, status.Attempts))) {
+			return false, nil
+		}
+	}
+
+	// The backups are started once per attempt, and checked on each
+	// reconcile until they finish
+	if status.Phase != integreatlyv1alpha1.PhaseInProgress {
+		attempt := metav1.NewTime(now)
+		status.Attempts++
+		status.LastAttempt = &attempt
+		status.Backup = preUpgradeBackupName(installPlan, status.Attempts)
+		status.Phase = integreatlyv1alpha1.PhaseInProgress
+		status.Message = ""
+		g.setStatus(status)
+
+		for _, component := range components {
+			if err := component.StartNamedBackup(client, componentBackupName(component, status.Backup)); err != nil {
+				return g.failed(status, policy, err)
+			}
+		}
+	}
+
+	done, err := checkPreUpgradeBackup(client, status, components)
+	if err != nil {
+		return g.failed(status, policy, err)
+	}
+	if !done {
+		if status.LastAttempt != nil && now.Before(status.LastAttempt.Add(policy.Timeout.Duration)) {
+			return false, nil
+		}
+		return g.failed(status, policy, fmt.Errorf("backup did not finish after %s", policy.Timeout.Duration))
+	}
+
+	status.Phase = integreatlyv1alpha1.PhaseCompleted
+	status.Message = fmt.Sprintf("backup completed after %d attempts", status.Attempts)
+	g.setStatus(status)
+	g.event("Normal", fmt.Sprintf("Pre-upgrade backup of %s completed", product))
+	return true, nil
+}
+
+// failed records the failure of the last attempt of the backup, and applies
+// the failure policy once the retries are exhausted
+func (g *PreUpgradeGate) failed(status integreatlyv1alpha1.PreUpgradeBackupStatus, policy integreatlyv1alpha1.PreUpgradeBackupPolicy, err error) (bool, error) {
+	status.Phase = integreatlyv1alpha1.PhaseFailed
+	status.Message = err.Error()
+	g.setStatus(status)
+
+	if status.Attempts > *policy.Retries {
+		return g.exhausted(status, policy)
+	}
+	next := status.LastAttempt.Add(retryInterval(policy, status.Attempts))
+	g.event("Warning", fmt.Sprintf("Pre-upgrade backup of %s failed, attempt %d of %d. Retrying after %s: %v",
+		status.Product, status.Attempts, *policy.Retries+1, next.UTC().Format(time.RFC3339), err))
+	return false, fmt.Errorf("pre-upgrade backup failed, retrying after %s: %w", next.UTC().Format(time.RFC3339), err)
+}
+
+// checkPreUpgradeBackup returns true once the backups of every component
+// started by the last attempt completed
+func checkPreUpgradeBackup(client k8sclient.Client, status integreatlyv1alpha1.PreUpgradeBackupStatus, components []ComponentBackupExecutor) (bool, error) {
+	// The backups of the operators before the backups ran in the background
+	// aren't named, and stopped with the operator
+	if status.Backup == "" {
+		return false, errors.New("backup interrupted")
+	}
+
+	done := true
+	for _, component := range components {
+		componentDone, err := component.CheckNamedBackup(client, componentBackupName(component, status.Backup))
+		if err != nil {
+			return false, err
+		}
+		done = done && componentDone
+	}
+	return done, nil
+}
+
+// namedComponents returns the components of the backup, each started and
+// checked on its own
+func namedComponents(executor BackupExecutor) ([]ComponentBackupExecutor, error) {
+	components := []ComponentBackupExecutor{}
+	for _, each := range Components(executor) {
+		component, ok := each.(ComponentBackupExecutor)
+		if !ok {
+			return nil, fmt.Errorf("backup %T can't run in the background", each)
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// preUpgradeBackupName returns the name of the backups of an attempt, the
+// backup of each component is named after it
+func preUpgradeBackupName(installPlan string, attempt int) string {
+	return fmt.Sprintf("preupgrade-%s-%d", installPlan, attempt)
+}
+
+// componentBackupName returns the name of the snapshot or Job of the
+// component for the backup
+func componentBackupName(component ComponentBackupExecutor, backupName string) string {
+	return jobName(fmt.Sprintf("%s-%s", component.ComponentName(), backupName))
+}
+
+// exhausted applies the failure policy once the retries of the backup are
+// exhausted. The event is only emitted the first time
+func (g *PreUpgradeGate) exhausted(status integreatlyv1alpha1.PreUpgradeBackupStatus, policy integreatlyv1alpha1.PreUpgradeBackupPolicy) (bool, error) {
+	if policy.FailurePolicy == integreatlyv1alpha1.PreUpgradeBackupWarn {
+		status.Message = fmt.Sprintf("upgrade continued without backup after %d attempts: %s", status.Attempts, status.Message)
+		g.setStatus(status)
+		g.event("Warning", fmt.Sprintf("Pre-upgrade backup of %s failed after %d attempts, continuing with the upgrade", status.Product, status.Attempts))
+		return true, nil
+	}
+
+	err := fmt.Errorf("pre-upgrade backup failed after %d attempts, the upgrade is blocked: %s", status.Attempts, status.Message)
+	if status.Phase != integreatlyv1alpha1.PhaseBlocked {
+		status.Phase = integreatlyv1alpha1.PhaseBlocked
+		g.setStatus(status)
+		g.event("Warning", fmt.Sprintf("Pre-upgrade backup of %s failed after %d attempts, the upgrade is blocked", status.Product, status.Attempts))
+	}
+	return false, err
+}
+
+// Statuses returns the outcomes of the backups, to be set in the status of
+// the installation
+func (g *PreUpgradeGate) Statuses() []integreatlyv1alpha1.PreUpgradeBackupStatus {
+	if g == nil {
+		return nil
+	}
+
+	g.statuses.mu.Lock()
+	defer g.statuses.mu.Unlock()
+
+	statuses := make([]integreatlyv1alpha1.PreUpgradeBackupStatus, 0, len(g.statuses.byName))
+	for _, status := range g.statuses.byName {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Product < statuses[j].Product })
+	return statuses
+}
+
+// policy returns the pre-upgrade backup policy of the product, with the
+// defaults applied
+func (g *PreUpgradeGate) policy(ctx context.Context, client k8sclient.Client, product integreatlyv1alpha1.ProductName) (integreatlyv1alpha1.PreUpgradeBackupPolicy, error) {
+	policy := integreatlyv1alpha1.PreUpgradeBackupPolicy{}
+
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{}
+	err := client.Get(ctx, k8sclient.ObjectKey{Name: maintenance.RHMIConfigName, Namespace: g.installation.Namespace}, rhmiConfig)
+	if err != nil && !k8serr.IsNotFound(err) {
+		return policy, fmt.Errorf("failed to get the pre-upgrade backup policy: %w", err)
+	}
+	if err == nil && rhmiConfig.Spec.Backup.PreUpgrade != nil {
+		policy = rhmiConfig.Spec.Backup.PreUpgrade.ForProduct(product)
+	}
+
+	if policy.Skip == nil {
+		skip := false
+		policy.Skip = &skip
+	}
+	if policy.Timeout == nil {
+		policy.Timeout = &metav1.Duration{Duration: DefaultTimeout}
+	}
+	if policy.Retries == nil {
+		retries := 0
+		policy.Retries = &retries
+	}
+	if policy.RetryInterval == nil {
+		policy.RetryInterval = &metav1.Duration{Duration: DefaultPreUpgradeRetryInterval}
+	}
+	if policy.FailurePolicy == "" {
+		policy.FailurePolicy = integreatlyv1alpha1.PreUpgradeBackupBlock
+	}
+	return policy, nil
+}
+
+// status returns the outcome of the backup of the product for the install
+// plan. The outcomes of the backups of previous upgrades are discarded
+func (g *PreUpgradeGate) status(product integreatlyv1alpha1.ProductName, installPlan string) integreatlyv1alpha1.PreUpgradeBackupStatus {
+	g.statuses.mu.Lock()
+	defer g.statuses.mu.Unlock()
+
+	status, ok := g.statuses.byName[product]
+	if !ok || status.InstallPlan != installPlan {
+		return integreatlyv1alpha1.PreUpgradeBackupStatus{Product: product, InstallPlan: installPlan}
+	}
+	return status
+}
+
+func (g *PreUpgradeGate) setStatus(status integreatlyv1alpha1.PreUpgradeBackupStatus) {
+	g.statuses.mu.Lock()
+	defer g.statuses.mu.Unlock()
+
+	g.statuses.byName[status.Product] = status
+}
+
+func (g *PreUpgradeGate) event(eventType, message string) {
+	if g.recorder == nil {
+		return
+	}
+	g.recorder.Event(g.installation, eventType, integreatlyv1alpha1.EventPreUpgradeBackup, message)
+}
+
+// retryInterval returns the time to wait after the given number of attempts,
+// the interval is doubled on each retry
+func retryInterval(policy integreatlyv1alpha1.PreUpgradeBackupPolicy, attempts int) time.Duration {
+	interval := policy.RetryInterval.Duration
+	for i := 1; i < attempts && interval < maxPreUpgradeRetryInterval; i++ {
+		interval *= 2
+	}
+	if interval > maxPreUpgradeRetryInterval {
+		return maxPreUpgradeRetryInterval
+	}
+	return interval
+}
+
+type preUpgradeGateContextKey struct{}
+
+// WithPreUpgradeGate returns a copy of the context carrying the gate, it's
+// passed to the product reconcilers
+func WithPreUpgradeGate(ctx context.Context, gate *PreUpgradeGate) context.Context {
+	return context.WithValue(ctx, preUpgradeGateContextKey{}, gate)
+}
+
+// PreUpgradeGateFromContext returns the gate of the context, or nil if
+// there's none
+func PreUpgradeGateFromContext(ctx context.Context) *PreUpgradeGate {
+	gate, _ := ctx.Value(preUpgradeGateContextKey{}).(*PreUpgradeGate)
+	return gate
+}
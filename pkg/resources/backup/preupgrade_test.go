@@ -0,0 +1,293 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/maintenance"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// failingBackupExecutor fails the first failures backups it performs, and
+// reports the backups it starts as running for the first pending checks
+type failingBackupExecutor struct {
+	failures int
+	pending  int
+	backups  int
+	checks   int
+	started  []string
+}
+
+func (e *failingBackupExecutor) PerformBackup(client k8sclient.Client, timeout time.Duration) error {
+	e.backups++
+	if e.backups <= e.failures {
+		return errors.New("snapshot failed")
+	}
+	return nil
+}
+
+func (e *failingBackupExecutor) ComponentName() string {
+	return "threescale-postgres-rhmi"
+}
+
+func (e *failingBackupExecutor) BackupType() string {
+	return string(PostgresSnapshotType)
+}
+
+func (e *failingBackupExecutor) PerformNamedBackup(client k8sclient.Client, backupName string, timeout time.Duration) error {
+	return e.PerformBackup(client, timeout)
+}
+
+func (e *failingBackupExecutor) StartNamedBackup(client k8sclient.Client, backupName string) error {
+	e.backups++
+	e.started = append(e.started, backupName)
+	return nil
+}
+
+func (e *failingBackupExecutor) CheckNamedBackup(client k8sclient.Client, backupName string) (bool, error) {
+	if e.backups <= e.failures {
+		return false, errors.New("snapshot failed")
+	}
+	e.checks++
+	return e.checks > e.pending, nil
+}
+
+func TestPreUpgradeGate(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	namespace := "redhat-rhmi-operator"
+	retries := 1
+	skip := true
+	rhmiConfig := func(preUpgrade *integreatlyv1alpha1.PreUpgradeBackup) runtime.Object {
+		return &integreatlyv1alpha1.RHMIConfig{
+			ObjectMeta: metav1.ObjectMeta{Name: maintenance.RHMIConfigName, Namespace: namespace},
+			Spec: integreatlyv1alpha1.RHMIConfigSpec{
+				Backup: integreatlyv1alpha1.Backup{PreUpgrade: preUpgrade},
+			},
+		}
+	}
+
+	type step struct {
+		// after is the time since the first reconcile
+		after            time.Duration
+		expectedApproved bool
+		expectedError    bool
+		expectedPhase    integreatlyv1alpha1.StatusPhase
+		expectedAttempts int
+	}
+
+	cases := []struct {
+		Name            string
+		Objects         []runtime.Object
+		Failures        int
+		Pending         int
+		Steps           []step
+		ExpectedBackups int
+		ExpectedEvents  int
+	}{
+		{
+			Name:     "test upgrade is blocked by default",
+			Failures: 5,
+			Steps: []step{
+				{expectedError: true, expectedPhase: integreatlyv1alpha1.PhaseBlocked, expectedAttempts: 1},
+				{after: time.Hour, expectedError: true, expectedPhase: integreatlyv1alpha1.PhaseBlocked, expectedAttempts: 1},
+			},
+			ExpectedBackups: 1,
+			ExpectedEvents:  1,
+		},
+		{
+			Name: "test failed backup is retried after the interval",
+			Objects: []runtime.Object{rhmiConfig(&integreatlyv1alpha1.PreUpgradeBackup{
+				PreUpgradeBackupPolicy: integreatlyv1alpha1.PreUpgradeBackupPolicy{Retries: &retries},
+			})},
+			Failures: 1,
+			Steps: []step{
+				{expectedError: true, expectedPhase: integreatlyv1alpha1.PhaseFailed, expectedAttempts: 1},
+				{after: time.Minute, expectedPhase: integreatlyv1alpha1.PhaseFailed, expectedAttempts: 1},
+				{after: DefaultPreUpgradeRetryInterval, expectedApproved: true, expectedPhase: integreatlyv1alpha1.PhaseCompleted, expectedAttempts: 2},
+			},
+			ExpectedBackups: 2,
+			ExpectedEvents:  2,
+		},
+		{
+			Name: "test upgrade continues when the failure policy is warn",
+			Objects: []runtime.Object{rhmiConfig(&integreatlyv1alpha1.PreUpgradeBackup{
+				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.PreUpgradeBackupPolicy{
+					integreatlyv1alpha1.Product3Scale: {FailurePolicy: integreatlyv1alpha1.PreUpgradeBackupWarn},
+				},
+			})},
+			Failures: 5,
+			Steps: []step{
+				{expectedApproved: true, expectedPhase: integreatlyv1alpha1.PhaseFailed, expectedAttempts: 1},
+			},
+			ExpectedBackups: 1,
+			ExpectedEvents:  1,
+		},
+		{
+			Name:    "test running backup is checked on the following reconciles",
+			Pending: 2,
+			Steps: []step{
+				{expectedPhase: integreatlyv1alpha1.PhaseInProgress, expectedAttempts: 1},
+				{after: time.Minute, expectedPhase: integreatlyv1alpha1.PhaseInProgress, expectedAttempts: 1},
+				{after: 2 * time.Minute, expectedApproved: true, expectedPhase: integreatlyv1alpha1.PhaseCompleted, expectedAttempts: 1},
+			},
+			ExpectedBackups: 1,
+			ExpectedEvents:  1,
+		},
+		{
+			Name:    "test running backup times out",
+			Pending: 100,
+			Steps: []step{
+				{expectedPhase: integreatlyv1alpha1.PhaseInProgress, expectedAttempts: 1},
+				{after: DefaultTimeout + time.Minute, expectedError: true, expectedPhase: integreatlyv1alpha1.PhaseBlocked, expectedAttempts: 1},
+			},
+			ExpectedBackups: 1,
+			ExpectedEvents:  1,
+		},
+		{
+			Name: "test backup is skipped",
+			Objects: []runtime.Object{rhmiConfig(&integreatlyv1alpha1.PreUpgradeBackup{
+				PreUpgradeBackupPolicy: integreatlyv1alpha1.PreUpgradeBackupPolicy{Skip: &skip},
+			})},
+			Steps: []step{
+				{expectedApproved: true, expectedPhase: integreatlyv1alpha1.PhaseCompleted},
+			},
+			ExpectedEvents: 1,
+		},
+		{
+			Name: "test policy of another product doesn't apply",
+			Objects: []runtime.Object{rhmiConfig(&integreatlyv1alpha1.PreUpgradeBackup{
+				Products: map[integreatlyv1alpha1.ProductName]integreatlyv1alpha1.PreUpgradeBackupPolicy{
+					integreatlyv1alpha1.ProductRHSSO: {Skip: &skip},
+				},
+			})},
+			Steps: []step{
+				{expectedApproved: true, expectedPhase: integreatlyv1alpha1.PhaseCompleted, expectedAttempts: 1},
+			},
+			ExpectedBackups: 1,
+			ExpectedEvents:  1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, tc.Objects...)
+			executor := &failingBackupExecutor{failures: tc.Failures, pending: tc.Pending}
+			recorder := record.NewFakeRecorder(10)
+			installation := &integreatlyv1alpha1.RHMI{ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: namespace}}
+			started := time.Now()
+
+			for i, step := range tc.Steps {
+				// Each reconcile starts from the status of the previous one
+				gate := NewPreUpgradeGate(recorder, installation)
+				gate.now = func() time.Time { return started.Add(step.after) }
+				ctx := WithPreUpgradeGate(context.TODO(), gate.ForProduct(integreatlyv1alpha1.Product3Scale))
+
+				approved, err := PreUpgradeGateFromContext(ctx).Backup(ctx, client, "install-plan", executor)
+				if approved != step.expectedApproved {
+					t.Fatalf("step %d: expected approved %t, got %t", i, step.expectedApproved, approved)
+				}
+				if (err != nil) != step.expectedError {
+					t.Fatalf("step %d: expected error %t, got %v", i, step.expectedError, err)
+				}
+
+				installation.Status.PreUpgradeBackups = gate.Statuses()
+				if len(installation.Status.PreUpgradeBackups) != 1 {
+					t.Fatalf("step %d: expected the status of the backup, got %v", i, installation.Status.PreUpgradeBackups)
+				}
+				status := installation.Status.PreUpgradeBackups[0]
+				if status.Phase != step.expectedPhase || status.Attempts != step.expectedAttempts {
+					t.Fatalf("step %d: expected phase %s after %d attempts, got %+v", i, step.expectedPhase, step.expectedAttempts, status)
+				}
+			}
+
+			if executor.backups != tc.ExpectedBackups {
+				t.Fatalf("expected %d backups, got %d", tc.ExpectedBackups, executor.backups)
+			}
+			// The backup of each attempt is named after the install plan
+			for i, name := range executor.started {
+				if expected := fmt.Sprintf("threescale-postgres-rhmi-preupgrade-install-plan-%d", i+1); name != expected {
+					t.Fatalf("expected backup %s, got %s", expected, name)
+				}
+			}
+			if len(recorder.Events) != tc.ExpectedEvents {
+				t.Fatalf("expected %d events, got %d", tc.ExpectedEvents, len(recorder.Events))
+			}
+		})
+	}
+}
+
+func TestPreUpgradeGateNewInstallPlan(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	installation := &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: "redhat-rhmi-operator"},
+		Status: integreatlyv1alpha1.RHMIStatus{
+			PreUpgradeBackups: []integreatlyv1alpha1.PreUpgradeBackupStatus{
+				{Product: integreatlyv1alpha1.Product3Scale, InstallPlan: "previous", Phase: integreatlyv1alpha1.PhaseBlocked, Attempts: 3},
+			},
+		},
+	}
+	gate := NewPreUpgradeGate(record.NewFakeRecorder(10), installation).ForProduct(integreatlyv1alpha1.Product3Scale)
+	executor := &failingBackupExecutor{}
+
+	approved, err := gate.Backup(context.TODO(), fake.NewFakeClientWithScheme(scheme), "install-plan", executor)
+	if err != nil || !approved {
+		t.Fatalf("expected the upgrade of a new install plan to be approved, got %t: %v", approved, err)
+	}
+	if status := gate.Statuses()[0]; status.InstallPlan != "install-plan" || status.Attempts != 1 {
+		t.Fatalf("expected the status of the previous upgrade to be replaced, got %+v", status)
+	}
+}
+
+func TestPreUpgradeGateInterrupted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	// A backup in progress without a name was run by an operator that
+	// waited for it, and stopped with it
+	lastAttempt := metav1.Now()
+	installation := &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhmi", Namespace: "redhat-rhmi-operator"},
+		Status: integreatlyv1alpha1.RHMIStatus{
+			PreUpgradeBackups: []integreatlyv1alpha1.PreUpgradeBackupStatus{
+				{Product: integreatlyv1alpha1.Product3Scale, InstallPlan: "install-plan", Phase: integreatlyv1alpha1.PhaseInProgress, Attempts: 1, LastAttempt: &lastAttempt},
+			},
+		},
+	}
+	gate := NewPreUpgradeGate(record.NewFakeRecorder(10), installation).ForProduct(integreatlyv1alpha1.Product3Scale)
+	executor := &failingBackupExecutor{}
+
+	approved, err := gate.Backup(context.TODO(), fake.NewFakeClientWithScheme(scheme), "install-plan", executor)
+	if approved || err == nil {
+		t.Fatalf("expected the interrupted backup to fail, got %t: %v", approved, err)
+	}
+	if status := gate.Statuses()[0]; status.Phase != integreatlyv1alpha1.PhaseBlocked || executor.backups != 0 {
+		t.Fatalf("expected the interrupted backup to block the upgrade without a new backup, got %+v", status)
+	}
+}
+
+func TestPreUpgradeGateNil(t *testing.T) {
+	executor := &failingBackupExecutor{failures: 1}
+	approved, err := PreUpgradeGateFromContext(context.TODO()).Backup(context.TODO(), nil, "install-plan", executor)
+	if approved || err == nil {
+		t.Fatalf("expected the upgrade to be blocked by the failed backup, got %t: %v", approved, err)
+	}
+}
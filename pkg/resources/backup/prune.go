@@ -0,0 +1,184 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	crov1alpha1 "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	crotypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// snapshot is a PostgresSnapshot or RedisSnapshot considered for pruning
+type snapshot struct {
+	kind         string
+	resourceName string
+	phase        crotypes.StatusPhase
+	meta         metav1.ObjectMeta
+	object       runtime.Object
+}
+
+// PruneSnapshots removes the PostgresSnapshot and RedisSnapshot CRs of the
+// namespace that are beyond the retention, and returns the removed ones as
+// <kind>/<name>. The cloud resource operator removes the snapshots from the
+// cloud provider with their CRs. Only the snapshots created by the operator
+// are pruned, and snapshots in progress or referred to by a RHMIBackup or
+// RHMIRestore are left untouched. The snapshots created by the operator are
+// labelled with their component, or, for the pre-upgrade snapshots taken
+// before the label was added, named after it
+func PruneSnapshots(ctx context.Context, client k8sclient.Client, namespace string, retention *integreatlyv1alpha1.BackupRetention, now time.Time) ([]string, error) {
+	if retention == nil {
+		return nil, nil
+	}
+
+	postgresSnapshots := &crov1alpha1.PostgresSnapshotList{}
+	if err := client.List(ctx, postgresSnapshots, k8sclient.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("failed to list postgres snapshots: %w", err)
+	}
+	redisSnapshots := &crov1alpha1.RedisSnapshotList{}
+	if err := client.List(ctx, redisSnapshots, k8sclient.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("failed to list redis snapshots: %w", err)
+	}
+
+	referenced, err := referencedSnapshots(ctx, client, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	postgres := []snapshot{}
+	for i := range postgresSnapshots.Items {
+		s := &postgresSnapshots.Items[i]
+		if !createdByOperator(s.ObjectMeta, s.Spec.ResourceName) {
+			continue
+		}
+		postgres = append(postgres, snapshot{kind: string(PostgresSnapshotType), resourceName: s.Spec.ResourceName, phase: s.Status.Phase, meta: s.ObjectMeta, object: s})
+	}
+	redis := []snapshot{}
+	for i := range redisSnapshots.Items {
+		s := &redisSnapshots.Items[i]
+		if !createdByOperator(s.ObjectMeta, s.Spec.ResourceName) {
+			continue
+		}
+		redis = append(redis, snapshot{kind: string(RedisSnapshotType), resourceName: s.Spec.ResourceName, phase: s.Status.Phase, meta: s.ObjectMeta, object: s})
+	}
+
+	expired := append(
+		expiredSnapshots(postgres, retention.ForType("postgres"), now),
+		expiredSnapshots(redis, retention.ForType("redis"), now)...,
+	)
+
+	pruned := []string{}
+	for _, s := range expired {
+		if referenced[snapshotKey(s.kind, s.meta.Name)] {
+			continue
+		}
+		log.Infof("Removing snapshot beyond the retention", l.Fields{"kind": s.kind, "name": s.meta.Name, "resourceName": s.resourceName})
+		if err := client.Delete(ctx, s.object); err != nil && !k8serr.IsNotFound(err) {
+			return pruned, fmt.Errorf("failed to remove %s %s: %w", s.kind, s.meta.Name, err)
+		}
+		pruned = append(pruned, snapshotKey(s.kind, s.meta.Name))
+	}
+
+	return pruned, nil
+}
+
+// referencedSnapshots returns the snapshots backing up a RHMIBackup or
+// restored by a RHMIRestore of the namespace, as <kind>/<name>. They're kept
+// while the RHMIBackup or RHMIRestore exists
+func referencedSnapshots(ctx context.Context, client k8sclient.Client, namespace string) (map[string]bool, error) {
+	referenced := map[string]bool{}
+
+	backups := &integreatlyv1alpha1.RHMIBackupList{}
+	if err := client.List(ctx, backups, k8sclient.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("failed to list rhmi backups: %w", err)
+	}
+	for _, backup := range backups.Items {
+		for _, component := range backup.Status.Components {
+			if component.Backup != "" {
+				referenced[snapshotKey(component.Type, component.Backup)] = true
+			}
+		}
+	}
+
+	restores := &integreatlyv1alpha1.RHMIRestoreList{}
+	if err := client.List(ctx, restores, k8sclient.InNamespace(namespace)); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("failed to list rhmi restores: %w", err)
+	}
+	for _, restore := range restores.Items {
+		for _, step := range restore.Status.Steps {
+			if step.Backup != "" {
+				referenced[snapshotKey(step.Type, step.Backup)] = true
+			}
+		}
+	}
+
+	return referenced, nil
+}
+
+// createdByOperator returns true if the snapshot of the resource was created
+// by the operator
+func createdByOperator(snapshotMeta metav1.ObjectMeta, resourceName string) bool {
+	if _, ok := snapshotMeta.Labels[backupComponentKey]; ok {
+		return true
+	}
+	return strings.HasPrefix(snapshotMeta.Name, resourceName+preUpgradeSnapshotInfix)
+}
+
+func snapshotKey(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// expiredSnapshots returns the finished snapshots that are beyond all the
+// limits of the retention. The most recent snapshots of each instance are
+// counted for KeepLast
+func expiredSnapshots(snapshots []snapshot, retention integreatlyv1alpha1.SnapshotRetention, now time.Time) []snapshot {
+	if retention.KeepLast == nil && retention.KeepDays == nil {
+		return nil
+	}
+
+	byResource := map[string][]snapshot{}
+	resourceNames := []string{}
+	for _, s := range snapshots {
+		if s.meta.DeletionTimestamp != nil {
+			continue
+		}
+		if s.phase != crotypes.PhaseComplete && s.phase != crotypes.PhaseFailed {
+			continue
+		}
+		if _, ok := byResource[s.resourceName]; !ok {
+			resourceNames = append(resourceNames, s.resourceName)
+		}
+		byResource[s.resourceName] = append(byResource[s.resourceName], s)
+	}
+	sort.Strings(resourceNames)
+
+	expired := []snapshot{}
+	for _, resourceName := range resourceNames {
+		resourceSnapshots := byResource[resourceName]
+		sort.SliceStable(resourceSnapshots, func(i, j int) bool {
+			return resourceSnapshots[j].meta.CreationTimestamp.Before(&resourceSnapshots[i].meta.CreationTimestamp)
+		})
+
+		for i, s := range resourceSnapshots {
+			if retention.KeepLast != nil && i < *retention.KeepLast {
+				continue
+			}
+			if retention.KeepDays != nil && s.meta.CreationTimestamp.Time.After(now.AddDate(0, 0, -*retention.KeepDays)) {
+				continue
+			}
+			expired = append(expired, s)
+		}
+	}
+
+	return expired
+}
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	scheme, err := buildSchemeForAWSBackup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := integreatlyv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	namespace := "redhat-rhmi-operator"
+	now := time.Now()
+	labels := func(resourceName string) map[string]string {
+		return map[string]string{"integreatly": "yes", backupComponentKey: resourceName}
+	}
+	postgresSnapshot := func(name, resourceName string, age time.Duration, phase types.StatusPhase) runtime.Object {
+		return &v1alpha1.PostgresSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels(resourceName), CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec:       v1alpha1.PostgresSnapshotSpec{ResourceName: resourceName},
+			Status:     types.ResourceTypeSnapshotStatus{Phase: phase},
+		}
+	}
+	redisSnapshot := func(name, resourceName string, age time.Duration) runtime.Object {
+		return &v1alpha1.RedisSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels(resourceName), CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Spec:       v1alpha1.RedisSnapshotSpec{ResourceName: resourceName},
+			Status:     types.ResourceTypeSnapshotStatus{Phase: types.PhaseComplete},
+		}
+	}
+	day := 24 * time.Hour
+	snapshots := []runtime.Object{
+		postgresSnapshot("threescale-postgres-1", "threescale-postgres-rhmi", 30*day, types.PhaseComplete),
+		postgresSnapshot("threescale-postgres-2", "threescale-postgres-rhmi", 10*day, types.PhaseFailed),
+		postgresSnapshot("threescale-postgres-3", "threescale-postgres-rhmi", day, types.PhaseComplete),
+		postgresSnapshot("threescale-postgres-4", "threescale-postgres-rhmi", 40*day, types.PhaseInProgress),
+		postgresSnapshot("rhsso-postgres-1", "rhsso-postgres-rhmi", 20*day, types.PhaseComplete),
+		redisSnapshot("threescale-redis-1", "threescale-redis-rhmi", 20*day),
+		redisSnapshot("threescale-redis-2", "threescale-redis-rhmi", 5*day),
+		// created outside of the operator
+		&v1alpha1.PostgresSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "manual-postgres-1", Namespace: namespace, CreationTimestamp: metav1.NewTime(now.Add(-50 * day))},
+			Spec:       v1alpha1.PostgresSnapshotSpec{ResourceName: "threescale-postgres-rhmi"},
+			Status:     types.ResourceTypeSnapshotStatus{Phase: types.PhaseComplete},
+		},
+		// a pre-upgrade snapshot created before the snapshots were labelled
+		&v1alpha1.PostgresSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: "rhsso-postgres-rhmi-preupgrade-snapshot-2021-01-01-000000", Namespace: namespace, CreationTimestamp: metav1.NewTime(now.Add(-25 * day))},
+			Spec:       v1alpha1.PostgresSnapshotSpec{ResourceName: "rhsso-postgres-rhmi"},
+			Status:     types.ResourceTypeSnapshotStatus{Phase: types.PhaseComplete},
+		},
+		postgresSnapshot("ups-postgres-1", "ups-postgres-rhmi", 50*day, types.PhaseComplete),
+		postgresSnapshot("ups-postgres-2", "ups-postgres-rhmi", 45*day, types.PhaseComplete),
+		postgresSnapshot("ups-postgres-3", "ups-postgres-rhmi", 40*day, types.PhaseComplete),
+		// the snapshots of a backup, and the snapshots being restored, are kept
+		&integreatlyv1alpha1.RHMIBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: namespace},
+			Status: integreatlyv1alpha1.RHMIBackupStatus{
+				Components: []integreatlyv1alpha1.RHMIBackupComponentStatus{
+					{Name: "ups-postgres-rhmi", Type: string(PostgresSnapshotType), Backup: "ups-postgres-1"},
+				},
+			},
+		},
+		&integreatlyv1alpha1.RHMIRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "restore", Namespace: namespace},
+			Status: integreatlyv1alpha1.RHMIRestoreStatus{
+				Steps: []integreatlyv1alpha1.RHMIRestoreStep{
+					{Action: integreatlyv1alpha1.RestoreActionRestore, Component: "ups-postgres-rhmi", Type: string(PostgresSnapshotType), Backup: "ups-postgres-2"},
+				},
+			},
+		},
+	}
+	totalSnapshots := len(snapshots) - 2
+
+	cases := []struct {
+		Name           string
+		Retention      *integreatlyv1alpha1.BackupRetention
+		ExpectedPruned []string
+	}{
+		{
+			Name: "test snapshots are kept without retention",
+		},
+		{
+			Name: "test most recent snapshots of each instance are kept",
+			Retention: &integreatlyv1alpha1.BackupRetention{
+				SnapshotRetention: integreatlyv1alpha1.SnapshotRetention{KeepLast: intPtr(1)},
+			},
+			ExpectedPruned: []string{
+				"PostgresSnapshot/rhsso-postgres-rhmi-preupgrade-snapshot-2021-01-01-000000",
+				"PostgresSnapshot/threescale-postgres-2",
+				"PostgresSnapshot/threescale-postgres-1",
+				"RedisSnapshot/threescale-redis-1",
+			},
+		},
+		{
+			Name: "test snapshots are kept while within any limit",
+			Retention: &integreatlyv1alpha1.BackupRetention{
+				SnapshotRetention: integreatlyv1alpha1.SnapshotRetention{KeepLast: intPtr(1), KeepDays: intPtr(15)},
+			},
+			ExpectedPruned: []string{
+				"PostgresSnapshot/rhsso-postgres-rhmi-preupgrade-snapshot-2021-01-01-000000",
+				"PostgresSnapshot/threescale-postgres-1",
+				"RedisSnapshot/threescale-redis-1",
+			},
+		},
+		{
+			Name: "test retention of a resource type",
+			Retention: &integreatlyv1alpha1.BackupRetention{
+				SnapshotRetention: integreatlyv1alpha1.SnapshotRetention{KeepDays: intPtr(60)},
+				Redis:             &integreatlyv1alpha1.SnapshotRetention{KeepDays: intPtr(7)},
+			},
+			ExpectedPruned: []string{
+				"RedisSnapshot/threescale-redis-1",
+			},
+		},
+		{
+			Name: "test manual and referenced snapshots are kept, and unlabelled pre-upgrade snapshots pruned",
+			Retention: &integreatlyv1alpha1.BackupRetention{
+				SnapshotRetention: integreatlyv1alpha1.SnapshotRetention{KeepDays: intPtr(2)},
+			},
+			ExpectedPruned: []string{
+				"PostgresSnapshot/rhsso-postgres-1",
+				"PostgresSnapshot/rhsso-postgres-rhmi-preupgrade-snapshot-2021-01-01-000000",
+				"PostgresSnapshot/threescale-postgres-2",
+				"PostgresSnapshot/threescale-postgres-1",
+				"PostgresSnapshot/ups-postgres-3",
+				"RedisSnapshot/threescale-redis-2",
+				"RedisSnapshot/threescale-redis-1",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, snapshots...)
+
+			pruned, err := PruneSnapshots(context.TODO(), client, namespace, tc.Retention, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprint(pruned) != fmt.Sprint(tc.ExpectedPruned) && len(pruned)+len(tc.ExpectedPruned) > 0 {
+				t.Fatalf("expected %v to be pruned, got %v", tc.ExpectedPruned, pruned)
+			}
+
+			postgresSnapshots := &v1alpha1.PostgresSnapshotList{}
+			if err := client.List(context.TODO(), postgresSnapshots, k8sclient.InNamespace(namespace)); err != nil {
+				t.Fatal(err)
+			}
+			redisSnapshots := &v1alpha1.RedisSnapshotList{}
+			if err := client.List(context.TODO(), redisSnapshots, k8sclient.InNamespace(namespace)); err != nil {
+				t.Fatal(err)
+			}
+			if remaining := len(postgresSnapshots.Items) + len(redisSnapshots.Items); remaining != totalSnapshots-len(tc.ExpectedPruned) {
+				t.Fatalf("expected %d snapshots to remain, got %d", totalSnapshots-len(tc.ExpectedPruned), remaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package backup
+
+import (
+	"fmt"
+	"time"
+
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultRestoreTimeout is the time each step of a restore is given to
+// complete. Replacing an AWS instance takes longer than creating a snapshot
+const DefaultRestoreTimeout = time.Hour
+
+// RestoreExecutor knows how to restore a component from one of its backups.
+// The restore runs in the background, its progress is checked until it
+// completes
+type RestoreExecutor interface {
+	// ComponentName returns the name of the resource restored
+	ComponentName() string
+	// StartRestore starts restoring the component from the backup named
+	// backupName, without waiting for it. Starting a restore that was
+	// already started does nothing
+	StartRestore(client k8sclient.Client, backupName string) error
+	// CheckRestore returns true once the restore from the backup backupName
+	// completed, or an error if it failed
+	CheckRestore(client k8sclient.Client, backupName string) (bool, error)
+}
+
+// NewRestoreExecutor returns the executor that restores the backups created
+// by the executor
+func NewRestoreExecutor(executor ComponentBackupExecutor) (RestoreExecutor, error) {
+	switch e := executor.(type) {
+	case *AWSBackupExecutor:
+		return NewAWSRestoreExecutor(e.SnapshotNamespace, e.ResourceName, e.SnapshotType), nil
+	case *ClusterStorageBackupExecutor:
+		return NewClusterStorageRestoreExecutor(e.Namespace, e.ResourceName, e.DumpType, e.Destination), nil
+	case *CronJobBackupExecutor:
+		return NewCronJobRestoreExecutor(e.CronJobName, e.Namespace), nil
+	default:
+		return nil, fmt.Errorf("restore of %s backups is not supported", executor.BackupType())
+	}
+}
+
+// restoreJobName returns the name of the Job restoring the backup backupName
+func restoreJobName(backupName string) string {
+	return jobName(backupName + "-restore")
+}
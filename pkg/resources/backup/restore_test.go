@@ -0,0 +1,215 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// unsupportedBackupExecutor is a backup without a restore
+type unsupportedBackupExecutor struct {
+	ComponentBackupExecutor
+}
+
+func (e unsupportedBackupExecutor) BackupType() string {
+	return "Unsupported"
+}
+
+func TestNewRestoreExecutor(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Executor      ComponentBackupExecutor
+		ExpectedType  RestoreExecutor
+		ExpectedError bool
+	}{
+		{
+			Name:         "test snapshots are restored on AWS",
+			Executor:     &AWSBackupExecutor{SnapshotNamespace: "ns", ResourceName: "threescale-redis-rhmi", SnapshotType: RedisSnapshotType},
+			ExpectedType: &AWSRestoreExecutor{},
+		},
+		{
+			Name:         "test dumps are restored by a Job",
+			Executor:     &ClusterStorageBackupExecutor{Namespace: "ns", ResourceName: "threescale-postgres-rhmi", DumpType: PostgresDumpType},
+			ExpectedType: &ClusterStorageRestoreExecutor{},
+		},
+		{
+			Name:         "test CronJob backups are restored by the restore CronJob",
+			Executor:     &CronJobBackupExecutor{CronJobName: "enmasse-pv-backup", Namespace: "ns"},
+			ExpectedType: &CronJobRestoreExecutor{},
+		},
+		{
+			Name:          "test other backups are not supported",
+			Executor:      unsupportedBackupExecutor{},
+			ExpectedError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			executor, err := NewRestoreExecutor(tc.Executor)
+			if tc.ExpectedError {
+				if err == nil {
+					t.Fatal("expected the restore to be unsupported")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprintf("%T", executor) != fmt.Sprintf("%T", tc.ExpectedType) {
+				t.Fatalf("expected a %T, got %T", tc.ExpectedType, executor)
+			}
+			if executor.ComponentName() == "" {
+				t.Fatal("expected the restore to have a component name")
+			}
+		})
+	}
+}
+
+func TestClusterStorageRestoreExecutor(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name              string
+		DumpType          ClusterStorageBackupType
+		Destination       ClusterStorageDestination
+		ExpectedCommand   string
+		ExpectedInit      []string
+		ExpectedClaimName string
+	}{
+		{
+			Name:              "test postgres dump is restored from the claim",
+			DumpType:          PostgresDumpType,
+			ExpectedCommand:   "pg_restore",
+			ExpectedClaimName: "threescale-backend-redis-backups",
+		},
+		{
+			Name:            "test redis dump is downloaded from the bucket and replicated",
+			DumpType:        RedisDumpType,
+			Destination:     ClusterStorageDestination{S3SecretName: "backups-s3"},
+			ExpectedCommand: "slaveof",
+			ExpectedInit:    []string{"download"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme)
+			executor := NewClusterStorageRestoreExecutor("redhat-rhmi-operator", "threescale-backend-redis", tc.DumpType, tc.Destination)
+
+			backupName := "threescale-backend-redis-test-backup"
+			for i := 0; i < 2; i++ {
+				if err := executor.StartRestore(client, backupName); err != nil {
+					t.Fatalf("unexpected error starting the restore: %v", err)
+				}
+			}
+
+			job := &batchv1.Job{}
+			if err := client.Get(context.TODO(), types.NamespacedName{Name: restoreJobName(backupName), Namespace: "redhat-rhmi-operator"}, job); err != nil {
+				t.Fatalf("expected the restore Job to be created: %v", err)
+			}
+			if _, ok := job.Labels[backupComponentKey]; ok {
+				t.Fatal("expected the restore Job not to be counted in the retention of the backup Jobs")
+			}
+
+			podSpec := job.Spec.Template.Spec
+			if !strings.Contains(podSpec.Containers[0].Command[2], tc.ExpectedCommand) {
+				t.Fatalf("expected the restore to run %s, got %s", tc.ExpectedCommand, podSpec.Containers[0].Command[2])
+			}
+			initContainers := []string{}
+			for _, container := range podSpec.InitContainers {
+				initContainers = append(initContainers, container.Name)
+			}
+			if fmt.Sprint(initContainers) != fmt.Sprint(tc.ExpectedInit) {
+				t.Fatalf("expected init containers %v, got %v", tc.ExpectedInit, initContainers)
+			}
+			for _, volume := range podSpec.Volumes {
+				if volume.Name == backupVolumeName && volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName != tc.ExpectedClaimName {
+					t.Fatalf("expected the dump to be read from claim %s, got %s", tc.ExpectedClaimName, volume.PersistentVolumeClaim.ClaimName)
+				}
+			}
+
+			if done, err := executor.CheckRestore(client, backupName); done || err != nil {
+				t.Fatalf("expected the restore to be in progress, got %t, error %v", done, err)
+			}
+			now := metav1.Now()
+			job.Status.CompletionTime = &now
+			if err := client.Status().Update(context.TODO(), job); err != nil {
+				t.Fatal(err)
+			}
+			if done, err := executor.CheckRestore(client, backupName); !done || err != nil {
+				t.Fatalf("expected the restore to be completed, got %t, error %v", done, err)
+			}
+		})
+	}
+}
+
+func TestCronJobRestoreExecutor(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := batchv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := batchv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	restoreCronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "enmasse-pv-backup-restore", Namespace: "redhat-rhmi-amq-online"},
+		Spec: batchv1beta1.CronJobSpec{
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "restore", Image: backupImage}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("test restore without a restore CronJob", func(t *testing.T) {
+		client := fake.NewFakeClientWithScheme(scheme)
+		executor := NewCronJobRestoreExecutor("enmasse-pv-backup", "redhat-rhmi-amq-online")
+		if err := executor.StartRestore(client, "enmasse-pv-backup-test-backup"); err == nil || !strings.Contains(err.Error(), "must be restored manually") {
+			t.Fatalf("expected the restore to need the restore CronJob, got %v", err)
+		}
+	})
+
+	t.Run("test restore Job is created from the restore CronJob", func(t *testing.T) {
+		client := fake.NewFakeClientWithScheme(scheme, restoreCronJob)
+		executor := NewCronJobRestoreExecutor("enmasse-pv-backup", "redhat-rhmi-amq-online")
+		if err := executor.StartRestore(client, "enmasse-pv-backup-test-backup"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		job := &batchv1.Job{}
+		if err := client.Get(context.TODO(), types.NamespacedName{Name: restoreJobName("enmasse-pv-backup-test-backup"), Namespace: "redhat-rhmi-amq-online"}, job); err != nil {
+			t.Fatalf("expected the restore Job to be created: %v", err)
+		}
+		env := job.Spec.Template.Spec.Containers[0].Env
+		if len(env) != 1 || env[0].Name != "BACKUP_JOB" || env[0].Value != "enmasse-pv-backup-test-backup" {
+			t.Fatalf("expected the Job to be told the backup to restore, got %v", env)
+		}
+
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}}
+		if err := client.Status().Update(context.TODO(), job); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := executor.CheckRestore(client, "enmasse-pv-backup-test-backup"); err == nil {
+			t.Fatal("expected the failed restore Job to fail the restore")
+		}
+	})
+}
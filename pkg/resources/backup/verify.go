@@ -0,0 +1,341 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1"
+	crotypes "github.com/integr8ly/cloud-resource-operator/apis/integreatly/v1alpha1/types"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// verificationLabel labels the resources created to verify a backup,
+	// with the name of the resource backed up
+	verificationLabel = "integreatly.org/backup-verification"
+	// verificationPrefix prefixes the names of the throwaway instances, and
+	// of their tiers in the strategies of the cloud resource operator
+	verificationPrefix = "rhmi-verify-"
+)
+
+// BackupVerifier verifies a backup by restoring it into a throwaway instance
+// of the cloud resource operator and running a sanity check against it. The
+// verification runs in the background: it's started once, then checked
+// until it completes, and the instance is removed afterwards
+type BackupVerifier interface {
+	// StartVerification creates the throwaway instance restored from the
+	// backup backupName, unless it already exists
+	StartVerification(client k8sclient.Client, backupName string) error
+	// CheckVerification runs the check against the throwaway instance once
+	// it's restored, and returns true with the result of the check once it
+	// completes. It fails if the result is lower than the minimum of the
+	// check
+	CheckVerification(client k8sclient.Client, backupName string, check integreatlyv1alpha1.BackupSanityCheck) (bool, int64, error)
+	// CleanupVerification removes the throwaway instance and the Jobs run
+	// against it
+	CleanupVerification(client k8sclient.Client, backupName string) error
+}
+
+// NewBackupVerifier returns the verifier of the backups of the executor
+func NewBackupVerifier(executor ComponentBackupExecutor) (BackupVerifier, error) {
+	switch e := executor.(type) {
+	case *AWSBackupExecutor:
+		return NewAWSBackupVerifier(e.SnapshotNamespace, e.ResourceName, e.SnapshotType), nil
+	case *ClusterStorageBackupExecutor:
+		return NewClusterStorageBackupVerifier(e.Namespace, e.ResourceName, e.DumpType, e.Destination), nil
+	default:
+		return nil, fmt.Errorf("verification of %s backups is not supported", executor.BackupType())
+	}
+}
+
+// DefaultSanityCheck returns the check run against the instances restored
+// from backups of the given type, for the components without a check of
+// their own. Postgres is expected to have tables. Redis may be empty, so its
+// check has no minimum and the backups it restores are reported unverified,
+// the products with a redis that can't be empty have checks of their own
+func DefaultSanityCheck(backupType string) integreatlyv1alpha1.BackupSanityCheck {
+	if backupType == string(RedisSnapshotType) || backupType == string(RedisDumpType) {
+		return integreatlyv1alpha1.BackupSanityCheck{Query: "DBSIZE"}
+	}
+	return integreatlyv1alpha1.BackupSanityCheck{
+		Query: "SELECT count(*) FROM information_schema.tables WHERE table_schema = 'public'",
+		Min:   1,
+	}
+}
+
+// verificationName returns the name of the throwaway instance restored from
+// the backup. It's short enough for an ElastiCache replication group
+func verificationName(backupName string) string {
+	return fmt.Sprintf("%s%x", verificationPrefix, sha256.Sum256([]byte(backupName)))[:len(verificationPrefix)+jobNameHashLength]
+}
+
+// throwawayResource returns the Postgres or Redis CR of the throwaway
+// instance restored from a backup of the source CR
+func throwawayResource(source cloudResource, name string) (cloudResource, error) {
+	var cr cloudResource
+	switch source.(type) {
+	case *v1alpha1.Postgres:
+		cr = &v1alpha1.Postgres{}
+	case *v1alpha1.Redis:
+		cr = &v1alpha1.Redis{}
+	default:
+		return nil, fmt.Errorf("unsupported cloud resource %T", source)
+	}
+	cr.SetName(name)
+	cr.SetNamespace(source.GetNamespace())
+	return cr, nil
+}
+
+// createThrowawayResource creates the Postgres or Redis CR of the throwaway
+// instance, with the deployment type of the source CR and the given tier,
+// unless it already exists
+func createThrowawayResource(client k8sclient.Client, source, cr cloudResource, tier string) error {
+	sourceSpec, _ := resourceSpec(source)
+	spec, _ := resourceSpec(cr)
+	*spec = crotypes.ResourceTypeSpec{
+		Type:      sourceSpec.Type,
+		Tier:      tier,
+		SecretRef: &crotypes.SecretRef{Name: cr.GetName(), Namespace: cr.GetNamespace()},
+	}
+	cr.SetLabels(map[string]string{
+		"productName":     source.GetLabels()["productName"],
+		verificationLabel: source.GetName(),
+	})
+
+	if err := client.Create(context.TODO(), cr); err != nil && !k8serr.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the throwaway instance %s to verify the backup of %s: %w", cr.GetName(), source.GetName(), err)
+	}
+	return nil
+}
+
+// restoredInstance returns the connection details of the throwaway instance
+// of the Postgres or Redis CR, or nil until the cloud resource operator
+// completes it
+func restoredInstance(client k8sclient.Client, cr cloudResource) (*sanityCheckTarget, error) {
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: cr.GetName(), Namespace: cr.GetNamespace()}, cr); err != nil {
+		return nil, fmt.Errorf("failed to get the throwaway instance %s: %w", cr.GetName(), err)
+	}
+	_, status := resourceSpec(cr)
+	if status.Phase != crotypes.PhaseComplete || status.SecretRef == nil {
+		return nil, nil
+	}
+
+	details, err := connectionSecret(client, status.SecretRef)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cr.(*v1alpha1.Redis); ok {
+		return &sanityCheckTarget{
+			resourceType: providers.RedisResourceType,
+			host:         details["uri"],
+			port:         details["port"],
+		}, nil
+	}
+	return &sanityCheckTarget{
+		resourceType: providers.PostgresResourceType,
+		host:         details["host"],
+		port:         details["port"],
+		credentials: map[string]string{
+			"user":     details["username"],
+			"password": details["password"],
+			"database": details["database"],
+		},
+	}, nil
+}
+
+// resourceSpec returns the spec and status of a Postgres or Redis CR
+func resourceSpec(cr cloudResource) (*crotypes.ResourceTypeSpec, *crotypes.ResourceTypeStatus) {
+	switch resource := cr.(type) {
+	case *v1alpha1.Postgres:
+		return &resource.Spec, &resource.Status
+	case *v1alpha1.Redis:
+		return &resource.Spec, &resource.Status
+	default:
+		return &crotypes.ResourceTypeSpec{}, &crotypes.ResourceTypeStatus{}
+	}
+}
+
+// connectionSecret returns the connection details of a Postgres or Redis CR
+func connectionSecret(client k8sclient.Client, secretRef *crotypes.SecretRef) (map[string]string, error) {
+	if secretRef == nil {
+		return nil, fmt.Errorf("no connection secret found")
+	}
+	secret := &corev1.Secret{}
+	if err := client.Get(context.TODO(), types.NamespacedName{Name: secretRef.Name, Namespace: secretRef.Namespace}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get the connection secret %s: %w", secretRef.Name, err)
+	}
+
+	details := map[string]string{}
+	for key, value := range secret.Data {
+		details[key] = string(value)
+	}
+	return details, nil
+}
+
+// sanityCheckTarget is the instance the sanity check connects to
+type sanityCheckTarget struct {
+	resourceType providers.ResourceType
+	host         string
+	port         string
+	// credentials of the postgres instance: user, password and database
+	credentials map[string]string
+}
+
+// runSanityCheck creates the Job running the check against the instance,
+// unless it already exists, and returns true with the result of the check
+// once the Job completes. The Job writes the result to its termination
+// message
+func runSanityCheck(client k8sclient.Client, namespace, name string, target sanityCheckTarget, check integreatlyv1alpha1.BackupSanityCheck) (bool, int64, error) {
+	if err := startSanityCheck(client, namespace, name, target, check); err != nil {
+		return false, 0, err
+	}
+
+	done, jobErr := checkJob(client, name, namespace)
+	if jobErr != nil {
+		output, _ := terminationMessage(client, namespace, name)
+		return false, 0, fmt.Errorf("sanity check failed: %v: %s", jobErr, output)
+	}
+	if !done {
+		return false, 0, nil
+	}
+
+	output, err := terminationMessage(client, namespace, name)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// redis-cli prints "(integer) n" when attached to a terminal
+	output = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(output), "(integer)"))
+	result, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return true, 0, fmt.Errorf("sanity check returned %q, expected a number", output)
+	}
+	if result < check.Min {
+		return true, result, fmt.Errorf("sanity check returned %d, expected at least %d", result, check.Min)
+	}
+	return true, result, nil
+}
+
+// startSanityCheck creates the Job running the check against the instance,
+// and the secret with the credentials of the instance, unless they exist
+func startSanityCheck(client k8sclient.Client, namespace, name string, target sanityCheckTarget, check integreatlyv1alpha1.BackupSanityCheck) error {
+	ctx := context.TODO()
+	labels := map[string]string{"integreatly": "yes", verificationLabel: name}
+
+	env := []corev1.EnvVar{
+		{Name: "CHECK_QUERY", Value: check.Query},
+	}
+	container := corev1.Container{
+		Name:                     "sanity-check",
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	switch target.resourceType {
+	case providers.PostgresResourceType:
+		// The credentials are copied to a secret of the Job, as they may
+		// not be the ones of the connection secret of the instance
+		secretName := sanityCheckSecretName(name)
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace, Labels: labels},
+			StringData: target.credentials,
+		}
+		if err := client.Create(ctx, secret); err != nil && !k8serr.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create the credentials of the sanity check: %w", err)
+		}
+
+		container.Image = postgresImage
+		container.Command = []string{"/bin/sh", "-c", `psql -v ON_ERROR_STOP=1 -tA -c "$CHECK_QUERY" > /dev/termination-log`}
+		container.Env = append(env,
+			corev1.EnvVar{Name: "PGHOST", Value: target.host},
+			corev1.EnvVar{Name: "PGPORT", Value: target.port},
+			secretEnvVar("PGUSER", secretName, "user", false),
+			secretEnvVar("PGPASSWORD", secretName, "password", false),
+			secretEnvVar("PGDATABASE", secretName, "database", false),
+		)
+	case providers.RedisResourceType:
+		container.Image = redisImage
+		container.Command = []string{"/bin/sh", "-c", `redis-cli -h "$REDIS_HOST" -p "$REDIS_PORT" $CHECK_QUERY > /dev/termination-log`}
+		container.Env = append(env,
+			corev1.EnvVar{Name: "REDIS_HOST", Value: target.host},
+			corev1.EnvVar{Name: "REDIS_PORT", Value: target.port},
+		)
+	default:
+		return fmt.Errorf("Unsupported instance type for the sanity check. Expected %s or %s, got %s",
+			providers.PostgresResourceType, providers.RedisResourceType, target.resourceType)
+	}
+
+	backoffLimit := int32(0)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers:    []corev1.Container{container},
+				},
+			},
+		},
+	}
+	if err := client.Create(ctx, job); err != nil && !k8serr.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create the sanity check Job %s: %w", name, err)
+	}
+	return nil
+}
+
+// cleanupSanityCheck removes the Job running the check and its credentials
+func cleanupSanityCheck(client k8sclient.Client, namespace, name string) error {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	if err := client.Delete(context.TODO(), job, k8sclient.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to remove the sanity check Job %s: %w", name, err)
+	}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: sanityCheckSecretName(name), Namespace: namespace}}
+	if err := client.Delete(context.TODO(), secret); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to remove the credentials of the sanity check %s: %w", name, err)
+	}
+	return nil
+}
+
+// sanityCheckSecretName returns the name of the secret with the credentials
+// of the sanity check, the connection secret of the throwaway instance has
+// the name of the check
+func sanityCheckSecretName(name string) string {
+	return name + "-check"
+}
+
+// deleteThrowawayResource removes the Postgres or Redis CR of the throwaway
+// instance, the cloud resource operator then removes the instance
+func deleteThrowawayResource(client k8sclient.Client, cr cloudResource) error {
+	if err := client.Delete(context.TODO(), cr); err != nil && !k8serr.IsNotFound(err) {
+		return fmt.Errorf("failed to remove the throwaway instance %s: %w", cr.GetName(), err)
+	}
+	return nil
+}
+
+// terminationMessage returns the termination message of the pod of the Job
+func terminationMessage(client k8sclient.Client, namespace, jobName string) (string, error) {
+	pods := &corev1.PodList{}
+	if err := client.List(context.TODO(), pods, k8sclient.InNamespace(namespace), k8sclient.MatchingLabels{"job-name": jobName}); err != nil {
+		return "", fmt.Errorf("failed to list the pods of Job %s: %w", jobName, err)
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				return status.State.Terminated.Message, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no result found for Job %s", jobName)
+}
@@ -0,0 +1,103 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	l "github.com/integr8ly/integreatly-operator/pkg/resources/logger"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Workload is a resource that sets the number of replicas of the pods using
+// the data of a product. It's scaled down while the data is restored.
+//
+// The resource scaled is the one the product operator reads the replicas
+// from, such as the APIManager for 3scale, as the operator would revert a
+// change to the deployments it manages
+type Workload struct {
+	GroupVersionKind schema.GroupVersionKind
+	Name             string
+	Namespace        string
+	// ReplicasPath is the path of the replicas in the resource, e.g.
+	// spec.replicas
+	ReplicasPath string
+	// PodSelector selects the pods of the workload. When set, the scale
+	// down waits until there are no pods left
+	PodSelector map[string]string
+}
+
+// NewDeploymentWorkload returns the workload of a Deployment
+func NewDeploymentWorkload(name, namespace string, podSelector map[string]string) Workload {
+	return Workload{
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Name:             name,
+		Namespace:        namespace,
+		ReplicasPath:     "spec.replicas",
+		PodSelector:      podSelector,
+	}
+}
+
+func (w Workload) path() []string {
+	return strings.Split(w.ReplicasPath, ".")
+}
+
+func (w Workload) get(client k8sclient.Client) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(w.GroupVersionKind)
+	if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: w.Name, Namespace: w.Namespace}, obj); err != nil {
+		return nil, fmt.Errorf("failed to get %s %s in namespace %s: %w", w.GroupVersionKind.Kind, w.Name, w.Namespace, err)
+	}
+	return obj, nil
+}
+
+// Replicas returns the current replicas of the workload
+func (w Workload) Replicas(client k8sclient.Client) (int64, error) {
+	obj, err := w.get(client)
+	if err != nil {
+		return 0, err
+	}
+	replicas, found, err := unstructured.NestedInt64(obj.Object, w.path()...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s of %s %s: %w", w.ReplicasPath, w.GroupVersionKind.Kind, w.Name, err)
+	}
+	if !found {
+		// Kubernetes defaults the replicas to 1
+		return 1, nil
+	}
+	return replicas, nil
+}
+
+// Scale sets the replicas of the workload
+func (w Workload) Scale(client k8sclient.Client, replicas int64) error {
+	log.Infof("Scaling workload", l.Fields{"kind": w.GroupVersionKind.Kind, "name": w.Name, "ns": w.Namespace, "replicas": replicas})
+
+	obj, err := w.get(client)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(obj.Object, replicas, w.path()...); err != nil {
+		return fmt.Errorf("failed to set %s of %s %s: %w", w.ReplicasPath, w.GroupVersionKind.Kind, w.Name, err)
+	}
+	if err := client.Update(context.TODO(), obj); err != nil {
+		return fmt.Errorf("failed to scale %s %s in namespace %s: %w", w.GroupVersionKind.Kind, w.Name, w.Namespace, err)
+	}
+	return nil
+}
+
+// ScaledDown returns true once the pods of the workload are gone
+func (w Workload) ScaledDown(client k8sclient.Client) (bool, error) {
+	if len(w.PodSelector) == 0 {
+		return true, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := client.List(context.TODO(), pods, k8sclient.InNamespace(w.Namespace), k8sclient.MatchingLabels(w.PodSelector)); err != nil {
+		return false, fmt.Errorf("failed to list the pods of %s %s: %w", w.GroupVersionKind.Kind, w.Name, err)
+	}
+	return len(pods.Items) == 0, nil
+}
@@ -0,0 +1,93 @@
+package backup
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWorkloadScale(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := appsv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	apiManager := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps.3scale.net/v1alpha1",
+		"kind":       "APIManager",
+		"metadata":   map[string]interface{}{"name": "3scale", "namespace": "3scale"},
+		"spec": map[string]interface{}{
+			"system": map[string]interface{}{"appSpec": map[string]interface{}{"replicas": int64(2)}},
+		},
+	}}
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "ratelimit", Namespace: "marin3r"}}
+
+	cases := []struct {
+		Name             string
+		Workload         Workload
+		ExpectedReplicas int64
+	}{
+		{
+			Name: "test replicas of a custom resource",
+			Workload: Workload{
+				GroupVersionKind: schema.GroupVersionKind{Group: "apps.3scale.net", Version: "v1alpha1", Kind: "APIManager"},
+				Name:             "3scale",
+				Namespace:        "3scale",
+				ReplicasPath:     "spec.system.appSpec.replicas",
+			},
+			ExpectedReplicas: 2,
+		},
+		{
+			Name:             "test replicas default to 1 when unset",
+			Workload:         NewDeploymentWorkload("ratelimit", "marin3r", map[string]string{"app": "ratelimit"}),
+			ExpectedReplicas: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, apiManager.DeepCopy(), deployment.DeepCopy())
+
+			replicas, err := tc.Workload.Replicas(client)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if replicas != tc.ExpectedReplicas {
+				t.Fatalf("expected %d replicas, got %d", tc.ExpectedReplicas, replicas)
+			}
+
+			if err := tc.Workload.Scale(client, 0); err != nil {
+				t.Fatalf("unexpected error scaling down: %v", err)
+			}
+			if replicas, err := tc.Workload.Replicas(client); err != nil || replicas != 0 {
+				t.Fatalf("expected the workload to be scaled down, got %d replicas, error %v", replicas, err)
+			}
+			if scaledDown, err := tc.Workload.ScaledDown(client); err != nil || !scaledDown {
+				t.Fatalf("expected the pods of the workload to be gone, got %t, error %v", scaledDown, err)
+			}
+		})
+	}
+}
+
+func TestWorkloadScaledDown(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "ratelimit-1", Namespace: "marin3r", Labels: map[string]string{"app": "ratelimit"}}}
+	client := fake.NewFakeClientWithScheme(scheme, pod)
+
+	workload := NewDeploymentWorkload("ratelimit", "marin3r", map[string]string{"app": "ratelimit"})
+	if scaledDown, err := workload.ScaledDown(client); err != nil || scaledDown {
+		t.Fatalf("expected the workload to be scaling down while its pod runs, got %t, error %v", scaledDown, err)
+	}
+}
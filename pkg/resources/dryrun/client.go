@@ -0,0 +1,212 @@
+package dryrun
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// ignoredMetadataFields are removed from the objects before calculating the
+// diff of an update, as they change on every write and add noise to the plan
+var ignoredMetadataFields = []string{
+	"resourceVersion",
+	"generation",
+	"managedFields",
+	"creationTimestamp",
+	"uid",
+	"selfLink",
+}
+
+// Client wraps a k8sclient.Client so that every write is sent to the API
+// server in dry run mode and recorded in a Recorder instead of being applied.
+// Reads are delegated to the wrapped client
+type Client struct {
+	client   k8sclient.Client
+	dryRun   k8sclient.Client
+	scheme   *runtime.Scheme
+	recorder Recorder
+}
+
+var _ k8sclient.Client = &Client{}
+
+func NewClient(client k8sclient.Client, scheme *runtime.Scheme, recorder Recorder) *Client {
+	return &Client{
+		client:   client,
+		dryRun:   k8sclient.NewDryRunClient(client),
+		scheme:   scheme,
+		recorder: recorder,
+	}
+}
+
+func (c *Client) Get(ctx context.Context, key k8sclient.ObjectKey, obj runtime.Object) error {
+	return c.client.Get(ctx, key, obj)
+}
+
+func (c *Client) List(ctx context.Context, list runtime.Object, opts ...k8sclient.ListOption) error {
+	return c.client.List(ctx, list, opts...)
+}
+
+func (c *Client) Create(ctx context.Context, obj runtime.Object, opts ...k8sclient.CreateOption) error {
+	err := c.dryRun.Create(ctx, obj, opts...)
+	c.record(ActionCreate, "", obj, nil, err)
+	return err
+}
+
+func (c *Client) Update(ctx context.Context, obj runtime.Object, opts ...k8sclient.UpdateOption) error {
+	live := c.getLive(ctx, obj)
+	err := c.dryRun.Update(ctx, obj, opts...)
+	c.record(ActionUpdate, "", obj, live, err)
+	return err
+}
+
+func (c *Client) Patch(ctx context.Context, obj runtime.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	live := c.getLive(ctx, obj)
+	err := c.dryRun.Patch(ctx, obj, patch, opts...)
+	c.record(ActionPatch, "", obj, live, err)
+	return err
+}
+
+func (c *Client) Delete(ctx context.Context, obj runtime.Object, opts ...k8sclient.DeleteOption) error {
+	err := c.dryRun.Delete(ctx, obj, opts...)
+	c.record(ActionDelete, "", obj, nil, err)
+	return err
+}
+
+func (c *Client) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...k8sclient.DeleteAllOfOption) error {
+	err := c.dryRun.DeleteAllOf(ctx, obj, opts...)
+
+	deleteOpts := &k8sclient.DeleteAllOfOptions{}
+	deleteOpts.ApplyOptions(opts)
+	change := c.newChange(ActionDeleteAllOf, "", obj, err)
+	change.Namespace = deleteOpts.Namespace
+	change.Name = ""
+	c.recorder.Record(change)
+
+	return err
+}
+
+func (c *Client) Status() k8sclient.StatusWriter {
+	return &statusWriter{client: c}
+}
+
+type statusWriter struct {
+	client *Client
+}
+
+func (sw *statusWriter) Update(ctx context.Context, obj runtime.Object, opts ...k8sclient.UpdateOption) error {
+	live := sw.client.getLive(ctx, obj)
+	err := sw.client.dryRun.Status().Update(ctx, obj, opts...)
+	sw.client.record(ActionUpdate, "status", obj, live, err)
+	return err
+}
+
+func (sw *statusWriter) Patch(ctx context.Context, obj runtime.Object, patch k8sclient.Patch, opts ...k8sclient.PatchOption) error {
+	live := sw.client.getLive(ctx, obj)
+	err := sw.client.dryRun.Status().Patch(ctx, obj, patch, opts...)
+	sw.client.record(ActionPatch, "status", obj, live, err)
+	return err
+}
+
+// RecordExec adds to the plan a command the reconcilers would have run in the
+// pod. Commands can't be run in dry run mode, so the callers must skip them
+func (c *Client) RecordExec(namespace, pod, command string) {
+	c.recorder.Record(ObjectChange{
+		Action:     ActionExec,
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Namespace:  namespace,
+		Name:       pod,
+		Message:    command,
+	})
+}
+
+// getLive retrieves the current state of the object from the cluster, or nil
+// if it can't be retrieved
+func (c *Client) getLive(ctx context.Context, obj runtime.Object) runtime.Object {
+	key, err := k8sclient.ObjectKeyFromObject(obj)
+	if err != nil {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil
+	}
+
+	var live runtime.Object
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		live = u
+	} else if live, err = c.scheme.New(gvk); err != nil {
+		return nil
+	}
+
+	if err := c.client.Get(ctx, key, live); err != nil {
+		return nil
+	}
+	return live
+}
+
+// record adds the change to the recorder. Updates and patches that don't
+// modify the live object are not recorded
+func (c *Client) record(action Action, subresource string, obj, live runtime.Object, err error) {
+	change := c.newChange(action, subresource, obj, err)
+
+	if live != nil {
+		change.Diff = diff(live, obj)
+		if change.Diff == "" && err == nil {
+			return
+		}
+	}
+
+	c.recorder.Record(change)
+}
+
+func (c *Client) newChange(action Action, subresource string, obj runtime.Object, err error) ObjectChange {
+	change := ObjectChange{
+		Action:      action,
+		Subresource: subresource,
+	}
+
+	if gvk, gvkErr := apiutil.GVKForObject(obj, c.scheme); gvkErr == nil {
+		change.APIVersion, change.Kind = gvk.ToAPIVersionAndKind()
+	}
+	if accessor, accessorErr := meta.Accessor(obj); accessorErr == nil {
+		change.Namespace = accessor.GetNamespace()
+		change.Name = accessor.GetName()
+	}
+	if err != nil {
+		change.Error = err.Error()
+	}
+
+	return change
+}
+
+func diff(live, desired runtime.Object) string {
+	liveMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+	if err != nil {
+		return ""
+	}
+	desiredMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(desired)
+	if err != nil {
+		return ""
+	}
+
+	for _, obj := range []map[string]interface{}{liveMap, desiredMap} {
+		// typed clients don't always populate the TypeMeta
+		delete(obj, "apiVersion")
+		delete(obj, "kind")
+		if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+			for _, field := range ignoredMetadataFields {
+				delete(metadata, field)
+			}
+		}
+	}
+
+	return cmp.Diff(liveMap, desiredMap)
+}
@@ -0,0 +1,172 @@
+package dryrun
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const testNamespace = "redhat-rhoam-3scale"
+
+var (
+	testStage   = integreatlyv1alpha1.ProductsStage
+	testProduct = integreatlyv1alpha1.Product3Scale
+)
+
+func getBuildScheme() (*runtime.Scheme, error) {
+	scheme := runtime.NewScheme()
+	if err := corev1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return scheme, nil
+}
+
+func TestDryRunClient(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existingConfigMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "existing",
+			Namespace: testNamespace,
+		},
+		Data: map[string]string{
+			"key": "old-value",
+		},
+	}
+
+	cases := []struct {
+		Name     string
+		Write    func(client k8sclient.Client) error
+		Validate func(t *testing.T, serverClient k8sclient.Client, plan *Plan)
+	}{
+		{
+			Name: "create is recorded and not applied",
+			Write: func(client k8sclient.Client) error {
+				return client.Create(context.TODO(), &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "new",
+						Namespace: testNamespace,
+					},
+				})
+			},
+			Validate: func(t *testing.T, serverClient k8sclient.Client, plan *Plan) {
+				changes := plan.Stages[0].Products[testProduct].Changes
+				if len(changes) != 1 {
+					t.Fatalf("expected 1 change, got %d", len(changes))
+				}
+				if changes[0].Action != ActionCreate || changes[0].Kind != "ConfigMap" || changes[0].Name != "new" {
+					t.Fatalf("unexpected change recorded: %+v", changes[0])
+				}
+				err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: "new", Namespace: testNamespace}, &corev1.ConfigMap{})
+				if !k8serr.IsNotFound(err) {
+					t.Fatalf("expected configmap not to be created, got error: %v", err)
+				}
+			},
+		},
+		{
+			Name: "update is recorded with diff and not applied",
+			Write: func(client k8sclient.Client) error {
+				cm := &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "existing",
+						Namespace: testNamespace,
+					},
+				}
+				_, err := controllerutil.CreateOrUpdate(context.TODO(), client, cm, func() error {
+					cm.Data["key"] = "new-value"
+					return nil
+				})
+				return err
+			},
+			Validate: func(t *testing.T, serverClient k8sclient.Client, plan *Plan) {
+				changes := plan.Stages[0].Products[testProduct].Changes
+				if len(changes) != 1 {
+					t.Fatalf("expected 1 change, got %d", len(changes))
+				}
+				if changes[0].Action != ActionUpdate {
+					t.Fatalf("expected update action, got %s", changes[0].Action)
+				}
+				if !strings.Contains(changes[0].Diff, "new-value") || !strings.Contains(changes[0].Diff, "old-value") {
+					t.Fatalf("expected diff to contain old and new values, got: %s", changes[0].Diff)
+				}
+				cm := &corev1.ConfigMap{}
+				if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: "existing", Namespace: testNamespace}, cm); err != nil {
+					t.Fatal(err)
+				}
+				if cm.Data["key"] != "old-value" {
+					t.Fatalf("expected configmap not to be updated, found value %s", cm.Data["key"])
+				}
+			},
+		},
+		{
+			Name: "update without changes is not recorded",
+			Write: func(client k8sclient.Client) error {
+				cm := &corev1.ConfigMap{}
+				if err := client.Get(context.TODO(), k8sclient.ObjectKey{Name: "existing", Namespace: testNamespace}, cm); err != nil {
+					return err
+				}
+				return client.Update(context.TODO(), cm)
+			},
+			Validate: func(t *testing.T, serverClient k8sclient.Client, plan *Plan) {
+				if plan.Changes() != 0 {
+					t.Fatalf("expected no changes, got %d", plan.Changes())
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			serverClient := fake.NewFakeClientWithScheme(scheme, existingConfigMap.DeepCopy())
+			plan := NewPlan(&integreatlyv1alpha1.RHMI{}, "")
+			client := NewClient(serverClient, scheme, plan.RecorderFor(testStage, testProduct))
+
+			if err := tc.Write(client); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tc.Validate(t, serverClient, plan)
+		})
+	}
+}
+
+func TestPlanPublish(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverClient := fake.NewFakeClientWithScheme(scheme)
+	plan := NewPlan(&integreatlyv1alpha1.RHMI{Spec: integreatlyv1alpha1.RHMISpec{Type: string(integreatlyv1alpha1.InstallationTypeManagedApi)}}, "1.5.0")
+	plan.RecorderFor(testStage, testProduct).Record(ObjectChange{Action: ActionDelete, Kind: "Secret", Name: "secret"})
+	plan.SetProductResult(testStage, testProduct, integreatlyv1alpha1.PhaseCompleted, nil)
+
+	if err := plan.Publish(context.TODO(), serverClient, testNamespace); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := serverClient.Get(context.TODO(), k8sclient.ObjectKey{Name: PlanConfigMapName, Namespace: testNamespace}, cm); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"toVersion": "1.5.0"`, `"action": "delete"`, `"phase": "completed"`} {
+		if !strings.Contains(cm.Data[PlanConfigMapKey], expected) {
+			t.Fatalf("expected published plan to contain %s, got: %s", expected, cm.Data[PlanConfigMapKey])
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package dryrun
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// EventRecorder is a record.EventRecorder that adds the events to the plan
+// instead of emitting them
+type EventRecorder struct {
+	scheme   *runtime.Scheme
+	recorder Recorder
+}
+
+var _ record.EventRecorder = &EventRecorder{}
+
+func NewEventRecorder(scheme *runtime.Scheme, recorder Recorder) *EventRecorder {
+	return &EventRecorder{
+		scheme:   scheme,
+		recorder: recorder,
+	}
+}
+
+func (e *EventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	change := ObjectChange{
+		Action:  ActionEvent,
+		Message: fmt.Sprintf("%s %s: %s", eventtype, reason, message),
+	}
+	if gvk, err := apiutil.GVKForObject(object, e.scheme); err == nil {
+		change.APIVersion, change.Kind = gvk.ToAPIVersionAndKind()
+	}
+	if key, err := k8sclient.ObjectKeyFromObject(object); err == nil {
+		change.Namespace = key.Namespace
+		change.Name = key.Name
+	}
+
+	e.recorder.Record(change)
+}
+
+func (e *EventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (e *EventRecorder) AnnotatedEventf(object runtime.Object, _ map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	e.Eventf(object, eventtype, reason, messageFmt, args...)
+}
@@ -0,0 +1,210 @@
+package dryrun
+
+import (
+	"fmt"
+
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+)
+
+// KeycloakClientFactory builds Keycloak clients that record their writes in
+// the recorder instead of sending them. The Keycloak API can't validate a
+// change without applying it, so the writes succeed without doing anything
+// and the ids they return are empty. Reads are sent to Keycloak
+type KeycloakClientFactory struct {
+	factory  keycloakCommon.KeycloakClientFactory
+	recorder Recorder
+}
+
+var _ keycloakCommon.KeycloakClientFactory = &KeycloakClientFactory{}
+
+func NewKeycloakClientFactory(factory keycloakCommon.KeycloakClientFactory, recorder Recorder) *KeycloakClientFactory {
+	return &KeycloakClientFactory{
+		factory:  factory,
+		recorder: recorder,
+	}
+}
+
+func (f *KeycloakClientFactory) AuthenticatedClient(kc keycloak.Keycloak) (keycloakCommon.KeycloakInterface, error) {
+	client, err := f.factory.AuthenticatedClient(kc)
+	if err != nil {
+		return nil, err
+	}
+	return &keycloakClient{KeycloakInterface: client, recorder: f.recorder}, nil
+}
+
+// keycloakClient delegates the reads to the wrapped client and overrides
+// every write of keycloakCommon.KeycloakInterface
+type keycloakClient struct {
+	keycloakCommon.KeycloakInterface
+	recorder Recorder
+}
+
+// record adds the write to the plan. The URL is the path of the resource in
+// the admin API of Keycloak
+func (c *keycloakClient) record(action Action, pathFormat string, args ...interface{}) {
+	c.recorder.Record(ObjectChange{
+		Action: action,
+		URL:    "/auth/admin/" + fmt.Sprintf(pathFormat, args...),
+	})
+}
+
+func (c *keycloakClient) CreateRealm(realm *keycloak.KeycloakRealm) (string, error) {
+	c.record(ActionCreate, "realms/%s", realm.Spec.Realm.Realm)
+	return "", nil
+}
+
+func (c *keycloakClient) UpdateRealm(specRealm *keycloak.KeycloakRealm) error {
+	c.record(ActionUpdate, "realms/%s", specRealm.Spec.Realm.Realm)
+	return nil
+}
+
+func (c *keycloakClient) DeleteRealm(realmName string) error {
+	c.record(ActionDelete, "realms/%s", realmName)
+	return nil
+}
+
+func (c *keycloakClient) CreateClient(client *keycloak.KeycloakAPIClient, realmName string) (string, error) {
+	c.record(ActionCreate, "realms/%s/clients/%s", realmName, client.ClientID)
+	return "", nil
+}
+
+func (c *keycloakClient) UpdateClient(specClient *keycloak.KeycloakAPIClient, realmName string) error {
+	c.record(ActionUpdate, "realms/%s/clients/%s", realmName, specClient.ClientID)
+	return nil
+}
+
+func (c *keycloakClient) DeleteClient(clientID, realmName string) error {
+	c.record(ActionDelete, "realms/%s/clients/%s", realmName, clientID)
+	return nil
+}
+
+func (c *keycloakClient) CreateUser(user *keycloak.KeycloakAPIUser, realmName string) (string, error) {
+	c.record(ActionCreate, "realms/%s/users/%s", realmName, user.UserName)
+	return "", nil
+}
+
+func (c *keycloakClient) CreateFederatedIdentity(fid keycloak.FederatedIdentity, userID string, realmName string) (string, error) {
+	c.record(ActionCreate, "realms/%s/users/%s/federated-identity/%s", realmName, userID, fid.IdentityProvider)
+	return "", nil
+}
+
+func (c *keycloakClient) RemoveFederatedIdentity(fid keycloak.FederatedIdentity, userID string, realmName string) error {
+	c.record(ActionDelete, "realms/%s/users/%s/federated-identity/%s", realmName, userID, fid.IdentityProvider)
+	return nil
+}
+
+func (c *keycloakClient) UpdatePassword(user *keycloak.KeycloakAPIUser, realmName, _ string) error {
+	c.record(ActionUpdate, "realms/%s/users/%s/reset-password", realmName, user.ID)
+	return nil
+}
+
+func (c *keycloakClient) UpdateUser(specUser *keycloak.KeycloakAPIUser, realmName string) error {
+	c.record(ActionUpdate, "realms/%s/users/%s", realmName, specUser.ID)
+	return nil
+}
+
+func (c *keycloakClient) DeleteUser(userID, realmName string) error {
+	c.record(ActionDelete, "realms/%s/users/%s", realmName, userID)
+	return nil
+}
+
+func (c *keycloakClient) AddUserToGroup(realmName, userID, groupID string) error {
+	c.record(ActionUpdate, "realms/%s/users/%s/groups/%s", realmName, userID, groupID)
+	return nil
+}
+
+func (c *keycloakClient) DeleteUserFromGroup(realmName, userID, groupID string) error {
+	c.record(ActionDelete, "realms/%s/users/%s/groups/%s", realmName, userID, groupID)
+	return nil
+}
+
+func (c *keycloakClient) CreateGroup(group string, realmName string) (string, error) {
+	c.record(ActionCreate, "realms/%s/groups/%s", realmName, group)
+	return "", nil
+}
+
+func (c *keycloakClient) MakeGroupDefault(groupID string, realmName string) error {
+	c.record(ActionUpdate, "realms/%s/default-groups/%s", realmName, groupID)
+	return nil
+}
+
+func (c *keycloakClient) SetGroupChild(groupID, realmName string, childGroup *keycloakCommon.Group) error {
+	c.record(ActionCreate, "realms/%s/groups/%s/children/%s", realmName, groupID, childGroup.Name)
+	return nil
+}
+
+func (c *keycloakClient) CreateGroupClientRole(role *keycloak.KeycloakUserRole, realmName, clientID, groupID string) (string, error) {
+	c.record(ActionCreate, "realms/%s/groups/%s/role-mappings/clients/%s/%s", realmName, groupID, clientID, role.Name)
+	return "", nil
+}
+
+func (c *keycloakClient) CreateGroupRealmRole(role *keycloak.KeycloakUserRole, realmName, groupID string) (string, error) {
+	c.record(ActionCreate, "realms/%s/groups/%s/role-mappings/realm/%s", realmName, groupID, role.Name)
+	return "", nil
+}
+
+func (c *keycloakClient) CreateIdentityProvider(identityProvider *keycloak.KeycloakIdentityProvider, realmName string) (string, error) {
+	c.record(ActionCreate, "realms/%s/identity-provider/instances/%s", realmName, identityProvider.Alias)
+	return "", nil
+}
+
+func (c *keycloakClient) UpdateIdentityProvider(specIdentityProvider *keycloak.KeycloakIdentityProvider, realmName string) error {
+	c.record(ActionUpdate, "realms/%s/identity-provider/instances/%s", realmName, specIdentityProvider.Alias)
+	return nil
+}
+
+func (c *keycloakClient) DeleteIdentityProvider(alias, realmName string) error {
+	c.record(ActionDelete, "realms/%s/identity-provider/instances/%s", realmName, alias)
+	return nil
+}
+
+func (c *keycloakClient) CreateUserClientRole(role *keycloak.KeycloakUserRole, realmName, clientID, userID string) (string, error) {
+	c.record(ActionCreate, "realms/%s/users/%s/role-mappings/clients/%s/%s", realmName, userID, clientID, role.Name)
+	return "", nil
+}
+
+func (c *keycloakClient) DeleteUserClientRole(role *keycloak.KeycloakUserRole, realmName, clientID, userID string) error {
+	c.record(ActionDelete, "realms/%s/users/%s/role-mappings/clients/%s/%s", realmName, userID, clientID, role.Name)
+	return nil
+}
+
+func (c *keycloakClient) CreateUserRealmRole(role *keycloak.KeycloakUserRole, realmName, userID string) (string, error) {
+	c.record(ActionCreate, "realms/%s/users/%s/role-mappings/realm/%s", realmName, userID, role.Name)
+	return "", nil
+}
+
+func (c *keycloakClient) DeleteUserRealmRole(role *keycloak.KeycloakUserRole, realmName, userID string) error {
+	c.record(ActionDelete, "realms/%s/users/%s/role-mappings/realm/%s", realmName, userID, role.Name)
+	return nil
+}
+
+func (c *keycloakClient) CreateAuthenticationFlow(authFlow keycloakCommon.AuthenticationFlow, realmName string) (string, error) {
+	c.record(ActionCreate, "realms/%s/authentication/flows/%s", realmName, authFlow.Alias)
+	return "", nil
+}
+
+func (c *keycloakClient) AddExecutionToAuthenticatonFlow(flowAlias, realmName string, providerID string, _ keycloakCommon.Requirement) error {
+	c.record(ActionCreate, "realms/%s/authentication/flows/%s/executions/%s", realmName, flowAlias, providerID)
+	return nil
+}
+
+func (c *keycloakClient) UpdateAuthenticationExecutionForFlow(flowAlias, realmName string, execution *keycloak.AuthenticationExecutionInfo) error {
+	c.record(ActionUpdate, "realms/%s/authentication/flows/%s/executions/%s", realmName, flowAlias, execution.ID)
+	return nil
+}
+
+func (c *keycloakClient) CreateAuthenticatorConfig(authenticatorConfig *keycloak.AuthenticatorConfig, realmName, executionID string) (string, error) {
+	c.record(ActionCreate, "realms/%s/authentication/executions/%s/config/%s", realmName, executionID, authenticatorConfig.Alias)
+	return "", nil
+}
+
+func (c *keycloakClient) UpdateAuthenticatorConfig(authenticatorConfig *keycloak.AuthenticatorConfig, realmName string) error {
+	c.record(ActionUpdate, "realms/%s/authentication/config/%s", realmName, authenticatorConfig.ID)
+	return nil
+}
+
+func (c *keycloakClient) DeleteAuthenticatorConfig(configID, realmName string) error {
+	c.record(ActionDelete, "realms/%s/authentication/config/%s", realmName, configID)
+	return nil
+}
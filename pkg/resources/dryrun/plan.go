@@ -0,0 +1,205 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// PlanConfigMapName is the name of the ConfigMap, in the installation
+	// namespace, the dry run plan is published to
+	PlanConfigMapName = "rhmi-dry-run-plan"
+	// PlanConfigMapKey is the key of the ConfigMap data holding the JSON plan
+	PlanConfigMapKey = "plan.json"
+)
+
+type Action string
+
+const (
+	ActionCreate      Action = "create"
+	ActionUpdate      Action = "update"
+	ActionPatch       Action = "patch"
+	ActionDelete      Action = "delete"
+	ActionDeleteAllOf Action = "deleteAllOf"
+	// ActionEvent is an event the reconcilers would have emitted
+	ActionEvent Action = "event"
+	// ActionExec is a command the reconcilers would have run in a pod
+	ActionExec Action = "exec"
+)
+
+// ObjectChange describes a single write the reconcilers attempted against the
+// cluster while running in dry run mode
+type ObjectChange struct {
+	Action      Action `json:"action"`
+	APIVersion  string `json:"apiVersion"`
+	Kind        string `json:"kind"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	// Diff between the object currently in the cluster and the object the
+	// API server would have persisted. Only set for updates and patches
+	Diff string `json:"diff,omitempty"`
+	// URL of the request, for the changes sent to the API server by clients
+	// other than the controller-runtime one and to the APIs of the products
+	URL string `json:"url,omitempty"`
+	// Message of the event, or command run in the pod
+	Message string `json:"message,omitempty"`
+	// Error returned by the API server when validating the change
+	Error string `json:"error,omitempty"`
+}
+
+type ProductPlan struct {
+	Name    integreatlyv1alpha1.ProductName `json:"name"`
+	Phase   integreatlyv1alpha1.StatusPhase `json:"phase"`
+	Error   string                          `json:"error,omitempty"`
+	Changes []ObjectChange                  `json:"changes"`
+}
+
+type StagePlan struct {
+	Name     integreatlyv1alpha1.StageName                    `json:"name"`
+	Products map[integreatlyv1alpha1.ProductName]*ProductPlan `json:"products"`
+}
+
+// Plan collects the changes recorded by the dry run clients of every product
+// of every stage. It's safe for concurrent use
+type Plan struct {
+	mu sync.Mutex
+
+	InstallationType string       `json:"installationType"`
+	Version          string       `json:"version,omitempty"`
+	ToVersion        string       `json:"toVersion,omitempty"`
+	GeneratedAt      metav1.Time  `json:"generatedAt"`
+	Stages           []*StagePlan `json:"stages"`
+}
+
+// Recorder receives the changes intercepted by a dry run client
+type Recorder interface {
+	Record(change ObjectChange)
+}
+
+func NewPlan(installation *integreatlyv1alpha1.RHMI, toVersion string) *Plan {
+	return &Plan{
+		InstallationType: installation.Spec.Type,
+		Version:          installation.Status.Version,
+		ToVersion:        toVersion,
+		GeneratedAt:      metav1.Now(),
+		Stages:           []*StagePlan{},
+	}
+}
+
+// RecorderFor returns a Recorder that adds the changes to the plan of the
+// given product in the given stage
+func (p *Plan) RecorderFor(stage integreatlyv1alpha1.StageName, product integreatlyv1alpha1.ProductName) Recorder {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.productPlan(stage, product)
+	return &productRecorder{plan: p, stage: stage, product: product}
+}
+
+// SetProductResult records the phase and error returned by the product
+// reconciler at the end of the dry run
+func (p *Plan) SetProductResult(stage integreatlyv1alpha1.StageName, product integreatlyv1alpha1.ProductName, phase integreatlyv1alpha1.StatusPhase, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	productPlan := p.productPlan(stage, product)
+	productPlan.Phase = phase
+	if err != nil {
+		productPlan.Error = err.Error()
+	}
+}
+
+// Changes returns the total number of changes recorded in the plan
+func (p *Plan) Changes() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	count := 0
+	for _, stage := range p.Stages {
+		for _, product := range stage.Products {
+			count += len(product.Changes)
+		}
+	}
+	return count
+}
+
+// Publish writes the plan as JSON into the PlanConfigMapName ConfigMap in the
+// given namespace. The client must not be a dry run client
+func (p *Plan) Publish(ctx context.Context, serverClient k8sclient.Client, namespace string) error {
+	p.mu.Lock()
+	data, err := json.MarshalIndent(p, "", "  ")
+	p.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal dry run plan: %w", err)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      PlanConfigMapName,
+			Namespace: namespace,
+		},
+	}
+	if _, err := controllerutil.CreateOrUpdate(ctx, serverClient, cm, func() error {
+		cm.Data = map[string]string{
+			PlanConfigMapKey: string(data),
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to publish dry run plan to configmap %s: %w", PlanConfigMapName, err)
+	}
+
+	return nil
+}
+
+// productPlan returns the plan for the product, creating it if it doesn't
+// exist yet. Must be called while holding the lock
+func (p *Plan) productPlan(stage integreatlyv1alpha1.StageName, product integreatlyv1alpha1.ProductName) *ProductPlan {
+	var stagePlan *StagePlan
+	for _, s := range p.Stages {
+		if s.Name == stage {
+			stagePlan = s
+			break
+		}
+	}
+	if stagePlan == nil {
+		stagePlan = &StagePlan{
+			Name:     stage,
+			Products: map[integreatlyv1alpha1.ProductName]*ProductPlan{},
+		}
+		p.Stages = append(p.Stages, stagePlan)
+	}
+
+	productPlan, ok := stagePlan.Products[product]
+	if !ok {
+		productPlan = &ProductPlan{
+			Name:    product,
+			Changes: []ObjectChange{},
+		}
+		stagePlan.Products[product] = productPlan
+	}
+
+	return productPlan
+}
+
+type productRecorder struct {
+	plan    *Plan
+	stage   integreatlyv1alpha1.StageName
+	product integreatlyv1alpha1.ProductName
+}
+
+func (r *productRecorder) Record(change ObjectChange) {
+	r.plan.mu.Lock()
+	defer r.plan.mu.Unlock()
+
+	productPlan := r.plan.productPlan(r.stage, r.product)
+	productPlan.Changes = append(productPlan.Changes, change)
+}
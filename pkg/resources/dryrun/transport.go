@@ -0,0 +1,148 @@
+package dryrun
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/transport"
+)
+
+// actionsByMethod maps the HTTP methods that modify the state of an API to
+// the action recorded in the plan. Requests with other methods are reads and
+// are sent as they are
+var actionsByMethod = map[string]Action{
+	http.MethodPost:   ActionCreate,
+	http.MethodPut:    ActionUpdate,
+	http.MethodPatch:  ActionPatch,
+	http.MethodDelete: ActionDelete,
+}
+
+// WrapKubeTransport returns the transport wrapper of the clients built from a
+// rest.Config, such as the typed OpenShift clients. Writes to objects and to
+// their status are sent with the dryRun=All parameter, so the API server
+// validates them without persisting them. Writes to other subresources, such
+// as the instantiation of a DeploymentConfig, don't all honour the parameter
+// so they aren't sent and an empty object is returned instead. Every write
+// is recorded in the recorder
+func WrapKubeTransport(recorder Recorder) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &kubeTransport{delegate: rt, recorder: recorder}
+	}
+}
+
+type kubeTransport struct {
+	delegate http.RoundTripper
+	recorder Recorder
+}
+
+func (t *kubeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	action, ok := actionsByMethod[req.Method]
+	if !ok {
+		return t.delegate.RoundTrip(req)
+	}
+
+	subresource := kubeSubresource(req.URL.Path)
+	change := ObjectChange{
+		Action:      action,
+		URL:         req.URL.Path,
+		Subresource: subresource,
+	}
+
+	if subresource != "" && subresource != "status" {
+		t.recorder.Record(change)
+		return newResponse(req, http.StatusCreated, "{}"), nil
+	}
+
+	// The request is cloned, as a RoundTripper must not modify it
+	dryRunReq := req.Clone(req.Context())
+	query := dryRunReq.URL.Query()
+	query.Set("dryRun", "All")
+	dryRunReq.URL.RawQuery = query.Encode()
+
+	resp, err := t.delegate.RoundTrip(dryRunReq)
+	if err != nil {
+		change.Error = err.Error()
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		change.Error = resp.Status
+	}
+	t.recorder.Record(change)
+
+	return resp, err
+}
+
+// kubeSubresource returns the subresource of the path of a request to the
+// API server, if any. The paths are either /api/<version>/<resource path> or
+// /apis/<group>/<version>/<resource path>, where the resource path is
+// optionally prefixed with namespaces/<namespace>
+func kubeSubresource(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) > 2 && segments[0] == "api":
+		segments = segments[2:]
+	case len(segments) > 3 && segments[0] == "apis":
+		segments = segments[3:]
+	default:
+		return ""
+	}
+
+	if len(segments) > 2 && segments[0] == "namespaces" {
+		segments = segments[2:]
+	}
+	// <resource>/<name>/<subresource>
+	if len(segments) > 2 {
+		return segments[2]
+	}
+	return ""
+}
+
+// WrapHTTPTransport wraps the transport of the clients of the APIs of the
+// products, such as the 3scale API. Their writes can't be validated without
+// being applied, so they are recorded in the recorder and not sent, and an
+// error is returned to the caller. Reads are sent as they are
+func WrapHTTPTransport(rt http.RoundTripper, recorder Recorder) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return &httpTransport{delegate: rt, recorder: recorder}
+}
+
+type httpTransport struct {
+	delegate http.RoundTripper
+	recorder Recorder
+}
+
+func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	action, ok := actionsByMethod[req.Method]
+	if !ok {
+		return t.delegate.RoundTrip(req)
+	}
+
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	// The query may hold credentials, such as the 3scale access token
+	url := *req.URL
+	url.RawQuery = ""
+	t.recorder.Record(ObjectChange{
+		Action: action,
+		URL:    url.String(),
+	})
+
+	return nil, fmt.Errorf("dry run: %s %s not sent", req.Method, url.String())
+}
+
+func newResponse(req *http.Request, statusCode int, body string) *http.Response {
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode: statusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
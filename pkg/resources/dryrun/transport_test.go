@@ -0,0 +1,203 @@
+package dryrun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	keycloakCommon "github.com/integr8ly/keycloak-client/pkg/common"
+	keycloak "github.com/keycloak/keycloak-operator/pkg/apis/keycloak/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testRecorder struct {
+	changes []ObjectChange
+}
+
+func (r *testRecorder) Record(change ObjectChange) {
+	r.changes = append(r.changes, change)
+}
+
+func TestKubeTransport(t *testing.T) {
+	cases := []struct {
+		Name           string
+		Method         string
+		Path           string
+		ExpectSent     bool
+		ExpectDryRun   bool
+		ExpectRecorded bool
+	}{
+		{
+			Name:       "reads are sent as they are",
+			Method:     http.MethodGet,
+			Path:       "/apis/apps.openshift.io/v1/namespaces/redhat-rhoam-3scale/deploymentconfigs/system-app",
+			ExpectSent: true,
+		},
+		{
+			Name:           "object writes are sent in dry run mode",
+			Method:         http.MethodPost,
+			Path:           "/apis/oauth.openshift.io/v1/oauthclients",
+			ExpectSent:     true,
+			ExpectDryRun:   true,
+			ExpectRecorded: true,
+		},
+		{
+			Name:           "status writes are sent in dry run mode",
+			Method:         http.MethodPut,
+			Path:           "/api/v1/namespaces/redhat-rhoam-3scale/pods/system-app-1/status",
+			ExpectSent:     true,
+			ExpectDryRun:   true,
+			ExpectRecorded: true,
+		},
+		{
+			Name:           "subresource writes are not sent",
+			Method:         http.MethodPost,
+			Path:           "/apis/apps.openshift.io/v1/namespaces/redhat-rhoam-3scale/deploymentconfigs/system-app/instantiate",
+			ExpectRecorded: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var received *http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+
+			recorder := &testRecorder{}
+			client := &http.Client{Transport: WrapKubeTransport(recorder)(http.DefaultTransport)}
+
+			req, err := http.NewRequest(tc.Method, server.URL+tc.Path, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			resp.Body.Close()
+
+			if (received != nil) != tc.ExpectSent {
+				t.Fatalf("expected request sent to be %v", tc.ExpectSent)
+			}
+			if received != nil && (received.URL.Query().Get("dryRun") == "All") != tc.ExpectDryRun {
+				t.Fatalf("expected dry run to be %v, got query %s", tc.ExpectDryRun, received.URL.RawQuery)
+			}
+			if req.URL.RawQuery != "" {
+				t.Fatalf("expected the original request not to be modified, got query %s", req.URL.RawQuery)
+			}
+			if (len(recorder.changes) == 1) != tc.ExpectRecorded {
+				t.Fatalf("expected change recorded to be %v, got %+v", tc.ExpectRecorded, recorder.changes)
+			}
+			if tc.ExpectRecorded && recorder.changes[0].URL != tc.Path {
+				t.Fatalf("unexpected change recorded: %+v", recorder.changes[0])
+			}
+		})
+	}
+}
+
+func TestHTTPTransport(t *testing.T) {
+	sent := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+	}))
+	defer server.Close()
+
+	recorder := &testRecorder{}
+	client := &http.Client{Transport: WrapHTTPTransport(nil, recorder)}
+
+	resp, err := client.Get(server.URL + "/admin/api/users.json?access_token=secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if sent != 1 || len(recorder.changes) != 0 {
+		t.Fatalf("expected the read to be sent and not recorded, got %d requests and changes %+v", sent, recorder.changes)
+	}
+
+	if _, err := client.Post(server.URL+"/admin/api/users.json?access_token=secret", "application/json", strings.NewReader("{}")); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if sent != 1 {
+		t.Fatal("expected the write not to be sent")
+	}
+	if len(recorder.changes) != 1 || recorder.changes[0].Action != ActionCreate || recorder.changes[0].URL != server.URL+"/admin/api/users.json" {
+		t.Fatalf("unexpected changes recorded: %+v", recorder.changes)
+	}
+}
+
+func TestEventRecorder(t *testing.T) {
+	scheme, err := getBuildScheme()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := &testRecorder{}
+	eventRecorder := NewEventRecorder(scheme, recorder)
+	eventRecorder.Eventf(&integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhoam", Namespace: "redhat-rhoam-operator"},
+	}, corev1.EventTypeWarning, "ProductFailed", "%s failed", "3scale")
+
+	expected := ObjectChange{
+		Action:     ActionEvent,
+		APIVersion: integreatlyv1alpha1.GroupVersion.String(),
+		Kind:       "RHMI",
+		Namespace:  "redhat-rhoam-operator",
+		Name:       "rhoam",
+		Message:    "Warning ProductFailed: 3scale failed",
+	}
+	if len(recorder.changes) != 1 || recorder.changes[0] != expected {
+		t.Fatalf("expected change %+v, got %+v", expected, recorder.changes)
+	}
+}
+
+func TestKeycloakClientFactory(t *testing.T) {
+	reads := 0
+	keycloakClient := &keycloakCommon.KeycloakInterfaceMock{
+		ListUsersFunc: func(realmName string) ([]*keycloak.KeycloakAPIUser, error) {
+			reads++
+			return []*keycloak.KeycloakAPIUser{}, nil
+		},
+	}
+	factory := &keycloakCommon.KeycloakClientFactoryMock{
+		AuthenticatedClientFunc: func(kc keycloak.Keycloak) (keycloakCommon.KeycloakInterface, error) {
+			return keycloakClient, nil
+		},
+	}
+
+	recorder := &testRecorder{}
+	client, err := NewKeycloakClientFactory(factory, recorder).AuthenticatedClient(keycloak.Keycloak{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.ListUsers("openshift"); err != nil || reads != 1 {
+		t.Fatalf("expected the read to be delegated, got error %v", err)
+	}
+
+	// The mock panics if the write is delegated
+	if _, err := client.CreateUser(&keycloak.KeycloakAPIUser{UserName: "test-user"}, "openshift"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.DeleteUser("user-id", "openshift"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []ObjectChange{
+		{Action: ActionCreate, URL: "/auth/admin/realms/openshift/users/test-user"},
+		{Action: ActionDelete, URL: "/auth/admin/realms/openshift/users/user-id"},
+	}
+	if len(recorder.changes) != len(expected) {
+		t.Fatalf("expected changes %+v, got %+v", expected, recorder.changes)
+	}
+	for i := range expected {
+		if recorder.changes[i] != expected[i] {
+			t.Fatalf("expected changes %+v, got %+v", expected, recorder.changes)
+		}
+	}
+}
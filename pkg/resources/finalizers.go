@@ -85,9 +85,14 @@ func RemoveNamespace(ctx context.Context, inst *integreatlyv1alpha1.RHMI, client
 	return integreatlyv1alpha1.PhaseInProgress, nil
 }
 
+// GetProductFinalizer returns the finalizer added to the installation custom resource by a product
+func GetProductFinalizer(product string) string {
+	return product + ".integreatly.org" + "/finalizer"
+}
+
 // RemoveProductFinalizer removes a given finalizer from the installation custom resource
 func RemoveProductFinalizer(ctx context.Context, inst *integreatlyv1alpha1.RHMI, client k8sclient.Client, product string, log l.Logger) error {
-	finalizer := product + ".integreatly.org" + "/finalizer"
+	finalizer := GetProductFinalizer(product)
 	inst.SetFinalizers(Remove(inst.GetFinalizers(), finalizer))
 	err := client.Update(ctx, inst)
 	if err != nil {
@@ -0,0 +1,193 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// RHMIConfigName is the name of the RHMIConfig CR in the operator namespace
+const RHMIConfigName = "rhmi-config"
+
+type actionKey struct {
+	product integreatlyv1alpha1.ProductName
+	action  string
+}
+
+// Gate decides if the disruptive actions of the reconcilers, such as
+// restarting the pods of a product, can run now. Outside of the maintenance
+// window set in the RHMIConfig the actions are deferred, and listed in the
+// status of the installation until the window opens.
+//
+// A nil Gate allows every action
+type Gate struct {
+	client    k8sclient.Client
+	namespace string
+	// enforced is false while the installation or an upgrade is in
+	// progress, the disruptive actions are expected in that case
+	enforced bool
+	now      func() time.Time
+
+	mu       sync.Mutex
+	pending  map[actionKey]metav1.Time
+	deferred map[actionKey]bool
+	done     map[actionKey]bool
+}
+
+// NewGate returns the gate of the installation. The actions deferred in
+// previous reconciles are read from the status of the installation
+func NewGate(client k8sclient.Client, installation *integreatlyv1alpha1.RHMI) *Gate {
+	gate := &Gate{
+		client:    client,
+		namespace: installation.Namespace,
+		enforced:  installation.Status.Version != "" && installation.Status.ToVersion == "",
+		now:       time.Now,
+		pending:   map[actionKey]metav1.Time{},
+		deferred:  map[actionKey]bool{},
+		done:      map[actionKey]bool{},
+	}
+	for _, action := range installation.Status.DeferredActions {
+		gate.pending[actionKey{product: action.Product, action: action.Action}] = action.DeferredSince
+	}
+	return gate
+}
+
+// InWindow returns true if one of the maintenance windows is open and there's
+// no blackout in effect. It's always open when there's no RHMIConfig
+func (g *Gate) InWindow(ctx context.Context) (bool, error) {
+	if g == nil {
+		return true, nil
+	}
+
+	rhmiConfig := &integreatlyv1alpha1.RHMIConfig{}
+	if err := g.client.Get(ctx, k8sclient.ObjectKey{Name: RHMIConfigName, Namespace: g.namespace}, rhmiConfig); err != nil {
+		if k8serr.IsNotFound(err) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get the maintenance window: %w", err)
+	}
+
+	schedule, err := NewSchedule(rhmiConfig.Spec)
+	if err != nil {
+		return false, fmt.Errorf("failed to get the maintenance schedule: %w", err)
+	}
+	return schedule.InWindow(g.now().UTC())
+}
+
+// Allow returns true if the disruptive action can run now. Otherwise the
+// action is recorded as deferred, and the caller is expected to try again
+// in the next reconcile
+func (g *Gate) Allow(ctx context.Context, product integreatlyv1alpha1.ProductName, action string) (bool, error) {
+	if g == nil || !g.enforced {
+		return true, nil
+	}
+
+	inWindow, err := g.InWindow(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	key := actionKey{product: product, action: action}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if inWindow {
+		g.done[key] = true
+		return true, nil
+	}
+	g.deferred[key] = true
+	return false, nil
+}
+
+// IsPending returns true if the action was deferred in a previous reconcile
+// and hasn't run yet. It's used by reconcilers that can't tell from the state
+// of the cluster that the action is still needed
+func (g *Gate) IsPending(product integreatlyv1alpha1.ProductName, action string) bool {
+	if g == nil {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := actionKey{product: product, action: action}
+	_, ok := g.pending[key]
+	return ok && !g.done[key]
+}
+
+// Discard removes an action that is no longer needed
+func (g *Gate) Discard(product integreatlyv1alpha1.ProductName, action string) {
+	if g == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := actionKey{product: product, action: action}
+	delete(g.deferred, key)
+	g.done[key] = true
+}
+
+// DeferredActions returns the actions waiting for the maintenance window.
+// The actions deferred in previous reconciles are kept until they run or
+// are discarded, as the reconcile of their product may not have reached
+// them
+func (g *Gate) DeferredActions() []integreatlyv1alpha1.DeferredAction {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	actions := map[actionKey]metav1.Time{}
+	for key, since := range g.pending {
+		if !g.done[key] {
+			actions[key] = since
+		}
+	}
+	now := metav1.NewTime(g.now())
+	for key := range g.deferred {
+		if _, ok := actions[key]; !ok {
+			actions[key] = now
+		}
+	}
+
+	deferredActions := make([]integreatlyv1alpha1.DeferredAction, 0, len(actions))
+	for key, since := range actions {
+		deferredActions = append(deferredActions, integreatlyv1alpha1.DeferredAction{
+			Product:       key.product,
+			Action:        key.action,
+			DeferredSince: since,
+		})
+	}
+	sort.Slice(deferredActions, func(i, j int) bool {
+		if deferredActions[i].Product != deferredActions[j].Product {
+			return deferredActions[i].Product < deferredActions[j].Product
+		}
+		return deferredActions[i].Action < deferredActions[j].Action
+	})
+	return deferredActions
+}
+
+type gateContextKey struct{}
+
+// WithGate returns a copy of the context carrying the gate, it's passed to
+// the product reconcilers
+func WithGate(ctx context.Context, gate *Gate) context.Context {
+	return context.WithValue(ctx, gateContextKey{}, gate)
+}
+
+// GateFromContext returns the gate of the context, or nil if there's none
+func GateFromContext(ctx context.Context) *Gate {
+	gate, _ := ctx.Value(gateContextKey{}).(*Gate)
+	return gate
+}
@@ -0,0 +1,209 @@
+package maintenance
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "redhat-rhoam-operator"
+
+var (
+	// Thursday, inside the default maintenance window
+	insideWindow = time.Date(2021, time.March, 4, 3, 0, 0, 0, time.UTC)
+	// Monday, outside of the default maintenance window
+	outsideWindow = time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func getBuildScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	if err := integreatlyv1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return scheme
+}
+
+func installedRHMI() *integreatlyv1alpha1.RHMI {
+	return &integreatlyv1alpha1.RHMI{
+		ObjectMeta: metav1.ObjectMeta{Name: "rhoam", Namespace: testNamespace},
+		Status:     integreatlyv1alpha1.RHMIStatus{Version: "1.0.0"},
+	}
+}
+
+func rhmiConfig() *integreatlyv1alpha1.RHMIConfig {
+	return &integreatlyv1alpha1.RHMIConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: RHMIConfigName, Namespace: testNamespace},
+		Spec: integreatlyv1alpha1.RHMIConfigSpec{
+			Maintenance: integreatlyv1alpha1.Maintenance{ApplyFrom: "Thu 02:00"},
+		},
+	}
+}
+
+func newTestGate(client k8sclient.Client, installation *integreatlyv1alpha1.RHMI, now time.Time) *Gate {
+	gate := NewGate(client, installation)
+	gate.now = func() time.Time { return now }
+	return gate
+}
+
+func TestGateAllow(t *testing.T) {
+	scheme := getBuildScheme(t)
+
+	installing := installedRHMI()
+	installing.Status.Version = ""
+	upgrading := installedRHMI()
+	upgrading.Status.ToVersion = "1.1.0"
+
+	cases := []struct {
+		Name            string
+		Installation    *integreatlyv1alpha1.RHMI
+		Objects         []runtime.Object
+		Now             time.Time
+		ExpectedAllowed bool
+		ExpectedActions int
+	}{
+		{
+			Name:            "test actions are allowed during the installation",
+			Installation:    installing,
+			Objects:         []runtime.Object{rhmiConfig()},
+			Now:             outsideWindow,
+			ExpectedAllowed: true,
+		},
+		{
+			Name:            "test actions are allowed during an upgrade",
+			Installation:    upgrading,
+			Objects:         []runtime.Object{rhmiConfig()},
+			Now:             outsideWindow,
+			ExpectedAllowed: true,
+		},
+		{
+			Name:            "test actions are allowed without RHMIConfig",
+			Installation:    installedRHMI(),
+			Now:             outsideWindow,
+			ExpectedAllowed: true,
+		},
+		{
+			Name:            "test actions are allowed inside the maintenance window",
+			Installation:    installedRHMI(),
+			Objects:         []runtime.Object{rhmiConfig()},
+			Now:             insideWindow,
+			ExpectedAllowed: true,
+		},
+		{
+			Name:         "test actions are deferred during a blackout",
+			Installation: installedRHMI(),
+			Objects: []runtime.Object{&integreatlyv1alpha1.RHMIConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: RHMIConfigName, Namespace: testNamespace},
+				Spec: integreatlyv1alpha1.RHMIConfigSpec{
+					Maintenance: integreatlyv1alpha1.Maintenance{
+						ApplyFrom: "Thu 02:00",
+						Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "1 Mar 2021 00:00", To: "8 Mar 2021 00:00"}},
+					},
+				},
+			}},
+			Now:             insideWindow,
+			ExpectedAllowed: false,
+			ExpectedActions: 1,
+		},
+		{
+			Name:            "test actions are deferred outside of the maintenance window",
+			Installation:    installedRHMI(),
+			Objects:         []runtime.Object{rhmiConfig()},
+			Now:             outsideWindow,
+			ExpectedAllowed: false,
+			ExpectedActions: 1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			gate := newTestGate(fake.NewFakeClientWithScheme(scheme, tc.Objects...), tc.Installation, tc.Now)
+
+			allowed, err := gate.Allow(context.TODO(), integreatlyv1alpha1.Product3Scale, "rollout")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if allowed != tc.ExpectedAllowed {
+				t.Fatalf("expected allowed to be %v, got %v", tc.ExpectedAllowed, allowed)
+			}
+			if actions := gate.DeferredActions(); len(actions) != tc.ExpectedActions {
+				t.Fatalf("expected %d deferred actions, got %v", tc.ExpectedActions, actions)
+			}
+		})
+	}
+}
+
+func TestGateDeferredActions(t *testing.T) {
+	scheme := getBuildScheme(t)
+	since := metav1.NewTime(outsideWindow.Add(-24 * time.Hour))
+
+	installation := installedRHMI()
+	installation.Status.DeferredActions = []integreatlyv1alpha1.DeferredAction{
+		{Product: integreatlyv1alpha1.Product3Scale, Action: "rollout", DeferredSince: since},
+		{Action: "rebalance pods", DeferredSince: since},
+		{Product: integreatlyv1alpha1.ProductRHSSO, Action: "update statefulset", DeferredSince: since},
+	}
+	client := fake.NewFakeClientWithScheme(scheme, rhmiConfig())
+
+	// Outside of the window the previous actions are kept with their
+	// original time, new actions are added
+	gate := newTestGate(client, installation, outsideWindow)
+	if !gate.IsPending(integreatlyv1alpha1.Product3Scale, "rollout") {
+		t.Fatal("expected the 3scale rollout to be pending")
+	}
+	if _, err := gate.Allow(context.TODO(), integreatlyv1alpha1.Product3Scale, "rollout"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gate.Allow(context.TODO(), integreatlyv1alpha1.ProductRHSSOUser, "update statefulset"); err != nil {
+		t.Fatal(err)
+	}
+	gate.Discard("", "rebalance pods")
+
+	expected := []integreatlyv1alpha1.DeferredAction{
+		{Product: integreatlyv1alpha1.Product3Scale, Action: "rollout", DeferredSince: since},
+		{Product: integreatlyv1alpha1.ProductRHSSO, Action: "update statefulset", DeferredSince: since},
+		{Product: integreatlyv1alpha1.ProductRHSSOUser, Action: "update statefulset", DeferredSince: metav1.NewTime(outsideWindow)},
+	}
+	if actions := gate.DeferredActions(); !reflect.DeepEqual(actions, expected) {
+		t.Fatalf("expected deferred actions %v, got %v", expected, actions)
+	}
+
+	// Inside the window the actions run and are removed
+	gate = newTestGate(client, installation, insideWindow)
+	allowed, err := gate.Allow(context.TODO(), integreatlyv1alpha1.Product3Scale, "rollout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !allowed {
+		t.Fatal("expected the 3scale rollout to be allowed")
+	}
+	if gate.IsPending(integreatlyv1alpha1.Product3Scale, "rollout") {
+		t.Fatal("expected the 3scale rollout to not be pending once it ran")
+	}
+	if actions := gate.DeferredActions(); len(actions) != 2 {
+		t.Fatalf("expected the actions that didn't run to be kept, got %v", actions)
+	}
+}
+
+func TestGateFromContext(t *testing.T) {
+	if gate := GateFromContext(context.TODO()); gate != nil {
+		t.Fatalf("expected no gate, got %v", gate)
+	}
+
+	// A missing gate allows every action
+	allowed, err := GateFromContext(context.TODO()).Allow(context.TODO(), integreatlyv1alpha1.Product3Scale, "rollout")
+	if err != nil || !allowed {
+		t.Fatalf("expected the action to be allowed without gate, got %v %v", allowed, err)
+	}
+
+	gate := NewGate(nil, installedRHMI())
+	if GateFromContext(WithGate(context.TODO(), gate)) != gate {
+		t.Fatal("expected the gate of the context")
+	}
+}
@@ -0,0 +1,170 @@
+package maintenance
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+// maxScheduleWeeks is how far ahead NextWindow looks for a window that is not
+// covered by the blackout periods
+const maxScheduleWeeks = 104
+
+// Window is a weekly maintenance window
+type Window struct {
+	// ApplyFrom is the start of the window in format: sun 23:00
+	ApplyFrom string
+	Duration  time.Duration
+}
+
+type blackout struct {
+	from   time.Time
+	to     time.Time
+	period integreatlyv1alpha1.BlackoutPeriod
+}
+
+// Schedule is the maintenance schedule of the RHMIConfig: the weekly windows
+// where disruptive changes can be applied, and the blackout periods where
+// they never are
+type Schedule struct {
+	Windows []Window
+	// Location is the time zone of the windows and blackout periods
+	Location  *time.Location
+	blackouts []blackout
+}
+
+// NewSchedule returns the maintenance schedule of a RHMIConfig spec. The
+// ApplyFrom window is always part of the schedule, it defaults to
+// integreatlyv1alpha1.DefaultMaintenanceApplyFrom when empty
+func NewSchedule(configSpec integreatlyv1alpha1.RHMIConfigSpec) (*Schedule, error) {
+	loc, err := configSpec.GetLocation()
+	if err != nil {
+		return nil, err
+	}
+
+	spec := configSpec.Maintenance
+	if err := integreatlyv1alpha1.ValidateMaintenanceWindows(spec, loc); err != nil {
+		return nil, err
+	}
+
+	applyFrom := spec.ApplyFrom
+	if applyFrom == "" {
+		applyFrom = integreatlyv1alpha1.DefaultMaintenanceApplyFrom
+	}
+	schedule := &Schedule{
+		Windows:  []Window{{ApplyFrom: applyFrom, Duration: WindowDuration}},
+		Location: loc,
+	}
+
+	for _, window := range spec.Windows {
+		duration, err := window.GetDuration()
+		if err != nil {
+			return nil, err
+		}
+		schedule.Windows = append(schedule.Windows, Window{ApplyFrom: window.ApplyFrom, Duration: duration})
+	}
+
+	for _, period := range spec.Blackouts {
+		from, to, err := period.GetRange(loc)
+		if err != nil {
+			return nil, err
+		}
+		schedule.blackouts = append(schedule.blackouts, blackout{from: from, to: to, period: period})
+	}
+	sort.Slice(schedule.blackouts, func(i, j int) bool {
+		return schedule.blackouts[i].from.Before(schedule.blackouts[j].from)
+	})
+
+	return schedule, nil
+}
+
+// ActiveBlackout returns the blackout period that contains now, or nil if
+// there's none
+func (s *Schedule) ActiveBlackout(now time.Time) *integreatlyv1alpha1.BlackoutPeriod {
+	for _, b := range s.blackouts {
+		if !now.Before(b.from) && now.Before(b.to) {
+			period := b.period
+			return &period
+		}
+	}
+	return nil
+}
+
+// InWindow returns true if now is inside one of the maintenance windows and
+// outside of the blackout periods
+func (s *Schedule) InWindow(now time.Time) (bool, error) {
+	if s.ActiveBlackout(now) != nil {
+		return false, nil
+	}
+
+	for _, window := range s.Windows {
+		inWindow, err := IsInWindow(now, window.ApplyFrom, window.Duration, s.Location)
+		if err != nil {
+			return false, err
+		}
+		if inWindow {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// NextWindow returns the start and end of the first maintenance window that
+// starts on the day of from or later, as GetWeeklyWindow does. The parts of
+// the windows covered by a blackout period are left out
+func (s *Schedule) NextWindow(from time.Time) (time.Time, time.Time, error) {
+	from = from.In(s.Location)
+	for week := 0; week < maxScheduleWeeks; week++ {
+		weekFrom := from.AddDate(0, 0, 7*week)
+
+		var nextStart, nextEnd time.Time
+		for _, window := range s.Windows {
+			start, end, err := GetWeeklyWindow(weekFrom, window.ApplyFrom, window.Duration, s.Location)
+			if err != nil {
+				return time.Time{}, time.Time{}, err
+			}
+			start, end = s.trimBlackouts(start, end)
+			if !start.Before(end) {
+				continue
+			}
+			if nextStart.IsZero() || start.Before(nextStart) {
+				nextStart, nextEnd = start, end
+			}
+		}
+		if !nextStart.IsZero() {
+			return nextStart, nextEnd, nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, fmt.Errorf("no maintenance window found outside of the blackout periods in the next %d weeks", maxScheduleWeeks)
+}
+
+// SkipBlackouts returns t, or the end of the blackout periods that contain it
+func (s *Schedule) SkipBlackouts(t time.Time) time.Time {
+	for _, b := range s.blackouts {
+		if !t.Before(b.from) && t.Before(b.to) {
+			t = b.to
+		}
+	}
+	return t
+}
+
+// trimBlackouts returns the first part of the window that is not covered by
+// a blackout period. The result is empty if start is not before end
+func (s *Schedule) trimBlackouts(start, end time.Time) (time.Time, time.Time) {
+	for _, b := range s.blackouts {
+		if !start.Before(end) {
+			break
+		}
+		if !start.Before(b.from) && start.Before(b.to) {
+			// The blackout covers the start of the window
+			start = b.to
+		} else if start.Before(b.from) && b.from.Before(end) {
+			// The blackout starts inside the window
+			end = b.from
+		}
+	}
+	return start, end
+}
@@ -0,0 +1,285 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+func date(day, hour int) time.Time {
+	// March 2021, the 1st is a Monday
+	return time.Date(2021, time.March, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestNewSchedule(t *testing.T) {
+	cases := []struct {
+		Name        string
+		Spec        integreatlyv1alpha1.Maintenance
+		ExpectError bool
+		Expected    []Window
+	}{
+		{
+			Name:     "test default window",
+			Spec:     integreatlyv1alpha1.Maintenance{},
+			Expected: []Window{{ApplyFrom: integreatlyv1alpha1.DefaultMaintenanceApplyFrom, Duration: 6 * time.Hour}},
+		},
+		{
+			Name: "test additional windows",
+			Spec: integreatlyv1alpha1.Maintenance{
+				ApplyFrom: "Sun 22:00",
+				Windows: []integreatlyv1alpha1.MaintenanceWindow{
+					{ApplyFrom: "Wed 01:00", Duration: "2h"},
+					{ApplyFrom: "Fri 01:00"},
+				},
+			},
+			Expected: []Window{
+				{ApplyFrom: "Sun 22:00", Duration: 6 * time.Hour},
+				{ApplyFrom: "Wed 01:00", Duration: 2 * time.Hour},
+				{ApplyFrom: "Fri 01:00", Duration: 6 * time.Hour},
+			},
+		},
+		{
+			Name: "test invalid window duration",
+			Spec: integreatlyv1alpha1.Maintenance{
+				Windows: []integreatlyv1alpha1.MaintenanceWindow{{ApplyFrom: "Wed 01:00", Duration: "30m"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "test invalid window start",
+			Spec: integreatlyv1alpha1.Maintenance{
+				Windows: []integreatlyv1alpha1.MaintenanceWindow{{ApplyFrom: "Someday 01:00"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "test blackout ending before it starts",
+			Spec: integreatlyv1alpha1.Maintenance{
+				Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "2 Mar 2021 00:00", To: "1 Mar 2021 00:00"}},
+			},
+			ExpectError: true,
+		},
+		{
+			Name: "test invalid blackout format",
+			Spec: integreatlyv1alpha1.Maintenance{
+				Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "2021-03-01", To: "2021-03-02"}},
+			},
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			schedule, err := NewSchedule(integreatlyv1alpha1.RHMIConfigSpec{Maintenance: tc.Spec})
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(schedule.Windows) != len(tc.Expected) {
+				t.Fatalf("expected windows %v, got %v", tc.Expected, schedule.Windows)
+			}
+			for i, window := range tc.Expected {
+				if schedule.Windows[i] != window {
+					t.Fatalf("expected windows %v, got %v", tc.Expected, schedule.Windows)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleInWindow(t *testing.T) {
+	schedule, err := NewSchedule(integreatlyv1alpha1.RHMIConfigSpec{Maintenance: integreatlyv1alpha1.Maintenance{
+		ApplyFrom: "Thu 02:00",
+		Windows:   []integreatlyv1alpha1.MaintenanceWindow{{ApplyFrom: "Mon 10:00", Duration: "2h"}},
+		Blackouts: []integreatlyv1alpha1.BlackoutPeriod{
+			{From: "10 Mar 2021 00:00", To: "12 Mar 2021 00:00", Reason: "end of quarter"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name     string
+		Now      time.Time
+		Expected bool
+	}{
+		{Name: "test inside the ApplyFrom window", Now: date(4, 3), Expected: true},
+		{Name: "test inside an additional window", Now: date(1, 11), Expected: true},
+		{Name: "test after an additional window", Now: date(1, 12), Expected: false},
+		{Name: "test outside of the windows", Now: date(2, 3), Expected: false},
+		{Name: "test inside a window during a blackout", Now: date(11, 3), Expected: false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			inWindow, err := schedule.InWindow(tc.Now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inWindow != tc.Expected {
+				t.Fatalf("expected in window to be %v, got %v", tc.Expected, inWindow)
+			}
+		})
+	}
+
+	if blackout := schedule.ActiveBlackout(date(11, 3)); blackout == nil || blackout.Reason != "end of quarter" {
+		t.Fatalf("expected the end of quarter blackout, got %v", blackout)
+	}
+	if blackout := schedule.ActiveBlackout(date(12, 0)); blackout != nil {
+		t.Fatalf("expected no blackout at its end, got %v", blackout)
+	}
+}
+
+func TestScheduleNextWindow(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Spec          integreatlyv1alpha1.Maintenance
+		From          time.Time
+		ExpectedStart time.Time
+		ExpectedEnd   time.Time
+		ExpectError   bool
+	}{
+		{
+			Name:          "test single window",
+			Spec:          integreatlyv1alpha1.Maintenance{ApplyFrom: "Thu 02:00"},
+			From:          date(1, 0),
+			ExpectedStart: date(4, 2),
+			ExpectedEnd:   date(4, 8),
+		},
+		{
+			Name: "test earliest of multiple windows",
+			Spec: integreatlyv1alpha1.Maintenance{
+				ApplyFrom: "Thu 02:00",
+				Windows:   []integreatlyv1alpha1.MaintenanceWindow{{ApplyFrom: "Tue 10:00", Duration: "2h"}},
+			},
+			From:          date(1, 0),
+			ExpectedStart: date(2, 10),
+			ExpectedEnd:   date(2, 12),
+		},
+		{
+			Name: "test window covered by a blackout is skipped",
+			Spec: integreatlyv1alpha1.Maintenance{
+				ApplyFrom: "Thu 02:00",
+				Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "3 Mar 2021 00:00", To: "5 Mar 2021 00:00"}},
+			},
+			From:          date(1, 0),
+			ExpectedStart: date(11, 2),
+			ExpectedEnd:   date(11, 8),
+		},
+		{
+			Name: "test window starting during a blackout",
+			Spec: integreatlyv1alpha1.Maintenance{
+				ApplyFrom: "Thu 02:00",
+				Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "3 Mar 2021 00:00", To: "4 Mar 2021 05:00"}},
+			},
+			From:          date(1, 0),
+			ExpectedStart: date(4, 5),
+			ExpectedEnd:   date(4, 8),
+		},
+		{
+			Name: "test blackout starting during a window",
+			Spec: integreatlyv1alpha1.Maintenance{
+				ApplyFrom: "Thu 02:00",
+				Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "4 Mar 2021 04:00", To: "6 Mar 2021 00:00"}},
+			},
+			From:          date(1, 0),
+			ExpectedStart: date(4, 2),
+			ExpectedEnd:   date(4, 4),
+		},
+		{
+			Name: "test no window outside of the blackouts",
+			Spec: integreatlyv1alpha1.Maintenance{
+				ApplyFrom: "Thu 02:00",
+				Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "1 Mar 2021 00:00", To: "1 Mar 2024 00:00"}},
+			},
+			From:        date(1, 0),
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			schedule, err := NewSchedule(integreatlyv1alpha1.RHMIConfigSpec{Maintenance: tc.Spec})
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, end, err := schedule.NextWindow(tc.From)
+			if tc.ExpectError {
+				if err == nil {
+					t.Fatal("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(tc.ExpectedStart) || !end.Equal(tc.ExpectedEnd) {
+				t.Fatalf("expected window %s - %s, got %s - %s", tc.ExpectedStart, tc.ExpectedEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestScheduleSkipBlackouts(t *testing.T) {
+	schedule, err := NewSchedule(integreatlyv1alpha1.RHMIConfigSpec{Maintenance: integreatlyv1alpha1.Maintenance{
+		Blackouts: []integreatlyv1alpha1.BlackoutPeriod{
+			{From: "1 Mar 2021 00:00", To: "3 Mar 2021 00:00"},
+			{From: "2 Mar 2021 00:00", To: "4 Mar 2021 00:00"},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if skipped := schedule.SkipBlackouts(date(1, 12)); !skipped.Equal(date(4, 0)) {
+		t.Fatalf("expected the end of the overlapping blackouts, got %s", skipped)
+	}
+	if skipped := schedule.SkipBlackouts(date(5, 0)); !skipped.Equal(date(5, 0)) {
+		t.Fatalf("expected the time outside of the blackouts unchanged, got %s", skipped)
+	}
+}
+
+func TestScheduleTimeZone(t *testing.T) {
+	schedule, err := NewSchedule(integreatlyv1alpha1.RHMIConfigSpec{
+		TimeZone: "Australia/Sydney",
+		Maintenance: integreatlyv1alpha1.Maintenance{
+			ApplyFrom: "Tue 08:00",
+			Blackouts: []integreatlyv1alpha1.BlackoutPeriod{{From: "9 Mar 2021 00:00", To: "10 Mar 2021 00:00"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tuesday 2 March 09:00 in Sydney
+	inWindow, err := schedule.InWindow(date(1, 22))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inWindow {
+		t.Fatal("expected the local window to be open")
+	}
+
+	// The blackout of the 9th of March starts on the 8th in UTC
+	if blackout := schedule.ActiveBlackout(date(8, 22)); blackout == nil {
+		t.Fatal("expected the local blackout to be in effect")
+	}
+	start, _, err := schedule.NextWindow(date(3, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := date(15, 21); !start.Equal(expected) {
+		t.Fatalf("expected the window after the blackout %s, got %s", expected, start.UTC())
+	}
+
+	if _, err := NewSchedule(integreatlyv1alpha1.RHMIConfigSpec{TimeZone: "Mars/Olympus_Mons"}); err == nil {
+		t.Fatal("expected error for an unknown time zone")
+	}
+}
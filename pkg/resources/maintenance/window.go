@@ -0,0 +1,100 @@
+package maintenance
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	integreatlyv1alpha1 "github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+)
+
+// WindowDuration is the length of the weekly maintenance window
+const WindowDuration = integreatlyv1alpha1.DefaultMaintenanceDuration
+
+var shortDays = map[string]int{
+	"sun": 0,
+	"mon": 1,
+	"tue": 2,
+	"wed": 3,
+	"thu": 4,
+	"fri": 5,
+	"sat": 6,
+}
+
+// GetWeeklyWindow returns the start and end of the first weekly window that
+// starts on the day of from or later, in loc. windowStartStr must be in
+// format: sun 23:00, and is a time in loc
+func GetWeeklyWindow(from time.Time, windowStartStr string, duration time.Duration, loc *time.Location) (time.Time, time.Time, error) {
+	windowSegments := strings.Split(windowStartStr, " ")
+	if len(windowSegments) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("expected window format DDD HH:mm, found %s", windowStartStr)
+	}
+	windowDay, ok := shortDays[strings.ToLower(windowSegments[0])]
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid window day %s, found %s", windowSegments[0], windowStartStr)
+	}
+
+	windowTimeSegments := strings.Split(windowSegments[1], ":")
+	if len(windowTimeSegments) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("expected window format DDD HH:mm, found %s", windowStartStr)
+	}
+	windowHour, err := strconv.Atoi(windowTimeSegments[0])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	windowMin, err := strconv.Atoi(windowTimeSegments[1])
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from = from.In(loc)
+
+	//calculate how far away from maintenance day today is, within the current week
+	dayDiff := windowDay - int(from.Weekday())
+	if dayDiff < 0 {
+		dayDiff = 7 + dayDiff
+	}
+
+	// the days are added to the date rather than as 24 hours so the window
+	// keeps its local time across daylight saving time changes
+	windowStart := time.Date(from.Year(), from.Month(), from.Day()+dayDiff, windowHour, windowMin, 0, 0, loc)
+	return windowStart, windowStart.Add(duration), nil
+}
+
+// IsInWindow returns true if now is inside the weekly window starting at
+// windowStartStr in loc
+func IsInWindow(now time.Time, windowStartStr string, duration time.Duration, loc *time.Location) (bool, error) {
+	// A window that contains now started less than duration ago, the
+	// first window starting on that day is either the one containing now
+	// or one that ended already
+	start, end, err := GetWeeklyWindow(now.Add(-duration), windowStartStr, duration, loc)
+	if err != nil {
+		return false, err
+	}
+	return !now.Before(start) && now.Before(end), nil
+}
+
+// WeeklyTimeToUTC converts the weekly time windowStartStr in loc, in format:
+// sun 23:00, to UTC. The offset of loc at the next occurrence after now is
+// used, so the result changes with daylight saving time
+func WeeklyTimeToUTC(now time.Time, windowStartStr string, loc *time.Location) (string, error) {
+	start, _, err := GetWeeklyWindow(now, windowStartStr, 0, loc)
+	if err != nil {
+		return "", err
+	}
+	return start.UTC().Format("Mon 15:04"), nil
+}
+
+// DailyTimeToUTC converts the daily time timeStr in loc, in format: 23:00, to
+// UTC. The offset of loc on the day of now is used, so the result changes
+// with daylight saving time
+func DailyTimeToUTC(now time.Time, timeStr string, loc *time.Location) (string, error) {
+	parsed, err := time.Parse("15:04", timeStr)
+	if err != nil {
+		return "", err
+	}
+	now = now.In(loc)
+	daily := time.Date(now.Year(), now.Month(), now.Day(), parsed.Hour(), parsed.Minute(), 0, 0, loc)
+	return daily.UTC().Format("15:04"), nil
+}
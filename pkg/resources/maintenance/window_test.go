@@ -0,0 +1,222 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsInWindow(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Now       time.Time
+		ApplyFrom string
+		Expected  bool
+	}{
+		{
+			Name:      "test inside the window",
+			Now:       time.Date(2021, time.March, 4, 3, 0, 0, 0, time.UTC), // Thursday
+			ApplyFrom: "Thu 02:00",
+			Expected:  true,
+		},
+		{
+			Name:      "test at the start of the window",
+			Now:       time.Date(2021, time.March, 4, 2, 0, 0, 0, time.UTC),
+			ApplyFrom: "Thu 02:00",
+			Expected:  true,
+		},
+		{
+			Name:      "test at the end of the window",
+			Now:       time.Date(2021, time.March, 4, 8, 0, 0, 0, time.UTC),
+			ApplyFrom: "Thu 02:00",
+			Expected:  false,
+		},
+		{
+			Name:      "test before the window",
+			Now:       time.Date(2021, time.March, 4, 1, 59, 0, 0, time.UTC),
+			ApplyFrom: "Thu 02:00",
+			Expected:  false,
+		},
+		{
+			Name:      "test window crossing midnight",
+			Now:       time.Date(2021, time.March, 8, 2, 0, 0, 0, time.UTC), // Monday
+			ApplyFrom: "sun 23:00",
+			Expected:  true,
+		},
+		{
+			Name:      "test window crossing the end of the month",
+			Now:       time.Date(2021, time.March, 1, 1, 0, 0, 0, time.UTC), // Monday
+			ApplyFrom: "Sun 22:00",
+			Expected:  true,
+		},
+		{
+			Name:      "test other day of the week",
+			Now:       time.Date(2021, time.March, 3, 3, 0, 0, 0, time.UTC), // Wednesday
+			ApplyFrom: "Thu 02:00",
+			Expected:  false,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			inWindow, err := IsInWindow(tc.Now, tc.ApplyFrom, WindowDuration, time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if inWindow != tc.Expected {
+				t.Fatalf("expected in window to be %v, got %v", tc.Expected, inWindow)
+			}
+		})
+	}
+}
+
+func TestGetWeeklyWindow(t *testing.T) {
+	// Monday
+	from := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		Name          string
+		ApplyFrom     string
+		ExpectedStart time.Time
+	}{
+		{
+			Name:          "test same day",
+			ApplyFrom:     "Mon 00:00",
+			ExpectedStart: time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:          "test next day",
+			ApplyFrom:     "Tue 00:00",
+			ExpectedStart: time.Date(2020, time.June, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:          "test day before",
+			ApplyFrom:     "SuN 00:00",
+			ExpectedStart: time.Date(2020, time.June, 7, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:          "test 3 days after",
+			ApplyFrom:     "Thu 02:00",
+			ExpectedStart: time.Date(2020, time.June, 4, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			start, end, err := GetWeeklyWindow(from, tc.ApplyFrom, time.Hour, time.UTC)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(tc.ExpectedStart) {
+				t.Fatalf("expected window start %s, got %s", tc.ExpectedStart, start)
+			}
+			if end.Sub(start) != time.Hour {
+				t.Fatalf("expected window of %s, got %s", time.Hour, end.Sub(start))
+			}
+		})
+	}
+}
+
+func TestGetWeeklyWindowMalformed(t *testing.T) {
+	from := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, applyFrom := range []string{"", "Mon", "Mon02:00", "Mon 02", "Foo 02:00", "Mon 02:00 extra", "Mon aa:00"} {
+		t.Run(applyFrom, func(t *testing.T) {
+			if _, _, err := GetWeeklyWindow(from, applyFrom, time.Hour, time.UTC); err == nil {
+				t.Fatalf("expected error for malformed window %q", applyFrom)
+			}
+		})
+	}
+}
+
+func TestGetWeeklyWindowTimeZone(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name          string
+		From          time.Time
+		ApplyFrom     string
+		ExpectedStart time.Time
+	}{
+		{
+			Name:          "test window during daylight saving time",
+			From:          time.Date(2021, time.March, 25, 0, 0, 0, 0, sydney),
+			ApplyFrom:     "Mon 10:00",
+			ExpectedStart: time.Date(2021, time.March, 28, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:          "test window after the end of daylight saving time",
+			From:          time.Date(2021, time.April, 1, 0, 0, 0, 0, sydney),
+			ApplyFrom:     "Mon 10:00",
+			ExpectedStart: time.Date(2021, time.April, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			Name:          "test day of the window is the local day",
+			From:          time.Date(2021, time.March, 1, 20, 0, 0, 0, time.UTC), // Tuesday in Sydney
+			ApplyFrom:     "Tue 08:00",
+			ExpectedStart: time.Date(2021, time.March, 1, 21, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			start, end, err := GetWeeklyWindow(tc.From, tc.ApplyFrom, WindowDuration, sydney)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !start.Equal(tc.ExpectedStart) {
+				t.Fatalf("expected window start %s, got %s", tc.ExpectedStart, start.UTC())
+			}
+			if end.Sub(start) != WindowDuration {
+				t.Fatalf("expected window of %s, got %s", WindowDuration, end.Sub(start))
+			}
+		})
+	}
+}
+
+func TestTimeToUTC(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Daylight saving time in Sydney, UTC+11
+	summer := time.Date(2021, time.March, 1, 0, 0, 0, 0, time.UTC)
+	// Standard time in Sydney, UTC+10
+	winter := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	weekly, err := WeeklyTimeToUTC(summer, "Mon 01:00", sydney)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weekly != "Sun 14:00" {
+		t.Fatalf("expected Sun 14:00, got %s", weekly)
+	}
+	weekly, err = WeeklyTimeToUTC(winter, "Mon 01:00", sydney)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weekly != "Sun 15:00" {
+		t.Fatalf("expected Sun 15:00, got %s", weekly)
+	}
+
+	daily, err := DailyTimeToUTC(summer, "03:01", sydney)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily != "16:01" {
+		t.Fatalf("expected 16:01, got %s", daily)
+	}
+	daily, err = DailyTimeToUTC(winter, "03:01", sydney)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if daily != "17:01" {
+		t.Fatalf("expected 17:01, got %s", daily)
+	}
+
+	if _, err := DailyTimeToUTC(summer, "malformed", sydney); err == nil {
+		t.Fatal("expected error for a malformed time")
+	}
+}
@@ -0,0 +1,93 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	autoscalerManagedLabel = "integreatly.org/quota-autoscaler"
+)
+
+// AutoscalingConfig is the autoscaling of a workload by a quota
+type AutoscalingConfig struct {
+	MinReplicas int32 `json:"min_replicas"`
+	MaxReplicas int32 `json:"max_replicas"`
+	// TargetCPUUtilization is the average CPU utilization of the pods, as a
+	// percentage of their requests
+	TargetCPUUtilization int32 `json:"target_cpu_utilization"`
+}
+
+// clamp returns the replicas within the bounds of the autoscaling
+func (a *AutoscalingConfig) clamp(replicas int32) int32 {
+	if replicas < a.MinReplicas {
+		return a.MinReplicas
+	}
+	if replicas > a.MaxReplicas {
+		return a.MaxReplicas
+	}
+	return replicas
+}
+
+// ReconcileAutoscaler reconciles the HorizontalPodAutoscaler of the workload
+// ddcssName, named after and scaling the target. The autoscaler is removed
+// when the quota doesn't autoscale the workload.
+//
+// It returns the replicas desired by the autoscaler, within the bounds of
+// the quota, or 0 if the workload isn't autoscaled. The replicas of
+// workloads managed through a custom resource, such as the APIManager, are
+// set to them so their operator doesn't revert the autoscaler
+func ReconcileAutoscaler(ctx context.Context, client k8sclient.Client, productConfig ProductConfig, ddcssName, namespace string, target autoscalingv1.CrossVersionObjectReference) (int32, error) {
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      target.Name,
+			Namespace: namespace,
+		},
+	}
+
+	autoscaling, ok := productConfig.GetAutoscaling(ddcssName)
+	if !ok {
+		err := client.Get(ctx, k8sclient.ObjectKey{Name: hpa.Name, Namespace: hpa.Namespace}, hpa)
+		if k8serr.IsNotFound(err) {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get autoscaler %s: %w", hpa.Name, err)
+		}
+		if hpa.Labels[autoscalerManagedLabel] != ddcssName {
+			return 0, nil
+		}
+		if err := client.Delete(ctx, hpa); err != nil && !k8serr.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete autoscaler %s: %w", hpa.Name, err)
+		}
+		return 0, nil
+	}
+
+	_, err := controllerutil.CreateOrUpdate(ctx, client, hpa, func() error {
+		if hpa.Labels == nil {
+			hpa.Labels = map[string]string{}
+		}
+		hpa.Labels[autoscalerManagedLabel] = ddcssName
+
+		minReplicas := autoscaling.MinReplicas
+		targetCPUUtilization := autoscaling.TargetCPUUtilization
+		hpa.Spec = autoscalingv1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef:                 target,
+			MinReplicas:                    &minReplicas,
+			MaxReplicas:                    autoscaling.MaxReplicas,
+			TargetCPUUtilizationPercentage: &targetCPUUtilization,
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to reconcile autoscaler %s: %w", hpa.Name, err)
+	}
+
+	return autoscaling.clamp(hpa.Status.DesiredReplicas), nil
+}
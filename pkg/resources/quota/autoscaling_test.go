@@ -0,0 +1,143 @@
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileAutoscaler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := autoscalingv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	target := autoscalingv1.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "ratelimit"}
+	autoscaled := QuotaProductConfig{
+		productName: v1alpha1.ProductMarin3r,
+		resourceConfigs: map[string]ResourceConfig{
+			RateLimitName: {Autoscaling: &AutoscalingConfig{MinReplicas: 2, MaxReplicas: 4, TargetCPUUtilization: 80}},
+		},
+		quota: &Quota{},
+	}
+	static := QuotaProductConfig{
+		productName: v1alpha1.ProductMarin3r,
+		resourceConfigs: map[string]ResourceConfig{
+			RateLimitName: {Replicas: 3},
+		},
+		quota: &Quota{},
+	}
+	hpa := func(label string, desiredReplicas int32) *autoscalingv1.HorizontalPodAutoscaler {
+		h := &autoscalingv1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{Name: "ratelimit", Namespace: "test"},
+			Status:     autoscalingv1.HorizontalPodAutoscalerStatus{DesiredReplicas: desiredReplicas},
+		}
+		if label != "" {
+			h.Labels = map[string]string{autoscalerManagedLabel: label}
+		}
+		return h
+	}
+
+	cases := []struct {
+		Name             string
+		ProductConfig    QuotaProductConfig
+		Objects          []runtime.Object
+		ExpectedReplicas int32
+		ExpectedExists   bool
+	}{
+		{
+			Name:             "test autoscaler is created",
+			ProductConfig:    autoscaled,
+			ExpectedReplicas: 2,
+			ExpectedExists:   true,
+		},
+		{
+			Name:             "test desired replicas are within the bounds of the quota",
+			ProductConfig:    autoscaled,
+			Objects:          []runtime.Object{hpa(RateLimitName, 7)},
+			ExpectedReplicas: 4,
+			ExpectedExists:   true,
+		},
+		{
+			Name:          "test autoscaler is removed with static replicas",
+			ProductConfig: static,
+			Objects:       []runtime.Object{hpa(RateLimitName, 3)},
+		},
+		{
+			Name:           "test autoscaler not created by the quota is kept",
+			ProductConfig:  static,
+			Objects:        []runtime.Object{hpa("", 3)},
+			ExpectedExists: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, tc.Objects...)
+
+			replicas, err := ReconcileAutoscaler(context.TODO(), client, tc.ProductConfig, RateLimitName, "test", target)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if replicas != tc.ExpectedReplicas {
+				t.Fatalf("expected %d desired replicas, got %d", tc.ExpectedReplicas, replicas)
+			}
+
+			found := &autoscalingv1.HorizontalPodAutoscaler{}
+			err = client.Get(context.TODO(), k8sclient.ObjectKey{Name: "ratelimit", Namespace: "test"}, found)
+			if err != nil && !k8serr.IsNotFound(err) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if exists := err == nil; exists != tc.ExpectedExists {
+				t.Fatalf("expected autoscaler to exist %t, got %t", tc.ExpectedExists, exists)
+			}
+			if tc.ExpectedExists && tc.ProductConfig.resourceConfigs[RateLimitName].Autoscaling != nil {
+				if found.Spec.ScaleTargetRef != target || *found.Spec.MinReplicas != 2 || found.Spec.MaxReplicas != 4 || *found.Spec.TargetCPUUtilizationPercentage != 80 {
+					t.Fatalf("unexpected autoscaler spec %+v", found.Spec)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigureAutoscaledReplicas(t *testing.T) {
+	productConfig := QuotaProductConfig{
+		productName: v1alpha1.ProductMarin3r,
+		resourceConfigs: map[string]ResourceConfig{
+			RateLimitName: {Replicas: 3, Autoscaling: &AutoscalingConfig{MinReplicas: 2, MaxReplicas: 4, TargetCPUUtilization: 80}},
+		},
+		quota: &Quota{isUpdated: true},
+	}
+
+	cases := []struct {
+		Replicas         *int32
+		ExpectedReplicas int32
+	}{
+		{Replicas: nil, ExpectedReplicas: 2},
+		{Replicas: &[]int32{1}[0], ExpectedReplicas: 2},
+		{Replicas: &[]int32{4}[0], ExpectedReplicas: 4},
+		{Replicas: &[]int32{6}[0], ExpectedReplicas: 4},
+	}
+
+	for _, tc := range cases {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: RateLimitName},
+			Spec:       appsv1.DeploymentSpec{Replicas: tc.Replicas},
+		}
+		if err := productConfig.Configure(deployment); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if *deployment.Spec.Replicas != tc.ExpectedReplicas {
+			t.Fatalf("expected %d replicas, got %d", tc.ExpectedReplicas, *deployment.Spec.Replicas)
+		}
+	}
+}
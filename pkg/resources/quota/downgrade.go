@@ -0,0 +1,258 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+	"github.com/integr8ly/integreatly-operator/pkg/resources/ratelimit"
+
+	"github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DowngradeAcknowledgedAnnotation on the installation applies the
+	// downgrade to the quota it names in spite of the usage of the APIs
+	DowngradeAcknowledgedAnnotation = "integreatly.org/quota-downgrade-acknowledged"
+
+	// DowngradeStepInterval is the time between the steps of the scale down
+	// of a downgrade. Each step removes a replica from each workload
+	DowngradeStepInterval = 5 * time.Minute
+
+	// DowngradeUsagePeriod is the period of the usage of the APIs compared
+	// to the rate limit of the smaller quota
+	DowngradeUsagePeriod = time.Hour
+)
+
+// UsageDescriptor is the descriptor entry of the rate limit service whose
+// requests are counted, e.g. generic_key=slowpath for every request to the
+// APIs
+type UsageDescriptor struct {
+	Key   string
+	Value string
+}
+
+// GlobalUsageDescriptor counts every request to the APIs
+var GlobalUsageDescriptor = UsageDescriptor{Key: "generic_key", Value: ratelimit.RateLimitDescriptorValue}
+
+// invalidMetricChars are escaped to _ by the statsd exporter
+var invalidMetricChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// RequestsMetric returns the metric of the requests counted by the rate limit
+// service for the descriptor, as exported from its statsd metrics
+func (d UsageDescriptor) RequestsMetric() string {
+	metric := fmt.Sprintf("ratelimit_service_rate_limit_%s_%s_%s_total_hits", ratelimit.RateLimitDomain, d.Key, d.Value)
+	return invalidMetricChars.ReplaceAllString(metric, "_")
+}
+
+// UsageGetter reads the usage of the APIs
+type UsageGetter interface {
+	// PeakRequests returns the highest number of requests counted for the
+	// descriptor in a window of the duration over the period
+	PeakRequests(ctx context.Context, descriptor UsageDescriptor, window, period time.Duration) (float64, error)
+}
+
+// PrometheusUsageGetter reads the usage of the APIs from the rate limit
+// metrics scraped by Prometheus
+type PrometheusUsageGetter struct {
+	API prometheusv1.API
+}
+
+var _ UsageGetter = &PrometheusUsageGetter{}
+
+// NewPrometheusUsageGetter returns a UsageGetter querying the Prometheus at
+// the address
+func NewPrometheusUsageGetter(address string) (*PrometheusUsageGetter, error) {
+	client, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create prometheus client for %s: %w", address, err)
+	}
+	return &PrometheusUsageGetter{API: prometheusv1.NewAPI(client)}, nil
+}
+
+func (g *PrometheusUsageGetter) PeakRequests(ctx context.Context, descriptor UsageDescriptor, window, period time.Duration) (float64, error) {
+	query := fmt.Sprintf("max_over_time(sum(increase(%s[%s]))[%s:1m])", descriptor.RequestsMetric(), model.Duration(window), model.Duration(period))
+	value, _, err := g.API.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to query the usage of the APIs: %w", err)
+	}
+
+	vector, ok := value.(model.Vector)
+	if !ok {
+		return 0, fmt.Errorf("unexpected result of the usage of the APIs: %s", value.Type())
+	}
+	if len(vector) == 0 {
+		return 0, nil
+	}
+	return float64(vector[0].Value), nil
+}
+
+// usageWindows are the durations of the units of the rate limits. The usage
+// is scraped every few seconds, so the limits per second are checked
+// against the usage in a minute
+var usageWindows = map[string]time.Duration{
+	marin3rconfig.Second: time.Minute,
+	marin3rconfig.Minute: time.Minute,
+	marin3rconfig.Hour:   time.Hour,
+	marin3rconfig.Day:    24 * time.Hour,
+}
+
+// usageUnits are the units of the usageWindows
+var usageUnits = map[string]string{
+	marin3rconfig.Second: marin3rconfig.Minute,
+	marin3rconfig.Minute: marin3rconfig.Minute,
+	marin3rconfig.Hour:   marin3rconfig.Hour,
+	marin3rconfig.Day:    marin3rconfig.Day,
+}
+
+// CheckDowngrade compares the usage of the APIs with the rate limit of the
+// quota to, and returns the outcome of the change from the quota from. It
+// returns nil if the rate limit of the quota to isn't smaller.
+//
+// Each window of the rate limit of the quota to is compared with the peak
+// usage in a window of its unit over DowngradeUsagePeriod. The downgrade is
+// blocked if the usage exceeds the limit of a window, or can't be read, e.g.
+// as Prometheus is unreachable, unless it's acknowledged
+func CheckDowngrade(ctx context.Context, client k8sclient.Client, quotaConfig *corev1.ConfigMap, from, to string, usage UsageGetter, acknowledged bool, now time.Time) (*v1alpha1.QuotaDowngradeStatus, error) {
+	fromConfig, err := findQuotaConfig(ctx, client, from, quotaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota %s: %w", from, err)
+	}
+	toConfig, err := findQuotaConfig(ctx, client, to, quotaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota %s: %w", to, err)
+	}
+
+	fromLimit, err := requestsPerMinute(fromConfig.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	toLimit, err := requestsPerMinute(toConfig.RateLimit)
+	if err != nil {
+		return nil, err
+	}
+	if toLimit >= fromLimit {
+		return nil, nil
+	}
+
+	status := &v1alpha1.QuotaDowngradeStatus{
+		From:           from,
+		To:             to,
+		LimitPerMinute: int64(toLimit),
+	}
+
+	exceeded, err := exceededWindows(ctx, usage, toConfig.RateLimit, status)
+	switch {
+	case err != nil:
+		status.Message = fmt.Sprintf("the usage of the APIs could not be read: %v", err)
+	case len(exceeded) > 0:
+		status.Message = fmt.Sprintf("the peak usage of the APIs in the last %s exceeds the rate limit of quota %s: %s",
+			DowngradeUsagePeriod, to, strings.Join(exceeded, ", "))
+	}
+
+	switch {
+	case status.Message == "":
+		status.Phase = v1alpha1.PhaseInProgress
+	case acknowledged:
+		status.Phase = v1alpha1.PhaseInProgress
+		status.Acknowledged = true
+		status.Message = "downgrade acknowledged: " + status.Message
+	default:
+		status.Phase = v1alpha1.PhaseBlocked
+		status.Message = fmt.Sprintf("%s. Set the %s annotation to %s to downgrade regardless",
+			status.Message, DowngradeAcknowledgedAnnotation, to)
+		return status, nil
+	}
+
+	start := metav1.NewTime(now)
+	status.StartTime = &start
+	return status, nil
+}
+
+// exceededWindows returns the windows of the rate limit whose limit is
+// exceeded by the peak usage of the APIs, and sets the peak usage in a
+// minute in the status
+func exceededWindows(ctx context.Context, usage UsageGetter, rateLimit marin3rconfig.RateLimitConfig, status *v1alpha1.QuotaDowngradeStatus) ([]string, error) {
+	peakPerMinute, err := usage.PeakRequests(ctx, GlobalUsageDescriptor, time.Minute, DowngradeUsagePeriod)
+	if err != nil {
+		return nil, err
+	}
+	status.PeakRequestsPerMinute = int64(math.Ceil(peakPerMinute))
+
+	exceeded := []string{}
+	for _, window := range rateLimit.AllWindows() {
+		duration, ok := usageWindows[window.Unit]
+		if !ok {
+			return nil, fmt.Errorf("unknown rate limit unit %s", window.Unit)
+		}
+		unit := usageUnits[window.Unit]
+		limit, err := marin3rconfig.ConvertRate(window.Unit, unit, int(window.RequestsPerUnit))
+		if err != nil {
+			return nil, err
+		}
+
+		peak := peakPerMinute
+		if duration != time.Minute {
+			// Every request is counted by the global descriptor, including
+			// the requests limited by the windows
+			peak, err = usage.PeakRequests(ctx, GlobalUsageDescriptor, duration, DowngradeUsagePeriod)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if peak > limit {
+			exceeded = append(exceeded, fmt.Sprintf("%d requests per %s, the limit is %d per %s",
+				int64(math.Ceil(peak)), unit, int64(limit), unit))
+		}
+	}
+	return exceeded, nil
+}
+
+// StageScaleDown removes the replicas of the workloads scaled down by the
+// downgrade in steps: each workload loses one replica per
+// DowngradeStepInterval since the downgrade started, until it reaches the
+// replicas of the quota. It returns true once every workload reached them
+func (s *Quota) StageScaleDown(ctx context.Context, client k8sclient.Client, quotaConfig *corev1.ConfigMap, downgrade *v1alpha1.QuotaDowngradeStatus, now time.Time) (bool, error) {
+	fromConfig, err := findQuotaConfig(ctx, client, downgrade.From, quotaConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to read quota %s: %w", downgrade.From, err)
+	}
+
+	steps := int32(1)
+	if downgrade.StartTime != nil {
+		steps += int32(now.Sub(downgrade.StartTime.Time) / DowngradeStepInterval)
+	}
+
+	done := true
+	for _, pc := range s.productConfigs {
+		for name, config := range pc.resourceConfigs {
+			fromReplicas := fromConfig.Resources[name].Replicas
+			if fromReplicas-steps > config.Replicas {
+				config.Replicas = fromReplicas - steps
+				pc.resourceConfigs[name] = config
+				done = false
+			}
+		}
+	}
+
+	// The staged replicas are applied as the replicas of an updated quota
+	s.isUpdated = true
+	return done, nil
+}
+
+// requestsPerMinute returns the sustained rate allowed by the rate limit,
+// the lowest rate of its windows
+func requestsPerMinute(rateLimit marin3rconfig.RateLimitConfig) (float64, error) {
+	return rateLimit.LowestRate(marin3rconfig.Minute)
+}
@@ -0,0 +1,238 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type usageGetterMock struct {
+	// peaks are the peak requests by the duration of the window, the
+	// windows without a peak have the peak of a minute
+	peaks map[time.Duration]float64
+	peak  float64
+	err   error
+}
+
+func (m *usageGetterMock) PeakRequests(_ context.Context, _ UsageDescriptor, window, _ time.Duration) (float64, error) {
+	if peak, ok := m.peaks[window]; ok {
+		return peak, m.err
+	}
+	return m.peak, m.err
+}
+
+const windowedQuota = "windowed"
+
+func TestCheckDowngrade(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		Name                 string
+		From                 string
+		To                   string
+		Usage                *usageGetterMock
+		Acknowledged         bool
+		ExpectedPhase        v1alpha1.StatusPhase
+		ExpectedAcknowledged bool
+		ExpectedMessage      string
+		ExpectedPeak         int64
+	}{
+		{
+			Name:  "test upgrade is not checked",
+			From:  DEVQUOTA,
+			To:    TWENTYMILLIONQUOTA,
+			Usage: &usageGetterMock{peak: 1000},
+		},
+		{
+			Name:          "test downgrade within the usage starts",
+			From:          TWENTYMILLIONQUOTA,
+			To:            DEVQUOTA,
+			Usage:         &usageGetterMock{peak: 0.4},
+			ExpectedPhase: v1alpha1.PhaseInProgress,
+			ExpectedPeak:  1,
+		},
+		{
+			Name:            "test downgrade exceeding the usage is blocked",
+			From:            TWENTYMILLIONQUOTA,
+			To:              DEVQUOTA,
+			Usage:           &usageGetterMock{peak: 120},
+			ExpectedPhase:   v1alpha1.PhaseBlocked,
+			ExpectedMessage: "exceeds the rate limit of quota 1: 120 requests per minute, the limit is 1 per minute",
+			ExpectedPeak:    120,
+		},
+		{
+			Name:                 "test acknowledged downgrade exceeding the usage starts",
+			From:                 TWENTYMILLIONQUOTA,
+			To:                   DEVQUOTA,
+			Usage:                &usageGetterMock{peak: 120},
+			Acknowledged:         true,
+			ExpectedPhase:        v1alpha1.PhaseInProgress,
+			ExpectedAcknowledged: true,
+			ExpectedMessage:      "downgrade acknowledged",
+			ExpectedPeak:         120,
+		},
+		{
+			Name:            "test downgrade is blocked when the usage can't be read",
+			From:            TWENTYMILLIONQUOTA,
+			To:              DEVQUOTA,
+			Usage:           &usageGetterMock{err: errors.New("connection refused")},
+			ExpectedPhase:   v1alpha1.PhaseBlocked,
+			ExpectedMessage: "could not be read: connection refused. Set the " + DowngradeAcknowledgedAnnotation,
+		},
+		{
+			Name:                 "test acknowledged downgrade starts when the usage can't be read",
+			From:                 TWENTYMILLIONQUOTA,
+			To:                   DEVQUOTA,
+			Usage:                &usageGetterMock{err: errors.New("connection refused")},
+			Acknowledged:         true,
+			ExpectedPhase:        v1alpha1.PhaseInProgress,
+			ExpectedAcknowledged: true,
+			ExpectedMessage:      "downgrade acknowledged: the usage of the APIs could not be read",
+		},
+		{
+			Name:            "test downgrade exceeding the usage of a window is blocked",
+			From:            TWENTYMILLIONQUOTA,
+			To:              windowedQuota,
+			Usage:           &usageGetterMock{peak: 50, peaks: map[time.Duration]float64{24 * time.Hour: 5000}},
+			ExpectedPhase:   v1alpha1.PhaseBlocked,
+			ExpectedMessage: "5000 requests per day, the limit is 1000 per day",
+			ExpectedPeak:    50,
+		},
+		{
+			Name:            "test downgrade exceeding the usage of a window per second is blocked",
+			From:            TWENTYMILLIONQUOTA,
+			To:              windowedQuota,
+			Usage:           &usageGetterMock{peak: 150, peaks: map[time.Duration]float64{24 * time.Hour: 500}},
+			ExpectedPhase:   v1alpha1.PhaseBlocked,
+			ExpectedMessage: "150 requests per minute, the limit is 120 per minute",
+			ExpectedPeak:    150,
+		},
+		{
+			Name:          "test downgrade within the usage of every window starts",
+			From:          TWENTYMILLIONQUOTA,
+			To:            windowedQuota,
+			Usage:         &usageGetterMock{peak: 50, peaks: map[time.Duration]float64{24 * time.Hour: 500}},
+			ExpectedPhase: v1alpha1.PhaseInProgress,
+			ExpectedPeak:  50,
+		},
+	}
+
+	// windowedQuota allows 100 requests per minute, 2 per second and 1000
+	// per day
+	windowed := &v1alpha1.QuotaProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: windowedQuota},
+		Spec: v1alpha1.QuotaProfileSpec{
+			RateLimit: v1alpha1.QuotaProfileRateLimit{
+				Unit:            "minute",
+				RequestsPerUnit: 100,
+				Windows: []v1alpha1.QuotaProfileRateLimitWindow{
+					{Unit: "second", RequestsPerUnit: 2},
+					{Unit: "day", RequestsPerUnit: 1000},
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, windowed.DeepCopy())
+			now := time.Now()
+
+			status, err := CheckDowngrade(context.TODO(), client, getQuotaConfig(nil), tc.From, tc.To, tc.Usage, tc.Acknowledged, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.ExpectedPhase == "" {
+				if status != nil {
+					t.Fatalf("expected no downgrade, got %+v", status)
+				}
+				return
+			}
+
+			if status.Phase != tc.ExpectedPhase || status.Acknowledged != tc.ExpectedAcknowledged {
+				t.Fatalf("expected phase %s and acknowledged %t, got %+v", tc.ExpectedPhase, tc.ExpectedAcknowledged, status)
+			}
+			if status.PeakRequestsPerMinute != tc.ExpectedPeak {
+				t.Fatalf("expected a peak of %d requests per minute, got %d", tc.ExpectedPeak, status.PeakRequestsPerMinute)
+			}
+			if !strings.Contains(status.Message, tc.ExpectedMessage) {
+				t.Fatalf("expected message containing %q, got %q", tc.ExpectedMessage, status.Message)
+			}
+			if (status.StartTime != nil) != (tc.ExpectedPhase == v1alpha1.PhaseInProgress) {
+				t.Fatalf("expected the start time to be set once the downgrade starts, got %+v", status)
+			}
+		})
+	}
+}
+
+func TestStageScaleDown(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewFakeClientWithScheme(scheme)
+
+	started := time.Now()
+	downgrade := &v1alpha1.QuotaDowngradeStatus{
+		From:      TWENTYMILLIONQUOTA,
+		To:        DEVQUOTA,
+		Phase:     v1alpha1.PhaseInProgress,
+		StartTime: &metav1.Time{Time: started},
+	}
+
+	steps := []struct {
+		after            time.Duration
+		expectedReplicas int32
+		expectedDone     bool
+	}{
+		{after: 0, expectedReplicas: 2},
+		{after: DowngradeStepInterval, expectedReplicas: 1},
+		{after: 2 * DowngradeStepInterval, expectedReplicas: 0, expectedDone: true},
+	}
+
+	for i, step := range steps {
+		quota := &Quota{}
+		if err := GetQuota(context.TODO(), client, DEVQUOTA, getQuotaConfig(nil), quota, false); err != nil {
+			t.Fatal(err)
+		}
+
+		done, err := quota.StageScaleDown(context.TODO(), client, getQuotaConfig(nil), downgrade, started.Add(step.after))
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		if done != step.expectedDone {
+			t.Fatalf("step %d: expected done %t, got %t", i, step.expectedDone, done)
+		}
+		if !quota.IsUpdated() {
+			t.Fatalf("step %d: expected the staged replicas to be applied", i)
+		}
+		if replicas := quota.GetProduct(v1alpha1.Product3Scale).GetReplicas(BackendListenerName); replicas != step.expectedReplicas {
+			t.Fatalf("step %d: expected %d backend listener replicas, got %d", i, step.expectedReplicas, replicas)
+		}
+		// Workloads that aren't scaled down keep the replicas of the quota
+		if replicas := quota.GetProduct(v1alpha1.Product3Scale).GetReplicas(ApicastProductionName); replicas != 1 {
+			t.Fatalf("step %d: expected 1 apicast production replica, got %d", i, replicas)
+		}
+	}
+}
+
+func TestUsageDescriptorRequestsMetric(t *testing.T) {
+	if metric := GlobalUsageDescriptor.RequestsMetric(); metric != "ratelimit_service_rate_limit_apicast_ratelimit_generic_key_slowpath_total_hits" {
+		t.Fatalf("unexpected metric of the global rate limit %s", metric)
+	}
+	descriptor := UsageDescriptor{Key: "header_match", Value: "tenant-a"}
+	if metric := descriptor.RequestsMetric(); metric != "ratelimit_service_rate_limit_apicast_ratelimit_header_match_tenant_a_total_hits" {
+		t.Fatalf("unexpected metric of the descriptor %s", metric)
+	}
+}
@@ -0,0 +1,287 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// PreviewAnnotation on the installation requests a preview of the change
+	// to the quota it names, without applying it
+	PreviewAnnotation = "integreatly.org/quota-preview"
+
+	// CapacityAcknowledgedAnnotation on the installation applies the change
+	// to the quota it names, although the cluster doesn't have the capacity
+	// for it
+	CapacityAcknowledgedAnnotation = "integreatly.org/quota-capacity-acknowledged"
+
+	// PreviewRefreshInterval is the time after which the preview of a
+	// pending change is computed again, as the capacity of the cluster
+	// changes
+	PreviewRefreshInterval = 15 * time.Minute
+)
+
+// PreviewChange computes the impact of changing the quota of the
+// installation from the quota from to the quota to: the replicas and
+// resource requests of each workload, the rate limit, and whether the
+// schedulable nodes of the cluster have the capacity for the new requests.
+// The requests of each pod are the requests of the quota, as the quota is
+// applied to a single container per pod
+func PreviewChange(ctx context.Context, client k8sclient.Client, quotaConfig *corev1.ConfigMap, from, to string, now time.Time) (*v1alpha1.QuotaPreview, error) {
+	toConfig, err := findQuotaConfig(ctx, client, to, quotaConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read quota %s: %w", to, err)
+	}
+	fromConfig := quotaConfigReceiver{}
+	if from != "" {
+		fromConfig, err = findQuotaConfig(ctx, client, from, quotaConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read quota %s: %w", from, err)
+		}
+	}
+
+	preview := &v1alpha1.QuotaPreview{
+		From:        from,
+		To:          to,
+		GeneratedAt: metav1.NewTime(now),
+		Components:  []v1alpha1.QuotaComponentChange{},
+	}
+
+	requiredCPU := resource.Quantity{}
+	requiredMemory := resource.Quantity{}
+	// largestPod is the largest pod added or grown by the change, it has to
+	// fit in a single node
+	largestPod := corev1.ResourceList{}
+
+	productNames := make([]string, 0, len(products))
+	for product := range products {
+		productNames = append(productNames, string(product))
+	}
+	sort.Strings(productNames)
+
+	for _, product := range productNames {
+		for _, name := range products[v1alpha1.ProductName(product)] {
+			fromResources := fromConfig.Resources[name]
+			toResources := toConfig.Resources[name]
+
+			fromCPU, fromMemory := requests(fromResources)
+			toCPU, toMemory := requests(toResources)
+			if fromResources.Replicas == toResources.Replicas && fromCPU.Cmp(toCPU) == 0 && fromMemory.Cmp(toMemory) == 0 {
+				continue
+			}
+
+			preview.Components = append(preview.Components, v1alpha1.QuotaComponentChange{
+				Product:      v1alpha1.ProductName(product),
+				Name:         name,
+				FromReplicas: fromResources.Replicas,
+				ToReplicas:   toResources.Replicas,
+				FromCPU:      fromCPU.String(),
+				ToCPU:        toCPU.String(),
+				FromMemory:   fromMemory.String(),
+				ToMemory:     toMemory.String(),
+			})
+
+			requiredCPU.Add(total(toCPU, toResources.Replicas))
+			requiredCPU.Sub(total(fromCPU, fromResources.Replicas))
+			requiredMemory.Add(total(toMemory, toResources.Replicas))
+			requiredMemory.Sub(total(fromMemory, fromResources.Replicas))
+
+			if toResources.Replicas > 0 && (toResources.Replicas > fromResources.Replicas || toCPU.Cmp(fromCPU) > 0 || toMemory.Cmp(fromMemory) > 0) {
+				largestPod = maxResources(largestPod, corev1.ResourceList{corev1.ResourceCPU: toCPU, corev1.ResourceMemory: toMemory})
+			}
+		}
+	}
+
+	if rateLimit(fromConfig.RateLimit) != rateLimit(toConfig.RateLimit) {
+		preview.RateLimit = &v1alpha1.QuotaRateLimitChange{
+			To: rateLimit(toConfig.RateLimit),
+		}
+		if from != "" {
+			preview.RateLimit.From = rateLimit(fromConfig.RateLimit)
+		}
+	}
+
+	capacity, err := clusterCapacity(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	preview.Capacity = capacity.check(requiredCPU, requiredMemory, largestPod)
+
+	return preview, nil
+}
+
+// nodeCapacity is the allocatable and requested resources of the
+// schedulable nodes
+type nodeCapacity struct {
+	allocatable corev1.ResourceList
+	requested   corev1.ResourceList
+	// free are the resources not requested in each node
+	free []corev1.ResourceList
+}
+
+// clusterCapacity sums the allocatable resources of the nodes that accept
+// pods, and the requests of the pods running on them
+func clusterCapacity(ctx context.Context, client k8sclient.Client) (*nodeCapacity, error) {
+	nodes := &corev1.NodeList{}
+	if err := client.List(ctx, nodes); err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods := &corev1.PodList{}
+	if err := client.List(ctx, pods); err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	requestedByNode := map[string]corev1.ResourceList{}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			requestedByNode[pod.Spec.NodeName] = addResources(requestedByNode[pod.Spec.NodeName], container.Resources.Requests)
+		}
+	}
+
+	capacity := &nodeCapacity{allocatable: corev1.ResourceList{}, requested: corev1.ResourceList{}}
+	for _, node := range nodes.Items {
+		if !schedulable(node) {
+			continue
+		}
+		requested := requestedByNode[node.Name]
+		capacity.allocatable = addResources(capacity.allocatable, node.Status.Allocatable)
+		capacity.requested = addResources(capacity.requested, requested)
+
+		free := corev1.ResourceList{}
+		for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+			quantity := node.Status.Allocatable[name].DeepCopy()
+			quantity.Sub(requested[name])
+			free[name] = quantity
+		}
+		capacity.free = append(capacity.free, free)
+	}
+	return capacity, nil
+}
+
+// check returns whether the required resources fit in the free resources
+// of the nodes, and the largest pod in a single node
+func (c *nodeCapacity) check(requiredCPU, requiredMemory resource.Quantity, largestPod corev1.ResourceList) v1alpha1.QuotaCapacity {
+	allocatableCPU := c.allocatable[corev1.ResourceCPU]
+	allocatableMemory := c.allocatable[corev1.ResourceMemory]
+	requestedCPU := c.requested[corev1.ResourceCPU]
+	requestedMemory := c.requested[corev1.ResourceMemory]
+
+	result := v1alpha1.QuotaCapacity{
+		AllocatableCPU:    allocatableCPU.String(),
+		AllocatableMemory: allocatableMemory.String(),
+		RequestedCPU:      requestedCPU.String(),
+		RequestedMemory:   requestedMemory.String(),
+		RequiredCPU:       requiredCPU.String(),
+		RequiredMemory:    requiredMemory.String(),
+		Fits:              true,
+	}
+
+	freeCPU := allocatableCPU.DeepCopy()
+	freeCPU.Sub(requestedCPU)
+	freeMemory := allocatableMemory.DeepCopy()
+	freeMemory.Sub(requestedMemory)
+
+	problems := []string{}
+	if requiredCPU.Cmp(freeCPU) > 0 {
+		problems = append(problems, fmt.Sprintf("the change requires %s CPU, %s is free", requiredCPU.String(), freeCPU.String()))
+	}
+	if requiredMemory.Cmp(freeMemory) > 0 {
+		problems = append(problems, fmt.Sprintf("the change requires %s memory, %s is free", requiredMemory.String(), freeMemory.String()))
+	}
+	if len(largestPod) > 0 && !fitsInANode(largestPod, c.free) {
+		cpu := largestPod[corev1.ResourceCPU]
+		memory := largestPod[corev1.ResourceMemory]
+		problems = append(problems, fmt.Sprintf("no node has %s CPU and %s memory free for the largest pod", cpu.String(), memory.String()))
+	}
+
+	if len(problems) > 0 {
+		result.Fits = false
+		result.Message = strings.Join(problems, "; ")
+	}
+	return result
+}
+
+func fitsInANode(pod corev1.ResourceList, free []corev1.ResourceList) bool {
+	for _, node := range free {
+		if node.Cpu().Cmp(*pod.Cpu()) >= 0 && node.Memory().Cmp(*pod.Memory()) >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// schedulable returns false for the nodes that don't accept the pods of the
+// products, such as cordoned or master nodes
+func schedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
+			return false
+		}
+	}
+	return true
+}
+
+func requests(config ResourceConfig) (resource.Quantity, resource.Quantity) {
+	return config.Resources.Requests[corev1.ResourceCPU], config.Resources.Requests[corev1.ResourceMemory]
+}
+
+// total returns the requests of the given number of replicas
+func total(quantity resource.Quantity, replicas int32) resource.Quantity {
+	result := resource.Quantity{}
+	for i := int32(0); i < replicas; i++ {
+		result.Add(quantity)
+	}
+	return result
+}
+
+func addResources(list, add corev1.ResourceList) corev1.ResourceList {
+	if list == nil {
+		list = corev1.ResourceList{}
+	}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		quantity := list[name].DeepCopy()
+		quantity.Add(add[name])
+		list[name] = quantity
+	}
+	return list
+}
+
+func maxResources(a, b corev1.ResourceList) corev1.ResourceList {
+	result := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		quantity := b[name]
+		if quantity.Cmp(a[name]) < 0 {
+			quantity = a[name]
+		}
+		result[name] = quantity
+	}
+	return result
+}
+
+func rateLimit(config marin3rconfig.RateLimitConfig) string {
+	windows := []string{}
+	for _, window := range config.AllWindows() {
+		windows = append(windows, fmt.Sprintf("%d/%s", window.RequestsPerUnit, window.Unit))
+	}
+	if config.ShadowMode {
+		return fmt.Sprintf("%s (shadow mode)", strings.Join(windows, " + "))
+	}
+	return strings.Join(windows, " + ")
+}
@@ -0,0 +1,160 @@
+package quota
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPreviewChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	node := func(name, cpu, memory string, modifyFn func(*corev1.Node)) runtime.Object {
+		n := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+		if modifyFn != nil {
+			modifyFn(n)
+		}
+		return n
+	}
+	pod := func(name, nodeName, cpu string) runtime.Object {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: corev1.PodSpec{
+				NodeName: nodeName,
+				Containers: []corev1.Container{{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)},
+					},
+				}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+
+	cases := []struct {
+		Name            string
+		From            string
+		Objects         []runtime.Object
+		ExpectedFits    bool
+		ExpectedMessage string
+	}{
+		{
+			Name:         "test change fits in the cluster",
+			From:         DEVQUOTA,
+			Objects:      []runtime.Object{node("worker", "4", "8Gi", nil)},
+			ExpectedFits: true,
+		},
+		{
+			Name:            "test change requires more CPU than free",
+			From:            DEVQUOTA,
+			Objects:         []runtime.Object{node("worker", "4", "8Gi", nil), pod("running", "worker", "3500m")},
+			ExpectedMessage: "the change requires 700m CPU, 500m is free",
+		},
+		{
+			Name: "test pod doesn't fit in a single node",
+			From: DEVQUOTA,
+			Objects: []runtime.Object{
+				node("worker-a", "200m", "8Gi", nil),
+				node("worker-b", "200m", "8Gi", nil),
+				node("worker-c", "200m", "8Gi", nil),
+				node("worker-d", "200m", "8Gi", nil),
+			},
+			ExpectedMessage: "no node has 250m CPU and 450 memory free",
+		},
+		{
+			Name: "test unschedulable nodes are ignored",
+			From: DEVQUOTA,
+			Objects: []runtime.Object{
+				node("cordoned", "4", "8Gi", func(n *corev1.Node) { n.Spec.Unschedulable = true }),
+				node("master", "4", "8Gi", func(n *corev1.Node) {
+					n.Spec.Taints = []corev1.Taint{{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule}}
+				}),
+			},
+			ExpectedMessage: "the change requires 700m CPU, 0 is free",
+		},
+		{
+			Name:         "test initial quota",
+			Objects:      []runtime.Object{node("worker", "4", "8Gi", nil)},
+			ExpectedFits: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			client := fake.NewFakeClientWithScheme(scheme, tc.Objects...)
+			now := time.Now()
+
+			preview, err := PreviewChange(context.TODO(), client, getQuotaConfig(nil), tc.From, TWENTYMILLIONQUOTA, now)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if preview.From != tc.From || preview.To != TWENTYMILLIONQUOTA || !preview.GeneratedAt.Time.Equal(metav1.NewTime(now).Time) {
+				t.Fatalf("unexpected preview %+v", preview)
+			}
+			if preview.Capacity.Fits != tc.ExpectedFits {
+				t.Fatalf("expected fits %t, got %+v", tc.ExpectedFits, preview.Capacity)
+			}
+			if !strings.Contains(preview.Capacity.Message, tc.ExpectedMessage) {
+				t.Fatalf("expected message containing %q, got %q", tc.ExpectedMessage, preview.Capacity.Message)
+			}
+
+			expectedComponents := map[string]v1alpha1.QuotaComponentChange{
+				BackendListenerName: {Product: v1alpha1.Product3Scale, Name: BackendListenerName, ToReplicas: 3, FromCPU: "0", ToCPU: "250m", FromMemory: "0", ToMemory: "450"},
+			}
+			if tc.From == DEVQUOTA {
+				expectedComponents[ApicastProductionName] = v1alpha1.QuotaComponentChange{Product: v1alpha1.Product3Scale, Name: ApicastProductionName, FromReplicas: 1, FromCPU: "50m", ToCPU: "0", FromMemory: "50Mi", ToMemory: "0"}
+			}
+			if len(preview.Components) != len(expectedComponents) {
+				t.Fatalf("expected %d components to change, got %+v", len(expectedComponents), preview.Components)
+			}
+			for _, component := range preview.Components {
+				if component != expectedComponents[component.Name] {
+					t.Fatalf("expected change %+v, got %+v", expectedComponents[component.Name], component)
+				}
+			}
+
+			expectedRateLimit := v1alpha1.QuotaRateLimitChange{To: "347/minute"}
+			if tc.From == DEVQUOTA {
+				expectedRateLimit.From = "1/minute"
+			}
+			if preview.RateLimit == nil || *preview.RateLimit != expectedRateLimit {
+				t.Fatalf("expected rate limit change %+v, got %+v", expectedRateLimit, preview.RateLimit)
+			}
+		})
+	}
+}
+
+func TestPreviewChangeUnknownQuota(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	client := fake.NewFakeClientWithScheme(scheme)
+	if _, err := PreviewChange(context.TODO(), client, getQuotaConfig(nil), DEVQUOTA, "unknown", time.Now()); err == nil {
+		t.Fatal("expected an error previewing an unknown quota")
+	}
+}
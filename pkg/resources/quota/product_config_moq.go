@@ -26,6 +26,12 @@ var _ ProductConfig = &ProductConfigMock{}
 // 			GetActiveQuotaFunc: func() string {
 // 				panic("mock out the GetActiveQuota method")
 // 			},
+// 			GetAlertLimitsFunc: func() map[string]*marin3rconfig.AlertConfig {
+// 				panic("mock out the GetAlertLimits method")
+// 			},
+// 			GetAutoscalingFunc: func(ddcssName string) (*AutoscalingConfig, bool) {
+// 				panic("mock out the GetAutoscaling method")
+// 			},
 // 			GetRateLimitConfigFunc: func() marin3rconfig.RateLimitConfig {
 // 				panic("mock out the GetRateLimitConfig method")
 // 			},
@@ -48,6 +54,12 @@ type ProductConfigMock struct {
 	// GetActiveQuotaFunc mocks the GetActiveQuota method.
 	GetActiveQuotaFunc func() string
 
+	// GetAlertLimitsFunc mocks the GetAlertLimits method.
+	GetAlertLimitsFunc func() map[string]*marin3rconfig.AlertConfig
+
+	// GetAutoscalingFunc mocks the GetAutoscaling method.
+	GetAutoscalingFunc func(ddcssName string) (*AutoscalingConfig, bool)
+
 	// GetRateLimitConfigFunc mocks the GetRateLimitConfig method.
 	GetRateLimitConfigFunc func() marin3rconfig.RateLimitConfig
 
@@ -67,6 +79,14 @@ type ProductConfigMock struct {
 		// GetActiveQuota holds details about calls to the GetActiveQuota method.
 		GetActiveQuota []struct {
 		}
+		// GetAlertLimits holds details about calls to the GetAlertLimits method.
+		GetAlertLimits []struct {
+		}
+		// GetAutoscaling holds details about calls to the GetAutoscaling method.
+		GetAutoscaling []struct {
+			// DdcssName is the ddcssName argument value.
+			DdcssName string
+		}
 		// GetRateLimitConfig holds details about calls to the GetRateLimitConfig method.
 		GetRateLimitConfig []struct {
 		}
@@ -83,6 +103,8 @@ type ProductConfigMock struct {
 	}
 	lockConfigure          sync.RWMutex
 	lockGetActiveQuota     sync.RWMutex
+	lockGetAlertLimits     sync.RWMutex
+	lockGetAutoscaling     sync.RWMutex
 	lockGetRateLimitConfig sync.RWMutex
 	lockGetReplicas        sync.RWMutex
 	lockGetResourceConfig  sync.RWMutex
@@ -145,6 +167,63 @@ func (mock *ProductConfigMock) GetActiveQuotaCalls() []struct {
 	return calls
 }
 
+// GetAlertLimits calls GetAlertLimitsFunc.
+func (mock *ProductConfigMock) GetAlertLimits() map[string]*marin3rconfig.AlertConfig {
+	if mock.GetAlertLimitsFunc == nil {
+		panic("ProductConfigMock.GetAlertLimitsFunc: method is nil but ProductConfig.GetAlertLimits was just called")
+	}
+	callInfo := struct {
+	}{}
+	mock.lockGetAlertLimits.Lock()
+	mock.calls.GetAlertLimits = append(mock.calls.GetAlertLimits, callInfo)
+	mock.lockGetAlertLimits.Unlock()
+	return mock.GetAlertLimitsFunc()
+}
+
+// GetAlertLimitsCalls gets all the calls that were made to GetAlertLimits.
+// Check the length with:
+//     len(mockedProductConfig.GetAlertLimitsCalls())
+func (mock *ProductConfigMock) GetAlertLimitsCalls() []struct {
+} {
+	var calls []struct {
+	}
+	mock.lockGetAlertLimits.RLock()
+	calls = mock.calls.GetAlertLimits
+	mock.lockGetAlertLimits.RUnlock()
+	return calls
+}
+
+// GetAutoscaling calls GetAutoscalingFunc.
+func (mock *ProductConfigMock) GetAutoscaling(ddcssName string) (*AutoscalingConfig, bool) {
+	if mock.GetAutoscalingFunc == nil {
+		panic("ProductConfigMock.GetAutoscalingFunc: method is nil but ProductConfig.GetAutoscaling was just called")
+	}
+	callInfo := struct {
+		DdcssName string
+	}{
+		DdcssName: ddcssName,
+	}
+	mock.lockGetAutoscaling.Lock()
+	mock.calls.GetAutoscaling = append(mock.calls.GetAutoscaling, callInfo)
+	mock.lockGetAutoscaling.Unlock()
+	return mock.GetAutoscalingFunc(ddcssName)
+}
+
+// GetAutoscalingCalls gets all the calls that were made to GetAutoscaling.
+// Check the length with:
+//     len(mockedProductConfig.GetAutoscalingCalls())
+func (mock *ProductConfigMock) GetAutoscalingCalls() []struct {
+	DdcssName string
+} {
+	var calls []struct {
+		DdcssName string
+	}
+	mock.lockGetAutoscaling.RLock()
+	calls = mock.calls.GetAutoscaling
+	mock.lockGetAutoscaling.RUnlock()
+	return calls
+}
+
 // GetRateLimitConfig calls GetRateLimitConfigFunc.
 func (mock *ProductConfigMock) GetRateLimitConfig() marin3rconfig.RateLimitConfig {
 	if mock.GetRateLimitConfigFunc == nil {
@@ -1,6 +1,7 @@
 package quota
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,8 +14,10 @@ import (
 	appsv1 "github.com/openshift/api/apps/v1"
 	appsv12 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -55,6 +58,7 @@ type Quota struct {
 	productConfigs  map[v1alpha1.ProductName]QuotaProductConfig
 	isUpdated       bool
 	rateLimitConfig marin3rconfig.RateLimitConfig
+	alertLimits     map[string]*marin3rconfig.AlertConfig
 }
 
 //go:generate moq -out product_config_moq.go . ProductConfig
@@ -62,7 +66,9 @@ type ProductConfig interface {
 	Configure(obj metav1.Object) error
 	GetResourceConfig(ddcssName string) (corev1.ResourceRequirements, bool)
 	GetReplicas(ddcssName string) int32
+	GetAutoscaling(ddcssName string) (*AutoscalingConfig, bool)
 	GetRateLimitConfig() marin3rconfig.RateLimitConfig
+	GetAlertLimits() map[string]*marin3rconfig.AlertConfig
 	GetActiveQuota() string
 }
 
@@ -77,34 +83,26 @@ type QuotaProductConfig struct {
 type ResourceConfig struct {
 	Replicas  int32                       `json:"replicas,omitempty"`
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Autoscaling replaces the static replicas with a HorizontalPodAutoscaler
+	Autoscaling *AutoscalingConfig `json:"autoscaling,omitempty"`
 }
 
 type quotaConfigReceiver struct {
 	Name      string                        `json:"name,omitempty"`
 	RateLimit marin3rconfig.RateLimitConfig `json:"rate-limiting,omitempty"`
 	Resources map[string]ResourceConfig     `json:"resources,omitempty"`
+	// AlertLimits are only set by QuotaProfiles
+	AlertLimits map[string]*marin3rconfig.AlertConfig `json:"-"`
 }
 
-func GetQuota(QuotaId string, QuotaConfig *corev1.ConfigMap, retQuota *Quota, isUpdated bool) error {
-	allQuotas := &[]quotaConfigReceiver{}
-	err := json.Unmarshal([]byte(QuotaConfig.Data[ConfigMapData]), allQuotas)
+// GetQuota populates retQuota with the quota QuotaId. The quota is read from
+// the QuotaProfile of that name in the namespace of the quota config map, or
+// from the quota config map when there's none
+func GetQuota(ctx context.Context, client k8sclient.Client, QuotaId string, QuotaConfig *corev1.ConfigMap, retQuota *Quota, isUpdated bool) error {
+	quotaReceiver, err := findQuotaConfig(ctx, client, QuotaId, QuotaConfig)
 	if err != nil {
 		return err
 	}
-	quotaReceiver := quotaConfigReceiver{}
-
-	for _, quota := range *allQuotas {
-		if quota.Name == QuotaId {
-			quotaReceiver = quota
-			break
-		}
-	}
-
-	// if the quota receiver is empty at this point we haven't found a quota which matches the config
-	// return in progress
-	if quotaReceiver.Name == "" {
-		return errors.New("wasn't able to find a quota in the quota config which matches the Quotaid")
-	}
 
 	retQuota.name = quotaReceiver.Name
 	retQuota.productConfigs = map[v1alpha1.ProductName]QuotaProductConfig{}
@@ -125,9 +123,93 @@ func GetQuota(QuotaId string, QuotaConfig *corev1.ConfigMap, retQuota *Quota, is
 
 	//populate rate limit configuration
 	retQuota.rateLimitConfig = quotaReceiver.RateLimit
+	retQuota.alertLimits = quotaReceiver.AlertLimits
 	return nil
 }
 
+// findQuotaConfig returns the configuration of the quota from its
+// QuotaProfile, or from the quota config map
+func findQuotaConfig(ctx context.Context, client k8sclient.Client, QuotaId string, QuotaConfig *corev1.ConfigMap) (quotaConfigReceiver, error) {
+	if QuotaId != "" {
+		profile := &v1alpha1.QuotaProfile{}
+		err := client.Get(ctx, k8sclient.ObjectKey{Name: QuotaId, Namespace: QuotaConfig.Namespace}, profile)
+		if err == nil {
+			return fromQuotaProfile(profile), nil
+		}
+		if !k8serr.IsNotFound(err) {
+			return quotaConfigReceiver{}, fmt.Errorf("failed to get quota profile %s: %w", QuotaId, err)
+		}
+	}
+
+	allQuotas := &[]quotaConfigReceiver{}
+	err := json.Unmarshal([]byte(QuotaConfig.Data[ConfigMapData]), allQuotas)
+	if err != nil {
+		return quotaConfigReceiver{}, err
+	}
+
+	for _, quota := range *allQuotas {
+		if quota.Name == QuotaId {
+			return quota, nil
+		}
+	}
+
+	// if the quota receiver is empty at this point we haven't found a quota which matches the config
+	// return in progress
+	return quotaConfigReceiver{}, errors.New("wasn't able to find a quota in the quota config which matches the Quotaid")
+}
+
+// fromQuotaProfile returns the configuration of the quota of the profile
+func fromQuotaProfile(profile *v1alpha1.QuotaProfile) quotaConfigReceiver {
+	receiver := quotaConfigReceiver{
+		Name: profile.Name,
+		RateLimit: marin3rconfig.RateLimitConfig{
+			Unit:            profile.Spec.RateLimit.Unit,
+			RequestsPerUnit: profile.Spec.RateLimit.RequestsPerUnit,
+			ShadowMode:      profile.Spec.RateLimit.ShadowMode,
+		},
+		Resources: map[string]ResourceConfig{},
+	}
+	for _, window := range profile.Spec.RateLimit.Windows {
+		receiver.RateLimit.Windows = append(receiver.RateLimit.Windows, marin3rconfig.RateLimitWindow{
+			Unit:            window.Unit,
+			RequestsPerUnit: window.RequestsPerUnit,
+		})
+	}
+	for name, resources := range profile.Spec.Resources {
+		config := ResourceConfig{
+			Replicas:  resources.Replicas,
+			Resources: *resources.Resources.DeepCopy(),
+		}
+		if resources.Autoscaling != nil {
+			config.Autoscaling = &AutoscalingConfig{
+				MinReplicas:          resources.Autoscaling.MinReplicas,
+				MaxReplicas:          resources.Autoscaling.MaxReplicas,
+				TargetCPUUtilization: resources.Autoscaling.TargetCPUUtilization,
+			}
+		}
+		receiver.Resources[name] = config
+	}
+	if len(profile.Spec.RateLimit.AlertLimits) > 0 {
+		receiver.AlertLimits = map[string]*marin3rconfig.AlertConfig{}
+	}
+	for name, limit := range profile.Spec.RateLimit.AlertLimits {
+		alert := &marin3rconfig.AlertConfig{
+			Type:     limit.Type,
+			Level:    limit.Level,
+			RuleName: limit.RuleName,
+			Period:   limit.Period,
+		}
+		if limit.Threshold != nil {
+			alert.Threshold = &marin3rconfig.AlertThresholdConfig{
+				MinRate: limit.Threshold.MinRate,
+				MaxRate: limit.Threshold.MaxRate,
+			}
+		}
+		receiver.AlertLimits[name] = alert
+	}
+	return receiver
+}
+
 func (s *Quota) GetProduct(productName v1alpha1.ProductName) QuotaProductConfig {
 	// handle product not found e.g. return nil?
 	return s.productConfigs[productName]
@@ -156,6 +238,11 @@ func (s *Quota) GetRateLimitConfig() marin3rconfig.RateLimitConfig {
 	return s.rateLimitConfig
 }
 
+// GetAlertLimits returns the rate limit alerts overridden by the quota
+func (p QuotaProductConfig) GetAlertLimits() map[string]*marin3rconfig.AlertConfig {
+	return p.quota.alertLimits
+}
+
 func (p QuotaProductConfig) GetActiveQuota() string {
 	return p.quota.name
 }
@@ -164,6 +251,13 @@ func (p QuotaProductConfig) GetReplicas(ddcssName string) int32 {
 	return p.resourceConfigs[ddcssName].Replicas
 }
 
+// GetAutoscaling returns the autoscaling of the workload, and false if its
+// replicas are static
+func (p QuotaProductConfig) GetAutoscaling(ddcssName string) (*AutoscalingConfig, bool) {
+	autoscaling := p.resourceConfigs[ddcssName].Autoscaling
+	return autoscaling, autoscaling != nil
+}
+
 func (p QuotaProductConfig) Configure(obj metav1.Object) error {
 	name := obj.GetName()
 
@@ -184,8 +278,9 @@ func (p QuotaProductConfig) Configure(obj metav1.Object) error {
 		p.mutatePodTemplate(&t.Spec.Template, name)
 		break
 	case *keycloak.Keycloak:
-		configReplicas := p.resourceConfigs[name].Replicas
-		if p.quota.isUpdated || t.Spec.Instances < int(configReplicas) {
+		if autoscaling, ok := p.GetAutoscaling(name); ok {
+			t.Spec.Instances = int(autoscaling.clamp(int32(t.Spec.Instances)))
+		} else if configReplicas := p.resourceConfigs[name].Replicas; p.quota.isUpdated || t.Spec.Instances < int(configReplicas) {
 			t.Spec.Instances = int(configReplicas)
 		}
 		resources := p.resourceConfigs[KeycloakName].Resources
@@ -237,6 +332,10 @@ func checkStatefulSetReplicas(deployment *appsv12.StatefulSet) {
 }
 
 func (p QuotaProductConfig) mutateAPIManagerReplicas(replicas *int64, name string) {
+	if autoscaling, ok := p.GetAutoscaling(name); ok {
+		*replicas = int64(autoscaling.clamp(int32(*replicas)))
+		return
+	}
 	configReplicas := p.resourceConfigs[name].Replicas
 	value := int64(configReplicas)
 	if p.quota.isUpdated || *replicas < value || *replicas == 0 {
@@ -251,6 +350,12 @@ func (p QuotaProductConfig) mutatePodTemplate(template *corev1.PodTemplateSpec,
 }
 
 func (p QuotaProductConfig) mutateReplicas(replicas *int32, name string) {
+	// The replicas of an autoscaled workload are set by its
+	// HorizontalPodAutoscaler, within the bounds of the quota
+	if autoscaling, ok := p.GetAutoscaling(name); ok {
+		*replicas = autoscaling.clamp(*replicas)
+		return
+	}
 	configReplicas := p.resourceConfigs[name].Replicas
 	if p.quota.isUpdated || *replicas < configReplicas || *replicas == 0 {
 		*replicas = configReplicas
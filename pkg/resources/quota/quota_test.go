@@ -1,6 +1,10 @@
 package quota
 
 import (
+	"context"
+	"reflect"
+	"testing"
+
 	threescalev1 "github.com/3scale/3scale-operator/pkg/apis/apps/v1alpha1"
 	"github.com/integr8ly/integreatly-operator/apis/v1alpha1"
 	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
@@ -10,8 +14,8 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"reflect"
-	"testing"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const (
@@ -28,6 +32,7 @@ func TestGetQuota(t *testing.T) {
 		QuotaConfig *corev1.ConfigMap
 		Quota       *Quota
 		isUpdated   bool
+		Objects     []runtime.Object
 	}
 	tests := []struct {
 		name     string
@@ -72,30 +77,30 @@ func TestGetQuota(t *testing.T) {
 									},
 								},
 							}
-							rcs[ApicastStagingName] = ResourceConfig{0, corev1.ResourceRequirements{}}
-							rcs[BackendListenerName] = ResourceConfig{0, corev1.ResourceRequirements{}}
-							rcs[BackendWorkerName] = ResourceConfig{0, corev1.ResourceRequirements{}}
+							rcs[ApicastStagingName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
+							rcs[BackendListenerName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
+							rcs[BackendWorkerName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
 						}),
 						quota: pointerToQuota,
 					},
 					v1alpha1.ProductGrafana: {
 						v1alpha1.ProductGrafana,
 						getResourceConfig(func(rcs map[string]ResourceConfig) {
-							rcs[GrafanaName] = ResourceConfig{0, corev1.ResourceRequirements{}}
+							rcs[GrafanaName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
 						}),
 						pointerToQuota,
 					},
 					v1alpha1.ProductMarin3r: {
 						v1alpha1.ProductMarin3r,
 						getResourceConfig(func(rcs map[string]ResourceConfig) {
-							rcs[RateLimitName] = ResourceConfig{0, corev1.ResourceRequirements{}}
+							rcs[RateLimitName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
 						}),
 						pointerToQuota,
 					},
 					v1alpha1.ProductRHSSOUser: {
 						v1alpha1.ProductRHSSOUser,
 						getResourceConfig(func(rcs map[string]ResourceConfig) {
-							rcs[KeycloakName] = ResourceConfig{0, corev1.ResourceRequirements{}}
+							rcs[KeycloakName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
 						}),
 						pointerToQuota,
 					},
@@ -141,30 +146,30 @@ func TestGetQuota(t *testing.T) {
 									},
 								},
 							}
-							rcs[ApicastStagingName] = ResourceConfig{0, corev1.ResourceRequirements{}}
-							rcs[ApicastProductionName] = ResourceConfig{0, corev1.ResourceRequirements{}}
-							rcs[BackendWorkerName] = ResourceConfig{0, corev1.ResourceRequirements{}}
+							rcs[ApicastStagingName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
+							rcs[ApicastProductionName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
+							rcs[BackendWorkerName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
 						}),
 						quota: pointerToQuota,
 					},
 					v1alpha1.ProductGrafana: {
 						v1alpha1.ProductGrafana,
 						getResourceConfig(func(rcs map[string]ResourceConfig) {
-							rcs[GrafanaName] = ResourceConfig{0, corev1.ResourceRequirements{}}
+							rcs[GrafanaName] = ResourceConfig{0, corev1.ResourceRequirements{}, nil}
 						}),
 						pointerToQuota,
 					},
 					v1alpha1.ProductMarin3r: {
 						productName: v1alpha1.ProductMarin3r,
 						resourceConfigs: map[string]ResourceConfig{
-							RateLimitName: {0, corev1.ResourceRequirements{}},
+							RateLimitName: {0, corev1.ResourceRequirements{}, nil},
 						},
 						quota: pointerToQuota,
 					},
 					v1alpha1.ProductRHSSOUser: {
 						productName: v1alpha1.ProductRHSSOUser,
 						resourceConfigs: map[string]ResourceConfig{
-							KeycloakName: {0, corev1.ResourceRequirements{}},
+							KeycloakName: {0, corev1.ResourceRequirements{}, nil},
 						},
 						quota: pointerToQuota,
 					},
@@ -186,10 +191,71 @@ func TestGetQuota(t *testing.T) {
 				// check the rate limit amounts
 			},
 		},
+		{
+			name: "test quota profile takes precedence over the config map",
+			args: args{
+				QuotaId:     DEVQUOTA,
+				QuotaConfig: getQuotaConfig(nil),
+				Quota:       &Quota{},
+				Objects: []runtime.Object{
+					&v1alpha1.QuotaProfile{
+						ObjectMeta: metav1.ObjectMeta{Name: DEVQUOTA},
+						Spec: v1alpha1.QuotaProfileSpec{
+							RateLimit: v1alpha1.QuotaProfileRateLimit{
+								Unit:            "hour",
+								RequestsPerUnit: 5000,
+								Windows: []v1alpha1.QuotaProfileRateLimitWindow{
+									{Unit: "second", RequestsPerUnit: 10},
+								},
+								AlertLimits: map[string]v1alpha1.QuotaProfileAlertLimit{
+									"api-usage-alert-level1": {
+										Type:      "Threshold",
+										Level:     "info",
+										RuleName:  "RHOAMApiUsageLevel1ThresholdExceeded",
+										Period:    "4h",
+										Threshold: &v1alpha1.QuotaProfileAlertThreshold{MinRate: "80%"},
+									},
+								},
+							},
+							Resources: map[string]v1alpha1.QuotaProfileResources{
+								ApicastProductionName: {Replicas: 4},
+							},
+						},
+					},
+				},
+			},
+			validate: func(quota *Quota, t *testing.T) {
+				if quota.GetName() != DEVQUOTA {
+					t.Errorf("Expected quota '%v' but got '%v'", DEVQUOTA, quota.GetName())
+				}
+				wantRateLimit := marin3rconfig.RateLimitConfig{
+					Unit:            "hour",
+					RequestsPerUnit: 5000,
+					Windows:         []marin3rconfig.RateLimitWindow{{Unit: "second", RequestsPerUnit: 10}},
+				}
+				if !reflect.DeepEqual(quota.GetRateLimitConfig(), wantRateLimit) {
+					t.Errorf("Expected rate limit '%v' but got '%v'", wantRateLimit, quota.GetRateLimitConfig())
+				}
+				product := quota.GetProduct(v1alpha1.Product3Scale)
+				if gotReplicas := product.GetReplicas(ApicastProductionName); gotReplicas != 4 {
+					t.Errorf("Expected apicast_production replicas to be '4' but got '%v'", gotReplicas)
+				}
+				alert, ok := product.GetAlertLimits()["api-usage-alert-level1"]
+				if !ok || alert.Threshold == nil || alert.Threshold.MinRate != "80%" {
+					t.Errorf("Expected the alert limits of the quota profile but got '%v'", product.GetAlertLimits())
+				}
+			},
+		},
+	}
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
 	}
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := GetQuota(tt.args.QuotaId, tt.args.QuotaConfig, tt.args.Quota, tt.args.isUpdated)
+			client := fake.NewFakeClientWithScheme(scheme, tt.args.Objects...)
+			err := GetQuota(context.TODO(), client, tt.args.QuotaId, tt.args.QuotaConfig, tt.args.Quota, tt.args.isUpdated)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GetQuota() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"fmt"
+	"regexp"
+
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+)
+
+/*
+	Defines the rate limits of the descriptors with a limit, each with the
+	actions of the descriptor and its parents. The additional windows of the
+	limit of a descriptor have their own rate limits, with the action of the
+	window after the actions of the descriptor
+	rateLimits:
+	- actions:
+	  - headerValueMatch:
+	      descriptorValue: tenant-a
+	      headers:
+	      - name: :authority
+	        safeRegexMatch:
+	          googleRe2: {}
+	          regex: ^[^.]+-tenant-a-apicast-(production|staging)\..*$
+	  - requestHeaders:
+	      descriptorKey: per-client
+	      headerName: x-client-id
+	  stage: 0
+	- actions:
+	  - headerValueMatch: &tenant-a
+	  - genericKey:
+	      descriptorValue: slowpath_day
+	  stage: 0
+*/
+func DescriptorRateLimits(descriptors []marin3rconfig.DescriptorConfig) []*route.RateLimit {
+	return descriptorRateLimits(descriptors, []*route.RateLimit_Action{})
+}
+
+func descriptorRateLimits(descriptors []marin3rconfig.DescriptorConfig, parentActions []*route.RateLimit_Action) []*route.RateLimit {
+	rateLimits := []*route.RateLimit{}
+	for _, descriptor := range descriptors {
+		actions := append(append([]*route.RateLimit_Action{}, parentActions...), descriptorAction(descriptor))
+		if descriptor.RateLimit != nil {
+			rateLimits = append(rateLimits, &route.RateLimit{
+				Stage:   &wrappers.UInt32Value{Value: 0},
+				Actions: actions,
+			})
+			for _, window := range descriptor.RateLimit.Windows {
+				rateLimits = append(rateLimits, &route.RateLimit{
+					Stage:   &wrappers.UInt32Value{Value: 0},
+					Actions: append(append([]*route.RateLimit_Action{}, actions...), windowAction(window.Unit)),
+				})
+			}
+		}
+		rateLimits = append(rateLimits, descriptorRateLimits(descriptor.Descriptors, actions)...)
+	}
+	return rateLimits
+}
+
+// descriptorAction returns the action that adds the descriptor entry of the
+// descriptor to the requests it matches
+func descriptorAction(descriptor marin3rconfig.DescriptorConfig) *route.RateLimit_Action {
+	if descriptor.PerHeaderValue() {
+		return &route.RateLimit_Action{
+			ActionSpecifier: &route.RateLimit_Action_RequestHeaders_{
+				RequestHeaders: &route.RateLimit_Action_RequestHeaders{
+					HeaderName:    descriptor.Match.Header,
+					DescriptorKey: descriptor.Name,
+				},
+			},
+		}
+	}
+
+	return &route.RateLimit_Action{
+		ActionSpecifier: &route.RateLimit_Action_HeaderValueMatch_{
+			HeaderValueMatch: &route.RateLimit_Action_HeaderValueMatch{
+				DescriptorValue: descriptor.Name,
+				ExpectMatch:     &wrappers.BoolValue{Value: true},
+				Headers:         []*route.HeaderMatcher{headerMatcher(descriptor.Match)},
+			},
+		},
+	}
+}
+
+func headerMatcher(match marin3rconfig.DescriptorMatch) *route.HeaderMatcher {
+	switch match.Type {
+	case marin3rconfig.DescriptorMatchAccount:
+		// The routes of the APIcast of an account are
+		// <service>-<account>-apicast-<environment>.<domain>
+		return regexHeaderMatcher(":authority",
+			fmt.Sprintf(`^[^.]+-%s-apicast-(production|staging)\..*$`, regexp.QuoteMeta(match.Value)))
+	case marin3rconfig.DescriptorMatchService:
+		// The path of the requests to the backend listener includes the
+		// query parameters
+		return regexHeaderMatcher(":path",
+			fmt.Sprintf(`^.*[?&]service_id=%s(&.*)?$`, regexp.QuoteMeta(match.Value)))
+	case marin3rconfig.DescriptorMatchPath:
+		return &route.HeaderMatcher{
+			Name:                 ":path",
+			HeaderMatchSpecifier: &route.HeaderMatcher_PrefixMatch{PrefixMatch: match.Value},
+		}
+	default:
+		return &route.HeaderMatcher{
+			Name:                 match.Header,
+			HeaderMatchSpecifier: &route.HeaderMatcher_ExactMatch{ExactMatch: match.Value},
+		}
+	}
+}
+
+func regexHeaderMatcher(name, regex string) *route.HeaderMatcher {
+	return &route.HeaderMatcher{
+		Name: name,
+		HeaderMatchSpecifier: &route.HeaderMatcher_SafeRegexMatch{
+			SafeRegexMatch: &matcher.RegexMatcher{
+				EngineType: &matcher.RegexMatcher_GoogleRe2{GoogleRe2: &matcher.RegexMatcher_GoogleRE2{}},
+				Regex:      regex,
+			},
+		},
+	}
+}
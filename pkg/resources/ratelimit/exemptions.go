@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+
+	envoyapi "github.com/envoyproxy/go-control-plane/envoy/api/v2"
+	envoycore "github.com/envoyproxy/go-control-plane/envoy/api/v2/core"
+	listener "github.com/envoyproxy/go-control-plane/envoy/api/v2/listener"
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	matcher "github.com/envoyproxy/go-control-plane/envoy/type/matcher"
+	"github.com/golang/protobuf/proto"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+)
+
+// ExemptClusterName returns the name of the cluster the exempted requests
+// are routed to. It has the endpoints of the cluster clusterName, so that
+// envoy counts the exempted requests on their own, as the
+// marin3rconfig.ExemptRequestsMetric of the exempt cluster
+func ExemptClusterName(clusterName string) string {
+	return clusterName + "-exempt"
+}
+
+/*
+	Defines the cluster of the exempted requests, a copy of the cluster under
+	the exempt cluster name, or nil if no traffic is exempted
+	- name: clusterName-exempt
+	  value: |
+		loadAssignment:
+		  clusterName: clusterName-exempt
+		  endpoints: &clusterEndpoints
+*/
+func ExemptClusterResource(cluster *envoyapi.Cluster, exemptions *marin3rconfig.ExemptionsConfig) *envoyapi.Cluster {
+	if exemptions.IsEmpty() {
+		return nil
+	}
+	exemptCluster := proto.Clone(cluster).(*envoyapi.Cluster)
+	exemptCluster.Name = ExemptClusterName(cluster.Name)
+	if exemptCluster.LoadAssignment != nil {
+		exemptCluster.LoadAssignment.ClusterName = exemptCluster.Name
+	}
+	return exemptCluster
+}
+
+/*
+	Defines the routes of the exempted paths and services, that take
+	precedence over the rate limited routes. The routes have no rate limits,
+	so the rate limit filter generates no descriptors for their requests and
+	doesn't call the rate limit service
+	routes:
+	- match:
+		prefix: /healthz
+	  route:
+		cluster: clusterName-exempt
+	- match:
+		prefix: /
+		queryParameters:
+		- name: service_id
+		  stringMatch:
+			exact: "2"
+	  route:
+		cluster: clusterName-exempt
+*/
+func ExemptRoutes(clusterName string, exemptions *marin3rconfig.ExemptionsConfig) []*route.Route {
+	routes := []*route.Route{}
+	if exemptions == nil {
+		return routes
+	}
+
+	for _, path := range exemptions.Paths {
+		routes = append(routes, exemptRoute(clusterName, &route.RouteMatch{
+			PathSpecifier: &route.RouteMatch_Prefix{Prefix: path},
+		}))
+	}
+	for _, service := range exemptions.Services {
+		routes = append(routes, exemptRoute(clusterName, &route.RouteMatch{
+			PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"},
+			QueryParameters: []*route.QueryParameterMatcher{{
+				Name: "service_id",
+				QueryParameterMatchSpecifier: &route.QueryParameterMatcher_StringMatch{
+					StringMatch: &matcher.StringMatcher{
+						MatchPattern: &matcher.StringMatcher_Exact{Exact: service},
+					},
+				},
+			}},
+		}))
+	}
+	return routes
+}
+
+/*
+	Defines the virtual host of the exempted hosts, or nil if there are none
+	virtualHosts:
+	- domains: &hosts
+	  name: clusterName-exempt
+	  routes:
+	  - match:
+		  prefix: /
+		route:
+		  cluster: clusterName-exempt
+*/
+func ExemptVirtualHost(clusterName string, exemptions *marin3rconfig.ExemptionsConfig) *route.VirtualHost {
+	if exemptions == nil || len(exemptions.Hosts) == 0 {
+		return nil
+	}
+	return ExemptAllVirtualHost(clusterName+"-exempt", exemptions.Hosts, clusterName)
+}
+
+// ExemptAllVirtualHost returns a virtual host that exempts every request to
+// the domains, routed to the exempt cluster of the cluster clusterName
+func ExemptAllVirtualHost(name string, domains []string, clusterName string) *route.VirtualHost {
+	return &route.VirtualHost{
+		Name:    name,
+		Domains: domains,
+		Routes: []*route.Route{
+			exemptRoute(clusterName, &route.RouteMatch{
+				PathSpecifier: &route.RouteMatch_Prefix{Prefix: "/"},
+			}),
+		},
+	}
+}
+
+/*
+	Defines the filter chain of the connections from the exempted source
+	addresses, or nil if there are none. The filters route every request
+	without rate limits. The source address is the address of the downstream
+	connection: the requests through an OpenShift route come from the
+	router, so the source CIDRs only exempt the connections from inside the
+	cluster
+	filterChains:
+	- filterChainMatch:
+		sourcePrefixRanges:
+		- addressPrefix: 10.128.0.0
+		  prefixLen: 14
+	  filters: &filters
+*/
+func ExemptFilterChain(exemptions *marin3rconfig.ExemptionsConfig, filters []*listener.Filter) (*listener.FilterChain, error) {
+	if exemptions == nil || len(exemptions.SourceCIDRs) == 0 {
+		return nil, nil
+	}
+
+	ranges := []*envoycore.CidrRange{}
+	for _, cidr := range exemptions.SourceCIDRs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exempted source CIDR %s: %w", cidr, err)
+		}
+		prefixLen, _ := ipNet.Mask.Size()
+		ranges = append(ranges, &envoycore.CidrRange{
+			AddressPrefix: ipNet.IP.String(),
+			PrefixLen:     &wrappers.UInt32Value{Value: uint32(prefixLen)},
+		})
+	}
+
+	return &listener.FilterChain{
+		FilterChainMatch: &listener.FilterChainMatch{
+			SourcePrefixRanges: ranges,
+		},
+		Filters: filters,
+	}, nil
+}
+
+func exemptRoute(clusterName string, match *route.RouteMatch) *route.Route {
+	return &route.Route{
+		Match: match,
+		Action: &route.Route_Route{
+			Route: &route.RouteAction{
+				ClusterSpecifier: &route.RouteAction_Cluster{
+					Cluster: ExemptClusterName(clusterName),
+				},
+			},
+		},
+	}
+}
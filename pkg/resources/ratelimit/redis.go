@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	// redisScanCount is the number of keys hinted to each SCAN command
+	redisScanCount = 1000
+	// redisMGetBatch is the maximum number of keys of each MGET command
+	redisMGetBatch = 500
+)
+
+// RedisCounters reads the counters of the rate limit service from the Redis
+// it stores them in. The rate limit service connects to Redis over plain TCP,
+// so does RedisCounters
+type RedisCounters struct {
+	// URL is the address of Redis, as <host>:<port>
+	URL string
+}
+
+// GetCounters returns the value of the counters whose key starts with prefix,
+// by key
+func (r *RedisCounters) GetCounters(ctx context.Context, prefix string) (map[string]uint64, error) {
+	options := []redis.DialOption{}
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		options = append(options, redis.DialReadTimeout(timeout), redis.DialWriteTimeout(timeout))
+	}
+	conn, err := redis.DialContext(ctx, "tcp", r.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to rate limit redis: %w", err)
+	}
+	defer conn.Close()
+
+	keys := []string{}
+	cursor := 0
+	for {
+		page, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", escapeRedisPattern(prefix)+"*", "COUNT", redisScanCount))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rate limit counters: %w", err)
+		}
+		pageKeys := []string{}
+		if _, err := redis.Scan(page, &cursor, &pageKeys); err != nil {
+			return nil, fmt.Errorf("unexpected SCAN reply: %w", err)
+		}
+		keys = append(keys, pageKeys...)
+		if cursor == 0 {
+			break
+		}
+	}
+
+	counters := map[string]uint64{}
+	for start := 0; start < len(keys); start += redisMGetBatch {
+		end := start + redisMGetBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		args := make([]interface{}, 0, end-start)
+		for _, key := range keys[start:end] {
+			args = append(args, key)
+		}
+		values, err := redis.Values(conn.Do("MGET", args...))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rate limit counters: %w", err)
+		}
+		if len(values) != end-start {
+			return nil, fmt.Errorf("unexpected MGET reply of %d values for %d keys", len(values), end-start)
+		}
+		for i, value := range values {
+			// The counter expired between the SCAN and the MGET
+			if value == nil {
+				continue
+			}
+			count, err := redis.Uint64(value, nil)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of rate limit counter %s: %w", keys[start+i], err)
+			}
+			counters[keys[start+i]] = count
+		}
+	}
+
+	return counters, nil
+}
+
+// escapeRedisPattern escapes the special characters of the glob-style
+// patterns of Redis
+func escapeRedisPattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+	return replacer.Replace(s)
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	route "github.com/envoyproxy/go-control-plane/envoy/api/v2/route"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	marin3rconfig "github.com/integr8ly/integreatly-operator/pkg/products/marin3r/config"
+)
+
+// WindowDescriptorValue returns the value of the descriptor of the
+// additional window of the unit, such as slowpath_day
+func WindowDescriptorValue(unit string) string {
+	return fmt.Sprintf("%s_%s", RateLimitDescriptorValue, unit)
+}
+
+/*
+	Defines the rate limits of the additional windows, shared by all the
+	requests like the 3scale rate limit descriptor
+	rateLimits:
+	- actions:
+	- genericKey:
+		descriptorValue: slowpath_day
+*/
+func WindowRateLimits(windows []marin3rconfig.RateLimitWindow) []*route.RateLimit {
+	rateLimits := []*route.RateLimit{}
+	for _, window := range windows {
+		rateLimits = append(rateLimits, &route.RateLimit{
+			Stage:   &wrappers.UInt32Value{Value: 0},
+			Actions: []*route.RateLimit_Action{windowAction(window.Unit)},
+		})
+	}
+	return rateLimits
+}
+
+// windowAction returns the action that adds the descriptor entry of the
+// window of the unit to the requests
+func windowAction(unit string) *route.RateLimit_Action {
+	return &route.RateLimit_Action{
+		ActionSpecifier: &route.RateLimit_Action_GenericKey_{
+			GenericKey: &route.RateLimit_Action_GenericKey{
+				DescriptorValue: WindowDescriptorValue(unit),
+			},
+		},
+	}
+}
@@ -160,7 +160,7 @@ func (r *Reconciler) ReconcileNamespace(ctx context.Context, namespace string, i
 type finalizerFunc func() (integreatlyv1alpha1.StatusPhase, error)
 
 func (r *Reconciler) ReconcileFinalizer(ctx context.Context, client k8sclient.Client, inst *integreatlyv1alpha1.RHMI, productName string, finalFunc finalizerFunc, log l.Logger) (integreatlyv1alpha1.StatusPhase, error) {
-	finalizer := productName + ".integreatly.org" + "/finalizer"
+	finalizer := GetProductFinalizer(productName)
 
 	// Replace finalizers with the new format finalizers
 	err := UpdateFinalizer(ctx, inst, client, productName, finalizer, log)
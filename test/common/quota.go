@@ -404,7 +404,7 @@ func getQuotaconfig(t TestingTB, c k8sclient.Client) (*quota.Quota, string, erro
 	}
 
 	quotaConfig := &quota.Quota{}
-	err = quota.GetQuota(quotaName, quotaConfigMap, quotaConfig, false)
+	err = quota.GetQuota(context.TODO(), c, quotaName, quotaConfigMap, quotaConfig, false)
 	if err != nil {
 		t.Fatal("failed to get quota config map, skipping test for now until fully implemented", err)
 		return nil, "", err
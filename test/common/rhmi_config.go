@@ -32,81 +32,134 @@ type MaintenanceBackup struct {
 
 // this state check covers test case - A22
 // verify that the RHMIConfig validation webhook for Maintenance and Backup values work as expected
-var maintenanceBackupStates = map[MaintenanceBackup]func(TestingTB) func(error) error{
+var maintenanceBackupStates = []struct {
+	State     MaintenanceBackup
+	Assertion func(TestingTB) func(error) error
+}{
 	// we expect no error as blank strings will be set to default vals
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "",
-		},
-	}: assertNoError,
+		Assertion: assertNoError,
+	},
 	// valid input format hh:mm and ddd hh:mm
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "20:05",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "20:05",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "Sun 22:10",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "Sun 22:10",
-		},
-	}: assertNoError,
+		Assertion: assertNoError,
+	},
 	// we expect an error due to both times being parsed as a 1 hour window
 	// for aws these windows can not overlap
 	// this state provides overlapping times
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "20:05",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "20:05",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "Sun 20:15",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "Sun 20:15",
-		},
-	}: assertValidationError,
+		Assertion: assertValidationError,
+	},
 	// another overlap check, we want to ensure we get an error from a single minute overlap
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "20:15",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "20:15",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "Thu 19:16",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "Thu 19:16",
-		},
-	}: assertValidationError,
+		Assertion: assertValidationError,
+	},
 	// we expect the following :
 	//  * Backup hh:mm
 	//  * Maintenance ddd hh:mm
 	// the following checks will verify malformed times
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "26:00",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "26:00",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "Sun 12:05",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "Sun 12:05",
-		},
-	}: assertValidationError,
+		Assertion: assertValidationError,
+	},
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "22:00",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "22:00",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "Malformed 12:05",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "Malformed 12:05",
+		Assertion: assertValidationError,
+	},
+	{
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "malformed",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "Sun 20:00",
+			},
 		},
-	}: assertValidationError,
+		Assertion: assertValidationError,
+	},
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "malformed",
+		State: MaintenanceBackup{
+			Backup: v1alpha1.Backup{
+				ApplyOn: "20:00",
+			},
+			Maintenance: v1alpha1.Maintenance{
+				ApplyFrom: "malformed",
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "Sun 20:00",
+		Assertion: assertValidationError,
+	},
+	// additional windows and blackouts must be correctly formatted
+	{
+		State: MaintenanceBackup{
+			Maintenance: v1alpha1.Maintenance{
+				Windows:   []v1alpha1.MaintenanceWindow{{ApplyFrom: "Wed 10:00", Duration: "2h"}},
+				Blackouts: []v1alpha1.BlackoutPeriod{{From: "20 Dec 2030 00:00", To: "4 Jan 2031 00:00", Reason: "holidays"}},
+			},
 		},
-	}: assertValidationError,
+		Assertion: assertNoError,
+	},
 	{
-		Backup: v1alpha1.Backup{
-			ApplyOn: "20:00",
+		State: MaintenanceBackup{
+			Maintenance: v1alpha1.Maintenance{
+				Windows: []v1alpha1.MaintenanceWindow{{ApplyFrom: "Wed 10:00", Duration: "25h"}},
+			},
 		},
-		Maintenance: v1alpha1.Maintenance{
-			ApplyFrom: "malformed",
+		Assertion: assertValidationError,
+	},
+	{
+		State: MaintenanceBackup{
+			Maintenance: v1alpha1.Maintenance{
+				Blackouts: []v1alpha1.BlackoutPeriod{{From: "4 Jan 2031 00:00", To: "20 Dec 2030 00:00"}},
+			},
 		},
-	}: assertValidationError,
+		Assertion: assertValidationError,
+	},
 }
 
 var upgradeSectionStates = map[v1alpha1.Upgrade]func(TestingTB) func(error) error{
@@ -185,11 +238,14 @@ func TestRHMIConfigCRs(t TestingTB, ctx *TestingContext) {
 	}
 
 	// test for possible state changes for the Backup and Maintenance section
-	for state, assertion := range maintenanceBackupStates {
+	for _, maintenanceBackupState := range maintenanceBackupStates {
+		state, assertion := maintenanceBackupState.State, maintenanceBackupState.Assertion
 
 		err := wait.Poll(pollInterval, pollTimeout, func() (done bool, err error) {
 			newErr := verifyRHMIConfigValidation(ctx.Client, assertion(t), func(cr *v1alpha1.RHMIConfig) {
 				cr.Spec.Maintenance.ApplyFrom = state.Maintenance.ApplyFrom
+				cr.Spec.Maintenance.Windows = state.Maintenance.Windows
+				cr.Spec.Maintenance.Blackouts = state.Maintenance.Blackouts
 				cr.Spec.Backup.ApplyOn = state.Backup.ApplyOn
 			})
 			if newErr != nil {